@@ -5,11 +5,15 @@ package clog
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/golang/glog"
@@ -34,6 +38,12 @@ const (
 	orchestrator  = "orchestrator"
 )
 
+// Log output formats
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
 // Verbose is a boolean type that implements Infof (like Printf) etc.
 type Verbose bool
 
@@ -41,6 +51,8 @@ var stdKeys map[string]bool
 var stdKeysOrder = []string{manifestID, sessionID, nonce, seqNo, orchSessionID, ethaddress, orchestrator}
 var publicLogKeys = []string{manifestID, sessionID, orchSessionID, ClientIP, seqNo, ethaddress, orchestrator}
 
+var jsonFormat atomic.Bool
+
 func init() {
 	stdKeys = make(map[string]bool)
 	for _, key := range stdKeysOrder {
@@ -130,10 +142,34 @@ func GetVal(ctx context.Context, key string) string {
 	return val
 }
 
+// SetFormat switches the output of the logs between the text and the JSON format
+func SetFormat(format string) error {
+	switch format {
+	case TextFormat:
+		jsonFormat.Store(false)
+	case JSONFormat:
+		jsonFormat.Store(true)
+	default:
+		return fmt.Errorf("invalid log format %q, must be %q or %q", format, TextFormat, JSONFormat)
+	}
+	return nil
+}
+
+func GetFormat() string {
+	if jsonFormat.Load() {
+		return JSONFormat
+	}
+	return TextFormat
+}
+
 func Warningf(ctx context.Context, format string, args ...interface{}) {
 	if !glog.V(2) {
 		return
 	}
+	if jsonFormat.Load() {
+		logJSON(ctx, "warning", 1, false, false, format, args...)
+		return
+	}
 	msg, _ := formatMessage(ctx, false, false, format, args...)
 	glog.WarningDepth(1, msg)
 }
@@ -142,6 +178,10 @@ func Errorf(ctx context.Context, format string, args ...interface{}) {
 	if !glog.V(1) {
 		return
 	}
+	if jsonFormat.Load() {
+		logJSON(ctx, "error", 1, false, false, format, args...)
+		return
+	}
 	msg, _ := formatMessage(ctx, false, false, format, args...)
 	glog.ErrorDepth(1, msg)
 }
@@ -178,6 +218,15 @@ func (v Verbose) InfofErr(ctx context.Context, format string, args ...interface{
 }
 
 func infof(ctx context.Context, lastErr bool, publicLog bool, format string, args ...interface{}) {
+	if jsonFormat.Load() {
+		isErr := lastErr && len(args) > 0 && args[len(args)-1] != nil
+		if bool(glog.V(2)) && isErr {
+			logJSON(ctx, "error", 2, lastErr, publicLog, format, args...)
+		} else if glog.V(1) {
+			logJSON(ctx, "info", 2, lastErr, publicLog, format, args...)
+		}
+		return
+	}
 	msg, isErr := formatMessage(ctx, lastErr, publicLog, format, args...)
 	if bool(glog.V(2)) && isErr {
 		glog.ErrorDepth(2, msg)
@@ -232,6 +281,59 @@ func formatMessage(ctx context.Context, lastErr bool, publicLog bool, format str
 	return sb.String(), err != nil
 }
 
+// formatJSON formats a log line as a JSON object with the key/value pairs of
+// the context as fields
+func formatJSON(ctx context.Context, level, caller string, lastErr bool, publicLog bool, format string, args ...interface{}) []byte {
+	entry := make(map[string]interface{})
+	if ctx != nil {
+		if cmap, _ := ctx.Value(clogContextKey).(*values); cmap != nil {
+			cmap.mu.RLock()
+			for key, val := range cmap.vals {
+				entry[key] = val
+				if key == seqNo || key == nonce {
+					if n, err := strconv.ParseUint(val, 10, 64); err == nil {
+						entry[key] = n
+					}
+				}
+			}
+			cmap.mu.RUnlock()
+		}
+	}
+	var err interface{}
+	if lastErr && len(args) > 0 {
+		err = args[len(args)-1]
+		args = args[:len(args)-1]
+	}
+	if err != nil {
+		entry["err"] = fmt.Sprint(err)
+	}
+	entry["level"] = level
+	entry["time"] = time.Now().UTC().Format(time.RFC3339Nano)
+	entry["caller"] = caller
+	entry["public"] = publicLog
+	entry["msg"] = fmt.Sprintf(format, args...)
+	b, _ := json.Marshal(entry)
+	return b
+}
+
+// logJSON logs the JSON object of a log line through glog, so that it is
+// written to the same destinations as the text format
+func logJSON(ctx context.Context, level string, depth int, lastErr bool, publicLog bool, format string, args ...interface{}) {
+	caller := "???:1"
+	if _, file, line, ok := runtime.Caller(depth + 1); ok {
+		caller = filepath.Base(file) + ":" + strconv.Itoa(line)
+	}
+	msg := string(formatJSON(ctx, level, caller, lastErr, publicLog, format, args...))
+	switch level {
+	case "error":
+		glog.ErrorDepth(depth+1, msg)
+	case "warning":
+		glog.WarningDepth(depth+1, msg)
+	default:
+		glog.InfoDepth(depth+1, msg)
+	}
+}
+
 func PublicInfof(ctx context.Context, format string, args ...interface{}) {
 	publicCtx := context.Background()
 
@@ -1,11 +1,17 @@
 package clog
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"flag"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStdKeys(t *testing.T) {
@@ -82,3 +88,61 @@ func TestPublicLogs(t *testing.T) {
 	msg, _ := formatMessage(ctx, false, true, "testing message num=%d", 123)
 	assert.Equal("[PublicLogs] manifestID=fooManID sessionID=fooSessionID nonce=999 seqNo=555 orchSessionID=fooOrchID ethaddress=0x0 orchestrator=http://127.0.0.1:8935 foo=Bar testing message num=123", msg)
 }
+
+func TestJSONFormat(t *testing.T) {
+	assert := assert.New(t)
+	ctx := AddManifestID(context.Background(), "manID")
+	ctx = AddSeqNo(ctx, 9427)
+	ctx = AddNonce(ctx, 1038)
+	ctx = AddVal(ctx, ClientIP, "127.0.0.1")
+	ctx = AddVal(ctx, "customKey", "customVal")
+
+	var entry map[string]interface{}
+	b := formatJSON(ctx, "error", "clog_test.go:1", true, false, "testing message num=%d", 452, errors.New("test error"))
+	assert.Nil(json.Unmarshal(b, &entry))
+	assert.Equal("manID", entry["manifestID"])
+	assert.Equal(float64(9427), entry["seqNo"])
+	assert.Equal(float64(1038), entry["nonce"])
+	assert.Equal("127.0.0.1", entry["clientIP"])
+	assert.Equal("customVal", entry["customKey"])
+	assert.Equal("error", entry["level"])
+	assert.Equal("clog_test.go:1", entry["caller"])
+	assert.Equal(false, entry["public"])
+	assert.Equal("testing message num=452", entry["msg"])
+	assert.Equal("test error", entry["err"])
+	assert.NotEmpty(entry["time"])
+
+	// Switch the output at runtime; the lines are written through glog
+	stderr, err := os.CreateTemp(t.TempDir(), "stderr")
+	require.Nil(t, err)
+	oldStderr, oldToStderr := os.Stderr, flag.Lookup("logtostderr").Value.String()
+	defer func() {
+		os.Stderr = oldStderr
+		flag.Set("logtostderr", oldToStderr)
+		SetFormat(TextFormat)
+	}()
+	os.Stderr = stderr
+	require.Nil(t, flag.Set("logtostderr", "true"))
+	assert.Nil(SetFormat(JSONFormat))
+	assert.Equal(JSONFormat, GetFormat())
+	ctx = AddVal(ctx, "foo", "Bar")
+	PublicInfof(ctx, "testing message num=%d", 123)
+	out, err := os.ReadFile(stderr.Name())
+	require.Nil(t, err)
+	line := string(bytes.TrimSpace(out))
+	assert.True(strings.HasPrefix(line, "I"))
+	assert.Contains(line, "clog_test.go:")
+	entry = nil
+	assert.Nil(json.Unmarshal([]byte(line[strings.Index(line, "] ")+2:]), &entry))
+	assert.Equal(true, entry["public"])
+	assert.Equal("info", entry["level"])
+	assert.Equal("manID", entry["manifestID"])
+	assert.Contains(entry["caller"], "clog_test.go:")
+	// Private keys are not published
+	assert.NotContains(entry, "nonce")
+	assert.NotContains(entry, "foo")
+
+	assert.Nil(SetFormat(TextFormat))
+	assert.Equal(TextFormat, GetFormat())
+	assert.EqualError(SetFormat("xml"), `invalid log format "xml", must be "text" or "json"`)
+}
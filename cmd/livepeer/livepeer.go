@@ -15,6 +15,7 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 
+	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/cmd/livepeer/starter"
 	"github.com/livepeer/livepeer-data/pkg/mistconnector"
 	"github.com/peterbourgon/ff/v3"
@@ -37,6 +38,7 @@ func main() {
 	mistJSON := flag.Bool("j", false, "Print application info as json")
 	version := flag.Bool("version", false, "Print out the version")
 	verbosity := flag.String("v", "3", "Log verbosity.  {4|5|6}")
+	logFormat := flag.String("logFormat", clog.TextFormat, "Log format. {text|json}")
 
 	cfg := parseLivepeerConfig()
 
@@ -52,6 +54,9 @@ func main() {
 	}
 
 	vFlag.Value.Set(*verbosity)
+	if err := clog.SetFormat(*logFormat); err != nil {
+		glog.Exit(err)
+	}
 
 	if *mistJSON {
 		mistconnector.PrintMistConfigJson(
@@ -118,14 +123,19 @@ func parseLivepeerConfig() starter.LivepeerConfig {
 	cfg.CliAddr = flag.String("cliAddr", *cfg.CliAddr, "Address to bind for  CLI commands")
 	cfg.HttpAddr = flag.String("httpAddr", *cfg.HttpAddr, "Address to bind for HTTP commands")
 	cfg.ServiceAddr = flag.String("serviceAddr", *cfg.ServiceAddr, "Orchestrator only. Overrides the on-chain serviceURI that broadcasters can use to contact this node; may be an IP or hostname.")
-	cfg.VerifierURL = flag.String("verifierUrl", *cfg.VerifierURL, "URL of the verifier to use")
+	cfg.VerifierURL = flag.String("verifierUrl", *cfg.VerifierURL, "URL of the verifier to use. Cannot be combined with -verifyQuality or -localFastVerification")
 	cfg.VerifierPath = flag.String("verifierPath", *cfg.VerifierPath, "Path to verifier shared volume")
+	cfg.VerifyQuality = flag.String("verifyQuality", *cfg.VerifyQuality, "Comma separated SSIM and PSNR thresholds for in-process quality verification, optionally per profile. Example: ssim=0.9,psnr=30,P144p30fps16x9:psnr=25")
+	cfg.EvidenceStore = flag.String("evidenceStore", *cfg.EvidenceStore, "URL of object store for evidence of segments that fail verification. Evidence is always stored in the node database")
+	cfg.FailurePenalty = flag.Duration("failurePenalty", *cfg.FailurePenalty, "Duration for which an orchestrator is excluded from discovery for every fatal verification failure")
 	cfg.LocalVerify = flag.Bool("localVerify", *cfg.LocalVerify, "Set to true to enable local verification i.e. pixel count and signature verification.")
 	cfg.HttpIngest = flag.Bool("httpIngest", *cfg.HttpIngest, "Set to true to enable HTTP ingest")
 
 	// Broadcaster's Selection Algorithm
 	cfg.OrchAddr = flag.String("orchAddr", *cfg.OrchAddr, "Comma-separated list of orchestrators to connect to")
 	cfg.OrchWebhookURL = flag.String("orchWebhookUrl", *cfg.OrchWebhookURL, "Orchestrator discovery callback URL")
+	cfg.OrchRegistryURL = flag.String("orchRegistryUrl", *cfg.OrchRegistryURL, "URL of a signed orchestrator registry document used for discovery")
+	cfg.OrchRegistryKey = flag.String("orchRegistryKey", *cfg.OrchRegistryKey, "ETH address of the trusted key that signs the orchestrator registry")
 	cfg.OrchBlacklist = flag.String("orchBlocklist", "", "Comma-separated list of blocklisted orchestrators")
 	cfg.OrchMinLivepeerVersion = flag.String("orchMinLivepeerVersion", *cfg.OrchMinLivepeerVersion, "Minimal go-livepeer version orchestrator should have to be selected")
 	cfg.SelectRandWeight = flag.Float64("selectRandFreq", *cfg.SelectRandWeight, "Weight of the random factor in the orchestrator selection algorithm")
@@ -133,7 +143,15 @@ func parseLivepeerConfig() starter.LivepeerConfig {
 	cfg.SelectPriceWeight = flag.Float64("selectPriceWeight", *cfg.SelectPriceWeight, "Weight of the price factor in the orchestrator selection algorithm")
 	cfg.SelectPriceExpFactor = flag.Float64("selectPriceExpFactor", *cfg.SelectPriceExpFactor, "Expresses how significant a small change of price is for the selection algorithm; default 100")
 	cfg.OrchPerfStatsURL = flag.String("orchPerfStatsUrl", *cfg.OrchPerfStatsURL, "URL of Orchestrator Performance Stream Tester")
-	cfg.Region = flag.String("region", *cfg.Region, "Region in which a broadcaster is deployed; used to select the region while using the orchestrator's performance stats")
+	cfg.Region = flag.String("region", *cfg.Region, "Region in which a broadcaster is deployed; used to select the region while using the orchestrator's performance stats and to prefer orchestrators in the same region")
+	cfg.OrchPingInterval = flag.Duration("orchPingInterval", *cfg.OrchPingInterval, "How often a broadcaster pings orchestrators to measure its round trip time to them and prefer nearby orchestrators in discovery. Set to 0 to disable")
+	cfg.OrchMaxRTT = flag.Duration("orchMaxRTT", *cfg.OrchMaxRTT, "Orchestrators with a higher round trip time are only used when there aren't enough nearby orchestrators. Set to 0 to disable")
+	cfg.OrchInfoCacheTTL = flag.Duration("orchInfoCacheTTL", *cfg.OrchInfoCacheTTL, "How long a broadcaster caches the capabilities and price of on-chain orchestrators to only query the ones matching a stream during discovery. Set to 0 to disable")
+	cfg.OrchGeoIPFile = flag.String("orchGeoIPFile", *cfg.OrchGeoIPFile, "CSV file of network,region lines used to locate orchestrators that have no region tags in the orchestrator registry")
+	cfg.GossipAddr = flag.String("gossipAddr", *cfg.GossipAddr, "Address to listen on for orchestrator gossip from other broadcasters; default port "+starter.GossipPort)
+	cfg.GossipPeers = flag.String("gossipPeers", *cfg.GossipPeers, "Comma-separated list of broadcasters to gossip orchestrators with, formatted as <ETH address>@<host>:<port>. Off-chain only")
+	cfg.GossipKeyFile = flag.String("gossipKeyFile", *cfg.GossipKeyFile, "File of the hex encoded key that signs gossip; created if it doesn't exist. Defaults to gossip.key in the data dir")
+	cfg.GossipTrustWeight = flag.Float64("gossipTrustWeight", *cfg.GossipTrustWeight, "Weight of the observations of each gossip peer relative to the broadcaster's own observations")
 	cfg.MaxPricePerUnit = flag.String("maxPricePerUnit", *cfg.MaxPricePerUnit, "The maximum transcoding price per 'pixelsPerUnit' a broadcaster is willing to accept. If not set explicitly, broadcaster is willing to accept ANY price. Can be specified in wei or a custom currency in the format <price><currency> (e.g. 0.50USD). When using a custom currency, a corresponding price feed must be configured with -priceFeedAddr")
 	cfg.MinPerfScore = flag.Float64("minPerfScore", *cfg.MinPerfScore, "The minimum orchestrator's performance score a broadcaster is willing to accept")
 
@@ -145,11 +163,25 @@ func parseLivepeerConfig() starter.LivepeerConfig {
 	cfg.OrchSecret = flag.String("orchSecret", *cfg.OrchSecret, "Shared secret with the orchestrator as a standalone transcoder or path to file")
 	cfg.TranscodingOptions = flag.String("transcodingOptions", *cfg.TranscodingOptions, "Transcoding options for broadcast job, or path to json config")
 	cfg.MaxAttempts = flag.Int("maxAttempts", *cfg.MaxAttempts, "Maximum transcode attempts")
+	cfg.LadderSplit = flag.Int("ladderSplit", *cfg.LadderSplit, "Number of orchestrators to split the rendition ladder of each segment across")
+	cfg.RaceSessions = flag.Int("raceSessions", *cfg.RaceSessions, "Number of orchestrators to send each segment to, using the first results that are returned. Untrusted orchestrators only race for segments whose results are verified")
+	cfg.RaceMaxCost = flag.Float64("raceMaxCost", *cfg.RaceMaxCost, "Maximum cost of racing a segment as a multiple of the cost of transcoding it once. Orchestrators that lose a race are paid for the segment. 0 means no maximum")
 	cfg.MaxSessions = flag.String("maxSessions", *cfg.MaxSessions, "Maximum number of concurrent transcoding sessions for Orchestrator or 'auto' for dynamic limit, maximum number of RTMP streams for Broadcaster, or maximum capacity for transcoder.")
 	cfg.CurrentManifest = flag.Bool("currentManifest", *cfg.CurrentManifest, "Expose the currently active ManifestID as \"/stream/current.m3u8\"")
 	cfg.Nvidia = flag.String("nvidia", *cfg.Nvidia, "Comma-separated list of Nvidia GPU device IDs (or \"all\" for all available devices)")
 	cfg.Netint = flag.String("netint", *cfg.Netint, "Comma-separated list of NetInt device GUIDs (or \"all\" for all available devices)")
+	cfg.CPUs = flag.String("cpus", *cfg.CPUs, "Comma-separated list of CPU IDs used for software transcoding (or \"all\" for all available CPUs). If none of -cpus, -cpuThreads and -cpuPinning is set, software transcoding is not limited to CPU cores")
+	cfg.CPUThreads = flag.Int("cpuThreads", *cfg.CPUThreads, "Number of CPUs reserved for transcoding a segment of a session with software transcoding and threads used to encode it. If 0, one CPU is reserved and the encoder picks its number of threads")
+	cfg.CPUPinning = flag.Bool("cpuPinning", *cfg.CPUPinning, "Pin software transcoding of a segment to the CPUs reserved for it. Linux only")
 	cfg.TestTranscoder = flag.Bool("testTranscoder", *cfg.TestTranscoder, "Test Nvidia GPU transcoding at startup")
+	cfg.TranscoderPixelCapacity = flag.Int64("transcoderPixelCapacity", *cfg.TranscoderPixelCapacity, "Output pixels per second a standalone transcoder can encode, used by the orchestrator to schedule sessions. If 0, it is measured with a benchmark at startup. If negative, the orchestrator schedules sessions by -maxSessions only")
+	cfg.TranscoderGracePeriod = flag.Duration("transcoderGracePeriod", *cfg.TranscoderGracePeriod, "Time an orchestrator keeps the sessions of a disconnected standalone transcoder for it to reconnect and resume them. Segments are transcoded by other transcoders in the meantime. Set to 0 to end the sessions immediately")
+	cfg.TranscoderPull = flag.Bool("transcoderPull", *cfg.TranscoderPull, "Lease segments from the orchestrator over HTTP instead of receiving them on the registration stream. Useful for standalone transcoders behind NAT or on preemptible instances")
+	cfg.TranscoderLeaseTimeout = flag.Duration("transcoderLeaseTimeout", *cfg.TranscoderLeaseTimeout, "Time an orchestrator waits for the results of a segment leased by a pull mode transcoder before queueing the segment again. Capped at half of the time the orchestrator waits for the results of the segment")
+	cfg.TranscodeCacheSize = flag.Int64("transcodeCacheSize", *cfg.TranscodeCacheSize, "Maximum size in bytes of the transcoded segments an orchestrator caches to return them again for the same source segment and profiles. Set to 0 to disable the cache")
+	cfg.TranscodeCacheFee = flag.Int64("transcodeCacheFee", *cfg.TranscodeCacheFee, "Percentage of the regular fee an orchestrator charges for transcoded segments returned from the cache")
+	cfg.ReservationFee = flag.Int64("reservationFee", *cfg.ReservationFee, "Percentage of the regular fee of transcoding the reserved sessions for their whole time window that an orchestrator requires as prepayment for a capacity reservation. Must be > 0 because reservations are only accepted with a prepayment")
+	cfg.TranscoderDrainTimeout = flag.Duration("transcoderDrainTimeout", *cfg.TranscoderDrainTimeout, "Maximum time for a standalone transcoder to wait for its sessions to finish or be migrated when it receives SIGTERM. Set to 0 to exit immediately")
 
 	// Onchain:
 	cfg.EthAcctAddr = flag.String("ethAcctAddr", *cfg.EthAcctAddr, "Existing Eth account address. For use when multiple ETH accounts exist in the keystore directory")
@@ -181,17 +213,28 @@ func parseLivepeerConfig() starter.LivepeerConfig {
 	cfg.AutoAdjustPrice = flag.Bool("autoAdjustPrice", *cfg.AutoAdjustPrice, "Enable/disable automatic price adjustments based on the overhead for redeeming tickets")
 	cfg.PricePerGateway = flag.String("pricePerGateway", *cfg.PricePerGateway, `json list of price per gateway or path to json config file. Example: {"broadcasters":[{"ethaddress":"address1","priceperunit":0.5,"currency":"USD","pixelsperunit":1000000000000},{"ethaddress":"address2","priceperunit":0.3,"currency":"USD","pixelsperunit":1000000000000}]}`)
 	cfg.PricePerBroadcaster = flag.String("pricePerBroadcaster", *cfg.PricePerBroadcaster, `json list of price per broadcaster or path to json config file. Example: {"broadcasters":[{"ethaddress":"address1","priceperunit":0.5,"currency":"USD","pixelsperunit":1000000000000},{"ethaddress":"address2","priceperunit":0.3,"currency":"USD","pixelsperunit":1000000000000}]}`)
+	cfg.PriorityPrices = flag.String("priorityPrices", *cfg.PriorityPrices, "Comma separated price multipliers by stream priority class. The segments of a class with a price are charged the price of the class and only these classes can preempt the sessions of lower priority streams. Example: low=0.5,high=1.5")
 	// Interval to poll for blocks
 	cfg.BlockPollingInterval = flag.Int("blockPollingInterval", *cfg.BlockPollingInterval, "Interval in seconds at which different blockchain event services poll for blocks")
+	cfg.ReplayFromBlock = flag.Int("replayFromBlock", *cfg.ReplayFromBlock, "Block number from which to replay events to rebuild the state of unbonding locks, senders, orchestrators and polls. Set to 0 to disable")
+	// Governance
+	cfg.PollCreatorAddr = flag.String("pollCreatorAddr", *cfg.PollCreatorAddr, "ETH address of the PollCreator contract. Set to watch for polls and votes and serve them on /polls")
 	// Redemption service
 	cfg.Redeemer = flag.Bool("redeemer", *cfg.Redeemer, "Set to true to run a ticket redemption service")
 	cfg.RedeemerAddr = flag.String("redeemerAddr", *cfg.RedeemerAddr, "URL of the ticket redemption service to use")
 	// Reward service
 	cfg.Reward = flag.Bool("reward", false, "Set to true to run a reward service")
+	cfg.RewardMaxGasPrice = flag.Int("rewardMaxGasPrice", *cfg.RewardMaxGasPrice, "Gas price in wei above which the reward call is deferred until -rewardDeadlineBlocks. Set to 0 to call reward immediately")
+	cfg.RewardDeadlineBlocks = flag.Int("rewardDeadlineBlocks", *cfg.RewardDeadlineBlocks, "Number of L1 blocks into the round after which reward is called regardless of the gas price")
+	cfg.RewardMaxRetries = flag.Int("rewardMaxRetries", *cfg.RewardMaxRetries, "Number of times a failed reward call is retried within a round")
+	cfg.RewardGasPriceBump = flag.Int("rewardGasPriceBump", *cfg.RewardGasPriceBump, "Percentage by which the gas price of the reward transaction is raised on each retry, capped at -maxGasPrice")
 	// Metrics & logging:
 	cfg.Monitor = flag.Bool("monitor", *cfg.Monitor, "Set to true to send performance metrics")
 	cfg.MetricsPerStream = flag.Bool("metricsPerStream", *cfg.MetricsPerStream, "Set to true to group performance metrics per stream")
 	cfg.MetricsExposeClientIP = flag.Bool("metricsClientIP", *cfg.MetricsExposeClientIP, "Set to true to expose client's IP in metrics")
+	cfg.TracingOTLPEndpoint = flag.String("tracingOtlpEndpoint", *cfg.TracingOTLPEndpoint, "OTLP gRPC endpoint (host:port) to export segment traces to. Use an http:// URL to disable TLS")
+	cfg.TracingFile = flag.String("tracingFile", *cfg.TracingFile, "File to write segment traces to as JSON, e.g. for testing")
+	cfg.TracingSampleRate = flag.Float64("tracingSampleRate", *cfg.TracingSampleRate, "Fraction of segments that are traced, between 0 and 1")
 	cfg.MetadataQueueUri = flag.String("metadataQueueUri", *cfg.MetadataQueueUri, "URI for message broker to send operation metadata")
 	cfg.MetadataAmqpExchange = flag.String("metadataAmqpExchange", *cfg.MetadataAmqpExchange, "Name of AMQP exchange to send operation metadata")
 	cfg.MetadataPublishTimeout = flag.Duration("metadataPublishTimeout", *cfg.MetadataPublishTimeout, "Max time to wait in background for publishing operation metadata events")
@@ -202,9 +245,12 @@ func parseLivepeerConfig() starter.LivepeerConfig {
 	cfg.Objectstore = flag.String("objectStore", *cfg.Objectstore, "url of primary object store")
 	cfg.Recordstore = flag.String("recordStore", *cfg.Recordstore, "url of object store for recordings")
 
-	// Fast Verification GS bucket:
-	cfg.FVfailGsBucket = flag.String("FVfailGsbucket", *cfg.FVfailGsBucket, "Google Cloud Storage bucket for storing segments, which failed fast verification")
-	cfg.FVfailGsKey = flag.String("FVfailGskey", *cfg.FVfailGsKey, "Google Cloud Storage private key file name or key in JSON format for accessing FVfailGsBucket")
+	// Fast Verification:
+	cfg.FVfailStore = flag.String("FVfailStore", *cfg.FVfailStore, "URL of object store for storing segments, which failed fast verification")
+	cfg.FVfailGsBucket = flag.String("FVfailGsbucket", *cfg.FVfailGsBucket, "[Deprecated] Google Cloud Storage bucket for storing segments, which failed fast verification. Use -FVfailStore")
+	cfg.FVfailGsKey = flag.String("FVfailGskey", *cfg.FVfailGsKey, "[Deprecated] Google Cloud Storage private key file name or key in JSON format for accessing FVfailGsBucket. Use -FVfailStore")
+	cfg.LocalFastVerification = flag.Bool("localFastVerification", *cfg.LocalFastVerification, "Set to true to verify a random sample of renditions by transcoding locally and comparing MPEG-7 signatures, without a trusted orchestrator")
+	cfg.LocalFVSampleRate = flag.Float64("localFastVerificationSampleRate", *cfg.LocalFVSampleRate, "Fraction of segments that local fast verification re-transcodes on the gateway, unless the stream sets its own verificationSampleRate. Must be > 0 and <= 1")
 	// API
 	cfg.AuthWebhookURL = flag.String("authWebhookUrl", *cfg.AuthWebhookURL, "RTMP authentication webhook URL")
 
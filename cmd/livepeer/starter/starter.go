@@ -16,6 +16,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -69,6 +70,7 @@ const (
 	OrchestratorRpcPort = "8935"
 	OrchestratorCliPort = "7935"
 	TranscoderCliPort   = "6935"
+	GossipPort          = "9945"
 
 	RefreshPerfScoreInterval = 10 * time.Minute
 )
@@ -83,6 +85,9 @@ type LivepeerConfig struct {
 	VerifierURL             *string
 	EthController           *string
 	VerifierPath            *string
+	VerifyQuality           *string
+	EvidenceStore           *string
+	FailurePenalty          *time.Duration
 	LocalVerify             *bool
 	HttpIngest              *bool
 	Orchestrator            *bool
@@ -92,6 +97,9 @@ type LivepeerConfig struct {
 	OrchSecret              *string
 	TranscodingOptions      *string
 	MaxAttempts             *int
+	LadderSplit             *int
+	RaceSessions            *int
+	RaceMaxCost             *float64
 	SelectRandWeight        *float64
 	SelectStakeWeight       *float64
 	SelectPriceWeight       *float64
@@ -104,7 +112,18 @@ type LivepeerConfig struct {
 	CurrentManifest         *bool
 	Nvidia                  *string
 	Netint                  *string
+	CPUs                    *string
+	CPUThreads              *int
+	CPUPinning              *bool
 	TestTranscoder          *bool
+	TranscoderDrainTimeout  *time.Duration
+	TranscoderPixelCapacity *int64
+	TranscoderGracePeriod   *time.Duration
+	TranscoderPull          *bool
+	TranscoderLeaseTimeout  *time.Duration
+	TranscodeCacheSize      *int64
+	TranscodeCacheFee       *int64
+	ReservationFee          *int64
 	EthAcctAddr             *string
 	EthPassword             *string
 	EthKeystorePath         *string
@@ -125,13 +144,20 @@ type LivepeerConfig struct {
 	PricePerUnit            *string
 	PixelsPerUnit           *string
 	PriceFeedAddr           *string
+	PollCreatorAddr         *string
 	AutoAdjustPrice         *bool
 	PricePerGateway         *string
 	PricePerBroadcaster     *string
+	PriorityPrices          *string
 	BlockPollingInterval    *int
+	ReplayFromBlock         *int
 	Redeemer                *bool
 	RedeemerAddr            *string
 	Reward                  *bool
+	RewardMaxGasPrice       *int
+	RewardDeadlineBlocks    *int
+	RewardMaxRetries        *int
+	RewardGasPriceBump      *int
 	Monitor                 *bool
 	MetricsPerStream        *bool
 	MetricsExposeClientIP   *bool
@@ -141,10 +167,26 @@ type LivepeerConfig struct {
 	Datadir                 *string
 	Objectstore             *string
 	Recordstore             *string
+	FVfailStore             *string
 	FVfailGsBucket          *string
 	FVfailGsKey             *string
+	LocalFastVerification   *bool
+	LocalFVSampleRate       *float64
 	AuthWebhookURL          *string
 	OrchWebhookURL          *string
+	OrchRegistryURL         *string
+	OrchRegistryKey         *string
+	OrchPingInterval        *time.Duration
+	OrchMaxRTT              *time.Duration
+	OrchGeoIPFile           *string
+	OrchInfoCacheTTL        *time.Duration
+	GossipAddr              *string
+	GossipPeers             *string
+	GossipKeyFile           *string
+	GossipTrustWeight       *float64
+	TracingOTLPEndpoint     *string
+	TracingFile             *string
+	TracingSampleRate       *float64
 	OrchBlacklist           *string
 	OrchMinLivepeerVersion  *string
 	TestOrchAvail           *bool
@@ -161,6 +203,9 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultOrchAddr := ""
 	defaultVerifierURL := ""
 	defaultVerifierPath := ""
+	defaultVerifyQuality := ""
+	defaultEvidenceStore := ""
+	defaultFailurePenalty := verification.DefaultFailurePenalty
 
 	// Transcoding:
 	defaultOrchestrator := false
@@ -170,6 +215,9 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultOrchSecret := ""
 	defaultTranscodingOptions := "P240p30fps16x9,P360p30fps16x9"
 	defaultMaxAttempts := 3
+	defaultLadderSplit := 1
+	defaultRaceSessions := 1
+	defaultRaceMaxCost := 2.0
 	defaultSelectRandWeight := 0.3
 	defaultSelectStakeWeight := 0.7
 	defaultSelectPriceWeight := 0.0
@@ -181,7 +229,18 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultCurrentManifest := false
 	defaultNvidia := ""
 	defaultNetint := ""
+	defaultCPUs := ""
+	defaultCPUThreads := 0
+	defaultCPUPinning := false
 	defaultTestTranscoder := true
+	defaultTranscoderDrainTimeout := 2 * time.Minute
+	defaultTranscoderPixelCapacity := int64(0)
+	defaultTranscoderGracePeriod := core.TranscoderReconnectGracePeriod
+	defaultTranscoderPull := false
+	defaultTranscoderLeaseTimeout := core.TranscoderLeaseTimeout
+	defaultTranscodeCacheSize := int64(0)
+	defaultTranscodeCacheFee := int64(100)
+	defaultReservationFee := int64(10)
 
 	// Onchain:
 	defaultEthAcctAddr := ""
@@ -196,6 +255,11 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultEthController := ""
 	defaultInitializeRound := false
 	defaultInitializeRoundMaxDelay := 30 * time.Second
+	defaultReplayFromBlock := 0
+	defaultRewardMaxGasPrice := 0
+	defaultRewardDeadlineBlocks := 0
+	defaultRewardMaxRetries := 0
+	defaultRewardGasPriceBump := 10
 	defaultTicketEV := "8000000000"
 	defaultMaxFaceValue := "0"
 	defaultMaxTicketEV := "3000000000000"
@@ -204,9 +268,11 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultMaxPricePerUnit := "0"
 	defaultPixelsPerUnit := "1"
 	defaultPriceFeedAddr := "0x639Fe6ab55C921f74e7fac1ee960C0B6293ba612" // ETH / USD price feed address on Arbitrum Mainnet
+	defaultPollCreatorAddr := ""
 	defaultAutoAdjustPrice := true
 	defaultPricePerGateway := ""
 	defaultPricePerBroadcaster := ""
+	defaultPriorityPrices := ""
 	defaultBlockPollingInterval := 5
 	defaultRedeemer := false
 	defaultRedeemerAddr := ""
@@ -228,13 +294,29 @@ func DefaultLivepeerConfig() LivepeerConfig {
 	defaultObjectstore := ""
 	defaultRecordstore := ""
 
-	// Fast Verification GS bucket:
+	// Fast Verification:
+	defaultFVfailStore := ""
 	defaultFVfailGsBucket := ""
 	defaultFVfailGsKey := ""
+	defaultLocalFastVerification := false
+	defaultLocalFVSampleRate := 0.1
 
 	// API
 	defaultAuthWebhookURL := ""
 	defaultOrchWebhookURL := ""
+	defaultOrchRegistryURL := ""
+	defaultOrchRegistryKey := ""
+	defaultOrchPingInterval := time.Duration(0)
+	defaultOrchMaxRTT := time.Duration(0)
+	defaultOrchGeoIPFile := ""
+	defaultOrchInfoCacheTTL := time.Duration(0)
+	defaultGossipAddr := ""
+	defaultGossipPeers := ""
+	defaultGossipKeyFile := ""
+	defaultGossipTrustWeight := 0.5
+	defaultTracingOTLPEndpoint := ""
+	defaultTracingFile := ""
+	defaultTracingSampleRate := 1.0
 	defaultMinLivepeerVersion := ""
 
 	// Flags
@@ -242,35 +324,52 @@ func DefaultLivepeerConfig() LivepeerConfig {
 
 	return LivepeerConfig{
 		// Network & Addresses:
-		Network:      &defaultNetwork,
-		RtmpAddr:     &defaultRtmpAddr,
-		CliAddr:      &defaultCliAddr,
-		HttpAddr:     &defaultHttpAddr,
-		ServiceAddr:  &defaultServiceAddr,
-		OrchAddr:     &defaultOrchAddr,
-		VerifierURL:  &defaultVerifierURL,
-		VerifierPath: &defaultVerifierPath,
+		Network:        &defaultNetwork,
+		RtmpAddr:       &defaultRtmpAddr,
+		CliAddr:        &defaultCliAddr,
+		HttpAddr:       &defaultHttpAddr,
+		ServiceAddr:    &defaultServiceAddr,
+		OrchAddr:       &defaultOrchAddr,
+		VerifierURL:    &defaultVerifierURL,
+		VerifierPath:   &defaultVerifierPath,
+		VerifyQuality:  &defaultVerifyQuality,
+		EvidenceStore:  &defaultEvidenceStore,
+		FailurePenalty: &defaultFailurePenalty,
 
 		// Transcoding:
-		Orchestrator:         &defaultOrchestrator,
-		Transcoder:           &defaultTranscoder,
-		Gateway:              &defaultGateway,
-		Broadcaster:          &defaultBroadcaster,
-		OrchSecret:           &defaultOrchSecret,
-		TranscodingOptions:   &defaultTranscodingOptions,
-		MaxAttempts:          &defaultMaxAttempts,
-		SelectRandWeight:     &defaultSelectRandWeight,
-		SelectStakeWeight:    &defaultSelectStakeWeight,
-		SelectPriceWeight:    &defaultSelectPriceWeight,
-		SelectPriceExpFactor: &defaultSelectPriceExpFactor,
-		MaxSessions:          &defaultMaxSessions,
-		OrchPerfStatsURL:     &defaultOrchPerfStatsURL,
-		Region:               &defaultRegion,
-		MinPerfScore:         &defaultMinPerfScore,
-		CurrentManifest:      &defaultCurrentManifest,
-		Nvidia:               &defaultNvidia,
-		Netint:               &defaultNetint,
-		TestTranscoder:       &defaultTestTranscoder,
+		Orchestrator:            &defaultOrchestrator,
+		Transcoder:              &defaultTranscoder,
+		Gateway:                 &defaultGateway,
+		Broadcaster:             &defaultBroadcaster,
+		OrchSecret:              &defaultOrchSecret,
+		TranscodingOptions:      &defaultTranscodingOptions,
+		MaxAttempts:             &defaultMaxAttempts,
+		LadderSplit:             &defaultLadderSplit,
+		RaceSessions:            &defaultRaceSessions,
+		RaceMaxCost:             &defaultRaceMaxCost,
+		SelectRandWeight:        &defaultSelectRandWeight,
+		SelectStakeWeight:       &defaultSelectStakeWeight,
+		SelectPriceWeight:       &defaultSelectPriceWeight,
+		SelectPriceExpFactor:    &defaultSelectPriceExpFactor,
+		MaxSessions:             &defaultMaxSessions,
+		OrchPerfStatsURL:        &defaultOrchPerfStatsURL,
+		Region:                  &defaultRegion,
+		MinPerfScore:            &defaultMinPerfScore,
+		CurrentManifest:         &defaultCurrentManifest,
+		Nvidia:                  &defaultNvidia,
+		Netint:                  &defaultNetint,
+		CPUs:                    &defaultCPUs,
+		CPUThreads:              &defaultCPUThreads,
+		CPUPinning:              &defaultCPUPinning,
+		TestTranscoder:          &defaultTestTranscoder,
+		TranscoderDrainTimeout:  &defaultTranscoderDrainTimeout,
+		TranscoderPixelCapacity: &defaultTranscoderPixelCapacity,
+		TranscoderGracePeriod:   &defaultTranscoderGracePeriod,
+		TranscoderPull:          &defaultTranscoderPull,
+		TranscoderLeaseTimeout:  &defaultTranscoderLeaseTimeout,
+		TranscodeCacheSize:      &defaultTranscodeCacheSize,
+		TranscodeCacheFee:       &defaultTranscodeCacheFee,
+		ReservationFee:          &defaultReservationFee,
 
 		// Onchain:
 		EthAcctAddr:             &defaultEthAcctAddr,
@@ -285,6 +384,11 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		EthController:           &defaultEthController,
 		InitializeRound:         &defaultInitializeRound,
 		InitializeRoundMaxDelay: &defaultInitializeRoundMaxDelay,
+		ReplayFromBlock:         &defaultReplayFromBlock,
+		RewardMaxGasPrice:       &defaultRewardMaxGasPrice,
+		RewardDeadlineBlocks:    &defaultRewardDeadlineBlocks,
+		RewardMaxRetries:        &defaultRewardMaxRetries,
+		RewardGasPriceBump:      &defaultRewardGasPriceBump,
 		TicketEV:                &defaultTicketEV,
 		MaxFaceValue:            &defaultMaxFaceValue,
 		MaxTicketEV:             &defaultMaxTicketEV,
@@ -293,9 +397,11 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		MaxPricePerUnit:         &defaultMaxPricePerUnit,
 		PixelsPerUnit:           &defaultPixelsPerUnit,
 		PriceFeedAddr:           &defaultPriceFeedAddr,
+		PollCreatorAddr:         &defaultPollCreatorAddr,
 		AutoAdjustPrice:         &defaultAutoAdjustPrice,
 		PricePerGateway:         &defaultPricePerGateway,
 		PricePerBroadcaster:     &defaultPricePerBroadcaster,
+		PriorityPrices:          &defaultPriorityPrices,
 		BlockPollingInterval:    &defaultBlockPollingInterval,
 		Redeemer:                &defaultRedeemer,
 		RedeemerAddr:            &defaultRedeemerAddr,
@@ -317,13 +423,29 @@ func DefaultLivepeerConfig() LivepeerConfig {
 		Objectstore: &defaultObjectstore,
 		Recordstore: &defaultRecordstore,
 
-		// Fast Verification GS bucket:
-		FVfailGsBucket: &defaultFVfailGsBucket,
-		FVfailGsKey:    &defaultFVfailGsKey,
+		// Fast Verification:
+		FVfailStore:           &defaultFVfailStore,
+		FVfailGsBucket:        &defaultFVfailGsBucket,
+		FVfailGsKey:           &defaultFVfailGsKey,
+		LocalFastVerification: &defaultLocalFastVerification,
+		LocalFVSampleRate:     &defaultLocalFVSampleRate,
 
 		// API
 		AuthWebhookURL:         &defaultAuthWebhookURL,
 		OrchWebhookURL:         &defaultOrchWebhookURL,
+		OrchRegistryURL:        &defaultOrchRegistryURL,
+		OrchRegistryKey:        &defaultOrchRegistryKey,
+		OrchPingInterval:       &defaultOrchPingInterval,
+		OrchMaxRTT:             &defaultOrchMaxRTT,
+		OrchGeoIPFile:          &defaultOrchGeoIPFile,
+		OrchInfoCacheTTL:       &defaultOrchInfoCacheTTL,
+		GossipAddr:             &defaultGossipAddr,
+		GossipPeers:            &defaultGossipPeers,
+		GossipKeyFile:          &defaultGossipKeyFile,
+		GossipTrustWeight:      &defaultGossipTrustWeight,
+		TracingOTLPEndpoint:    &defaultTracingOTLPEndpoint,
+		TracingFile:            &defaultTracingFile,
+		TracingSampleRate:      &defaultTracingSampleRate,
 		OrchMinLivepeerVersion: &defaultMinLivepeerVersion,
 
 		// Flags
@@ -428,9 +550,24 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 	}
 
-	//Set Gs bucket for fast verification fail case
-	if *cfg.FVfailGsBucket != "" && *cfg.FVfailGsKey != "" {
-		drivers.SetCreds(*cfg.FVfailGsBucket, *cfg.FVfailGsKey)
+	//Set object store for fast verification fail case
+	fvFailStoreURL := *cfg.FVfailStore
+	if fvFailStoreURL == "" && *cfg.FVfailGsBucket != "" && *cfg.FVfailGsKey != "" {
+		glog.Warning("-FVfailGsbucket and -FVfailGskey are deprecated, use -FVfailStore instead")
+		fvFailStoreURL = gsFailStoreURL(*cfg.FVfailGsBucket, *cfg.FVfailGsKey)
+	}
+	var fvFailStore drivers.OSSession
+	if fvFailStoreURL != "" {
+		prepared, err := drivers.PrepareOSURL(fvFailStoreURL)
+		if err != nil {
+			glog.Exit("Error creating fast verification fail object store driver: ", err)
+		}
+		fvFailOS, err := drivers.ParseOSURL(prepared, true)
+		if err != nil {
+			glog.Exit("Error creating fast verification fail object store driver: ", err)
+		}
+		fvFailStore = fvFailOS.NewSession("")
+		server.FastVerificationFailStore = fvFailStore
 	}
 
 	//Set up DB
@@ -451,6 +588,9 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	}
 
 	var transcoderCaps []core.Capability
+	// Number of parallel sessions used to measure the pixel capacity of the transcoder
+	benchmarkSessions := 1
+	var cpuTranscoder *core.CPUTranscoder
 	if *cfg.Transcoder {
 		core.WorkDir = *cfg.Datadir
 		accel := ffmpeg.Software
@@ -488,10 +628,25 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			}
 			// Initialize LB transcoder
 			n.Transcoder = core.NewLoadBalancingTranscoder(devices, tf)
+			benchmarkSessions = len(devices)
 		} else {
 			// for local software mode, enable all capabilities
 			transcoderCaps = append(core.DefaultCapabilities(), core.OptionalCapabilities()...)
+			if *cfg.CPUThreads < 0 {
+				exit("-cpuThreads must not be negative")
+			}
 			n.Transcoder = core.NewLocalTranscoder(*cfg.Datadir)
+			// Software transcoding is only scheduled on CPU cores if they are configured
+			if *cfg.CPUs != "" || *cfg.CPUThreads > 0 || *cfg.CPUPinning {
+				cpus, err := parseCPUs(*cfg.CPUs)
+				if err != nil {
+					exit("Error while parsing '-cpus %v' flag: %v", *cfg.CPUs, err)
+				}
+				cpuTranscoder = core.NewCPUTranscoder(n.Transcoder, cpus, *cfg.CPUThreads, *cfg.CPUPinning)
+				glog.Infof("Transcoding on CPUs=%v threads=%d pinning=%v", cpus, *cfg.CPUThreads, *cfg.CPUPinning)
+				n.Transcoder = cpuTranscoder
+				benchmarkSessions = cpuTranscoder.Slots()
+			}
 		}
 	}
 
@@ -500,9 +655,25 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	} else if *cfg.Orchestrator {
 		n.NodeType = core.OrchestratorNode
 		if !*cfg.Transcoder {
+			core.TranscoderReconnectGracePeriod = *cfg.TranscoderGracePeriod
+			core.TranscoderLeaseTimeout = *cfg.TranscoderLeaseTimeout
 			n.TranscoderManager = core.NewRemoteTranscoderManager()
 			n.Transcoder = n.TranscoderManager
 		}
+		if *cfg.TranscodeCacheSize > 0 {
+			if *cfg.TranscodeCacheFee < 0 || *cfg.TranscodeCacheFee > 100 {
+				exit("-transcodeCacheFee must be between 0 and 100")
+			}
+			n.TranscodeCache = core.NewTranscodeCache(*cfg.TranscodeCacheSize, *cfg.TranscodeCacheFee)
+			glog.Infof("Caching transcoded segments size=%d fee=%d%%", *cfg.TranscodeCacheSize, *cfg.TranscodeCacheFee)
+		}
+		if *cfg.ReservationFee <= 0 {
+			exit("-reservationFee must be > 0")
+		}
+		core.ReservationFeePercent = *cfg.ReservationFee
+		if err := n.RestoreCapacityReservations(); err != nil {
+			glog.Errorf("Error restoring capacity reservations err=%q", err)
+		}
 	} else if *cfg.Transcoder {
 		n.NodeType = core.TranscoderNode
 	} else if *cfg.Broadcaster {
@@ -521,6 +692,17 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	hn, _ := os.Hostname()
 	lpmon.NodeID += hn
 
+	nodeType := lpmon.Default
+	switch n.NodeType {
+	case core.BroadcasterNode:
+		nodeType = lpmon.Broadcaster
+	case core.OrchestratorNode:
+		nodeType = lpmon.Orchestrator
+	case core.TranscoderNode:
+		nodeType = lpmon.Transcoder
+	case core.RedeemerNode:
+		nodeType = lpmon.Redeemer
+	}
 	if *cfg.Monitor {
 		if *cfg.MetricsExposeClientIP {
 			*cfg.MetricsPerStream = true
@@ -528,20 +710,22 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		lpmon.Enabled = true
 		lpmon.PerStreamMetrics = *cfg.MetricsPerStream
 		lpmon.ExposeClientIP = *cfg.MetricsExposeClientIP
-		nodeType := lpmon.Default
-		switch n.NodeType {
-		case core.BroadcasterNode:
-			nodeType = lpmon.Broadcaster
-		case core.OrchestratorNode:
-			nodeType = lpmon.Orchestrator
-		case core.TranscoderNode:
-			nodeType = lpmon.Transcoder
-		case core.RedeemerNode:
-			nodeType = lpmon.Redeemer
-		}
 		lpmon.InitCensus(nodeType, core.LivepeerVersion)
 	}
 
+	if *cfg.TracingOTLPEndpoint != "" || *cfg.TracingFile != "" {
+		shutdown, err := lpmon.InitTracing(ctx, nodeType, core.LivepeerVersion, lpmon.TracingConfig{
+			OTLPEndpoint: *cfg.TracingOTLPEndpoint,
+			File:         *cfg.TracingFile,
+			SampleRate:   *cfg.TracingSampleRate,
+		})
+		if err != nil {
+			exit("Error setting up tracing: %v", err)
+		}
+		glog.Infof("Tracing segments, otlpEndpoint=%q file=%q sampleRate=%v", *cfg.TracingOTLPEndpoint, *cfg.TracingFile, *cfg.TracingSampleRate)
+		defer shutdown(context.Background())
+	}
+
 	watcherErr := make(chan error)
 	serviceErr := make(chan error)
 	var timeWatcher *watchers.TimeWatcher
@@ -680,6 +864,7 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			WithLogs:            true,
 			Topics:              topics,
 			Client:              blockWatcherClient,
+			Checkpoints:         n.Database,
 		}
 		// Wait until all event watchers have been initialized before starting the block watcher
 		blockWatcher := blockwatch.New(blockWatcherCfg)
@@ -732,6 +917,24 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		go serviceRegistryWatcher.Watch()
 		defer serviceRegistryWatcher.Stop()
 
+		replayHandlers := []watchers.BlockEventHandler{unbondingWatcher, senderWatcher, orchWatcher, serviceRegistryWatcher}
+
+		// The PollCreator is not registered in the Controller so polls are only watched if its address is provided
+		if *cfg.PollCreatorAddr != "" {
+			if !ethcommon.IsHexAddress(*cfg.PollCreatorAddr) {
+				glog.Errorf("Invalid PollCreator address: %v", *cfg.PollCreatorAddr)
+				return
+			}
+			pollWatcher, err := watchers.NewPollWatcher(ethcommon.HexToAddress(*cfg.PollCreatorAddr), blockWatcher, blockWatcherClient, n.Database, n.Eth, timeWatcher)
+			if err != nil {
+				glog.Errorf("Failed to set up poll watcher: %v", err)
+				return
+			}
+			go pollWatcher.Watch()
+			defer pollWatcher.Stop()
+			replayHandlers = append(replayHandlers, pollWatcher)
+		}
+
 		core.PriceFeedWatcher, err = watchers.NewPriceFeedWatcher(backend, *cfg.PriceFeedAddr)
 		// The price feed watch loop is started on demand on first subscribe.
 		if err != nil {
@@ -804,6 +1007,12 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				n.SetBasePrice(p.EthAddress, autoPrice)
 			}
 
+			priorityPrices, err := core.ParsePriorityPrices(*cfg.PriorityPrices)
+			if err != nil {
+				glog.Exitf("Error parsing -priorityPrices: %v", err)
+			}
+			n.PriorityPrices = priorityPrices
+
 			n.AutoSessionLimit = *cfg.MaxSessions == "auto"
 			n.AutoAdjustPrice = *cfg.AutoAdjustPrice
 
@@ -981,7 +1190,18 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if reward {
 			// Start reward service
 			// The node will only call reward if it is active in the current round
-			rs := eth.NewRewardService(n.Eth, timeWatcher)
+			policy := eth.RewardPolicy{
+				MaxRetries:   *cfg.RewardMaxRetries,
+				GasPriceBump: uint64(*cfg.RewardGasPriceBump),
+			}
+			if *cfg.RewardMaxGasPrice > 0 {
+				policy.MaxGasPrice = big.NewInt(int64(*cfg.RewardMaxGasPrice))
+			}
+			if *cfg.RewardDeadlineBlocks > 0 {
+				policy.DeadlineBlocks = big.NewInt(int64(*cfg.RewardDeadlineBlocks))
+			}
+			rs := eth.NewRewardService(n.Eth, timeWatcher, n.Database, policy)
+			n.RewardService = rs
 			go func() {
 				if err := rs.Start(ctx); err != nil {
 					serviceErr <- err
@@ -1007,6 +1227,27 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		blockWatchCtx, cancel := context.WithCancel(ctx)
 		defer cancel()
 
+		// Replay historical events to rebuild the state of the unbonding locks, orchestrators and polls.
+		// The replay is checkpointed in the DB so a restarted node resumes where it left off, and the
+		// backfill below resumes after the last replayed block
+		if *cfg.ReplayFromBlock > 0 {
+			head, err := blockWatcherClient.HeaderByNumber(nil)
+			if err != nil {
+				glog.Errorf("Failed to get latest block for event replay: %v", err)
+				return
+			}
+			from := big.NewInt(int64(*cfg.ReplayFromBlock))
+			if from.Cmp(head.Number) <= 0 {
+				glog.Infof("Replaying block events from block %v (this can take a while)...", from)
+				last, err := watchers.ReplayEvents(blockWatchCtx, blockWatcher, "watchers", from, head.Number, replayHandlers...)
+				if err != nil {
+					glog.Errorf("Failed to replay events: %v", err)
+					return
+				}
+				glog.Infof("Done replaying block events lastBlock=%v", last)
+			}
+		}
+
 		// Backfill events that the node has missed since its last seen block. This method will block
 		// and the node will not continue setup until it finishes
 		glog.Infof("Backfilling block events (this can take a while)...\n")
@@ -1089,6 +1330,22 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			n.OrchPerfScore = &common.PerfScore{Scores: make(map[ethcommon.Address]float64)}
 			go refreshOrchPerfScoreLoop(ctx, strings.ToUpper(*cfg.Region), *cfg.OrchPerfStatsURL, n.OrchPerfScore)
 		}
+		if *cfg.OrchPingInterval > 0 {
+			var geo discovery.GeoIPTable
+			if *cfg.OrchGeoIPFile != "" {
+				geo, err = discovery.LoadGeoIPTable(*cfg.OrchGeoIPFile)
+				if err != nil {
+					glog.Exitf("Error loading -orchGeoIPFile: %v", err)
+				}
+			}
+			if n.OrchPerfScore == nil {
+				n.OrchPerfScore = &common.PerfScore{Scores: make(map[ethcommon.Address]float64)}
+			}
+			glog.Infof("Using orchestrator latency, region=%s, pingInterval=%v, maxRTT=%v, minPerfScore=%v", *cfg.Region, *cfg.OrchPingInterval, *cfg.OrchMaxRTT, *cfg.MinPerfScore)
+			discovery.Latency = discovery.NewLatencyMonitor(*cfg.Region, geo, n.OrchPerfScore, *cfg.OrchMaxRTT)
+		} else if *cfg.OrchGeoIPFile != "" {
+			glog.Exit("-orchGeoIPFile requires -orchPingInterval")
+		}
 
 		// When the node is on-chain mode always cache the on-chain orchestrators and poll for updates
 		// Right now we rely on the DBOrchestratorPoolCache constructor to do this. Consider separating the logic
@@ -1096,6 +1353,10 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		if *cfg.Network != "offchain" {
 			ctx, cancel := context.WithCancel(ctx)
 			defer cancel()
+			if *cfg.OrchInfoCacheTTL > 0 {
+				glog.Infof("Caching orchestrator capabilities and prices for %v", *cfg.OrchInfoCacheTTL)
+				discovery.OrchInfoCacheTTL = *cfg.OrchInfoCacheTTL
+			}
 			dbOrchPoolCache, err := discovery.NewDBOrchestratorPoolCache(ctx, n, timeWatcher, orchBlacklist)
 			if err != nil {
 				exit("Could not create orchestrator pool with DB cache: %v", err)
@@ -1112,13 +1373,62 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			}
 			glog.Info("Using orchestrator webhook URL ", whurl)
 			n.OrchestratorPool = discovery.NewWebhookPool(bcast, whurl)
+		} else if *cfg.OrchRegistryURL != "" {
+			regurl, err := validateURL(*cfg.OrchRegistryURL)
+			if err != nil {
+				glog.Exit("Error setting orch registry URL ", err)
+			}
+			if !ethcommon.IsHexAddress(*cfg.OrchRegistryKey) {
+				glog.Exit("Missing or invalid -orchRegistryKey; must be the ETH address that signs the orchestrator registry")
+			}
+			glog.Infof("Using orchestrator registry URL %v signed by %v", regurl, *cfg.OrchRegistryKey)
+			n.OrchestratorPool = discovery.NewRegistryPool(ctx, bcast, dbh, regurl, ethcommon.HexToAddress(*cfg.OrchRegistryKey), orchBlacklist)
 		} else if len(orchURLs) > 0 {
 			n.OrchestratorPool = discovery.NewOrchestratorPool(bcast, orchURLs, common.Score_Trusted, orchBlacklist)
 		}
 
+		if *cfg.GossipPeers != "" {
+			if *cfg.Network != "offchain" {
+				glog.Exit("-gossipPeers is only supported in off-chain mode")
+			}
+			if n.OrchestratorPool == nil {
+				glog.Exit("-gossipPeers requires -orchAddr, -orchWebhookUrl or -orchRegistryUrl")
+			}
+			peers, err := server.ParseGossipPeers(*cfg.GossipPeers)
+			if err != nil {
+				glog.Exitf("Error parsing -gossipPeers: %v", err)
+			}
+			keyFile := *cfg.GossipKeyFile
+			if keyFile == "" {
+				keyFile = filepath.Join(*cfg.Datadir, "gossip.key")
+			}
+			key, err := server.LoadGossipKey(keyFile)
+			if err != nil {
+				glog.Exitf("Error loading gossip key: %v", err)
+			}
+			gossipURL, err := url.ParseRequestURI("https://" + defaultAddr(*cfg.GossipAddr, "0.0.0.0", GossipPort))
+			if err != nil {
+				glog.Exitf("Error setting -gossipAddr: %v", err)
+			}
+			server.OrchStats = server.NewOrchestratorStats()
+			gossip := server.NewGossip(key, peers, server.OrchStats)
+			gossip.Pool = n.OrchestratorPool
+			glog.Infof("Gossiping orchestrators with %d peers, address=%v, trustWeight=%v", len(peers), gossip.Address().Hex(), *cfg.GossipTrustWeight)
+			go func() {
+				if err := gossip.Start(gossipURL, n.WorkDir); err != nil {
+					glog.Errorf("Gossip server error: %v", err)
+				}
+			}()
+			defer gossip.Stop()
+			go gossip.Run(ctx, common.GossipInterval)
+			n.OrchestratorPool = discovery.NewGossipPool(bcast, n.OrchestratorPool, gossip, *cfg.GossipTrustWeight, orchBlacklist)
+		}
+
 		if n.OrchestratorPool == nil {
 			// Not a fatal error; may continue operating in segment-only mode
 			glog.Error("No orchestrator specified; transcoding will not happen")
+		} else if discovery.Latency != nil {
+			discovery.Latency.Start(ctx, n.OrchestratorPool, *cfg.OrchPingInterval)
 		}
 
 		isLocalHTTP, err := isLocalURL("https://" + *cfg.HttpAddr)
@@ -1135,7 +1445,7 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 		}
 
 		// Disable local verification when running in off-chain mode
-		// To enable, set -localVerify or -verifierURL
+		// To enable, set -localVerify, -verifierURL or -verifyQuality
 		localVerify := true
 		if cfg.LocalVerify != nil {
 			localVerify = *cfg.LocalVerify
@@ -1144,6 +1454,17 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			localVerify = false
 		}
 
+		// Only one verifier can be used at a time
+		verifiers := 0
+		for _, enabled := range []bool{*cfg.VerifierURL != "", *cfg.VerifyQuality != "", *cfg.LocalFastVerification} {
+			if enabled {
+				verifiers++
+			}
+		}
+		if verifiers > 1 {
+			glog.Exit("Only one of -verifierUrl, -verifyQuality and -localFastVerification can be set")
+		}
+
 		if *cfg.VerifierURL != "" {
 			_, err := validateURL(*cfg.VerifierURL)
 			if err != nil {
@@ -1158,14 +1479,62 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 				glog.Exit("Requires a path to the verifier shared volume when local storage is in use; use -verifierPath or -objectStore")
 			}
 			verification.VerifierPath = *cfg.VerifierPath
+		} else if *cfg.VerifyQuality != "" {
+			qv, err := verification.ParseQualityThresholds(*cfg.VerifyQuality, nil)
+			if err != nil {
+				glog.Exitf("Error parsing -verifyQuality: %v", err)
+			}
+			glog.Info("Using local quality verification with thresholds ", *cfg.VerifyQuality)
+			server.Policy = &verification.Policy{Retries: 2, Verifier: qv}
+		} else if *cfg.LocalFastVerification {
+			if *cfg.LocalFVSampleRate <= 0 || *cfg.LocalFVSampleRate > 1 {
+				glog.Exit("-localFastVerificationSampleRate must be > 0 and <= 1")
+			}
+			glog.Infof("Using local fast verification with MPEG-7 signatures sampleRate=%v", *cfg.LocalFVSampleRate)
+			server.Policy = &verification.Policy{
+				Retries:    2,
+				Verifier:   &verification.SignatureVerifier{FailStore: fvFailStore},
+				SampleRate: *cfg.LocalFVSampleRate,
+			}
 		} else if localVerify {
 			glog.Info("Local verification enabled")
 			server.Policy = &verification.Policy{Retries: 2}
 		}
 
+		// Record evidence of failed verifications, and deny orchestrators with fatal failures from DB discovery
+		if server.Policy != nil {
+			stores := verification.EvidenceStores{&verification.DBEvidenceStore{DB: dbh, Penalty: *cfg.FailurePenalty}}
+			if *cfg.EvidenceStore != "" {
+				prepared, err := drivers.PrepareOSURL(*cfg.EvidenceStore)
+				if err != nil {
+					glog.Exit("Error creating verification evidence object store driver: ", err)
+				}
+				evidenceOS, err := drivers.ParseOSURL(prepared, true)
+				if err != nil {
+					glog.Exit("Error creating verification evidence object store driver: ", err)
+				}
+				stores = append(stores, &verification.OSEvidenceStore{OS: evidenceOS.NewSession("")})
+			}
+			server.EvidenceStore = stores
+		}
+
 		// Set max transcode attempts. <=0 is OK; it just means "don't transcode"
 		server.MaxAttempts = *cfg.MaxAttempts
 
+		if *cfg.LadderSplit < 1 {
+			glog.Exit("-ladderSplit must be at least 1")
+		}
+		server.LadderSplit = *cfg.LadderSplit
+
+		if *cfg.RaceSessions < 1 {
+			glog.Exit("-raceSessions must be at least 1")
+		}
+		if *cfg.RaceMaxCost < 0 {
+			glog.Exit("-raceMaxCost must not be negative")
+		}
+		server.RaceSessions = *cfg.RaceSessions
+		server.RaceMaxCost = *cfg.RaceMaxCost
+
 	} else if n.NodeType == core.OrchestratorNode {
 		*cfg.CliAddr = defaultAddr(*cfg.CliAddr, "127.0.0.1", OrchestratorCliPort)
 
@@ -1271,6 +1640,19 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 
 	}()
 
+	if cpuTranscoder != nil && n.NodeType != core.TranscoderNode {
+		// Seed the estimated transcoding time of the segments that are transcoded locally
+		go func() {
+			pixelThroughput, err := core.MeasurePixelThroughput(cpuTranscoder, n.WorkDir, benchmarkSessions)
+			if err != nil {
+				glog.Warningf("Unable to measure software transcoding throughput err=%q", err)
+				return
+			}
+			glog.Infof("Measured software transcoding throughput=%d pixels/s", pixelThroughput)
+			cpuTranscoder.SetPixelThroughput(pixelThroughput)
+		}()
+	}
+
 	if n.NodeType == core.TranscoderNode {
 		if n.OrchSecret == "" {
 			glog.Exit("Missing -orchSecret")
@@ -1279,7 +1661,22 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 			glog.Exit("Missing -orchAddr")
 		}
 
-		go server.RunTranscoder(n, orchURLs[0].Host, core.MaxSessions, transcoderCaps)
+		go func() {
+			pixelCapacity := *cfg.TranscoderPixelCapacity
+			if pixelCapacity == 0 {
+				var err error
+				pixelCapacity, err = core.MeasurePixelThroughput(n.Transcoder, n.WorkDir, benchmarkSessions)
+				if err != nil {
+					glog.Warningf("Unable to measure transcoder pixel capacity, registering without it err=%q", err)
+				} else {
+					glog.Infof("Measured transcoder pixel capacity=%d pixels/s", pixelCapacity)
+				}
+			}
+			if cpuTranscoder != nil {
+				cpuTranscoder.SetPixelThroughput(pixelCapacity)
+			}
+			server.RunTranscoder(n, orchURLs[0].Host, core.MaxSessions, pixelCapacity, transcoderCaps, *cfg.TranscoderPull, *cfg.TranscoderDrainTimeout)
+		}()
 	}
 
 	switch n.NodeType {
@@ -1321,6 +1718,18 @@ func StartLivepeer(ctx context.Context, cfg LivepeerConfig) {
 	}
 }
 
+// gsFailStoreURL converts the deprecated Google Cloud Storage bucket and key of the fast verification fail case into an
+// object store URL. The key is either a file name or the key in JSON format.
+func gsFailStoreURL(bucket, key string) string {
+	u := &url.URL{Scheme: "gs", Host: bucket}
+	if info, err := os.Stat(key); err == nil && !info.IsDir() {
+		u.RawQuery = url.Values{"keyfile": {key}}.Encode()
+	} else {
+		u.User = url.User(key)
+	}
+	return u.String()
+}
+
 func parseOrchAddrs(addrs string) []*url.URL {
 	var res []*url.URL
 	if len(addrs) > 0 {
@@ -1671,3 +2080,23 @@ func exit(msg string, args ...any) {
 	glog.Errorf(msg, args...)
 	os.Exit(2)
 }
+
+// parseCPUs parses a comma-separated list of CPU IDs or "all" or an empty string for all available CPUs
+func parseCPUs(cpus string) ([]int, error) {
+	if cpus == "all" || cpus == "" {
+		ids := make([]int, runtime.NumCPU())
+		for i := range ids {
+			ids[i] = i
+		}
+		return ids, nil
+	}
+	var ids []int
+	for _, c := range strings.Split(cpus, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(c))
+		if err != nil || id < 0 {
+			return nil, fmt.Errorf("invalid CPU ID %q", c)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
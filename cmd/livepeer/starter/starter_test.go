@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -390,3 +392,40 @@ func TestParsePricePerUnit(t *testing.T) {
 		})
 	}
 }
+
+func TestParseCPUs(t *testing.T) {
+	assert := assert.New(t)
+
+	cpus, err := parseCPUs("all")
+	assert.Nil(err)
+	assert.Len(cpus, runtime.NumCPU())
+	assert.Equal(0, cpus[0])
+	cpus, err = parseCPUs("")
+	assert.Nil(err)
+	assert.Len(cpus, runtime.NumCPU())
+
+	cpus, err = parseCPUs("0, 2,5")
+	assert.Nil(err)
+	assert.Equal([]int{0, 2, 5}, cpus)
+
+	_, err = parseCPUs("0,foo")
+	assert.EqualError(err, `invalid CPU ID "foo"`)
+	_, err = parseCPUs("-1")
+	assert.NotNil(err)
+}
+
+func TestGSFailStoreURL(t *testing.T) {
+	assert := assert.New(t)
+
+	// Key files are resolved by the object store driver
+	keyfile := filepath.Join(t.TempDir(), "key.json")
+	require.Nil(t, os.WriteFile(keyfile, []byte(`{"foo":"bar"}`), 0644))
+	assert.Equal("gs://bucket?keyfile="+url.QueryEscape(keyfile), gsFailStoreURL("bucket", keyfile))
+
+	// Keys in JSON format are passed as is
+	u, err := url.Parse(gsFailStoreURL("bucket", `{"foo":"bar"}`))
+	require.Nil(t, err)
+	assert.Equal("gs", u.Scheme)
+	assert.Equal("bucket", u.Host)
+	assert.Equal(`{"foo":"bar"}`, u.User.Username())
+}
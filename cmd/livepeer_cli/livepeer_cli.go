@@ -87,6 +87,9 @@ func (w *wizard) initializeOptions() []wizardOpt {
 		{desc: "Get node status", invoke: func() { w.stats(w.orchestrator) }},
 		{desc: "View protocol parameters", invoke: w.protocolStats},
 		{desc: "List registered orchestrators", invoke: func() { w.registeredOrchestratorStats() }},
+		{desc: "View delegator earnings history", invoke: w.delegatorEarningsStats},
+		{desc: "View projected orchestrator yields", invoke: w.orchestratorYieldStats},
+		{desc: "View polls", invoke: w.pollStats},
 		{desc: "Invoke \"initialize round\"", invoke: w.initializeRound},
 		{desc: "Invoke \"bond\"", invoke: w.bond},
 		{desc: "Invoke \"unbond\"", invoke: w.unbond},
@@ -115,6 +118,7 @@ func (w *wizard) initializeOptions() []wizardOpt {
 		{desc: "Set max ticket face value", invoke: w.setMaxFaceValue, orchestrator: true},
 		{desc: "Set price for broadcaster", invoke: w.setPriceForBroadcaster, orchestrator: true},
 		{desc: "Set maximum sessions", invoke: w.setMaxSessions, orchestrator: true, notOrchestrator: false},
+		{desc: "Set remote transcoder maintenance mode", invoke: w.setTranscoderDraining, orchestrator: true},
 		{desc: "Exit", invoke: func() {
 			fmt.Println("Goodbye, my friend")
 			os.Exit(0)
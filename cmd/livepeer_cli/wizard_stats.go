@@ -290,6 +290,106 @@ func (w *wizard) delegatorStats() {
 	table.Render()
 }
 
+func (w *wizard) delegatorEarningsStats() {
+	if w.offchain {
+		return
+	}
+	history, err := w.getDelegatorEarnings()
+	if err != nil {
+		glog.Errorf("Error getting delegator earnings: %v", err)
+		return
+	}
+
+	fmt.Println("+--------------------------+")
+	fmt.Println("|DELEGATOR EARNINGS HISTORY|")
+	fmt.Println("+--------------------------+")
+
+	fmt.Printf("Delegate Address: %v\n", history.Delegate.Hex())
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Round", "Stake", "Reward", "Fees", "Reward Called"})
+	for _, r := range history.Rounds {
+		table.Append([]string{
+			r.Round.String(),
+			eth.FormatUnits(r.Stake, "LPT"),
+			eth.FormatUnits(r.Reward, "LPT"),
+			eth.FormatUnits(r.Fees, "ETH"),
+			strconv.FormatBool(r.RewardCalled),
+		})
+	}
+	table.SetFooter([]string{"Total", "", eth.FormatUnits(history.TotalReward, "LPT"), eth.FormatUnits(history.TotalFees, "ETH"), ""})
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.Render()
+}
+
+func (w *wizard) orchestratorYieldStats() {
+	if w.offchain {
+		return
+	}
+	yields, err := w.getOrchestratorYields()
+	if err != nil {
+		glog.Errorf("Error getting orchestrator yields: %v", err)
+		return
+	}
+
+	fmt.Println("+----------------------------+")
+	fmt.Println("|PROJECTED ORCHESTRATOR YIELD|")
+	fmt.Println("+----------------------------+")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Address", "Delegated Stake", "Reward Cut (%)", "Fee Cut (%)", "Reward Calls (%)", "Reward Yield (%/yr)", "Fee Yield (ETH/LPT/yr)"})
+	for _, y := range yields {
+		table.Append([]string{
+			y.Address.Hex(),
+			eth.FormatUnits(y.DelegatedStake, "LPT"),
+			eth.FormatPerc(y.RewardCut),
+			eth.FormatPerc(flipPerc(y.FeeShare)),
+			strconv.FormatFloat(y.RewardCallRate*100, 'f', 1, 64),
+			strconv.FormatFloat(y.RewardYield, 'f', 2, 64),
+			strconv.FormatFloat(y.FeeYield, 'f', 6, 64),
+		})
+	}
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.Render()
+}
+
+func (w *wizard) pollStats() {
+	if w.offchain {
+		return
+	}
+	polls, err := w.getPolls()
+	if err != nil {
+		glog.Errorf("Error getting polls: %v", err)
+		return
+	}
+
+	fmt.Println("+-----+")
+	fmt.Println("|POLLS|")
+	fmt.Println("+-----+")
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Address", "Proposal", "Status", "End Block", "Yes", "No", "Voters", "Your Vote"})
+	for _, p := range polls {
+		// Tallies weighted by the current stake of the voters are estimates
+		status := p.Status
+		if p.Estimate {
+			status += " (current-stake estimate)"
+		}
+		table.Append([]string{
+			p.Address.Hex(),
+			p.Proposal,
+			status,
+			strconv.FormatInt(p.EndBlock, 10),
+			eth.FormatUnits(p.Yes, "LPT"),
+			eth.FormatUnits(p.No, "LPT"),
+			strconv.Itoa(p.Voters),
+			ownVote(p.Vote),
+		})
+	}
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.Render()
+}
+
 func (w *wizard) getProtocolParameters() (lpTypes.ProtocolParameters, error) {
 	resp, err := http.Get(fmt.Sprintf("http://%v:%v/protocolParameters", w.host, w.httpPort))
 	if err != nil {
@@ -439,6 +539,106 @@ func (w *wizard) getDelegatorInfo() (lpTypes.Delegator, error) {
 	return dInfo, nil
 }
 
+func (w *wizard) getDelegatorEarnings() (*eth.DelegatorEarningsHistory, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%v:%v/delegatorEarnings", w.host, w.httpPort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d %s", resp.StatusCode, result)
+	}
+
+	var history eth.DelegatorEarningsHistory
+	if err := json.Unmarshal(result, &history); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+func (w *wizard) getOrchestratorYields() ([]eth.OrchestratorYield, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%v:%v/orchestratorYields", w.host, w.httpPort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d %s", resp.StatusCode, result)
+	}
+
+	var yields []eth.OrchestratorYield
+	if err := json.Unmarshal(result, &yields); err != nil {
+		return nil, err
+	}
+
+	return yields, nil
+}
+
+func (w *wizard) getPolls() ([]*eth.PollInfo, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%v:%v/polls", w.host, w.httpPort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d %s", resp.StatusCode, result)
+	}
+
+	var polls []*eth.PollInfo
+	if err := json.Unmarshal(result, &polls); err != nil {
+		return nil, err
+	}
+
+	return polls, nil
+}
+
+func (w *wizard) getRegisteredTranscoders() ([]lcommon.RemoteTranscoderInfo, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%v:%v/registeredTranscoders", w.host, w.httpPort))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	result, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("http error: %d %s", resp.StatusCode, result)
+	}
+
+	var transcoders []lcommon.RemoteTranscoderInfo
+	if err := json.Unmarshal(result, &transcoders); err != nil {
+		return nil, err
+	}
+
+	return transcoders, nil
+}
+
 func (w *wizard) maxGasPrice() string {
 	max := httpGet(fmt.Sprintf("http://%v:%v/maxGasPrice", w.host, w.httpPort))
 	if max == "" {
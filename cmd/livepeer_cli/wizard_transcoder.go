@@ -234,13 +234,7 @@ func (w *wizard) vote() {
 		return
 	}
 
-	fmt.Print("Enter the contract address for the poll you want to vote in -")
-	poll := w.readStringAndValidate(func(in string) (string, error) {
-		if !ethcommon.IsHexAddress(in) {
-			return "", fmt.Errorf("invalid hex address address=%v", in)
-		}
-		return in, nil
-	})
+	poll := w.selectPoll()
 
 	var (
 		confirm = "n"
@@ -278,6 +272,63 @@ func (w *wizard) vote() {
 	fmt.Printf("\nVote success tx=0x%x\n", []byte(result))
 }
 
+// selectPoll lets the user pick one of the active polls discovered by the node. The poll address has to be entered
+// manually if the node does not watch for polls
+func (w *wizard) selectPoll() string {
+	polls, err := w.getPolls()
+	if err != nil {
+		glog.V(4).Infof("Error getting polls: %v", err)
+	}
+
+	var active []*eth.PollInfo
+	for _, p := range polls {
+		if p.Status == eth.PollActive {
+			active = append(active, p)
+		}
+	}
+
+	if len(active) == 0 {
+		fmt.Print("Enter the contract address for the poll you want to vote in -")
+		return w.readStringAndValidate(func(in string) (string, error) {
+			if !ethcommon.IsHexAddress(in) {
+				return "", fmt.Errorf("invalid hex address address=%v", in)
+			}
+			return in, nil
+		})
+	}
+
+	wtr := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(wtr, "Identifier\tAddress\tProposal\tEnd Block\tYes (%)\tNo (%)\tYour Vote")
+	for i, p := range active {
+		fmt.Fprintf(wtr, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", i, p.Address.Hex(), p.Proposal, p.EndBlock, votePerc(p.Yes, p.TotalVoteStake), votePerc(p.No, p.TotalVoteStake), ownVote(p.Vote))
+	}
+	wtr.Flush()
+
+	for {
+		fmt.Printf("Enter the identifier of the poll you want to vote in -")
+		id := w.readInt()
+		if id >= 0 && id < len(active) {
+			return active[id].Address.Hex()
+		}
+		fmt.Println("Must enter a valid identifier")
+	}
+}
+
+func votePerc(stake, total *big.Int) string {
+	if stake == nil || total == nil || total.Sign() == 0 {
+		return "0.00"
+	}
+	perc, _ := new(big.Rat).SetFrac(new(big.Int).Mul(stake, big.NewInt(100)), total).Float64()
+	return strconv.FormatFloat(perc, 'f', 2, 64)
+}
+
+func ownVote(choiceID *int64) string {
+	if choiceID == nil {
+		return "-"
+	}
+	return types.VoteChoice(*choiceID).String()
+}
+
 func (w *wizard) showVoteChoices() {
 	wtr := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
 	fmt.Fprintln(wtr, "Identifier\tVoting Choices")
@@ -367,3 +418,54 @@ func (w *wizard) setMaxSessions() {
 		return
 	}
 }
+
+func (w *wizard) setTranscoderDraining() {
+	transcoders, err := w.getRegisteredTranscoders()
+	if err != nil {
+		fmt.Printf("Error getting registered transcoders: %v\n", err)
+		return
+	}
+	if len(transcoders) == 0 {
+		fmt.Println("No remote transcoders are registered")
+		return
+	}
+
+	wtr := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(wtr, "Identifier\tID\tAddress\tLoad\tCapacity\tPixel Load (pixels/s)\tPixel Capacity (pixels/s)\tCapabilities\tDraining")
+	for i, t := range transcoders {
+		fmt.Fprintf(wtr, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", i, t.ID, t.Address, t.Load, t.Capacity, t.PixelLoad, t.PixelCapacity, strings.Join(t.Capabilities, ","), t.Draining)
+	}
+	wtr.Flush()
+
+	var transcoder lpcommon.RemoteTranscoderInfo
+	for {
+		fmt.Printf("Enter the identifier of the transcoder -")
+		id := w.readInt()
+		if id >= 0 && id < len(transcoders) {
+			transcoder = transcoders[id]
+			break
+		}
+		fmt.Println("Must enter a valid identifier")
+	}
+
+	draining := !transcoder.Draining
+	if draining {
+		fmt.Printf("Put transcoder %v in maintenance mode? New sessions will not be assigned to it (y/n) - ", transcoder.ID)
+	} else {
+		fmt.Printf("Put transcoder %v back in service? (y/n) - ", transcoder.ID)
+	}
+	if w.readStringYesOrNo() != "y" {
+		return
+	}
+
+	data := url.Values{
+		"transcoder": {transcoder.ID},
+		"draining":   {strconv.FormatBool(draining)},
+	}
+	result, ok := httpPostWithParams(fmt.Sprintf("http://%v:%v/setTranscoderDraining", w.host, w.httpPort), data)
+	if ok {
+		fmt.Printf(result)
+	} else {
+		fmt.Printf("Error setting transcoder draining: %v", result)
+	}
+}
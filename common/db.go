@@ -9,12 +9,16 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
 	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/lpms/ffmpeg"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 )
@@ -41,6 +45,25 @@ type DB struct {
 	findLatestMiniHeader             *sql.Stmt
 	findAllMiniHeadersSortedByNumber *sql.Stmt
 	deleteMiniHeader                 *sql.Stmt
+	updateRewardCall                 *sql.Stmt
+	selectRewardCalls                *sql.Stmt
+	updatePoll                       *sql.Stmt
+	deletePoll                       *sql.Stmt
+	selectPolls                      *sql.Stmt
+	updatePollVote                   *sql.Stmt
+	deletePollVote                   *sql.Stmt
+	selectPollVotes                  *sql.Stmt
+	updatePollTally                  *sql.Stmt
+	selectPollTally                  *sql.Stmt
+	insertEvidence                   *sql.Stmt
+	penalizeOrch                     *sql.Stmt
+	selectOrchPenalties              *sql.Stmt
+	deleteOrchPenalty                *sql.Stmt
+	updateOrchInfo                   *sql.Stmt
+	selectOrchInfos                  *sql.Stmt
+	insertReservation                *sql.Stmt
+	deleteReservation                *sql.Stmt
+	selectReservations               *sql.Stmt
 }
 
 // DBOrch is the type binding for a row result from the orchestrators table
@@ -61,12 +84,103 @@ type DBUnbondingLock struct {
 	WithdrawRound int64
 }
 
+// DBRewardCall is the type binding for a row result from the rewardCalls table
+type DBRewardCall struct {
+	Round    int64
+	Status   string
+	Block    int64
+	TxHash   string
+	Attempts int
+	Error    string
+}
+
+// DBPoll is the type binding for a row result from the polls table
+type DBPoll struct {
+	Address      ethcommon.Address
+	Proposal     string
+	EndBlock     int64
+	Quorum       int64
+	Quota        int64
+	CreatedBlock int64
+}
+
+// DBPollVote is the type binding for a row result from the pollVotes table
+type DBPollVote struct {
+	Poll     ethcommon.Address
+	Voter    ethcommon.Address
+	ChoiceID int64
+	Block    int64
+}
+
+// DBPollTally is the type binding for a row result from the pollTallies table
+type DBPollTally struct {
+	Poll        ethcommon.Address
+	Yes         *big.Int
+	No          *big.Int
+	Voters      int64
+	TotalBonded *big.Int
+	// L1Block is the L1 block at which the tally was taken
+	L1Block int64
+	// Block is the last block of the poll at which the stake of the voters was read
+	Block int64
+	// Estimate is set if the historical state at Block was not available and the tally uses the stake of the voters
+	// at the time the tally was taken instead
+	Estimate bool
+}
+
+// DBEvidence is the type binding for a row result from the verificationEvidence table
+type DBEvidence struct {
+	ID           int64
+	Orchestrator ethcommon.Address
+	ManifestID   string
+	SeqNo        uint64
+	Reason       string
+	Fatal        bool
+	// Encoded evidence record
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// DBEvidenceFilter is an object used to attach a filter to a selectEvidence query
+type DBEvidenceFilter struct {
+	Orchestrator *ethcommon.Address
+	Since        time.Time
+	Limit        int
+}
+
+// DBOrchPenalty is the type binding for a row result from the orchPenalties table
+type DBOrchPenalty struct {
+	Orchestrator ethcommon.Address
+	Failures     int64
+	DeniedUntil  time.Time
+}
+
+// DBOrchInfo is the type binding for a row result from the orchInfoCache table
+type DBOrchInfo struct {
+	Orchestrator ethcommon.Address
+	Capabilities *net.Capabilities
+	PriceInfo    *net.PriceInfo
+	ExpiresAt    time.Time
+}
+
+// DBCapacityReservation is the type binding for a row result from the capacityReservations table
+type DBCapacityReservation struct {
+	ID       string
+	Sender   ethcommon.Address
+	Sessions int
+	Profiles []ffmpeg.VideoProfile
+	Start    time.Time
+	End      time.Time
+}
+
 // DBOrchFilter is an object used to attach a filter to a selectOrch query
 type DBOrchFilter struct {
 	MaxPrice       *big.Rat
 	CurrentRound   *big.Int
 	Addresses      []ethcommon.Address
 	UpdatedLastDay bool
+	// Exclude orchestrators that are denied by a verification penalty
+	ExcludeDenied bool
 }
 
 var LivepeerDBVersion = 1
@@ -131,6 +245,85 @@ var schema = `
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_blockheaders_number ON blockheaders(number);
+
+	CREATE TABLE IF NOT EXISTS rewardCalls (
+		round int64,
+		status STRING,
+		block int64,
+		txHash STRING,
+		attempts int,
+		error STRING,
+		updatedAt STRING DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(round, status)
+	);
+
+	CREATE TABLE IF NOT EXISTS polls (
+		address STRING PRIMARY KEY,
+		proposal STRING,
+		endBlock int64,
+		quorum int64,
+		quota int64,
+		createdBlock int64
+	);
+
+	CREATE TABLE IF NOT EXISTS pollVotes (
+		poll STRING,
+		voter STRING,
+		choiceID int64,
+		block int64,
+		PRIMARY KEY(poll, voter, block)
+	);
+
+	CREATE TABLE IF NOT EXISTS pollTallies (
+		poll STRING PRIMARY KEY,
+		yes STRING,
+		no STRING,
+		voters int64,
+		totalBonded STRING,
+		l1Block int64,
+		block int64,
+		estimate BOOLEAN
+	);
+
+	CREATE TABLE IF NOT EXISTS verificationEvidence (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		createdAt int64,
+		orchestrator STRING,
+		manifestID STRING,
+		seqNo int64,
+		reason STRING,
+		fatal BOOLEAN,
+		data BLOB
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_verificationevidence_orchestrator ON verificationEvidence(orchestrator);
+
+	CREATE TABLE IF NOT EXISTS orchPenalties (
+		ethereumAddr STRING PRIMARY KEY,
+		failures int64,
+		deniedUntil int64
+	);
+
+	CREATE TABLE IF NOT EXISTS orchInfoCache (
+		ethereumAddr STRING PRIMARY KEY,
+		capabilities BLOB,
+		pricePerUnit int64,
+		pixelsPerUnit int64,
+		expiresAt int64
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_orchinfocache_expiresat ON orchInfoCache(expiresAt);
+
+	CREATE TABLE IF NOT EXISTS capacityReservations (
+		id STRING PRIMARY KEY,
+		sender STRING,
+		sessions int,
+		profiles BLOB,
+		startsAt int64,
+		endsAt int64
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_capacityreservations_endsat ON capacityReservations(endsAt);
 `
 
 func NewDBOrch(ethereumAddr string, serviceURI string, pricePerPixel int64, activationRound int64, deactivationRound int64, stake int64) *DBOrch {
@@ -360,6 +553,198 @@ func InitDB(dbPath string) (*DB, error) {
 	}
 	d.deleteMiniHeader = stmt
 
+	// Reward calls prepared statements
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO rewardCalls(round, status, block, txHash, attempts, error, updatedAt)
+	VALUES(:round, :status, :block, :txHash, :attempts, :error, datetime())
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updateRewardCall ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updateRewardCall = stmt
+
+	stmt, err = db.Prepare("SELECT round, status, block, txHash, attempts, error FROM rewardCalls ORDER BY round DESC, rowid DESC LIMIT ?")
+	if err != nil {
+		glog.Error("Unable to prepare selectRewardCalls ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectRewardCalls = stmt
+
+	// Polls prepared statements
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO polls(address, proposal, endBlock, quorum, quota, createdBlock)
+	VALUES(:address, :proposal, :endBlock, :quorum, :quota, :createdBlock)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updatePoll ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updatePoll = stmt
+
+	stmt, err = db.Prepare("DELETE FROM polls WHERE address=?")
+	if err != nil {
+		glog.Error("Unable to prepare deletePoll ", err)
+		d.Close()
+		return nil, err
+	}
+	d.deletePoll = stmt
+
+	stmt, err = db.Prepare("SELECT address, proposal, endBlock, quorum, quota, createdBlock FROM polls ORDER BY endBlock DESC")
+	if err != nil {
+		glog.Error("Unable to prepare selectPolls ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectPolls = stmt
+
+	// A vote only replaces an earlier vote of the same voter
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO pollVotes(poll, voter, choiceID, block)
+	VALUES(:poll, :voter, :choiceID, :block)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updatePollVote ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updatePollVote = stmt
+
+	stmt, err = db.Prepare("DELETE FROM pollVotes WHERE poll=? AND voter=? AND block=?")
+	if err != nil {
+		glog.Error("Unable to prepare deletePollVote ", err)
+		d.Close()
+		return nil, err
+	}
+	d.deletePollVote = stmt
+
+	// SQLite returns the choiceID of the row with the maximum block of each group
+	stmt, err = db.Prepare("SELECT poll, voter, choiceID, MAX(block) AS block FROM pollVotes WHERE poll=? GROUP BY voter ORDER BY block ASC")
+	if err != nil {
+		glog.Error("Unable to prepare selectPollVotes ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectPollVotes = stmt
+
+	stmt, err = db.Prepare(`
+	INSERT OR REPLACE INTO pollTallies(poll, yes, no, voters, totalBonded, l1Block, block, estimate)
+	VALUES(:poll, :yes, :no, :voters, :totalBonded, :l1Block, :block, :estimate)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updatePollTally ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updatePollTally = stmt
+
+	stmt, err = db.Prepare("SELECT poll, yes, no, voters, totalBonded, l1Block, block, estimate FROM pollTallies WHERE poll=?")
+	if err != nil {
+		glog.Error("Unable to prepare selectPollTally ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectPollTally = stmt
+
+	// Verification evidence prepared statements
+	stmt, err = db.Prepare(`
+	INSERT INTO verificationEvidence(createdAt, orchestrator, manifestID, seqNo, reason, fatal, data)
+	VALUES(:createdAt, :orchestrator, :manifestID, :seqNo, :reason, :fatal, :data)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare insertEvidence ", err)
+		d.Close()
+		return nil, err
+	}
+	d.insertEvidence = stmt
+
+	// Every failure extends the penalty of an orchestrator, starting from now if it already expired
+	stmt, err = db.Prepare(`
+	INSERT INTO orchPenalties(ethereumAddr, failures, deniedUntil)
+	VALUES(:ethereumAddr, 1, :now + :penalty)
+	ON CONFLICT(ethereumAddr) DO UPDATE SET
+	failures = orchPenalties.failures + 1,
+	deniedUntil = max(orchPenalties.deniedUntil, :now) + :penalty
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare penalizeOrch ", err)
+		d.Close()
+		return nil, err
+	}
+	d.penalizeOrch = stmt
+
+	stmt, err = db.Prepare("SELECT ethereumAddr, failures, deniedUntil FROM orchPenalties ORDER BY deniedUntil DESC")
+	if err != nil {
+		glog.Error("Unable to prepare selectOrchPenalties ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectOrchPenalties = stmt
+
+	stmt, err = db.Prepare("DELETE FROM orchPenalties WHERE ethereumAddr=?")
+	if err != nil {
+		glog.Error("Unable to prepare deleteOrchPenalty ", err)
+		d.Close()
+		return nil, err
+	}
+	d.deleteOrchPenalty = stmt
+
+	// Orchestrator info cache prepared statements
+	stmt, err = db.Prepare(`
+	INSERT INTO orchInfoCache(ethereumAddr, capabilities, pricePerUnit, pixelsPerUnit, expiresAt)
+	VALUES(:ethereumAddr, :capabilities, :pricePerUnit, :pixelsPerUnit, :expiresAt)
+	ON CONFLICT(ethereumAddr) DO UPDATE SET
+	capabilities = excluded.capabilities,
+	pricePerUnit = excluded.pricePerUnit,
+	pixelsPerUnit = excluded.pixelsPerUnit,
+	expiresAt = excluded.expiresAt
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare updateOrchInfo ", err)
+		d.Close()
+		return nil, err
+	}
+	d.updateOrchInfo = stmt
+
+	stmt, err = db.Prepare("SELECT ethereumAddr, capabilities, pricePerUnit, pixelsPerUnit, expiresAt FROM orchInfoCache WHERE expiresAt > ?")
+	if err != nil {
+		glog.Error("Unable to prepare selectOrchInfos ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectOrchInfos = stmt
+
+	// Capacity reservation prepared statements
+	stmt, err = db.Prepare(`
+	INSERT INTO capacityReservations(id, sender, sessions, profiles, startsAt, endsAt)
+	VALUES(:id, :sender, :sessions, :profiles, :startsAt, :endsAt)
+	`)
+	if err != nil {
+		glog.Error("Unable to prepare insertReservation ", err)
+		d.Close()
+		return nil, err
+	}
+	d.insertReservation = stmt
+
+	stmt, err = db.Prepare("DELETE FROM capacityReservations WHERE id = ?")
+	if err != nil {
+		glog.Error("Unable to prepare deleteReservation ", err)
+		d.Close()
+		return nil, err
+	}
+	d.deleteReservation = stmt
+
+	stmt, err = db.Prepare("SELECT id, sender, sessions, profiles, startsAt, endsAt FROM capacityReservations WHERE endsAt > ?")
+	if err != nil {
+		glog.Error("Unable to prepare selectReservations ", err)
+		d.Close()
+		return nil, err
+	}
+	d.selectReservations = stmt
+
 	glog.V(DEBUG).Info("Initialized DB node")
 	return &d, nil
 }
@@ -417,6 +802,63 @@ func (db *DB) Close() {
 	if db.deleteMiniHeader != nil {
 		db.deleteMiniHeader.Close()
 	}
+	if db.updateRewardCall != nil {
+		db.updateRewardCall.Close()
+	}
+	if db.selectRewardCalls != nil {
+		db.selectRewardCalls.Close()
+	}
+	if db.updatePoll != nil {
+		db.updatePoll.Close()
+	}
+	if db.deletePoll != nil {
+		db.deletePoll.Close()
+	}
+	if db.selectPolls != nil {
+		db.selectPolls.Close()
+	}
+	if db.updatePollVote != nil {
+		db.updatePollVote.Close()
+	}
+	if db.deletePollVote != nil {
+		db.deletePollVote.Close()
+	}
+	if db.selectPollVotes != nil {
+		db.selectPollVotes.Close()
+	}
+	if db.updatePollTally != nil {
+		db.updatePollTally.Close()
+	}
+	if db.selectPollTally != nil {
+		db.selectPollTally.Close()
+	}
+	if db.insertEvidence != nil {
+		db.insertEvidence.Close()
+	}
+	if db.penalizeOrch != nil {
+		db.penalizeOrch.Close()
+	}
+	if db.selectOrchPenalties != nil {
+		db.selectOrchPenalties.Close()
+	}
+	if db.deleteOrchPenalty != nil {
+		db.deleteOrchPenalty.Close()
+	}
+	if db.updateOrchInfo != nil {
+		db.updateOrchInfo.Close()
+	}
+	if db.selectOrchInfos != nil {
+		db.selectOrchInfos.Close()
+	}
+	if db.insertReservation != nil {
+		db.insertReservation.Close()
+	}
+	if db.deleteReservation != nil {
+		db.deleteReservation.Close()
+	}
+	if db.selectReservations != nil {
+		db.selectReservations.Close()
+	}
 	if db.dbh != nil {
 		db.dbh.Close()
 	}
@@ -460,6 +902,65 @@ func (db *DB) SetChainID(id *big.Int) error {
 	return nil
 }
 
+// RegistryVersion returns the version of the last orchestrator registry accepted from the given URL, or 0 if there is none
+func (db *DB) RegistryVersion(registryURL string) (uint64, error) {
+	if db == nil {
+		return 0, nil
+	}
+
+	versionString, err := db.selectKVStore("registryVersion_" + registryURL)
+	if err != nil {
+		return 0, err
+	}
+
+	if versionString == "" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseUint(versionString, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("unable to convert registry version string to uint64")
+	}
+
+	return version, nil
+}
+
+// SetRegistryVersion stores the version of the last orchestrator registry accepted from the given URL
+func (db *DB) SetRegistryVersion(registryURL string, version uint64) error {
+	if db == nil {
+		return nil
+	}
+
+	return db.updateKVStore("registryVersion_"+registryURL, strconv.FormatUint(version, 10))
+}
+
+// ReplayCheckpoint returns the last block handled by the event replay with the given name
+func (db *DB) ReplayCheckpoint(name string) (*big.Int, error) {
+	blkString, err := db.selectKVStore("replayCheckpoint_" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	if blkString == "" {
+		return nil, nil
+	}
+
+	blk, ok := new(big.Int).SetString(blkString, 10)
+	if !ok {
+		return nil, fmt.Errorf("unable to convert replay checkpoint string to big.Int")
+	}
+
+	return blk, nil
+}
+
+// SetReplayCheckpoint stores the last block handled by the event replay with the given name
+func (db *DB) SetReplayCheckpoint(name string, block *big.Int) error {
+	if block == nil {
+		return nil
+	}
+	return db.updateKVStore("replayCheckpoint_"+name, block.String())
+}
+
 func (db *DB) selectKVStore(key string) (string, error) {
 	row := db.selectKV.QueryRow(key)
 	var valueString string
@@ -853,6 +1354,10 @@ func buildFilterOrchsQuery(filter *DBOrchFilter) (string, error) {
 			}
 			filters = append(filters, fmt.Sprintf("ethereumAddr IN (%v)", strings.Join(hexAddrs, ", ")))
 		}
+
+		if filter.ExcludeDenied {
+			filters = append(filters, "ethereumAddr NOT IN (SELECT ethereumAddr FROM orchPenalties WHERE deniedUntil > CAST(strftime('%s', 'now') AS INTEGER))")
+		}
 	}
 
 	if len(filters) > 0 {
@@ -951,6 +1456,505 @@ func (db *DB) DeleteMiniHeader(hash ethcommon.Hash) error {
 	return nil
 }
 
+// UpdateRewardCall inserts or replaces an outcome of the reward call for a round. A round can have several
+// outcomes, i.e. a failed call is also reported as missed when the round ends
+func (db *DB) UpdateRewardCall(call *DBRewardCall) error {
+	if db == nil || call == nil {
+		return nil
+	}
+
+	_, err := db.updateRewardCall.Exec(
+		sql.Named("round", call.Round),
+		sql.Named("status", call.Status),
+		sql.Named("block", call.Block),
+		sql.Named("txHash", call.TxHash),
+		sql.Named("attempts", call.Attempts),
+		sql.Named("error", call.Error),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating reward call round=%v", call.Round)
+	}
+	return nil
+}
+
+// RewardCalls returns the outcomes of the most recent reward calls sorted in descending order by round,
+// with the latest outcome of a round first
+func (db *DB) RewardCalls(limit int) ([]*DBRewardCall, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectRewardCalls.Query(limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	calls := []*DBRewardCall{}
+	for rows.Next() {
+		var call DBRewardCall
+		if err := rows.Scan(&call.Round, &call.Status, &call.Block, &call.TxHash, &call.Attempts, &call.Error); err != nil {
+			return nil, err
+		}
+		calls = append(calls, &call)
+	}
+	return calls, nil
+}
+
+// UpdatePoll inserts or replaces a poll
+func (db *DB) UpdatePoll(poll *DBPoll) error {
+	if db == nil || poll == nil {
+		return nil
+	}
+
+	_, err := db.updatePoll.Exec(
+		sql.Named("address", poll.Address.Hex()),
+		sql.Named("proposal", poll.Proposal),
+		sql.Named("endBlock", poll.EndBlock),
+		sql.Named("quorum", poll.Quorum),
+		sql.Named("quota", poll.Quota),
+		sql.Named("createdBlock", poll.CreatedBlock),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating poll address=%v", poll.Address.Hex())
+	}
+	return nil
+}
+
+// DeletePoll deletes a poll and the votes cast in it
+func (db *DB) DeletePoll(addr ethcommon.Address) error {
+	if db == nil {
+		return nil
+	}
+
+	if _, err := db.deletePoll.Exec(addr.Hex()); err != nil {
+		return errors.Wrapf(err, "failed deleting poll address=%v", addr.Hex())
+	}
+	if _, err := db.dbh.Exec("DELETE FROM pollVotes WHERE poll=?", addr.Hex()); err != nil {
+		return errors.Wrapf(err, "failed deleting votes for poll address=%v", addr.Hex())
+	}
+	if _, err := db.dbh.Exec("DELETE FROM pollTallies WHERE poll=?", addr.Hex()); err != nil {
+		return errors.Wrapf(err, "failed deleting tally for poll address=%v", addr.Hex())
+	}
+	return nil
+}
+
+// Polls returns all known polls sorted in descending order by end block
+func (db *DB) Polls() ([]*DBPoll, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectPolls.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	polls := []*DBPoll{}
+	for rows.Next() {
+		var (
+			poll DBPoll
+			addr string
+		)
+		if err := rows.Scan(&addr, &poll.Proposal, &poll.EndBlock, &poll.Quorum, &poll.Quota, &poll.CreatedBlock); err != nil {
+			return nil, err
+		}
+		poll.Address = ethcommon.HexToAddress(addr)
+		polls = append(polls, &poll)
+	}
+	return polls, nil
+}
+
+// UpdatePollVote stores a vote. Earlier votes of the same voter are kept so that they are restored if the vote
+// is removed by a reorg
+func (db *DB) UpdatePollVote(vote *DBPollVote) error {
+	if db == nil || vote == nil {
+		return nil
+	}
+
+	_, err := db.updatePollVote.Exec(
+		sql.Named("poll", vote.Poll.Hex()),
+		sql.Named("voter", vote.Voter.Hex()),
+		sql.Named("choiceID", vote.ChoiceID),
+		sql.Named("block", vote.Block),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating vote poll=%v voter=%v", vote.Poll.Hex(), vote.Voter.Hex())
+	}
+	return nil
+}
+
+// DeletePollVote deletes a vote cast in the provided block, restoring the previous vote of the voter if any.
+// This method will return nil for non-existent votes
+func (db *DB) DeletePollVote(vote *DBPollVote) error {
+	if db == nil || vote == nil {
+		return nil
+	}
+
+	if _, err := db.deletePollVote.Exec(vote.Poll.Hex(), vote.Voter.Hex(), vote.Block); err != nil {
+		return errors.Wrapf(err, "failed deleting vote poll=%v voter=%v", vote.Poll.Hex(), vote.Voter.Hex())
+	}
+	return nil
+}
+
+// PollVotes returns the latest vote of each voter in a poll
+func (db *DB) PollVotes(poll ethcommon.Address) ([]*DBPollVote, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectPollVotes.Query(poll.Hex())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	votes := []*DBPollVote{}
+	for rows.Next() {
+		var (
+			vote         DBPollVote
+			pAddr, voter string
+		)
+		if err := rows.Scan(&pAddr, &voter, &vote.ChoiceID, &vote.Block); err != nil {
+			return nil, err
+		}
+		vote.Poll = ethcommon.HexToAddress(pAddr)
+		vote.Voter = ethcommon.HexToAddress(voter)
+		votes = append(votes, &vote)
+	}
+	return votes, nil
+}
+
+// UpdatePollTally stores the final tally of a poll
+func (db *DB) UpdatePollTally(tally *DBPollTally) error {
+	if db == nil || tally == nil {
+		return nil
+	}
+
+	_, err := db.updatePollTally.Exec(
+		sql.Named("poll", tally.Poll.Hex()),
+		sql.Named("yes", tally.Yes.String()),
+		sql.Named("no", tally.No.String()),
+		sql.Named("voters", tally.Voters),
+		sql.Named("totalBonded", tally.TotalBonded.String()),
+		sql.Named("l1Block", tally.L1Block),
+		sql.Named("block", tally.Block),
+		sql.Named("estimate", tally.Estimate),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed updating tally poll=%v", tally.Poll.Hex())
+	}
+	return nil
+}
+
+// PollTally returns the final tally of a poll or nil if the poll has not been tallied
+func (db *DB) PollTally(poll ethcommon.Address) (*DBPollTally, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	var (
+		pAddr, yes, no, totalBonded string
+		tally                       DBPollTally
+	)
+	err := db.selectPollTally.QueryRow(poll.Hex()).Scan(&pAddr, &yes, &no, &tally.Voters, &totalBonded, &tally.L1Block, &tally.Block, &tally.Estimate)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed selecting tally poll=%v", poll.Hex())
+	}
+
+	var ok bool
+	tally.Poll = ethcommon.HexToAddress(pAddr)
+	if tally.Yes, ok = new(big.Int).SetString(yes, 10); !ok {
+		return nil, fmt.Errorf("invalid yes stake %v for poll=%v", yes, poll.Hex())
+	}
+	if tally.No, ok = new(big.Int).SetString(no, 10); !ok {
+		return nil, fmt.Errorf("invalid no stake %v for poll=%v", no, poll.Hex())
+	}
+	if tally.TotalBonded, ok = new(big.Int).SetString(totalBonded, 10); !ok {
+		return nil, fmt.Errorf("invalid total bonded %v for poll=%v", totalBonded, poll.Hex())
+	}
+	return &tally, nil
+}
+
+// InsertEvidence stores an encoded record of a failed verification
+func (db *DB) InsertEvidence(e *DBEvidence) error {
+	if db == nil || e == nil {
+		return nil
+	}
+
+	createdAt := e.CreatedAt
+	if createdAt.IsZero() {
+		createdAt = time.Now()
+	}
+	_, err := db.insertEvidence.Exec(
+		sql.Named("createdAt", createdAt.Unix()),
+		sql.Named("orchestrator", e.Orchestrator.Hex()),
+		sql.Named("manifestID", e.ManifestID),
+		sql.Named("seqNo", int64(e.SeqNo)),
+		sql.Named("reason", e.Reason),
+		sql.Named("fatal", e.Fatal),
+		sql.Named("data", e.Data),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed inserting evidence orchestrator=%v manifestID=%v seqNo=%v", e.Orchestrator.Hex(), e.ManifestID, e.SeqNo)
+	}
+	return nil
+}
+
+// SelectEvidence returns the stored evidence records matching the filter sorted in descending order by creation time
+func (db *DB) SelectEvidence(filter *DBEvidenceFilter) ([]*DBEvidence, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	qry := "SELECT id, createdAt, orchestrator, manifestID, seqNo, reason, fatal, data FROM verificationEvidence"
+	var (
+		filters []string
+		args    []interface{}
+	)
+	if filter != nil && filter.Orchestrator != nil {
+		filters = append(filters, "orchestrator = ?")
+		args = append(args, filter.Orchestrator.Hex())
+	}
+	if filter != nil && !filter.Since.IsZero() {
+		filters = append(filters, "createdAt >= ?")
+		args = append(args, filter.Since.Unix())
+	}
+	if len(filters) > 0 {
+		qry += " WHERE " + strings.Join(filters, " AND ")
+	}
+	qry += " ORDER BY id DESC"
+	if filter != nil && filter.Limit > 0 {
+		qry += " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := db.dbh.Query(qry, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []*DBEvidence{}
+	for rows.Next() {
+		var (
+			e         DBEvidence
+			createdAt int64
+			orch      string
+			seqNo     int64
+		)
+		if err := rows.Scan(&e.ID, &createdAt, &orch, &e.ManifestID, &seqNo, &e.Reason, &e.Fatal, &e.Data); err != nil {
+			return nil, err
+		}
+		e.CreatedAt = time.Unix(createdAt, 0)
+		e.Orchestrator = ethcommon.HexToAddress(orch)
+		e.SeqNo = uint64(seqNo)
+		records = append(records, &e)
+	}
+	return records, nil
+}
+
+// PenalizeOrch records a failure for an orchestrator and denies it for the penalty duration.
+// Penalties add up, so an orchestrator that keeps failing is denied for longer
+func (db *DB) PenalizeOrch(addr ethcommon.Address, penalty time.Duration) error {
+	if db == nil {
+		return nil
+	}
+
+	_, err := db.penalizeOrch.Exec(
+		sql.Named("ethereumAddr", addr.Hex()),
+		sql.Named("now", time.Now().Unix()),
+		sql.Named("penalty", int64(penalty.Seconds())),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed penalizing orchestrator=%v", addr.Hex())
+	}
+	return nil
+}
+
+// OrchPenalties returns the penalties of all orchestrators that failed verification sorted in descending order by expiry
+func (db *DB) OrchPenalties() ([]*DBOrchPenalty, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectOrchPenalties.Query()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	penalties := []*DBOrchPenalty{}
+	for rows.Next() {
+		var (
+			p           DBOrchPenalty
+			addr        string
+			deniedUntil int64
+		)
+		if err := rows.Scan(&addr, &p.Failures, &deniedUntil); err != nil {
+			return nil, err
+		}
+		p.Orchestrator = ethcommon.HexToAddress(addr)
+		p.DeniedUntil = time.Unix(deniedUntil, 0)
+		penalties = append(penalties, &p)
+	}
+	return penalties, nil
+}
+
+// DeleteOrchPenalty lifts the penalty of an orchestrator and resets its failures
+func (db *DB) DeleteOrchPenalty(addr ethcommon.Address) error {
+	if db == nil {
+		return nil
+	}
+
+	if _, err := db.deleteOrchPenalty.Exec(addr.Hex()); err != nil {
+		return errors.Wrapf(err, "failed deleting penalty orchestrator=%v", addr.Hex())
+	}
+	return nil
+}
+
+// UpdateOrchInfo caches the capabilities and price advertised by an orchestrator until the info expires
+func (db *DB) UpdateOrchInfo(info *DBOrchInfo) error {
+	if db == nil || info == nil {
+		return nil
+	}
+
+	var caps []byte
+	if info.Capabilities != nil {
+		var err error
+		if caps, err = proto.Marshal(info.Capabilities); err != nil {
+			return errors.Wrapf(err, "failed encoding capabilities orchestrator=%v", info.Orchestrator.Hex())
+		}
+	}
+	var pricePerUnit, pixelsPerUnit int64
+	if info.PriceInfo != nil {
+		pricePerUnit, pixelsPerUnit = info.PriceInfo.PricePerUnit, info.PriceInfo.PixelsPerUnit
+	}
+
+	_, err := db.updateOrchInfo.Exec(
+		sql.Named("ethereumAddr", info.Orchestrator.Hex()),
+		sql.Named("capabilities", caps),
+		sql.Named("pricePerUnit", pricePerUnit),
+		sql.Named("pixelsPerUnit", pixelsPerUnit),
+		sql.Named("expiresAt", info.ExpiresAt.Unix()),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed caching info orchestrator=%v", info.Orchestrator.Hex())
+	}
+	return nil
+}
+
+// OrchInfos returns the cached orchestrator infos that expire after the given time
+func (db *DB) OrchInfos(expiresAfter time.Time) ([]*DBOrchInfo, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectOrchInfos.Query(expiresAfter.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	infos := []*DBOrchInfo{}
+	for rows.Next() {
+		var (
+			addr          string
+			caps          []byte
+			pricePerUnit  int64
+			pixelsPerUnit int64
+			expiresAt     int64
+		)
+		if err := rows.Scan(&addr, &caps, &pricePerUnit, &pixelsPerUnit, &expiresAt); err != nil {
+			return nil, err
+		}
+		info := &DBOrchInfo{
+			Orchestrator: ethcommon.HexToAddress(addr),
+			ExpiresAt:    time.Unix(expiresAt, 0),
+		}
+		if len(caps) > 0 {
+			info.Capabilities = &net.Capabilities{}
+			if err := proto.Unmarshal(caps, info.Capabilities); err != nil {
+				glog.Errorf("db: Unable to decode cached capabilities orchestrator=%v err=%q", addr, err)
+				continue
+			}
+		}
+		if pixelsPerUnit > 0 {
+			info.PriceInfo = &net.PriceInfo{PricePerUnit: pricePerUnit, PixelsPerUnit: pixelsPerUnit}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// InsertCapacityReservation stores a capacity reservation so that it survives restarts of the orchestrator
+func (db *DB) InsertCapacityReservation(r *DBCapacityReservation) error {
+	if db == nil || r == nil {
+		return nil
+	}
+
+	profiles, err := json.Marshal(r.Profiles)
+	if err != nil {
+		return errors.Wrapf(err, "failed encoding profiles reservation=%v", r.ID)
+	}
+	_, err = db.insertReservation.Exec(
+		sql.Named("id", r.ID),
+		sql.Named("sender", r.Sender.Hex()),
+		sql.Named("sessions", r.Sessions),
+		sql.Named("profiles", profiles),
+		sql.Named("startsAt", r.Start.Unix()),
+		sql.Named("endsAt", r.End.Unix()),
+	)
+	if err != nil {
+		return errors.Wrapf(err, "failed inserting reservation=%v", r.ID)
+	}
+	return nil
+}
+
+// DeleteCapacityReservation removes a capacity reservation
+func (db *DB) DeleteCapacityReservation(id string) error {
+	if db == nil {
+		return nil
+	}
+
+	if _, err := db.deleteReservation.Exec(id); err != nil {
+		return errors.Wrapf(err, "failed deleting reservation=%v", id)
+	}
+	return nil
+}
+
+// CapacityReservations returns the capacity reservations that end after the given time
+func (db *DB) CapacityReservations(endsAfter time.Time) ([]*DBCapacityReservation, error) {
+	if db == nil {
+		return nil, nil
+	}
+
+	rows, err := db.selectReservations.Query(endsAfter.Unix())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	reservations := []*DBCapacityReservation{}
+	for rows.Next() {
+		var (
+			r        DBCapacityReservation
+			sender   string
+			profiles []byte
+			start    int64
+			end      int64
+		)
+		if err := rows.Scan(&r.ID, &sender, &r.Sessions, &profiles, &start, &end); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(profiles, &r.Profiles); err != nil {
+			glog.Errorf("db: Unable to decode profiles reservation=%v err=%q", r.ID, err)
+			continue
+		}
+		r.Sender = ethcommon.HexToAddress(sender)
+		r.Start = time.Unix(start, 0)
+		r.End = time.Unix(end, 0)
+		reservations = append(reservations, &r)
+	}
+	return reservations, nil
+}
+
 func encodeLogsJSON(logs []types.Log) ([]byte, error) {
 	logsEnc, err := json.Marshal(logs)
 	if err != nil {
@@ -13,6 +13,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
 	"github.com/livepeer/lpms/ffmpeg"
 	_ "github.com/mattn/go-sqlite3"
@@ -103,6 +104,72 @@ func TestSetChainID(t *testing.T) {
 	assert.Equal(chainID, expectedChainIDInt)
 }
 
+func TestRegistryVersion(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// No version stored yet
+	version, err := dbh.RegistryVersion("https://registry")
+	assert.Nil(err)
+	assert.Equal(uint64(0), version)
+
+	require.Nil(dbh.SetRegistryVersion("https://registry", 3))
+	version, err = dbh.RegistryVersion("https://registry")
+	assert.Nil(err)
+	assert.Equal(uint64(3), version)
+
+	// Versions are stored per registry URL
+	version, err = dbh.RegistryVersion("https://other")
+	assert.Nil(err)
+	assert.Equal(uint64(0), version)
+
+	// Nil DB
+	var nilDB *DB
+	version, err = nilDB.RegistryVersion("https://registry")
+	assert.Nil(err)
+	assert.Equal(uint64(0), version)
+	assert.Nil(nilDB.SetRegistryVersion("https://registry", 3))
+}
+
+func TestReplayCheckpoint(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	dbh, dbraw, err := TempDB(t)
+	require.Nil(err)
+
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	// No checkpoint stored yet
+	blk, err := dbh.ReplayCheckpoint("foo")
+	assert.Nil(err)
+	assert.Nil(blk)
+
+	require.Nil(dbh.SetReplayCheckpoint("foo", big.NewInt(100)))
+	require.Nil(dbh.SetReplayCheckpoint("bar", big.NewInt(5)))
+
+	blk, err = dbh.ReplayCheckpoint("foo")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(100), blk)
+
+	// Overwrite checkpoint
+	require.Nil(dbh.SetReplayCheckpoint("foo", big.NewInt(200)))
+	blk, err = dbh.ReplayCheckpoint("foo")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(200), blk)
+
+	blk, err = dbh.ReplayCheckpoint("bar")
+	assert.Nil(err)
+	assert.Equal(big.NewInt(5), blk)
+}
+
 func TestDBLastSeenBlock(t *testing.T) {
 	dbh, dbraw, err := TempDB(t)
 	if err != nil {
@@ -1312,6 +1379,65 @@ func TestDeleteMiniHeader(t *testing.T) {
 	assert.Equal(headers[0].Hash, h1.Hash)
 }
 
+func TestRewardCalls(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	// No rows
+	calls, err := dbh.RewardCalls(10)
+	require.Nil(err)
+	assert.Len(calls, 0)
+
+	// Nil call is a no-op
+	assert.Nil(dbh.UpdateRewardCall(nil))
+
+	c0 := &DBRewardCall{Round: 5, Status: "called", Block: 100, TxHash: "0xabc", Attempts: 1}
+	c1 := &DBRewardCall{Round: 6, Status: "failed", Block: 200, Attempts: 3, Error: "boom"}
+	require.Nil(dbh.UpdateRewardCall(c0))
+	require.Nil(dbh.UpdateRewardCall(c1))
+
+	calls, err = dbh.RewardCalls(10)
+	require.Nil(err)
+	require.Len(calls, 2)
+	assert.Equal(c1, calls[0])
+	assert.Equal(c0, calls[1])
+
+	// A later outcome of a round keeps the earlier one
+	c2 := &DBRewardCall{Round: 6, Status: "missed", Block: 300, Attempts: 3, Error: "boom"}
+	require.Nil(dbh.UpdateRewardCall(c2))
+	calls, err = dbh.RewardCalls(10)
+	require.Nil(err)
+	require.Len(calls, 3)
+	assert.Equal(c2, calls[0])
+	assert.Equal(c1, calls[1])
+	assert.Equal(c0, calls[2])
+
+	// Updating an outcome replaces it
+	c1.Block = 250
+	require.Nil(dbh.UpdateRewardCall(c1))
+	calls, err = dbh.RewardCalls(10)
+	require.Nil(err)
+	require.Len(calls, 3)
+	assert.Equal(c1, calls[0])
+
+	// Limit is respected
+	calls, err = dbh.RewardCalls(1)
+	require.Nil(err)
+	require.Len(calls, 1)
+	assert.Equal(int64(6), calls[0].Round)
+
+	// Nil DB
+	var nilDB *DB
+	assert.Nil(nilDB.UpdateRewardCall(c0))
+	calls, err = nilDB.RewardCalls(1)
+	assert.Nil(err)
+	assert.Nil(calls)
+}
+
 func defaultWinningTicket(t *testing.T) (sessionID string, ticket *pm.Ticket, sig []byte, recipientRand *big.Int) {
 	sessionID = "foo bar"
 	ticket = &pm.Ticket{
@@ -1351,3 +1477,333 @@ func defaultMiniHeader() *blockwatch.MiniHeader {
 	block.Logs = []types.Log{log}
 	return block
 }
+
+func TestPolls(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	polls, err := dbh.Polls()
+	require.Nil(err)
+	assert.Len(polls, 0)
+
+	p0 := &DBPoll{Address: pm.RandAddress(), Proposal: "QmFoo", EndBlock: 100, Quorum: 333300, Quota: 500000, CreatedBlock: 10}
+	p1 := &DBPoll{Address: pm.RandAddress(), Proposal: "QmBar", EndBlock: 200, Quorum: 333300, Quota: 500000, CreatedBlock: 20}
+	require.Nil(dbh.UpdatePoll(p0))
+	require.Nil(dbh.UpdatePoll(p1))
+
+	// Sorted by end block descending
+	polls, err = dbh.Polls()
+	require.Nil(err)
+	require.Len(polls, 2)
+	assert.Equal(p1, polls[0])
+	assert.Equal(p0, polls[1])
+
+	voter := pm.RandAddress()
+	require.Nil(dbh.UpdatePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, ChoiceID: 0, Block: 50}))
+	require.Nil(dbh.UpdatePollVote(&DBPollVote{Poll: p0.Address, Voter: pm.RandAddress(), ChoiceID: 1, Block: 51}))
+	require.Nil(dbh.UpdatePollVote(&DBPollVote{Poll: p1.Address, Voter: voter, ChoiceID: 1, Block: 52}))
+
+	// A vote replaces an earlier vote of the same voter
+	require.Nil(dbh.UpdatePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, ChoiceID: 1, Block: 60}))
+	// A vote does not replace a later vote of the same voter
+	require.Nil(dbh.UpdatePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, ChoiceID: 0, Block: 55}))
+
+	votes, err := dbh.PollVotes(p0.Address)
+	require.Nil(err)
+	require.Len(votes, 2)
+	assert.Equal(&DBPollVote{Poll: p0.Address, Voter: voter, ChoiceID: 1, Block: 60}, votes[1])
+
+	// Only a vote cast in the provided block is deleted
+	require.Nil(dbh.DeletePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, Block: 50}))
+	votes, err = dbh.PollVotes(p0.Address)
+	require.Nil(err)
+	assert.Len(votes, 2)
+	// Deleting the latest vote restores the previous vote of the voter
+	require.Nil(dbh.DeletePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, Block: 60}))
+	votes, err = dbh.PollVotes(p0.Address)
+	require.Nil(err)
+	require.Len(votes, 2)
+	assert.Equal(&DBPollVote{Poll: p0.Address, Voter: voter, ChoiceID: 0, Block: 55}, votes[1])
+	require.Nil(dbh.DeletePollVote(&DBPollVote{Poll: p0.Address, Voter: voter, Block: 55}))
+	votes, err = dbh.PollVotes(p0.Address)
+	require.Nil(err)
+	assert.Len(votes, 1)
+
+	// Tallies
+	tally, err := dbh.PollTally(p0.Address)
+	require.Nil(err)
+	assert.Nil(tally)
+	t0 := &DBPollTally{Poll: p0.Address, Yes: big.NewInt(300), No: big.NewInt(100), Voters: 2, TotalBonded: big.NewInt(1000), L1Block: 101, Block: 1040}
+	require.Nil(dbh.UpdatePollTally(t0))
+	tally, err = dbh.PollTally(p0.Address)
+	require.Nil(err)
+	assert.Equal(t0, tally)
+	t0.Estimate = true
+	require.Nil(dbh.UpdatePollTally(t0))
+	tally, err = dbh.PollTally(p0.Address)
+	require.Nil(err)
+	assert.Equal(t0, tally)
+
+	// Deleting a poll deletes its votes
+	require.Nil(dbh.DeletePoll(p0.Address))
+	polls, err = dbh.Polls()
+	require.Nil(err)
+	require.Len(polls, 1)
+	assert.Equal(p1.Address, polls[0].Address)
+	votes, err = dbh.PollVotes(p0.Address)
+	require.Nil(err)
+	assert.Len(votes, 0)
+	tally, err = dbh.PollTally(p0.Address)
+	require.Nil(err)
+	assert.Nil(tally)
+	votes, err = dbh.PollVotes(p1.Address)
+	require.Nil(err)
+	assert.Len(votes, 1)
+
+	// Nil DB is a no-op
+	var nilDB *DB
+	assert.Nil(nilDB.UpdatePoll(p0))
+	polls, err = nilDB.Polls()
+	assert.Nil(err)
+	assert.Nil(polls)
+	tally, err = nilDB.PollTally(p0.Address)
+	assert.Nil(err)
+	assert.Nil(tally)
+}
+
+func TestVerificationEvidence(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	records, err := dbh.SelectEvidence(nil)
+	require.Nil(err)
+	assert.Len(records, 0)
+
+	// Nil evidence is a no-op
+	assert.Nil(dbh.InsertEvidence(nil))
+
+	o0, o1 := pm.RandAddress(), pm.RandAddress()
+	now := time.Unix(time.Now().Unix(), 0)
+	e0 := &DBEvidence{Orchestrator: o0, ManifestID: "foo", SeqNo: 1, Reason: "AudioMismatch", Fatal: true, Data: []byte(`{"a":1}`), CreatedAt: now.Add(-time.Hour)}
+	e1 := &DBEvidence{Orchestrator: o1, ManifestID: "foo", SeqNo: 2, Reason: "Tampered", Data: []byte(`{"b":2}`), CreatedAt: now}
+	e2 := &DBEvidence{Orchestrator: o0, ManifestID: "bar", SeqNo: 3, Reason: "LowQuality", Data: []byte(`{"c":3}`), CreatedAt: now}
+	for _, e := range []*DBEvidence{e0, e1, e2} {
+		require.Nil(dbh.InsertEvidence(e))
+	}
+
+	// Most recent first
+	records, err = dbh.SelectEvidence(nil)
+	require.Nil(err)
+	require.Len(records, 3)
+	e2.ID, e1.ID, e0.ID = 3, 2, 1
+	assert.Equal(e2, records[0])
+	assert.Equal(e1, records[1])
+	assert.Equal(e0, records[2])
+
+	records, err = dbh.SelectEvidence(&DBEvidenceFilter{Orchestrator: &o0})
+	require.Nil(err)
+	require.Len(records, 2)
+	assert.Equal(e2, records[0])
+	assert.Equal(e0, records[1])
+
+	records, err = dbh.SelectEvidence(&DBEvidenceFilter{Orchestrator: &o0, Since: now.Add(-time.Minute)})
+	require.Nil(err)
+	require.Len(records, 1)
+	assert.Equal(e2, records[0])
+
+	records, err = dbh.SelectEvidence(&DBEvidenceFilter{Limit: 1})
+	require.Nil(err)
+	require.Len(records, 1)
+	assert.Equal(e2, records[0])
+
+	// Nil DB is a no-op
+	var nilDB *DB
+	assert.Nil(nilDB.InsertEvidence(e0))
+	records, err = nilDB.SelectEvidence(nil)
+	assert.Nil(err)
+	assert.Nil(records)
+}
+
+func TestOrchPenalties(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	penalties, err := dbh.OrchPenalties()
+	require.Nil(err)
+	assert.Len(penalties, 0)
+
+	o0, o1, o2 := pm.RandAddress(), pm.RandAddress(), pm.RandAddress()
+	for _, o := range []ethcommon.Address{o0, o1, o2} {
+		require.Nil(dbh.UpdateOrch(NewDBOrch(o.Hex(), "https://"+o.Hex()+".lpt:8935", 1, 1, 10, 0)))
+	}
+
+	start := time.Now()
+	require.Nil(dbh.PenalizeOrch(o0, time.Hour))
+	require.Nil(dbh.PenalizeOrch(o0, time.Hour))
+	// An expired penalty does not deny an orchestrator
+	require.Nil(dbh.PenalizeOrch(o1, -time.Hour))
+
+	// Penalties add up
+	penalties, err = dbh.OrchPenalties()
+	require.Nil(err)
+	require.Len(penalties, 2)
+	assert.Equal(o0, penalties[0].Orchestrator)
+	assert.Equal(int64(2), penalties[0].Failures)
+	assert.WithinDuration(start.Add(2*time.Hour), penalties[0].DeniedUntil, 2*time.Second)
+	assert.Equal(o1, penalties[1].Orchestrator)
+	assert.Equal(int64(1), penalties[1].Failures)
+
+	// Denied orchestrators are only excluded when requested
+	orchs, err := dbh.SelectOrchs(&DBOrchFilter{ExcludeDenied: true})
+	require.Nil(err)
+	require.Len(orchs, 2)
+	for _, o := range orchs {
+		assert.NotEqual(o0.Hex(), o.EthereumAddr)
+	}
+	count, err := dbh.OrchCount(&DBOrchFilter{ExcludeDenied: true})
+	require.Nil(err)
+	assert.Equal(2, count)
+	orchs, err = dbh.SelectOrchs(nil)
+	require.Nil(err)
+	assert.Len(orchs, 3)
+
+	// Lifting a penalty allows the orchestrator again
+	require.Nil(dbh.DeleteOrchPenalty(o0))
+	orchs, err = dbh.SelectOrchs(&DBOrchFilter{ExcludeDenied: true})
+	require.Nil(err)
+	assert.Len(orchs, 3)
+	penalties, err = dbh.OrchPenalties()
+	require.Nil(err)
+	assert.Len(penalties, 1)
+
+	// Nil DB is a no-op
+	var nilDB *DB
+	assert.Nil(nilDB.PenalizeOrch(o0, time.Hour))
+	penalties, err = nilDB.OrchPenalties()
+	assert.Nil(err)
+	assert.Nil(penalties)
+}
+
+func TestOrchInfoCache(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	infos, err := dbh.OrchInfos(time.Now())
+	require.Nil(err)
+	assert.Len(infos, 0)
+
+	o0, o1, o2 := pm.RandAddress(), pm.RandAddress(), pm.RandAddress()
+	now := time.Now()
+	caps := &net.Capabilities{Bitstring: []uint64{7}, Mandatories: []uint64{1}}
+	require.Nil(dbh.UpdateOrchInfo(&DBOrchInfo{
+		Orchestrator: o0,
+		Capabilities: caps,
+		PriceInfo:    &net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 2},
+		ExpiresAt:    now.Add(time.Hour),
+	}))
+	// Legacy orchestrators don't advertise capabilities
+	require.Nil(dbh.UpdateOrchInfo(&DBOrchInfo{Orchestrator: o1, ExpiresAt: now.Add(time.Hour)}))
+	// Expired infos are not returned
+	require.Nil(dbh.UpdateOrchInfo(&DBOrchInfo{Orchestrator: o2, ExpiresAt: now.Add(-time.Second)}))
+
+	infos, err = dbh.OrchInfos(now)
+	require.Nil(err)
+	require.Len(infos, 2)
+	byAddr := map[ethcommon.Address]*DBOrchInfo{}
+	for _, info := range infos {
+		byAddr[info.Orchestrator] = info
+	}
+	require.Contains(byAddr, o0)
+	assert.Equal(caps.Bitstring, byAddr[o0].Capabilities.Bitstring)
+	assert.Equal(caps.Mandatories, byAddr[o0].Capabilities.Mandatories)
+	assert.Equal(int64(3), byAddr[o0].PriceInfo.PricePerUnit)
+	assert.Equal(int64(2), byAddr[o0].PriceInfo.PixelsPerUnit)
+	assert.Equal(now.Add(time.Hour).Unix(), byAddr[o0].ExpiresAt.Unix())
+	require.Contains(byAddr, o1)
+	assert.Nil(byAddr[o1].Capabilities)
+	assert.Nil(byAddr[o1].PriceInfo)
+
+	// Updates replace the cached info
+	require.Nil(dbh.UpdateOrchInfo(&DBOrchInfo{Orchestrator: o0, ExpiresAt: now.Add(-time.Second)}))
+	infos, err = dbh.OrchInfos(now)
+	require.Nil(err)
+	require.Len(infos, 1)
+	assert.Equal(o1, infos[0].Orchestrator)
+
+	// Nil DB is a no-op
+	var nilDB *DB
+	assert.Nil(nilDB.UpdateOrchInfo(&DBOrchInfo{Orchestrator: o0}))
+	infos, err = nilDB.OrchInfos(now)
+	assert.Nil(err)
+	assert.Nil(infos)
+}
+
+func TestCapacityReservations(t *testing.T) {
+	dbh, dbraw, err := TempDB(t)
+	defer dbh.Close()
+	defer dbraw.Close()
+	assert := assert.New(t)
+	require := require.New(t)
+	require.Nil(err)
+
+	reservations, err := dbh.CapacityReservations(time.Now())
+	require.Nil(err)
+	assert.Len(reservations, 0)
+
+	sender := pm.RandAddress()
+	now := time.Unix(time.Now().Unix(), 0)
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P240p30fps16x9}
+	require.Nil(dbh.InsertCapacityReservation(&DBCapacityReservation{
+		ID:       "a",
+		Sender:   sender,
+		Sessions: 2,
+		Profiles: profiles,
+		Start:    now,
+		End:      now.Add(time.Hour),
+	}))
+	// Reservations that ended are not returned
+	require.Nil(dbh.InsertCapacityReservation(&DBCapacityReservation{ID: "b", Sender: sender, Sessions: 1, Start: now.Add(-time.Hour), End: now.Add(-time.Second)}))
+	// IDs are unique
+	assert.NotNil(dbh.InsertCapacityReservation(&DBCapacityReservation{ID: "a", Sender: sender, Sessions: 1, End: now.Add(time.Hour)}))
+
+	reservations, err = dbh.CapacityReservations(now)
+	require.Nil(err)
+	require.Len(reservations, 1)
+	assert.Equal(&DBCapacityReservation{
+		ID:       "a",
+		Sender:   sender,
+		Sessions: 2,
+		Profiles: profiles,
+		Start:    now,
+		End:      now.Add(time.Hour),
+	}, reservations[0])
+
+	require.Nil(dbh.DeleteCapacityReservation("a"))
+	reservations, err = dbh.CapacityReservations(now)
+	require.Nil(err)
+	assert.Len(reservations, 0)
+
+	// Nil DB is a no-op
+	var nilDB *DB
+	assert.Nil(nilDB.InsertCapacityReservation(&DBCapacityReservation{ID: "a"}))
+	assert.Nil(nilDB.DeleteCapacityReservation("a"))
+	reservations, err = nilDB.CapacityReservations(now)
+	assert.Nil(err)
+	assert.Nil(reservations)
+}
@@ -13,8 +13,17 @@ import (
 )
 
 type RemoteTranscoderInfo struct {
+	ID       string
 	Address  string
 	Capacity int
+	Load     int
+	// PixelCapacity is the number of output pixels per second the transcoder can encode or 0 if it is unknown
+	PixelCapacity int64 `json:",omitempty"`
+	// PixelLoad is the estimated number of output pixels per second of the sessions assigned to the transcoder
+	PixelLoad    int64 `json:",omitempty"`
+	Capabilities []string
+	// Draining is true if the transcoder does not accept new sessions
+	Draining bool
 }
 
 type StreamInfo struct {
@@ -38,6 +47,7 @@ type NodeStatus struct {
 	RegisteredTranscoders       []RemoteTranscoderInfo
 	LocalTranscoding            bool // Indicates orchestrator that is also transcoder
 	BroadcasterPrices           map[string]*big.Rat
+	RewardCalls                 []*DBRewardCall `json:",omitempty"`
 	// xxx add transcoder's version here
 }
 
@@ -111,6 +121,24 @@ type SelectionAlgorithm interface {
 type PerfScore struct {
 	Mu     sync.Mutex
 	Scores map[ethcommon.Address]float64
+	// Scores computed by the gateway itself from its round trip time to
+	// orchestrators and their regions
+	Local map[ethcommon.Address]float64
+}
+
+// Score returns the performance score of an orchestrator, the product of its
+// score from the performance stats and its local score if both are known.
+// The caller must hold Mu.
+func (p *PerfScore) Score(addr ethcommon.Address) float64 {
+	score, ok := p.Scores[addr]
+	local, okLocal := p.Local[addr]
+	switch {
+	case ok && okLocal:
+		return score * local
+	case okLocal:
+		return local
+	}
+	return score
 }
 
 func ScoreAtLeast(minScore float32) ScorePred {
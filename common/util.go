@@ -47,6 +47,10 @@ var MinSegmentUploadTimeout = 2 * time.Second
 // WebhookDiscoveryRefreshInterval defines for long the Webhook Discovery values should be cached
 var WebhookDiscoveryRefreshInterval = 1 * time.Minute
 
+// RegistryDiscoveryRefreshInterval defines how often the orchestrator registry is polled for updates
+var RegistryDiscoveryRefreshInterval = 1 * time.Minute
+var GossipInterval = 1 * time.Minute
+
 // Max Segment Duration
 var MaxDuration = (5 * time.Minute)
 
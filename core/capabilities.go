@@ -3,6 +3,7 @@ package core
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 
 	"github.com/Masterminds/semver/v3"
@@ -229,7 +230,7 @@ func JobCapabilities(params *StreamParameters, segPar *SegmentParameters) (*Capa
 
 	// Define any default capabilities (especially ones that may be mandatory)
 	caps[Capability_AuthToken] = true
-	if params.VerificationFreq > 0 {
+	if params.VerificationFreq > 0 || params.VerificationRedundancy > 1 || params.VerifySignatures {
 		caps[Capability_MPEG7VideoSignature] = true
 	}
 	if segPar != nil {
@@ -468,6 +469,38 @@ func (cap *Capabilities) RemoveCapacity(goneCaps *Capabilities) {
 	}
 }
 
+func (capStr CapabilityString) hasCapability(capability Capability) bool {
+	if capability < 0 {
+		// invalid and unused capabilities are never set
+		return false
+	}
+	arrIdx := int(capability) / 64 // floors automatically
+	bitIdx := int(capability) % 64
+	if arrIdx >= len(capStr) {
+		return false
+	}
+	return capStr[arrIdx]&uint64(1<<bitIdx) != 0
+}
+
+// names returns the names of the known capabilities in ascending order of their IDs
+func (c *Capabilities) names() []string {
+	if c == nil {
+		return []string{}
+	}
+	caps := make([]Capability, 0, len(CapabilityNameLookup))
+	for capability := range CapabilityNameLookup {
+		if c.bitstring.hasCapability(capability) {
+			caps = append(caps, capability)
+		}
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	names := make([]string, len(caps))
+	for i, capability := range caps {
+		names[i] = CapabilityNameLookup[capability]
+	}
+	return names
+}
+
 func (capStr *CapabilityString) removeCapability(capability Capability) {
 	arrIdx := int(capability) / 64 // floors automatically
 	bitIdx := int(capability) % 64
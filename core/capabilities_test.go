@@ -255,7 +255,7 @@ func TestCapability_JobCapabilities(t *testing.T) {
 	}), "failed with fractional framerates")
 
 	// check MPEG7VideoSignature
-	params.VerificationFreq = 1
+	params.VerificationRedundancy = 3
 	assert.True(checkSuccess(params, []Capability{
 		Capability_H264,
 		Capability_MPEGTS,
@@ -264,6 +264,27 @@ func TestCapability_JobCapabilities(t *testing.T) {
 		Capability_MPEG7VideoSignature,
 	}), "failed with fast verification enabled")
 
+	params.VerificationRedundancy = 0
+	params.VerificationFreq = 1
+	assert.True(checkSuccess(params, []Capability{
+		Capability_H264,
+		Capability_MPEGTS,
+		Capability_FractionalFramerates,
+		Capability_AuthToken,
+		Capability_MPEG7VideoSignature,
+	}), "failed with fast verification frequency enabled")
+
+	// check MPEG7VideoSignature with local fast verification
+	params.VerificationFreq = 0
+	params.VerifySignatures = true
+	assert.True(checkSuccess(params, []Capability{
+		Capability_H264,
+		Capability_MPEGTS,
+		Capability_FractionalFramerates,
+		Capability_AuthToken,
+		Capability_MPEG7VideoSignature,
+	}), "failed with local fast verification enabled")
+
 	// check error case with format
 	params.Profiles = []ffmpeg.VideoProfile{{Format: -1}}
 	_, err = JobCapabilities(params, nil)
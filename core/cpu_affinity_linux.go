@@ -0,0 +1,15 @@
+//go:build linux
+// +build linux
+
+package core
+
+import "golang.org/x/sys/unix"
+
+// setCPUAffinity restricts the calling thread and the threads it starts to the given cores
+func setCPUAffinity(cores []int) error {
+	var set unix.CPUSet
+	for _, c := range cores {
+		set.Set(c)
+	}
+	return unix.SchedSetaffinity(0, &set)
+}
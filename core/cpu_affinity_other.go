@@ -0,0 +1,11 @@
+//go:build !linux
+// +build !linux
+
+package core
+
+import "errors"
+
+// setCPUAffinity is not supported on this platform
+func setCPUAffinity(cores []int) error {
+	return errors.New("CPU pinning is only supported on Linux")
+}
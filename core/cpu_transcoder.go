@@ -0,0 +1,245 @@
+package core
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
+)
+
+// Weight of the last transcoded segment in the estimated transcoding time per pixel
+const cpuEstimateWeight = 0.2
+
+// CPUTranscoder runs software transcoding on a bounded pool of CPU cores. Each segment reserves a number of cores
+// for its session and waits in a queue until they are free. If the number of threads per segment is set, segments are
+// encoded with as many threads as reserved cores, otherwise a single core is reserved and the encoder picks its threads.
+// Segments of higher priority streams are queued ahead of segments of lower priority streams. Segments are rejected
+// with ErrTranscoderBusy if they would not be transcoded before the end of their duration
+type CPUTranscoder struct {
+	transcoder Transcoder
+	cores      []int
+	// Number of cores reserved for transcoding a segment
+	threads int
+	// Limit the encoder to as many threads as reserved cores
+	limitThreads bool
+	// Pin transcoding to the reserved cores
+	pin bool
+
+	// The following fields need to be protected by the mutex `mu`
+	mu      sync.Mutex
+	free    []int
+	queue   []*cpuJob
+	running map[*cpuJob]struct{}
+	// Estimated transcoding seconds per output pixel, seeded by SetPixelThroughput and measured from the transcoded
+	// segments
+	secsPerPixel float64
+}
+
+type cpuJob struct {
+	// Estimated output pixels of the segment
+	pixels   float64
+	priority int32
+	estimate time.Duration
+	cores    []int
+	started  time.Time
+	// Closed when the cores are reserved for the segment
+	start chan struct{}
+}
+
+// NewCPUTranscoder creates a CPUTranscoder that runs transcoder on the given cores and reserves threads cores for
+// each segment. If threads is zero a single core is reserved for each segment and the encoder threads are not limited
+func NewCPUTranscoder(transcoder Transcoder, cores []int, threads int, pin bool) *CPUTranscoder {
+	limitThreads := threads > 0
+	if threads < 1 {
+		threads = 1
+	}
+	if threads > len(cores) {
+		threads = len(cores)
+	}
+	return &CPUTranscoder{
+		transcoder:   transcoder,
+		cores:        cores,
+		threads:      threads,
+		limitThreads: limitThreads,
+		pin:          pin,
+		free:         append([]int(nil), cores...),
+		running:      make(map[*cpuJob]struct{}),
+	}
+}
+
+// SetPixelThroughput seeds the estimated transcoding time of the segments with the number of output pixels per second
+// that the transcoder encodes in all of its slots, as measured by MeasurePixelThroughput. Without it the segments are
+// not rejected for their deadline until the first segment is transcoded
+func (ct *CPUTranscoder) SetPixelThroughput(pixelsPerSec int64) {
+	if pixelsPerSec <= 0 {
+		return
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.secsPerPixel = float64(ct.Slots()) / float64(pixelsPerSec)
+}
+
+// Slots returns the number of segments that can be transcoded in parallel
+func (ct *CPUTranscoder) Slots() int {
+	if ct.threads == 0 {
+		return 0
+	}
+	return len(ct.cores) / ct.threads
+}
+
+func (ct *CPUTranscoder) Transcode(ctx context.Context, md *SegTranscodingMetadata) (*TranscodeData, error) {
+	job := &cpuJob{
+		pixels:   float64(EstimateSessionCost(md.Profiles)) * md.Duration.Seconds(),
+		priority: md.Priority,
+		start:    make(chan struct{}),
+	}
+
+	ct.mu.Lock()
+	job.estimate = time.Duration(ct.secsPerPixel * job.pixels * float64(time.Second))
+	if wait := ct.queueWait(job.priority); md.Duration > 0 && wait+job.estimate > md.Duration {
+		ct.mu.Unlock()
+		clog.V(common.DEBUG).Infof(ctx, "CPU: Rejecting segment that would miss its deadline wait=%v estimate=%v duration=%v", wait, job.estimate, md.Duration)
+		return nil, ErrTranscoderBusy
+	}
+	ct.enqueue(job)
+	ct.dispatch()
+	ct.mu.Unlock()
+
+	var deadline <-chan time.Time
+	if md.Duration > 0 {
+		// The segment has to start by then to be transcoded before the end of its duration
+		timer := time.NewTimer(md.Duration - job.estimate)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	select {
+	case <-job.start:
+	case <-deadline:
+		if ct.dequeue(job) {
+			clog.V(common.DEBUG).Infof(ctx, "CPU: Segment missed its deadline in queue duration=%v", md.Duration)
+			return nil, ErrTranscoderBusy
+		}
+		<-job.start
+	case <-ctx.Done():
+		if ct.dequeue(job) {
+			return nil, ctx.Err()
+		}
+		<-job.start
+	}
+	defer ct.release(job)
+
+	if !ct.limitThreads {
+		return ct.run(ctx, md, job.cores)
+	}
+	// Encode with as many threads as there are reserved cores
+	mdCopy := *md
+	mdCopy.Threads = len(job.cores)
+	return ct.run(ctx, &mdCopy, job.cores)
+}
+
+func (ct *CPUTranscoder) run(ctx context.Context, md *SegTranscodingMetadata, cores []int) (*TranscodeData, error) {
+	if !ct.pin {
+		return ct.transcoder.Transcode(ctx, md)
+	}
+
+	type result struct {
+		*TranscodeData
+		error
+	}
+	res := make(chan result, 1)
+	go func() {
+		// The thread is not unlocked so that it exits with the goroutine instead of being reused with its affinity
+		runtime.LockOSThread()
+		if err := setCPUAffinity(cores); err != nil {
+			clog.Warningf(ctx, "CPU: Unable to pin transcoding to cores=%v err=%q", cores, err)
+		}
+		td, err := ct.transcoder.Transcode(ctx, md)
+		res <- result{td, err}
+	}()
+	r := <-res
+	return r.TranscodeData, r.error
+}
+
+func (ct *CPUTranscoder) EndTranscodingSession(sessionId string) {
+	ct.transcoder.EndTranscodingSession(sessionId)
+}
+
+// queueWait estimates how long a segment with the given priority queued now waits for cores.
+// Expects the mutex `ct.mu` to be locked by the caller.
+func (ct *CPUTranscoder) queueWait(priority int32) time.Duration {
+	var work time.Duration
+	for _, job := range ct.queue {
+		if job.priority >= priority {
+			work += job.estimate
+		}
+	}
+	for job := range ct.running {
+		if remaining := job.estimate - time.Since(job.started); remaining > 0 {
+			work += remaining
+		}
+	}
+	slots := ct.Slots()
+	if slots == 0 {
+		return work
+	}
+	return work / time.Duration(slots)
+}
+
+// enqueue queues a segment behind the segments of the same or a higher priority.
+// Expects the mutex `ct.mu` to be locked by the caller.
+func (ct *CPUTranscoder) enqueue(job *cpuJob) {
+	i := len(ct.queue)
+	for i > 0 && ct.queue[i-1].priority < job.priority {
+		i--
+	}
+	ct.queue = append(ct.queue, nil)
+	copy(ct.queue[i+1:], ct.queue[i:])
+	ct.queue[i] = job
+}
+
+// dispatch reserves the free cores for the queued segments in order.
+// Expects the mutex `ct.mu` to be locked by the caller.
+func (ct *CPUTranscoder) dispatch() {
+	for len(ct.queue) > 0 && ct.threads > 0 && len(ct.free) >= ct.threads {
+		job := ct.queue[0]
+		ct.queue = ct.queue[1:]
+		job.cores = append([]int(nil), ct.free[:ct.threads]...)
+		ct.free = ct.free[ct.threads:]
+		job.started = time.Now()
+		ct.running[job] = struct{}{}
+		close(job.start)
+	}
+}
+
+// dequeue removes a segment that did not start and returns whether it was still queued
+func (ct *CPUTranscoder) dequeue(job *cpuJob) bool {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	for i, j := range ct.queue {
+		if j == job {
+			ct.queue = append(ct.queue[:i], ct.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (ct *CPUTranscoder) release(job *cpuJob) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+
+	if job.pixels > 0 {
+		sample := time.Since(job.started).Seconds() / job.pixels
+		if ct.secsPerPixel == 0 {
+			ct.secsPerPixel = sample
+		} else {
+			ct.secsPerPixel = (1-cpuEstimateWeight)*ct.secsPerPixel + cpuEstimateWeight*sample
+		}
+	}
+	delete(ct.running, job)
+	ct.free = append(ct.free, job.cores...)
+	ct.dispatch()
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sleepTranscoder is a fake transcoder that takes a fixed time to transcode a segment
+type sleepTranscoder struct {
+	dur time.Duration
+
+	mu      sync.Mutex
+	running int
+	peak    int
+	ended   []string
+	threads []int
+}
+
+func (st *sleepTranscoder) Transcode(ctx context.Context, md *SegTranscodingMetadata) (*TranscodeData, error) {
+	st.mu.Lock()
+	st.threads = append(st.threads, md.Threads)
+	st.running++
+	if st.running > st.peak {
+		st.peak = st.running
+	}
+	st.mu.Unlock()
+
+	time.Sleep(st.dur)
+
+	st.mu.Lock()
+	st.running--
+	st.mu.Unlock()
+	return &TranscodeData{Segments: []*TranscodedSegmentData{{Data: []byte("foo")}}}, nil
+}
+
+func (st *sleepTranscoder) EndTranscodingSession(sessionId string) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.ended = append(st.ended, sessionId)
+}
+
+func TestCPUTranscoder_Concurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	st := &sleepTranscoder{dur: 20 * time.Millisecond}
+	ct := NewCPUTranscoder(st, []int{0, 1, 2, 3}, 2, false)
+	assert.Equal(2, ct.Slots())
+
+	// Segments without a duration wait in the queue until cores are free
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ct.Transcode(context.Background(), &SegTranscodingMetadata{})
+			assert.Nil(err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(2, st.peak)
+	// Segments are encoded with as many threads as there are reserved cores
+	assert.Equal([]int{2, 2, 2, 2, 2}, st.threads)
+	assert.Len(ct.free, 4)
+	assert.Empty(ct.queue)
+	assert.Empty(ct.running)
+
+	ct.EndTranscodingSession("foo")
+	assert.Equal([]string{"foo"}, st.ended)
+
+	// The thread budget is limited by the number of cores
+	ct = NewCPUTranscoder(st, []int{0, 1}, 4, false)
+	assert.Equal(1, ct.Slots())
+	md := &SegTranscodingMetadata{}
+	_, err := ct.Transcode(context.Background(), md)
+	assert.Nil(err)
+	assert.Equal(2, st.threads[len(st.threads)-1])
+	// The metadata of the caller is not modified
+	assert.Equal(0, md.Threads)
+
+	// The encoder threads are not limited without a number of threads
+	ct = NewCPUTranscoder(st, []int{0, 1}, 0, false)
+	assert.Equal(2, ct.Slots())
+	_, err = ct.Transcode(context.Background(), md)
+	assert.Nil(err)
+	assert.Equal(0, st.threads[len(st.threads)-1])
+}
+
+func TestCPUTranscoder_Deadline(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	st := &sleepTranscoder{dur: 40 * time.Millisecond}
+	ct := NewCPUTranscoder(st, []int{0}, 1, false)
+	md := &SegTranscodingMetadata{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}, Duration: 100 * time.Millisecond}
+
+	// The transcoding time is unknown until a segment is transcoded
+	_, err := ct.Transcode(context.Background(), md)
+	require.Nil(err)
+	assert.NotZero(ct.secsPerPixel)
+
+	// Segments are rejected if the queue would make them miss their deadline
+	var wg sync.WaitGroup
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := ct.Transcode(context.Background(), md)
+			errs <- err
+		}()
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	close(errs)
+	var busy int
+	for err := range errs {
+		if err == ErrTranscoderBusy {
+			busy++
+		} else {
+			assert.Nil(err)
+		}
+	}
+	assert.Equal(1, busy)
+	assert.Empty(ct.queue)
+}
+
+func TestCPUTranscoder_SetPixelThroughput(t *testing.T) {
+	assert := assert.New(t)
+
+	st := &sleepTranscoder{}
+	ct := NewCPUTranscoder(st, []int{0, 1, 2, 3}, 2, false)
+	ct.SetPixelThroughput(0)
+	assert.Zero(ct.secsPerPixel)
+
+	// Each of the 2 slots encodes half of the pixels
+	ct.SetPixelThroughput(1000)
+	assert.Equal(0.002, ct.secsPerPixel)
+
+	// Segments that would miss their deadline are rejected before any segment is transcoded
+	md := &SegTranscodingMetadata{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}, Duration: time.Second}
+	_, err := ct.Transcode(context.Background(), md)
+	assert.Equal(ErrTranscoderBusy, err)
+	assert.Empty(st.threads)
+}
+
+func TestCPUTranscoder_QueueTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	st := &sleepTranscoder{dur: 100 * time.Millisecond}
+	ct := NewCPUTranscoder(st, []int{0}, 1, false)
+
+	go ct.Transcode(context.Background(), &SegTranscodingMetadata{})
+	time.Sleep(5 * time.Millisecond)
+
+	// Segments that do not start before the end of their duration are dropped from the queue
+	_, err := ct.Transcode(context.Background(), &SegTranscodingMetadata{Duration: 20 * time.Millisecond})
+	assert.Equal(ErrTranscoderBusy, err)
+	assert.Empty(ct.queue)
+
+	// Segments are dropped from the queue when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = ct.Transcode(ctx, &SegTranscodingMetadata{})
+	assert.Equal(context.DeadlineExceeded, err)
+	assert.Empty(ct.queue)
+}
+
+func TestCPUTranscoder_Priority(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	ct := NewCPUTranscoder(&sleepTranscoder{}, []int{0}, 1, false)
+	normal1 := &cpuJob{priority: PriorityNormal, estimate: time.Second}
+	low := &cpuJob{priority: PriorityLow, estimate: time.Second}
+	normal2 := &cpuJob{priority: PriorityNormal, estimate: time.Second}
+	high := &cpuJob{priority: PriorityHigh, estimate: time.Second}
+
+	// Segments are queued behind the segments of the same or a higher priority
+	for _, job := range []*cpuJob{normal1, low, normal2, high} {
+		ct.enqueue(job)
+	}
+	require.Len(ct.queue, 4)
+	assert.Equal([]*cpuJob{high, normal1, normal2, low}, ct.queue)
+
+	// Segments only wait for the queued segments of the same or a higher priority
+	assert.Equal(4*time.Second, ct.queueWait(PriorityLow))
+	assert.Equal(3*time.Second, ct.queueWait(PriorityNormal))
+	assert.Equal(time.Second, ct.queueWait(PriorityHigh))
+	assert.Zero(ct.queueWait(PriorityHigh + 1))
+}
+
+func TestCPUTranscoder_Pinning(t *testing.T) {
+	st := &sleepTranscoder{}
+	ct := NewCPUTranscoder(st, []int{0}, 1, true)
+	td, err := ct.Transcode(context.Background(), &SegTranscodingMetadata{})
+	assert.Nil(t, err)
+	assert.Equal(t, "foo", string(td.Segments[0].Data))
+}
@@ -81,10 +81,13 @@ type LivepeerNode struct {
 	OrchSecret         string
 	Transcoder         Transcoder
 	TranscoderManager  *RemoteTranscoderManager
+	TranscodeCache     *TranscodeCache
+	PriorityPrices     map[int32]*big.Rat
 	Balances           *AddressBalances
 	Capabilities       *Capabilities
 	AutoAdjustPrice    bool
 	AutoSessionLimit   bool
+	RewardService      *eth.RewardService
 	// Broadcaster public fields
 	Sender pm.Sender
 
@@ -96,6 +99,11 @@ type LivepeerNode struct {
 	priceInfo    map[string]*AutoConvertedPrice
 	serviceURI   url.URL
 	segmentMutex *sync.RWMutex
+	// Priorities of the transcoding sessions in SegmentChans
+	segmentPriorities map[ManifestID]int32
+	// Capacity reservations by ID and the reservations that transcoding sessions are bound to
+	reservations     map[string]*CapacityReservation
+	reservedSessions map[ManifestID]*CapacityReservation
 }
 
 // NewLivepeerNode creates a new Livepeer Node. Eth can be nil.
@@ -37,7 +37,7 @@ func TestServeTranscoder(t *testing.T) {
 
 	// test that a transcoder was created
 	capabilities := NewCapabilities(DefaultCapabilities(), []Capability{})
-	go n.serveTranscoder(strm, 5, capabilities.ToNetCapabilities())
+	go n.serveTranscoder(strm, "", false, 5, 0, capabilities.ToNetCapabilities())
 	time.Sleep(1 * time.Second)
 
 	tc, ok := n.TranscoderManager.liveTranscoders[strm]
@@ -60,7 +60,7 @@ func TestRemoteTranscoder(t *testing.T) {
 	m := NewRemoteTranscoderManager()
 	initTranscoder := func() (*RemoteTranscoder, *StubTranscoderServer) {
 		strm := &StubTranscoderServer{manager: m}
-		tc := NewRemoteTranscoder(m, strm, 5, nil)
+		tc := NewRemoteTranscoder(m, strm, "", false, 5, nil)
 		return tc, strm
 	}
 
@@ -199,7 +199,7 @@ func TestManageTranscoders(t *testing.T) {
 
 	// test that transcoder is added to liveTranscoders and remoteTranscoders
 	wg1 := newWg(1)
-	go func() { m.Manage(strm, 5, capabilities.ToNetCapabilities()); wg1.Done() }()
+	go func() { m.Manage(strm, "", false, 5, capabilities.ToNetCapabilities()); wg1.Done() }()
 	time.Sleep(1 * time.Millisecond) // allow the manager to activate
 
 	assert.NotNil(m.liveTranscoders[strm])
@@ -213,7 +213,7 @@ func TestManageTranscoders(t *testing.T) {
 
 	// test that additional transcoder is added to liveTranscoders and remoteTranscoders
 	wg2 := newWg(1)
-	go func() { m.Manage(strm2, 4, capabilities.ToNetCapabilities()); wg2.Done() }()
+	go func() { m.Manage(strm2, "", false, 4, capabilities.ToNetCapabilities()); wg2.Done() }()
 	time.Sleep(1 * time.Millisecond) // allow the manager to activate
 
 	assert.NotNil(m.liveTranscoders[strm])
@@ -259,9 +259,9 @@ func TestSelectTranscoder(t *testing.T) {
 
 	// register transcoders, which adds transcoder to liveTranscoders and remoteTranscoders
 	wg := newWg(1)
-	go func() { m.Manage(strm, 1, capabilities.ToNetCapabilities()) }()
+	go func() { m.Manage(strm, "", false, 1, capabilities.ToNetCapabilities()) }()
 	time.Sleep(1 * time.Millisecond) // allow time for first stream to register
-	go func() { m.Manage(strm2, 1, richCapabilities.ToNetCapabilities()); wg.Done() }()
+	go func() { m.Manage(strm2, "", false, 1, richCapabilities.ToNetCapabilities()); wg.Done() }()
 	time.Sleep(1 * time.Millisecond) // allow time for second stream to register e for third stream to register
 
 	assert.NotNil(m.liveTranscoders[strm])
@@ -275,7 +275,7 @@ func TestSelectTranscoder(t *testing.T) {
 	// assert transcoder is returned from selectTranscoder
 	t1 := m.liveTranscoders[strm]
 	t2 := m.liveTranscoders[strm2]
-	currentTranscoder, err := m.selectTranscoder(testSessionId, nil)
+	currentTranscoder, err := m.selectTranscoder(testSessionId, nil, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.Equal(t2, currentTranscoder)
 	assert.Equal(1, t2.load)
@@ -284,37 +284,37 @@ func TestSelectTranscoder(t *testing.T) {
 
 	// assert that same transcoder is selected for same sessionId
 	// and that load stays the same
-	currentTranscoder, err = m.selectTranscoder(testSessionId, nil)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, nil, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.Equal(t2, currentTranscoder)
 	assert.Equal(1, t2.load)
 	m.completeStreamSession(testSessionId)
 
 	// assert that transcoders are selected according to capabilities
-	currentTranscoder, err = m.selectTranscoder(testSessionId, capabilities)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, capabilities, 0, PriorityNormal, true)
 	assert.Nil(err)
 	m.completeStreamSession(testSessionId)
-	currentTranscoderRich, err := m.selectTranscoder(testSessionId, richCapabilities)
+	currentTranscoderRich, err := m.selectTranscoder(testSessionId, richCapabilities, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.NotEqual(currentTranscoder, currentTranscoderRich)
 	m.completeStreamSession(testSessionId)
 
 	// assert no transcoders available for unsupported capability
-	currentTranscoder, err = m.selectTranscoder(testSessionId, allCapabilities)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, allCapabilities, 0, PriorityNormal, true)
 	assert.NotNil(err)
 	m.completeStreamSession(testSessionId)
 
 	// assert that a new transcoder is selected for a new sessionId
-	currentTranscoder, err = m.selectTranscoder(testSessionId2, nil)
+	currentTranscoder, err = m.selectTranscoder(testSessionId2, nil, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.Equal(t1, currentTranscoder)
 	assert.Equal(1, t1.load)
 
 	// Add some more load and assert no transcoder returned if all at capacity
-	currentTranscoder, err = m.selectTranscoder(testSessionId, nil)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, nil, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.Equal(t2, currentTranscoder)
-	noTrans, err := m.selectTranscoder(testSessionId3, nil)
+	noTrans, err := m.selectTranscoder(testSessionId3, nil, 0, PriorityNormal, true)
 	assert.Equal(err, ErrNoTranscodersAvailable)
 	assert.Nil(noTrans)
 
@@ -329,7 +329,7 @@ func TestSelectTranscoder(t *testing.T) {
 	assert.NotNil(m.liveTranscoders[strm])
 
 	// assert t1 is selected and t2 drained, but was previously selected
-	currentTranscoder, err = m.selectTranscoder(testSessionId, nil)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, nil, 0, PriorityNormal, true)
 	assert.Nil(err)
 	assert.Equal(t1, currentTranscoder)
 	assert.Equal(1, t1.load)
@@ -348,18 +348,391 @@ func TestSelectTranscoder(t *testing.T) {
 	// assert one transcoder with the correct Livepeer version is selected
 	minVersionCapabilities := NewCapabilities(DefaultCapabilities(), []Capability{})
 	minVersionCapabilities.SetMinVersionConstraint("0.4.0")
-	currentTranscoder, err = m.selectTranscoder(testSessionId, minVersionCapabilities)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, minVersionCapabilities, 0, PriorityNormal, true)
 	assert.Nil(err)
 	m.completeStreamSession(testSessionId)
 
 	// assert no transcoders available for min version higher than any transcoder
 	minVersionHighCapabilities := NewCapabilities(DefaultCapabilities(), []Capability{})
 	minVersionHighCapabilities.SetMinVersionConstraint("0.4.2")
-	currentTranscoder, err = m.selectTranscoder(testSessionId, minVersionHighCapabilities)
+	currentTranscoder, err = m.selectTranscoder(testSessionId, minVersionHighCapabilities, 0, PriorityNormal, true)
 	assert.NotNil(err)
 	m.completeStreamSession(testSessionId)
 }
 
+type drainTestStream struct {
+	common.StubServerStream
+	teardowns []string
+}
+
+func (s *drainTestStream) Send(n *net.NotifySegment) error {
+	if n.Url == "" && n.SegData != nil && n.SegData.AuthToken != nil {
+		s.teardowns = append(s.teardowns, n.SegData.AuthToken.SessionId)
+	}
+	return nil
+}
+
+func TestSelectTranscoder_Draining(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	strm := &drainTestStream{}
+	strm2 := &drainTestStream{}
+	capabilities := NewCapabilities(DefaultCapabilities(), []Capability{})
+	go func() { m.Manage(strm, "foo", false, 2, capabilities.ToNetCapabilities()) }()
+	go func() { m.Manage(strm2, "bar", false, 2, capabilities.ToNetCapabilities()) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 2)
+
+	assert.Equal(ErrTranscoderNotFound, m.SetTranscoderDraining("baz", true))
+
+	first, err := m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	firstStrm, secondStrm := strm, strm2
+	if first.id == "bar" {
+		firstStrm, secondStrm = strm2, strm
+	}
+	second := m.liveTranscoders[secondStrm]
+
+	// The session is migrated away from the draining transcoder
+	require.Nil(m.SetTranscoderDraining(first.id, true))
+	currentTranscoder, err := m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(second, currentTranscoder)
+	assert.Equal(0, first.load)
+	assert.Equal(1, second.load)
+	assert.Equal([]string{"s1"}, firstStrm.teardowns)
+
+	// New sessions are not assigned to the draining transcoder
+	currentTranscoder, err = m.selectTranscoder("s2", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(second, currentTranscoder)
+	assert.Equal(0, first.load)
+	assert.Equal(2, second.load)
+
+	// Sessions stay on a draining transcoder if there is no other transcoder to migrate them to
+	require.Nil(m.SetTranscoderDraining(second.id, true))
+	currentTranscoder, err = m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(second, currentTranscoder)
+	assert.Empty(secondStrm.teardowns)
+	_, err = m.selectTranscoder("s3", nil, 0, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+
+	ti := m.RegisteredTranscodersInfo()
+	require.Len(ti, 2)
+	for _, info := range ti {
+		assert.True(info.Draining)
+		assert.Equal(2, info.Capacity)
+		assert.Equal(capabilities.names(), info.Capabilities)
+		assert.NotEmpty(info.Capabilities)
+		if info.ID == second.id {
+			assert.Equal(2, info.Load)
+		} else {
+			assert.Equal(first.id, info.ID)
+			assert.Equal(0, info.Load)
+		}
+	}
+
+	// The sessions are migrated once a transcoder is back in service
+	require.Nil(m.SetTranscoderDraining(first.id, false))
+	currentTranscoder, err = m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(first, currentTranscoder)
+	assert.Equal(1, first.load)
+	assert.Equal(1, second.load)
+	assert.Equal([]string{"s1"}, secondStrm.teardowns)
+}
+
+func TestSelectTranscoder_PixelCapacity(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	bigStrm := &drainTestStream{}
+	smallStrm := &drainTestStream{}
+	go func() { m.ManageWithPixelCapacity(bigStrm, "big", false, 10, 1000, nil) }()
+	go func() { m.ManageWithPixelCapacity(smallStrm, "small", false, 10, 300, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 2)
+	big := m.liveTranscoders[bigStrm]
+	small := m.liveTranscoders[smallStrm]
+
+	// Sessions are assigned to the transcoder they fit best
+	currentTranscoder, err := m.selectTranscoder("a", nil, 200, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(small, currentTranscoder)
+	currentTranscoder, err = m.selectTranscoder("b", nil, 200, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(big, currentTranscoder)
+	currentTranscoder, err = m.selectTranscoder("c", nil, 100, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(small, currentTranscoder)
+	assert.Equal(int64(300), small.pixelLoad)
+	assert.Equal(int64(200), big.pixelLoad)
+
+	// No transcoder has enough pixel capacity left
+	_, err = m.selectTranscoder("d", nil, 900, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+
+	// Pixel capacity is released when sessions complete
+	m.completeStreamSession("b")
+	assert.Equal(int64(0), big.pixelLoad)
+	currentTranscoder, err = m.selectTranscoder("d", nil, 900, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(big, currentTranscoder)
+
+	ti := m.RegisteredTranscodersInfo()
+	require.Len(ti, 2)
+	for _, info := range ti {
+		if info.ID == "big" {
+			assert.Equal(int64(1000), info.PixelCapacity)
+			assert.Equal(int64(900), info.PixelLoad)
+		} else {
+			assert.Equal(int64(300), info.PixelCapacity)
+			assert.Equal(int64(300), info.PixelLoad)
+		}
+	}
+
+	// An idle transcoder accepts sessions that exceed its pixel capacity
+	m.completeStreamSession("d")
+	currentTranscoder, err = m.selectTranscoder("e", nil, 5000, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(big, currentTranscoder)
+	_, err = m.selectTranscoder("f", nil, 5000, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+}
+
+func TestSelectTranscoder_Priority(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	strm := &drainTestStream{}
+	go func() { m.Manage(strm, "foo", false, 2, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 1)
+	tc := m.liveTranscoders[strm]
+
+	_, err := m.selectTranscoder("low", nil, 0, PriorityLow, true)
+	require.Nil(err)
+	_, err = m.selectTranscoder("normal", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+
+	// Sessions that are not allowed to preempt wait for capacity
+	_, err = m.selectTranscoder("high", nil, 0, PriorityHigh, false)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+	assert.Empty(strm.teardowns)
+
+	// Sessions preempt the session with the lowest priority
+	currentTranscoder, err := m.selectTranscoder("high", nil, 0, PriorityHigh, true)
+	require.Nil(err)
+	assert.Equal(tc, currentTranscoder)
+	assert.Equal([]string{"low"}, strm.teardowns)
+	assert.Equal(2, tc.load)
+	_, ok := m.streamSessions["low"]
+	assert.False(ok)
+	assert.Equal(PriorityHigh, m.streamSessionPriorities["high"])
+
+	// Sessions do not preempt sessions of the same or a higher priority
+	_, err = m.selectTranscoder("normal2", nil, 0, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+	assert.Equal([]string{"low"}, strm.teardowns)
+
+	// The preempted session is not able to take the transcoder back
+	_, err = m.selectTranscoder("low", nil, 0, PriorityLow, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+
+	m.completeStreamSession("high")
+	_, ok = m.streamSessionPriorities["high"]
+	assert.False(ok)
+}
+
+func disconnectTranscoder(m *RemoteTranscoderManager, t *RemoteTranscoder) {
+	t.done()
+	for {
+		m.RTmutex.Lock()
+		_, live := m.liveTranscoders[t.stream]
+		m.RTmutex.Unlock()
+		if !live {
+			return
+		}
+		time.Sleep(1 * time.Millisecond)
+	}
+}
+
+func TestSelectTranscoder_Reconnect(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	m.reconnectGracePeriod = time.Minute
+	strm := &drainTestStream{}
+	go func() { m.ManageWithPixelCapacity(strm, "foo", false, 2, 1000, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 1)
+	first := m.liveTranscoders[strm]
+
+	_, err := m.selectTranscoder("s1", nil, 100, PriorityNormal, true)
+	require.Nil(err)
+	require.Nil(m.SetTranscoderDraining("foo", true))
+
+	// The session is kept while the transcoder reconnects
+	disconnectTranscoder(m, first)
+	_, err = m.selectTranscoder("s1", nil, 100, PriorityNormal, true)
+	assert.Equal(errTranscoderReconnecting, err)
+	assert.Equal(first, m.streamSessions["s1"])
+
+	reconnected := make(chan struct{})
+	go func() {
+		m.waitForReconnect("s1")
+		close(reconnected)
+	}()
+
+	// The session is resumed by the new connection of the transcoder
+	strm2 := &drainTestStream{}
+	go func() { m.ManageWithPixelCapacity(strm2, "foo", false, 2, 1000, nil) }()
+	select {
+	case <-reconnected:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the transcoder to reconnect")
+	}
+	m.RTmutex.Lock()
+	second := m.liveTranscoders[strm2]
+	m.RTmutex.Unlock()
+	require.NotNil(second)
+	assert.Equal(second, m.streamSessions["s1"])
+	assert.Equal(1, second.load)
+	assert.Equal(int64(100), second.pixelLoad)
+	assert.True(second.draining)
+	assert.Equal([]*RemoteTranscoder{second}, m.remoteTranscoders)
+	assert.Empty(m.disconnectedTranscoders)
+	require.Nil(m.SetTranscoderDraining("foo", false))
+	currentTranscoder, err := m.selectTranscoder("s1", nil, 100, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(second, currentTranscoder)
+
+	// The session is resumed if the transcoder reconnects before its previous stream is closed
+	strm3 := &drainTestStream{}
+	go func() { m.ManageWithPixelCapacity(strm3, "foo", false, 2, 1000, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	m.RTmutex.Lock()
+	third := m.liveTranscoders[strm3]
+	m.RTmutex.Unlock()
+	require.NotNil(third)
+	assert.Equal(third, m.streamSessions["s1"])
+	assert.Equal(1, third.load)
+	assert.Equal(0, second.load)
+	disconnectTranscoder(m, second)
+	time.Sleep(1 * time.Millisecond) // allow the manager to handle the eof
+	assert.Empty(m.disconnectedTranscoders)
+	currentTranscoder, err = m.selectTranscoder("s1", nil, 100, PriorityNormal, true)
+	require.Nil(err)
+	assert.Equal(third, currentTranscoder)
+}
+
+func TestTranscode_ReconnectFallback(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	m.reconnectGracePeriod = time.Minute
+	strm := &drainTestStream{}
+	go func() { m.Manage(strm, "foo", false, 2, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 1)
+	first := m.liveTranscoders[strm]
+
+	md := StubSegTranscodingMetadata()
+	sessionId := md.AuthToken.SessionId
+	cost := EstimateSessionCost(md.Profiles)
+	_, err := m.selectTranscoder(sessionId, nil, cost, PriorityNormal, true)
+	require.Nil(err)
+	disconnectTranscoder(m, first)
+
+	// Without another transcoder there is nothing to fall back to
+	assert.Nil(m.selectFallbackTranscoder(nil, cost))
+
+	fallbackStrm := &StubTranscoderServer{manager: m}
+	go func() { m.Manage(fallbackStrm, "bar", false, 2, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+
+	// The segment is transcoded by the other transcoder without waiting for the grace period
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res, err := m.Transcode(context.TODO(), md)
+		assert.Nil(err)
+		require.NotNil(res)
+		assert.Len(res.Segments, 1)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the segment to be transcoded")
+	}
+
+	// The session stays with the reconnecting transcoder and the fallback capacity is released
+	m.RTmutex.Lock()
+	defer m.RTmutex.Unlock()
+	fallback := m.liveTranscoders[fallbackStrm]
+	require.NotNil(fallback)
+	assert.Equal(first, m.streamSessions[sessionId])
+	assert.True(m.reconnecting(first))
+	assert.Equal(0, fallback.load)
+	assert.Equal(int64(0), fallback.pixelLoad)
+}
+
+func TestSelectTranscoder_ReconnectGracePeriodExpires(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	m.reconnectGracePeriod = 10 * time.Millisecond
+	strm := &drainTestStream{}
+	go func() { m.Manage(strm, "foo", false, 2, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	require.Len(m.remoteTranscoders, 1)
+	transcoder := m.liveTranscoders[strm]
+	_, err := m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+
+	disconnectTranscoder(m, transcoder)
+	done := make(chan struct{})
+	go func() {
+		m.waitForReconnect("s1")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the grace period to expire")
+	}
+
+	// The sessions end once the grace period expires
+	m.RTmutex.Lock()
+	assert.Empty(m.streamSessions)
+	assert.Empty(m.remoteTranscoders)
+	assert.Empty(m.disconnectedTranscoders)
+	m.RTmutex.Unlock()
+	_, err = m.selectTranscoder("s1", nil, 0, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+
+	// The sessions of transcoders that do not send an ID are not kept
+	legacyStrm := &drainTestStream{}
+	go func() { m.Manage(legacyStrm, "", false, 2, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	legacy := m.liveTranscoders[legacyStrm]
+	_, err = m.selectTranscoder("s2", nil, 0, PriorityNormal, true)
+	require.Nil(err)
+	disconnectTranscoder(m, legacy)
+	assert.Empty(m.disconnectedTranscoders)
+	_, err = m.selectTranscoder("s2", nil, 0, PriorityNormal, true)
+	assert.Equal(ErrNoTranscodersAvailable, err)
+	assert.Empty(m.streamSessions)
+}
+
 func TestCompleteStreamSession(t *testing.T) {
 	m := NewRemoteTranscoderManager()
 	strm := &StubTranscoderServer{manager: m}
@@ -369,12 +742,12 @@ func TestCompleteStreamSession(t *testing.T) {
 	capabilities := NewCapabilities(DefaultCapabilities(), []Capability{})
 
 	// register transcoders
-	go func() { m.Manage(strm, 1, capabilities.ToNetCapabilities()) }()
+	go func() { m.Manage(strm, "", false, 1, capabilities.ToNetCapabilities()) }()
 	time.Sleep(1 * time.Millisecond) // allow time for first stream to register
 	t1 := m.liveTranscoders[strm]
 
 	// selectTranscoder and assert that session is added
-	m.selectTranscoder(testSessionId, nil)
+	m.selectTranscoder(testSessionId, nil, 0, PriorityNormal, true)
 	assert.Equal(t1, m.streamSessions[testSessionId])
 	assert.Equal(1, t1.load)
 
@@ -455,7 +828,7 @@ func TestTranscoderManagerTranscoding(t *testing.T) {
 	assert.Equal(err, ErrNoTranscodersAvailable)
 
 	wg := newWg(1)
-	go func() { m.Manage(s, 5, capabilities.ToNetCapabilities()); wg.Done() }()
+	go func() { m.Manage(s, "", false, 5, capabilities.ToNetCapabilities()); wg.Done() }()
 	time.Sleep(1 * time.Millisecond)
 
 	assert.Len(m.remoteTranscoders, 1) // sanity
@@ -496,7 +869,7 @@ func TestTranscoderManagerTranscoding(t *testing.T) {
 
 	// fatal error should not retry
 	wg.Add(1)
-	go func() { m.Manage(s, 5, capabilities.ToNetCapabilities()); wg.Done() }()
+	go func() { m.Manage(s, "", false, 5, capabilities.ToNetCapabilities()); wg.Done() }()
 	time.Sleep(1 * time.Millisecond)
 
 	assert.Len(m.remoteTranscoders, 1) // sanity check
@@ -688,18 +1061,79 @@ func TestOrchCheckCapacity(t *testing.T) {
 	mid := ManifestID(md.AuthToken.SessionId)
 
 	// happy case
-	assert.Nil(o.CheckCapacity(mid))
+	assert.Nil(o.CheckCapacity(mid, PriorityNormal))
 
 	// capped case
 	MaxSessions = 0
-	assert.Equal(ErrOrchCap, o.CheckCapacity(mid))
+	assert.Equal(ErrOrchCap, o.CheckCapacity(mid, PriorityNormal))
 
 	// ensure existing segment chans pass while cap is active
 	MaxSessions = cap
 	_, err := n.getSegmentChan(context.TODO(), md) // store md into segment chans
 	assert.Nil(err)
 	MaxSessions = 0
-	assert.Nil(o.CheckCapacity(mid))
+	assert.Nil(o.CheckCapacity(mid, PriorityNormal))
+
+	// streams of a priced higher priority pass if there is a session to preempt
+	MaxSessions = 1
+	assert.Equal(ErrOrchCap, o.CheckCapacity("", PriorityNormal))
+	assert.Equal(ErrOrchCap, o.CheckCapacity("", PriorityHigh))
+	n.PriorityPrices = map[int32]*big.Rat{PriorityHigh: big.NewRat(2, 1)}
+	assert.Nil(o.CheckCapacity("", PriorityHigh))
+	MaxSessions = cap
+}
+
+func TestGetSegmentChan_Preemption(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 2
+
+	newMetadata := func(sessionID string, priority int32) *SegTranscodingMetadata {
+		md := StubSegTranscodingMetadata()
+		md.AuthToken = stubAuthToken()
+		md.AuthToken.SessionId = sessionID
+		md.Priority = priority
+		return md
+	}
+	low, err := n.getSegmentChan(context.TODO(), newMetadata("low", PriorityLow))
+	require.Nil(err)
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("normal", PriorityNormal))
+	require.Nil(err)
+
+	// Only priority classes with a configured price preempt
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("high", PriorityHigh))
+	assert.Equal(ErrOrchCap, err)
+	assert.False(n.IsTranscoding("high"))
+	n.PriorityPrices = map[int32]*big.Rat{PriorityHigh: big.NewRat(2, 1)}
+
+	// Sessions preempt the session with the lowest priority
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("high", PriorityHigh))
+	require.Nil(err)
+	assert.True(n.IsTranscoding("high"))
+	assert.False(n.IsTranscoding("low"))
+	assert.Len(n.SegmentChans, 2)
+	assert.NotContains(n.SegmentChans, ManifestID("low"))
+	assert.NotContains(n.segmentPriorities, ManifestID("low"))
+	assert.Equal(PriorityHigh, n.segmentPriorities["high"])
+	_, open := <-low
+	assert.False(open)
+
+	// Sessions do not preempt sessions of the same or a higher priority
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("normal2", PriorityNormal))
+	assert.Equal(ErrOrchCap, err)
+
+	// The preempted session is not able to come back
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("low", PriorityLow))
+	assert.Equal(ErrOrchCap, err)
+
+	n.endTranscodingSession("normal", context.TODO())
+	n.endTranscodingSession("high", context.TODO())
+	assert.Empty(n.segmentPriorities)
 }
 
 func TestProcessPayment_GivenRecipientError_ReturnsNil(t *testing.T) {
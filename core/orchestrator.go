@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"math/big"
 	"net/url"
 	"os"
@@ -19,6 +20,7 @@ import (
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/golang/glog"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
@@ -78,24 +80,30 @@ func (orch *orchestrator) TranscoderSecret() string {
 	return orch.node.OrchSecret
 }
 
-func (orch *orchestrator) CheckCapacity(mid ManifestID) error {
+// CheckCapacity returns whether the orchestrator can transcode a stream with the given priority. An orchestrator at
+// capacity can still transcode the stream if it has a session of a lower priority to preempt or if the session is
+// bound to a reservation
+func (orch *orchestrator) CheckCapacity(mid ManifestID, priority int32) error {
 	orch.node.segmentMutex.RLock()
 	defer orch.node.segmentMutex.RUnlock()
 	if _, ok := orch.node.SegmentChans[mid]; ok {
 		return nil
 	}
-	if len(orch.node.SegmentChans) >= MaxSessions {
+	if orch.node.atCapacity(mid) && orch.node.preemptibleSession(priority) == "" {
 		return ErrOrchCap
 	}
 	return nil
 }
 
 func (orch *orchestrator) TranscodeSeg(ctx context.Context, md *SegTranscodingMetadata, seg *stream.HLSSegment) (*TranscodeResult, error) {
-	return orch.node.sendToTranscodeLoop(ctx, md, seg)
+	ctx, span := monitor.StartSpan(ctx, "TranscodeSeg")
+	res, err := orch.node.sendToTranscodeLoop(ctx, md, seg)
+	monitor.EndSpan(span, err)
+	return res, err
 }
 
-func (orch *orchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
-	orch.node.serveTranscoder(stream, capacity, capabilities)
+func (orch *orchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities) {
+	orch.node.serveTranscoder(stream, id, pull, capacity, pixelCapacity, capabilities)
 }
 
 func (orch *orchestrator) TranscoderResults(tcID int64, res *RemoteTranscoderResult) {
@@ -415,6 +423,8 @@ type TranscodeResult struct {
 	Sig           []byte
 	TranscodeData *TranscodeData
 	OS            drivers.OSSession
+	// Cached is true if the results were found in the transcode cache
+	Cached bool
 }
 
 // TranscodeData contains the transcoding output for an input segment
@@ -484,25 +494,66 @@ func (rtm *RemoteTranscoderManager) removeTaskChan(taskID int64) {
 }
 
 func (n *LivepeerNode) getSegmentChan(ctx context.Context, md *SegTranscodingMetadata) (SegmentChan, error) {
+	for {
+		sc, preempt, err := n.newSegmentChan(ctx, md)
+		if preempt == "" {
+			return sc, err
+		}
+		// The session is ended without holding the lock because ending it needs the lock too
+		clog.Infof(ctx, "Preempting transcoding session sessionID=%s for priority=%d", preempt, md.Priority)
+		n.endTranscodingSession(string(preempt), ctx)
+	}
+}
+
+// newSegmentChan returns the segment chan of the session or creates it. If the orchestrator is at capacity, it returns
+// a session of a lower priority to preempt instead
+func (n *LivepeerNode) newSegmentChan(ctx context.Context, md *SegTranscodingMetadata) (SegmentChan, ManifestID, error) {
 	// concurrency concerns here? what if a chan is added mid-call?
 	n.segmentMutex.Lock()
 	defer n.segmentMutex.Unlock()
 	if sc, ok := n.SegmentChans[ManifestID(md.AuthToken.SessionId)]; ok {
-		return sc, nil
+		return sc, "", nil
 	}
-	if len(n.SegmentChans) >= MaxSessions {
-		return nil, ErrOrchCap
+	if n.atCapacity(ManifestID(md.AuthToken.SessionId)) {
+		if preempt := n.preemptibleSession(md.Priority); preempt != "" {
+			return nil, preempt, nil
+		}
+		return nil, "", ErrOrchCap
 	}
 	sc := make(SegmentChan, maxSegmentChannels)
 	clog.V(common.DEBUG).Infof(ctx, "Creating new segment chan")
 	if err := n.transcodeSegmentLoop(clog.Clone(context.Background(), ctx), md, sc); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	n.SegmentChans[ManifestID(md.AuthToken.SessionId)] = sc
+	if n.segmentPriorities == nil {
+		n.segmentPriorities = make(map[ManifestID]int32)
+	}
+	n.segmentPriorities[ManifestID(md.AuthToken.SessionId)] = md.Priority
 	if lpmon.Enabled {
 		lpmon.CurrentSessions(len(n.SegmentChans))
 	}
-	return sc, nil
+	return sc, "", nil
+}
+
+// preemptibleSession returns the session with the lowest priority below the given priority or an empty ID if there
+// is none or the priority cannot preempt. Sessions that use a reservation are not preempted. Caller should hold the
+// segmentMutex lock
+func (n *LivepeerNode) preemptibleSession(priority int32) ManifestID {
+	if !n.CanPreempt(priority) {
+		return ""
+	}
+	var preempt ManifestID
+	lowest := priority
+	for mid := range n.SegmentChans {
+		if _, ok := n.reservedSessions[mid]; ok {
+			continue
+		}
+		if p := n.segmentPriorities[mid]; p < lowest || (p == lowest && preempt != "" && mid < preempt) {
+			preempt, lowest = mid, p
+		}
+	}
+	return preempt
 }
 
 func (n *LivepeerNode) sendToTranscodeLoop(ctx context.Context, md *SegTranscodingMetadata, seg *stream.HLSSegment) (*TranscodeResult, error) {
@@ -538,6 +589,13 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 	// NOTE: If we ever process segments from the same job concurrently,
 	// we may still end up doing work multiple times. But this is OK for now.
 
+	if tData := n.TranscodeCache.Get(md); tData != nil {
+		clog.V(common.DEBUG).Infof(ctx, "Found transcoded segment in cache")
+		tr := n.transcodeResult(ctx, config, tData)
+		tr.Cached = true
+		return tr
+	}
+
 	//Assume d is in the right format, write it to disk
 	inName := common.RandName() + ".tempfile"
 	if _, err := os.Stat(n.WorkDir); os.IsNotExist(err) {
@@ -581,6 +639,7 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 		seg.Name = url
 	}
 	md.Fname = url
+	md.Preempt = n.CanPreempt(md.Priority)
 
 	//Do the transcoding
 	start := time.Now()
@@ -606,10 +665,6 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 		monitor.SegmentTranscoded(ctx, 0, seg.SeqNo, md.Duration, took, common.ProfilesNames(md.Profiles), true, true)
 	}
 
-	// Prepare the result object
-	var tr TranscodeResult
-	segHashes := make([][]byte, len(tSegments))
-
 	for i := range md.Profiles {
 		if tSegments[i].Data == nil || len(tSegments[i].Data) < 25 {
 			clog.Errorf(ctx, "Cannot find transcoded segment for bytes=%d", len(tSegments[i].Data))
@@ -622,10 +677,20 @@ func (n *LivepeerNode) transcodeSeg(ctx context.Context, config transcodeConfig,
 		}
 		clog.V(common.DEBUG).Infof(ctx, "Transcoded segment profile=%s bytes=%d",
 			md.Profiles[i].Name, len(tSegments[i].Data))
-		hash := crypto.Keccak256(tSegments[i].Data)
-		segHashes[i] = hash
 	}
 	os.Remove(fname)
+	n.TranscodeCache.Add(md, tData)
+
+	return n.transcodeResult(ctx, config, tData)
+}
+
+// transcodeResult prepares the result object for the transcoded segments and signs their hashes
+func (n *LivepeerNode) transcodeResult(ctx context.Context, config transcodeConfig, tData *TranscodeData) *TranscodeResult {
+	var tr TranscodeResult
+	segHashes := make([][]byte, len(tData.Segments))
+	for i, seg := range tData.Segments {
+		segHashes[i] = crypto.Keccak256(seg.Data)
+	}
 	tr.OS = config.OS
 	tr.TranscodeData = tData
 
@@ -722,6 +787,11 @@ func (n *LivepeerNode) endTranscodingSession(sessionId string, logCtx context.Co
 	if _, exists = n.SegmentChans[mid]; exists {
 		close(n.SegmentChans[mid])
 		delete(n.SegmentChans, mid)
+		delete(n.segmentPriorities, mid)
+		if r, ok := n.reservedSessions[mid]; ok {
+			delete(r.sessions, mid)
+			delete(n.reservedSessions, mid)
+		}
 		if lpmon.Enabled {
 			lpmon.CurrentSessions(len(n.SegmentChans))
 		}
@@ -732,7 +802,7 @@ func (n *LivepeerNode) endTranscodingSession(sessionId string, logCtx context.Co
 	}
 }
 
-func (n *LivepeerNode) serveTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
+func (n *LivepeerNode) serveTranscoder(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities) {
 	from := common.GetConnectionAddr(stream.Context())
 	coreCaps := CapabilitiesFromNetCapabilities(capabilities)
 	n.Capabilities.AddCapacity(coreCaps)
@@ -743,7 +813,7 @@ func (n *LivepeerNode) serveTranscoder(stream net.Transcoder_RegisterTranscoderS
 	}
 
 	// Manage blocks while transcoder is connected
-	n.TranscoderManager.Manage(stream, capacity, capabilities)
+	n.TranscoderManager.ManageWithPixelCapacity(stream, id, pull, capacity, pixelCapacity, capabilities)
 	glog.V(common.DEBUG).Infof("Closing transcoder=%s channel", from)
 
 	if n.AutoSessionLimit {
@@ -756,7 +826,11 @@ func (rtm *RemoteTranscoderManager) transcoderResults(tcID int64, res *RemoteTra
 	if err != nil {
 		return // do we need to return anything?
 	}
-	remoteChan <- res
+	select {
+	case remoteChan <- res:
+	default:
+		// Results were already received for the task, e.g. from a transcoder whose lease on the segment expired
+	}
 }
 
 type RemoteTranscoder struct {
@@ -764,9 +838,20 @@ type RemoteTranscoder struct {
 	stream       net.Transcoder_RegisterTranscoderServer
 	capabilities *Capabilities
 	eof          chan struct{}
+	id           string
+	// The sessions of a reattachable transcoder are kept for a grace period after it disconnects
+	reattachable bool
 	addr         string
 	capacity     int
 	load         int
+	// Output pixels per second the transcoder can encode or 0 if it did not measure its throughput
+	pixelCapacity int64
+	// Estimated output pixels per second of the sessions assigned to the transcoder
+	pixelLoad int64
+	// A draining transcoder is not assigned new sessions and its sessions are migrated to other transcoders
+	draining bool
+	// Segments for a pull mode transcoder to lease or nil if segments are sent on the stream
+	jobs *transcoderJobQueue
 }
 
 // RemoteTranscoderFatalError wraps error to indicate that error is fatal
@@ -783,6 +868,13 @@ func NewRemoteTranscoderFatalError(err error) error {
 var ErrRemoteTranscoderTimeout = errors.New("Remote transcoder took too long")
 var ErrNoTranscodersAvailable = errors.New("no transcoders available")
 var ErrNoCompatibleTranscodersAvailable = errors.New("no transcoders can provide requested capabilities")
+var ErrTranscoderNotFound = errors.New("transcoder not found")
+var errTranscoderReconnecting = errors.New("transcoder reconnecting")
+var errTranscoderDisconnected = errors.New("transcoder disconnected")
+
+// TranscoderReconnectGracePeriod is how long the sessions of a disconnected remote transcoder are kept for it to
+// reconnect and resume them. Segments of these sessions are transcoded by other transcoders in the meantime
+var TranscoderReconnectGracePeriod = 10 * time.Second
 
 func (rt *RemoteTranscoder) done() {
 	// select so we don't block indefinitely if there's no listener
@@ -812,18 +904,18 @@ func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscoding
 		return nil, err
 	}
 
+	logCtx, span := monitor.StartSpan(logCtx, "NotifySegment", attribute.Int64("taskId", taskID),
+		attribute.String("transcoder", rt.addr))
+	defer span.End()
+
 	start := time.Now()
 	msg := &net.NotifySegment{
 		Url:     fname,
 		TaskId:  taskID,
 		SegData: segData,
 		// Triggers failure on Os that don't know how to use SegData
-		Profiles: []byte("invalid"),
-	}
-	err = rt.stream.Send(msg)
-
-	if err != nil {
-		return signalEOF(err)
+		Profiles:     []byte("invalid"),
+		TraceContext: monitor.TraceContext(logCtx),
 	}
 
 	// set a minimum timeout to accommodate transport / processing overhead
@@ -833,11 +925,29 @@ func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscoding
 		dur = paddedDur
 	}
 
+	// Closed once a pull mode transcoder disconnects
+	var closed chan struct{}
+	if rt.jobs != nil {
+		rt.jobs.push(msg, dur)
+		defer rt.jobs.remove(taskID)
+		closed = rt.jobs.closed
+	} else if err = rt.stream.Send(msg); err != nil {
+		return signalEOF(err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), dur)
 	defer cancel()
 	select {
 	case <-ctx.Done():
+		if rt.jobs != nil {
+			// Expired leases are queued again so a slow pull mode transcoder is kept
+			clog.Errorf(logCtx, "Timed out waiting for results of pull mode transcoder=%s taskId=%d fname=%s", rt.addr, taskID, fname)
+			return nil, RemoteTranscoderFatalError{ErrRemoteTranscoderTimeout}
+		}
 		return signalEOF(ErrRemoteTranscoderTimeout)
+	case <-closed:
+		clog.Errorf(logCtx, "Pull mode transcoder=%s disconnected before returning results taskId=%d fname=%s", rt.addr, taskID, fname)
+		return nil, RemoteTranscoderFatalError{errTranscoderDisconnected}
 	case chanData := <-taskChan:
 		segmentLen := 0
 		if chanData.TranscodeData != nil {
@@ -848,14 +958,27 @@ func (rt *RemoteTranscoder) Transcode(logCtx context.Context, md *SegTranscoding
 		return chanData.TranscodeData, chanData.Err
 	}
 }
-func NewRemoteTranscoder(m *RemoteTranscoderManager, stream net.Transcoder_RegisterTranscoderServer, capacity int, caps *Capabilities) *RemoteTranscoder {
+func NewRemoteTranscoder(m *RemoteTranscoderManager, stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, caps *Capabilities) *RemoteTranscoder {
+	addr := common.GetConnectionAddr(stream.Context())
+	reattachable := id != ""
+	if !reattachable {
+		// Legacy transcoders do not send an ID so they are identified by their address
+		id = addr
+	}
+	var jobs *transcoderJobQueue
+	if pull {
+		jobs = newTranscoderJobQueue(m.leaseTimeout)
+	}
 	return &RemoteTranscoder{
 		manager:      m,
 		stream:       stream,
 		eof:          make(chan struct{}, 1),
 		capacity:     capacity,
-		addr:         common.GetConnectionAddr(stream.Context()),
+		id:           id,
+		reattachable: reattachable,
+		addr:         addr,
 		capabilities: caps,
+		jobs:         jobs,
 	}
 }
 
@@ -868,14 +991,51 @@ func NewRemoteTranscoderManager() *RemoteTranscoderManager {
 		taskMutex: &sync.RWMutex{},
 		taskChans: make(map[int64]TranscoderChan),
 
-		streamSessions: make(map[string]*RemoteTranscoder),
+		streamSessions:          make(map[string]*RemoteTranscoder),
+		streamSessionCosts:      make(map[string]int64),
+		streamSessionPriorities: make(map[string]int32),
+
+		disconnectedTranscoders: make(map[string]*disconnectedTranscoder),
+		reconnectGracePeriod:    TranscoderReconnectGracePeriod,
+
+		leaseTimeout: TranscoderLeaseTimeout,
 	}
 }
 
 type byLoadFactor []*RemoteTranscoder
 
 func loadFactor(r *RemoteTranscoder) float64 {
-	return float64(r.load) / float64(r.capacity)
+	factor := float64(r.load) / float64(r.capacity)
+	if r.pixelCapacity > 0 {
+		factor = math.Max(factor, float64(r.pixelLoad)/float64(r.pixelCapacity))
+	}
+	return factor
+}
+
+// hasCapacityFor returns whether a new session with the provided cost can be assigned to the transcoder.
+// An idle transcoder accepts any session so that sessions more expensive than any transcoder can still be transcoded
+func (rt *RemoteTranscoder) hasCapacityFor(cost int64) bool {
+	if rt.load >= rt.capacity {
+		return false
+	}
+	return rt.pixelCapacity <= 0 || rt.load == 0 || rt.pixelLoad+cost <= rt.pixelCapacity
+}
+
+// compatibleWith returns whether the transcoder provides the capabilities
+func (rt *RemoteTranscoder) compatibleWith(caps *Capabilities) bool {
+	// no capabilities = default capabilities, all transcoders must support them
+	return caps == nil ||
+		(caps.bitstring.CompatibleWith(rt.capabilities.bitstring) &&
+			caps.LivepeerVersionCompatibleWith(rt.capabilities.ToNetCapabilities()))
+}
+
+// fitsBetterThan returns whether a new session with the provided cost leaves less unused pixel capacity on the
+// transcoder than on other. Transcoders without a measured pixel capacity are not compared
+func (rt *RemoteTranscoder) fitsBetterThan(other *RemoteTranscoder, cost int64) bool {
+	if rt.pixelCapacity <= 0 || other.pixelCapacity <= 0 {
+		return false
+	}
+	return rt.pixelCapacity-rt.pixelLoad-cost < other.pixelCapacity-other.pixelLoad-cost
 }
 
 func (r byLoadFactor) Len() int      { return len(r) }
@@ -896,6 +1056,23 @@ type RemoteTranscoderManager struct {
 
 	// Map for keeping track of sessions and their respective transcoders
 	streamSessions map[string]*RemoteTranscoder
+	// Estimated output pixels per second of the sessions
+	streamSessionCosts map[string]int64
+	// Priorities of the sessions
+	streamSessionPriorities map[string]int32
+
+	// Transcoders that disconnected less than reconnectGracePeriod ago, by ID
+	disconnectedTranscoders map[string]*disconnectedTranscoder
+	reconnectGracePeriod    time.Duration
+
+	leaseTimeout time.Duration
+}
+
+type disconnectedTranscoder struct {
+	transcoder *RemoteTranscoder
+	timer      *time.Timer
+	// Closed when the transcoder reconnects or the grace period expires
+	done chan struct{}
 }
 
 // RegisteredTranscodersCount returns number of registered transcoders
@@ -910,16 +1087,52 @@ func (rtm *RemoteTranscoderManager) RegisteredTranscodersInfo() []common.RemoteT
 	rtm.RTmutex.Lock()
 	res := make([]common.RemoteTranscoderInfo, 0, len(rtm.liveTranscoders))
 	for _, transcoder := range rtm.liveTranscoders {
-		res = append(res, common.RemoteTranscoderInfo{Address: transcoder.addr, Capacity: transcoder.capacity})
+		res = append(res, common.RemoteTranscoderInfo{
+			ID:            transcoder.id,
+			Address:       transcoder.addr,
+			Capacity:      transcoder.capacity,
+			Load:          transcoder.load,
+			PixelCapacity: transcoder.pixelCapacity,
+			PixelLoad:     transcoder.pixelLoad,
+			Capabilities:  transcoder.capabilities.names(),
+			Draining:      transcoder.draining,
+		})
 	}
 	rtm.RTmutex.Unlock()
+	sort.Slice(res, func(i, j int) bool { return res[i].Address < res[j].Address })
 	return res
 }
 
+// SetTranscoderDraining sets the drain state of the live transcoder with the provided ID or address.
+// A draining transcoder is not assigned new sessions and its existing sessions are migrated to other transcoders
+// on their next segment if there is capacity for them
+func (rtm *RemoteTranscoderManager) SetTranscoderDraining(id string, draining bool) error {
+	rtm.RTmutex.Lock()
+	defer rtm.RTmutex.Unlock()
+
+	for _, transcoder := range rtm.liveTranscoders {
+		if transcoder.id == id || transcoder.addr == id {
+			transcoder.draining = draining
+			glog.Infof("Set transcoder=%s id=%s draining=%v load=%d", transcoder.addr, transcoder.id, draining, transcoder.load)
+			return nil
+		}
+	}
+	return ErrTranscoderNotFound
+}
+
 // Manage adds transcoder to list of live transcoders. Doesn't return until transcoder disconnects
-func (rtm *RemoteTranscoderManager) Manage(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
+func (rtm *RemoteTranscoderManager) Manage(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, capabilities *net.Capabilities) {
+	rtm.ManageWithPixelCapacity(stream, id, pull, capacity, 0, capabilities)
+}
+
+// ManageWithPixelCapacity adds a transcoder that measured the output pixels per second it can encode to the list of
+// live transcoders. A pixelCapacity of 0 means that it is unknown. Doesn't return until transcoder disconnects
+func (rtm *RemoteTranscoderManager) ManageWithPixelCapacity(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities) {
 	from := common.GetConnectionAddr(stream.Context())
-	transcoder := NewRemoteTranscoder(rtm, stream, capacity, CapabilitiesFromNetCapabilities(capabilities))
+	transcoder := NewRemoteTranscoder(rtm, stream, id, pull, capacity, CapabilitiesFromNetCapabilities(capabilities))
+	if pixelCapacity > 0 {
+		transcoder.pixelCapacity = pixelCapacity
+	}
 	go func() {
 		ctx := stream.Context()
 		<-ctx.Done()
@@ -929,6 +1142,9 @@ func (rtm *RemoteTranscoderManager) Manage(stream net.Transcoder_RegisterTransco
 	}()
 
 	rtm.RTmutex.Lock()
+	if transcoder.reattachable {
+		rtm.reattachTranscoder(transcoder)
+	}
 	rtm.liveTranscoders[transcoder.stream] = transcoder
 	rtm.remoteTranscoders = append(rtm.remoteTranscoders, transcoder)
 	sort.Sort(byLoadFactor(rtm.remoteTranscoders))
@@ -945,7 +1161,7 @@ func (rtm *RemoteTranscoderManager) Manage(stream net.Transcoder_RegisterTransco
 	glog.Infof("Got transcoder=%s eof, removing from live transcoders map", from)
 
 	rtm.RTmutex.Lock()
-	delete(rtm.liveTranscoders, transcoder.stream)
+	rtm.transcoderDisconnected(transcoder)
 	if monitor.Enabled {
 		totalLoad, totalCapacity, liveTranscodersNum = rtm.totalLoadAndCapacity()
 	}
@@ -955,6 +1171,160 @@ func (rtm *RemoteTranscoderManager) Manage(stream net.Transcoder_RegisterTransco
 	}
 }
 
+// transcoderDisconnected removes the transcoder from the live transcoders and keeps its sessions for the grace
+// period if it can reconnect. Caller should hold the RTmutex lock
+func (rtm *RemoteTranscoderManager) transcoderDisconnected(transcoder *RemoteTranscoder) {
+	// The transcoder is no longer live if it was already disconnected or it reconnected with a new stream
+	if rtm.liveTranscoders[transcoder.stream] != transcoder {
+		return
+	}
+	delete(rtm.liveTranscoders, transcoder.stream)
+	if transcoder.jobs != nil {
+		// Segments that were not transcoded yet are retried on other transcoders
+		transcoder.jobs.close()
+	}
+	if transcoder.reattachable && rtm.reconnectGracePeriod > 0 {
+		rtm.keepTranscoderSessions(transcoder)
+	}
+}
+
+// keepTranscoderSessions keeps the sessions of a disconnected transcoder for the grace period so that they are
+// resumed if the transcoder reconnects. Caller should hold the RTmutex lock
+func (rtm *RemoteTranscoderManager) keepTranscoderSessions(transcoder *RemoteTranscoder) {
+	if transcoder.load == 0 {
+		return
+	}
+	glog.Infof("Keeping sessions of transcoder=%s id=%s sessions=%d for gracePeriod=%v", transcoder.addr, transcoder.id, transcoder.load, rtm.reconnectGracePeriod)
+	d := &disconnectedTranscoder{transcoder: transcoder, done: make(chan struct{})}
+	d.timer = time.AfterFunc(rtm.reconnectGracePeriod, func() { rtm.expireDisconnectedTranscoder(d) })
+	rtm.disconnectedTranscoders[transcoder.id] = d
+}
+
+// expireDisconnectedTranscoder ends the sessions of a transcoder that did not reconnect within the grace period
+func (rtm *RemoteTranscoderManager) expireDisconnectedTranscoder(d *disconnectedTranscoder) {
+	rtm.RTmutex.Lock()
+	defer rtm.RTmutex.Unlock()
+
+	if rtm.disconnectedTranscoders[d.transcoder.id] != d {
+		// The transcoder already reconnected
+		return
+	}
+	delete(rtm.disconnectedTranscoders, d.transcoder.id)
+	for sessionId, t := range rtm.streamSessions {
+		if t == d.transcoder {
+			rtm.completeStreamSession(sessionId)
+		}
+	}
+	rtm.remoteTranscoders = removeFromRemoteTranscoders(d.transcoder, rtm.remoteTranscoders)
+	close(d.done)
+	glog.Infof("Transcoder=%s id=%s did not reconnect within gracePeriod=%v, ending its sessions", d.transcoder.addr, d.transcoder.id, rtm.reconnectGracePeriod)
+}
+
+// reattachTranscoder moves the sessions of a previous connection of the transcoder to the transcoder.
+// Caller should hold the RTmutex lock
+func (rtm *RemoteTranscoderManager) reattachTranscoder(transcoder *RemoteTranscoder) {
+	var prev *RemoteTranscoder
+	if d, ok := rtm.disconnectedTranscoders[transcoder.id]; ok {
+		d.timer.Stop()
+		delete(rtm.disconnectedTranscoders, transcoder.id)
+		close(d.done)
+		prev = d.transcoder
+	} else {
+		// The previous stream of the transcoder might not be closed yet
+		for stream, t := range rtm.liveTranscoders {
+			if t.reattachable && t.id == transcoder.id {
+				delete(rtm.liveTranscoders, stream)
+				prev = t
+				break
+			}
+		}
+	}
+	if prev == nil {
+		return
+	}
+
+	for sessionId, t := range rtm.streamSessions {
+		if t == prev {
+			rtm.streamSessions[sessionId] = transcoder
+			transcoder.load++
+			transcoder.pixelLoad += rtm.streamSessionCosts[sessionId]
+		}
+	}
+	prev.load = 0
+	prev.pixelLoad = 0
+	transcoder.draining = prev.draining
+	if prev.jobs != nil {
+		// Segments that were not transcoded yet are retried on other transcoders
+		prev.jobs.close()
+	}
+	rtm.remoteTranscoders = removeFromRemoteTranscoders(prev, rtm.remoteTranscoders)
+	glog.Infof("Reattached transcoder=%s id=%s sessions=%d", transcoder.addr, transcoder.id, transcoder.load)
+}
+
+// reconnecting returns whether the transcoder is disconnected and still within the grace period to reconnect.
+// Caller should hold the RTmutex lock
+func (rtm *RemoteTranscoderManager) reconnecting(transcoder *RemoteTranscoder) bool {
+	d, ok := rtm.disconnectedTranscoders[transcoder.id]
+	return ok && d.transcoder == transcoder
+}
+
+// selectFallbackTranscoder returns a live transcoder with capacity for a single segment of a session whose transcoder
+// is reconnecting, or nil if there is none. The session stays assigned to the reconnecting transcoder so that it
+// resumes the session if it reconnects within the grace period. The caller should release the transcoder with
+// releaseFallbackTranscoder once the segment is transcoded
+func (rtm *RemoteTranscoderManager) selectFallbackTranscoder(caps *Capabilities, cost int64) *RemoteTranscoder {
+	rtm.RTmutex.Lock()
+	defer rtm.RTmutex.Unlock()
+
+	var best *RemoteTranscoder
+	for _, t := range rtm.remoteTranscoders {
+		if _, ok := rtm.liveTranscoders[t.stream]; !ok || t.draining || !t.compatibleWith(caps) || !t.hasCapacityFor(cost) {
+			continue
+		}
+		if best == nil || t.fitsBetterThan(best, cost) {
+			best = t
+		}
+	}
+	if best != nil {
+		best.load++
+		best.pixelLoad += cost
+		sort.Sort(byLoadFactor(rtm.remoteTranscoders))
+	}
+	return best
+}
+
+// releaseFallbackTranscoder releases the capacity used by a segment on a fallback transcoder and ends the session
+// that the segment started on it
+func (rtm *RemoteTranscoderManager) releaseFallbackTranscoder(sessionId string, t *RemoteTranscoder, cost int64) {
+	rtm.RTmutex.Lock()
+	defer rtm.RTmutex.Unlock()
+
+	t.load--
+	t.pixelLoad -= cost
+	sort.Sort(byLoadFactor(rtm.remoteTranscoders))
+	if _, ok := rtm.liveTranscoders[t.stream]; ok {
+		// send empty segment to signal transcoder internal session teardown
+		msg := &net.NotifySegment{
+			SegData: &net.SegData{AuthToken: &net.AuthToken{SessionId: sessionId}},
+		}
+		_ = t.stream.Send(msg)
+	}
+}
+
+// waitForReconnect waits until the transcoder of a session reconnects or its grace period expires
+func (rtm *RemoteTranscoderManager) waitForReconnect(sessionId string) {
+	rtm.RTmutex.Lock()
+	var done chan struct{}
+	if t, ok := rtm.streamSessions[sessionId]; ok && rtm.reconnecting(t) {
+		done = rtm.disconnectedTranscoders[t.id].done
+	}
+	rtm.RTmutex.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
 func removeFromRemoteTranscoders(rt *RemoteTranscoder, remoteTranscoders []*RemoteTranscoder) []*RemoteTranscoder {
 	if len(remoteTranscoders) == 0 {
 		// No transcoders to remove, return
@@ -970,7 +1340,10 @@ func removeFromRemoteTranscoders(rt *RemoteTranscoder, remoteTranscoders []*Remo
 	return newRemoteTs
 }
 
-func (rtm *RemoteTranscoderManager) selectTranscoder(sessionId string, caps *Capabilities) (*RemoteTranscoder, error) {
+// selectTranscoder returns the transcoder of a session or assigns the session to a transcoder. New sessions are assigned
+// to the compatible transcoder that is left with the least unused pixel capacity, so that transcoders with more pixel
+// capacity stay available for more expensive sessions
+func (rtm *RemoteTranscoderManager) selectTranscoder(sessionId string, caps *Capabilities, cost int64, priority int32, preempt bool) (*RemoteTranscoder, error) {
 	rtm.RTmutex.Lock()
 	defer rtm.RTmutex.Unlock()
 
@@ -978,54 +1351,130 @@ func (rtm *RemoteTranscoderManager) selectTranscoder(sessionId string, caps *Cap
 		return len(rtm.remoteTranscoders) > 0
 	}
 
+	isCompatible := func(t *RemoteTranscoder) bool {
+		return t.compatibleWith(caps)
+	}
+
 	findCompatibleTranscoder := func(rtm *RemoteTranscoderManager) int {
 		for i := len(rtm.remoteTranscoders) - 1; i >= 0; i-- {
-			// no capabilities = default capabilities, all transcoders must support them
-			if caps == nil ||
-				(caps.bitstring.CompatibleWith(rtm.remoteTranscoders[i].capabilities.bitstring) &&
-					caps.LivepeerVersionCompatibleWith(rtm.remoteTranscoders[i].capabilities.ToNetCapabilities())) {
+			if isCompatible(rtm.remoteTranscoders[i]) {
 				return i
 			}
 		}
 		return -1
 	}
 
+	findAvailableTranscoder := func(rtm *RemoteTranscoderManager) int {
+		best := -1
+		for i := len(rtm.remoteTranscoders) - 1; i >= 0; i-- {
+			t := rtm.remoteTranscoders[i]
+			// draining and disconnected transcoders do not accept new sessions
+			if t.draining || rtm.reconnecting(t) || !isCompatible(t) || !t.hasCapacityFor(cost) {
+				continue
+			}
+			if best == -1 || t.fitsBetterThan(rtm.remoteTranscoders[best], cost) {
+				best = i
+			}
+		}
+		return best
+	}
+
+	// findPreemptibleSession returns the session with the lowest priority below the priority of the new session whose
+	// transcoder has capacity for the new session once the session is ended
+	findPreemptibleSession := func(rtm *RemoteTranscoderManager) string {
+		preempt := ""
+		lowest := priority
+		for id, t := range rtm.streamSessions {
+			p := rtm.streamSessionPriorities[id]
+			if p > lowest || (p == lowest && (preempt == "" || id > preempt)) {
+				continue
+			}
+			if _, ok := rtm.liveTranscoders[t.stream]; !ok || t.draining || rtm.reconnecting(t) || !isCompatible(t) {
+				continue
+			}
+			load, pixelLoad := t.load-1, t.pixelLoad-rtm.streamSessionCosts[id]
+			if load >= t.capacity || (t.pixelCapacity > 0 && load > 0 && pixelLoad+cost > t.pixelCapacity) {
+				continue
+			}
+			preempt, lowest = id, p
+		}
+		return preempt
+	}
+
 	for checkTranscoders(rtm) {
 		currentTranscoder, sessionExists := rtm.streamSessions[sessionId]
-		lastCompatibleTranscoder := findCompatibleTranscoder(rtm)
-		if lastCompatibleTranscoder == -1 {
-			return nil, ErrNoCompatibleTranscodersAvailable
-		}
-		if !sessionExists {
-			currentTranscoder = rtm.remoteTranscoders[lastCompatibleTranscoder]
+		if sessionExists {
+			if _, ok := rtm.liveTranscoders[currentTranscoder.stream]; !ok {
+				if rtm.reconnecting(currentTranscoder) {
+					// Keep the stream session for the transcoder to resume it when it reconnects
+					return nil, errTranscoderReconnecting
+				}
+				// Remove the stream session because the transcoder is no longer live
+				rtm.completeStreamSession(sessionId)
+				rtm.remoteTranscoders = removeFromRemoteTranscoders(currentTranscoder, rtm.remoteTranscoders)
+				continue
+			}
+			if !currentTranscoder.draining {
+				return currentTranscoder, nil
+			}
 		}
 
-		if _, ok := rtm.liveTranscoders[currentTranscoder.stream]; !ok {
-			// Remove the stream session because the transcoder is no longer live
+		availableTranscoder := findAvailableTranscoder(rtm)
+		if availableTranscoder == -1 {
 			if sessionExists {
-				rtm.completeStreamSession(sessionId)
+				// Keep the session on the draining transcoder because there is no transcoder to migrate it to
+				return currentTranscoder, nil
+			}
+			if findCompatibleTranscoder(rtm) == -1 {
+				return nil, ErrNoCompatibleTranscodersAvailable
 			}
+			if preempted := findPreemptibleSession(rtm); preempt && preempted != "" {
+				glog.Infof("Preempting session=%s priority=%d for session=%s priority=%d", preempted,
+					rtm.streamSessionPriorities[preempted], sessionId, priority)
+				rtm.migrateStreamSession(preempted, rtm.streamSessions[preempted])
+				continue
+			}
+			// All compatible transcoders are at capacity or draining
+			return nil, ErrNoTranscodersAvailable
+		}
+
+		nextTranscoder := rtm.remoteTranscoders[availableTranscoder]
+		if _, ok := rtm.liveTranscoders[nextTranscoder.stream]; !ok {
 			// transcoder does not exist in table; remove and retry
-			rtm.remoteTranscoders = removeFromRemoteTranscoders(currentTranscoder, rtm.remoteTranscoders)
+			rtm.remoteTranscoders = removeFromRemoteTranscoders(nextTranscoder, rtm.remoteTranscoders)
 			continue
 		}
-		if !sessionExists {
-			if currentTranscoder.load == currentTranscoder.capacity {
-				// Head of queue is at capacity, so the rest must be too. Exit early
-				return nil, ErrNoTranscodersAvailable
-			}
-
-			// Assinging transcoder to session for future use
-			rtm.streamSessions[sessionId] = currentTranscoder
-			currentTranscoder.load++
-			sort.Sort(byLoadFactor(rtm.remoteTranscoders))
+		if sessionExists {
+			// Migrate the session away from the draining transcoder
+			rtm.migrateStreamSession(sessionId, currentTranscoder)
 		}
-		return currentTranscoder, nil
+
+		// Assinging transcoder to session for future use
+		rtm.streamSessions[sessionId] = nextTranscoder
+		rtm.streamSessionCosts[sessionId] = cost
+		rtm.streamSessionPriorities[sessionId] = priority
+		nextTranscoder.load++
+		nextTranscoder.pixelLoad += cost
+		sort.Sort(byLoadFactor(rtm.remoteTranscoders))
+		return nextTranscoder, nil
 	}
 
 	return nil, ErrNoTranscodersAvailable
 }
 
+// migrateStreamSession ends a stream session on a draining transcoder or a preempted session so that it can be
+// assigned to another transcoder
+// caller should hold the mutex lock
+func (rtm *RemoteTranscoderManager) migrateStreamSession(sessionId string, from *RemoteTranscoder) {
+	glog.Infof("Migrating session=%s from transcoder=%s", sessionId, from.addr)
+	// send empty segment to signal transcoder internal session teardown
+	msg := &net.NotifySegment{
+		SegData: &net.SegData{AuthToken: &net.AuthToken{SessionId: sessionId}},
+	}
+	_ = from.stream.Send(msg)
+	rtm.completeStreamSession(sessionId)
+}
+
 // ends transcoding session and releases resources
 func (node *LivepeerNode) EndTranscodingSession(sessionId string) {
 	node.endTranscodingSession(sessionId, context.TODO())
@@ -1043,8 +1492,11 @@ func (rtm *RemoteTranscoderManager) completeStreamSession(sessionId string) {
 		return
 	}
 	t.load--
+	t.pixelLoad -= rtm.streamSessionCosts[sessionId]
 	sort.Sort(byLoadFactor(rtm.remoteTranscoders))
 	delete(rtm.streamSessions, sessionId)
+	delete(rtm.streamSessionCosts, sessionId)
+	delete(rtm.streamSessionPriorities, sessionId)
 }
 
 // Caller of this function should hold RTmutex lock
@@ -1059,16 +1511,51 @@ func (rtm *RemoteTranscoderManager) totalLoadAndCapacity() (int, int, int) {
 
 // Transcode does actual transcoding using remote transcoder from the pool
 func (rtm *RemoteTranscoderManager) Transcode(ctx context.Context, md *SegTranscodingMetadata) (*TranscodeData, error) {
-	currentTranscoder, err := rtm.selectTranscoder(md.AuthToken.SessionId, md.Caps)
+	cost := EstimateSessionCost(md.Profiles)
+	currentTranscoder, err := rtm.selectTranscoder(md.AuthToken.SessionId, md.Caps, cost, md.Priority, md.Preempt)
+	for err == errTranscoderReconnecting {
+		// Transcode the segment on another transcoder instead of waiting for the transcoder of the session to reconnect
+		if fallback := rtm.selectFallbackTranscoder(md.Caps, cost); fallback != nil {
+			return rtm.transcodeWithFallback(ctx, md, fallback, cost)
+		}
+		rtm.waitForReconnect(md.AuthToken.SessionId)
+		currentTranscoder, err = rtm.selectTranscoder(md.AuthToken.SessionId, md.Caps, cost, md.Priority, md.Preempt)
+	}
 	if err != nil {
 		return nil, err
 	}
 	res, err := currentTranscoder.Transcode(ctx, md)
+	_, fatal := err.(RemoteTranscoderFatalError)
 	if err != nil {
 		rtm.RTmutex.Lock()
-		rtm.completeStreamSession(md.AuthToken.SessionId)
+		if fatal && err.(RemoteTranscoderFatalError).error != ErrRemoteTranscoderTimeout {
+			// The segment could not be sent to the transcoder, keep the session if the transcoder can reconnect
+			rtm.transcoderDisconnected(currentTranscoder)
+		}
+		// The session might have been resumed by a new connection of the transcoder already
+		if rtm.streamSessions[md.AuthToken.SessionId] == currentTranscoder && !rtm.reconnecting(currentTranscoder) {
+			rtm.completeStreamSession(md.AuthToken.SessionId)
+		}
+		rtm.RTmutex.Unlock()
+	}
+	return rtm.retryFatal(ctx, md, res, err)
+}
+
+// transcodeWithFallback transcodes a segment on a fallback transcoder while the transcoder of its session reconnects
+func (rtm *RemoteTranscoderManager) transcodeWithFallback(ctx context.Context, md *SegTranscodingMetadata, fallback *RemoteTranscoder, cost int64) (*TranscodeData, error) {
+	glog.Infof("Transcoding segment of session=%s on transcoder=%s while its transcoder reconnects", md.AuthToken.SessionId, fallback.addr)
+	res, err := fallback.Transcode(ctx, md)
+	if fatalErr, ok := err.(RemoteTranscoderFatalError); ok && fatalErr.error != ErrRemoteTranscoderTimeout {
+		rtm.RTmutex.Lock()
+		rtm.transcoderDisconnected(fallback)
 		rtm.RTmutex.Unlock()
 	}
+	rtm.releaseFallbackTranscoder(md.AuthToken.SessionId, fallback, cost)
+	return rtm.retryFatal(ctx, md, res, err)
+}
+
+// retryFatal retries a segment that could not be sent to a transcoder
+func (rtm *RemoteTranscoderManager) retryFatal(ctx context.Context, md *SegTranscodingMetadata, res *TranscodeData, err error) (*TranscodeData, error) {
 	_, fatal := err.(RemoteTranscoderFatalError)
 	if fatal {
 		// Don't retry if we've timed out; broadcaster likely to have moved on
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Priority classes of streams. Segments of higher priority streams are scheduled first and their sessions may preempt
+// the sessions of lower priority streams when the orchestrator is at capacity
+const (
+	// PriorityLow is for best-effort work such as VOD
+	PriorityLow int32 = -1
+	// PriorityNormal is the priority of streams that do not set one
+	PriorityNormal int32 = 0
+	// PriorityHigh is for premium live streams
+	PriorityHigh int32 = 1
+)
+
+var priorityNames = map[string]int32{
+	"low":    PriorityLow,
+	"normal": PriorityNormal,
+	"high":   PriorityHigh,
+}
+
+// ParsePriority parses a priority class name or number
+func ParsePriority(s string) (int32, error) {
+	if p, ok := priorityNames[strings.ToLower(strings.TrimSpace(s))]; ok {
+		return p, nil
+	}
+	p, err := strconv.ParseInt(strings.TrimSpace(s), 10, 32)
+	if err != nil || int32(p) != ClampPriority(int32(p)) {
+		return 0, fmt.Errorf("invalid priority %q", s)
+	}
+	return int32(p), nil
+}
+
+// ClampPriority returns the priority class closest to the given priority
+func ClampPriority(priority int32) int32 {
+	if priority < PriorityLow {
+		return PriorityLow
+	}
+	if priority > PriorityHigh {
+		return PriorityHigh
+	}
+	return priority
+}
+
+// ParsePriorityPrices parses a comma separated list of price multipliers by priority class, e.g. "low=0.5,high=1.5"
+func ParsePriorityPrices(s string) (map[int32]*big.Rat, error) {
+	prices := make(map[int32]*big.Rat)
+	if strings.TrimSpace(s) == "" {
+		return prices, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid priority price %q", entry)
+		}
+		priority, err := ParsePriority(kv[0])
+		if err != nil {
+			return nil, err
+		}
+		multiplier, ok := new(big.Rat).SetString(strings.TrimSpace(kv[1]))
+		if !ok || multiplier.Sign() < 0 {
+			return nil, fmt.Errorf("invalid price multiplier %q for priority %q", kv[1], kv[0])
+		}
+		prices[priority] = multiplier
+	}
+	return prices, nil
+}
+
+// PriorityPrice returns the price of a stream with the given priority for the given base price
+func (n *LivepeerNode) PriorityPrice(price *big.Rat, priority int32) *big.Rat {
+	multiplier, ok := n.PriorityPrices[priority]
+	if !ok || price == nil {
+		return price
+	}
+	return new(big.Rat).Mul(price, multiplier)
+}
+
+// CanPreempt returns whether sessions of the given priority may preempt sessions of a lower priority. Only the
+// priority classes with a configured price can preempt so that the preempting stream pays for it
+func (n *LivepeerNode) CanPreempt(priority int32) bool {
+	_, ok := n.PriorityPrices[priority]
+	return ok
+}
+
+// IsTranscoding returns whether the orchestrator has a transcoding session for the stream
+func (n *LivepeerNode) IsTranscoding(mid ManifestID) bool {
+	n.segmentMutex.RLock()
+	defer n.segmentMutex.RUnlock()
+	_, ok := n.SegmentChans[mid]
+	return ok
+}
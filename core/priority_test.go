@@ -0,0 +1,75 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	assert := assert.New(t)
+
+	p, err := ParsePriority("High")
+	assert.Nil(err)
+	assert.Equal(PriorityHigh, p)
+	p, err = ParsePriority(" low ")
+	assert.Nil(err)
+	assert.Equal(PriorityLow, p)
+	p, err = ParsePriority("1")
+	assert.Nil(err)
+	assert.Equal(PriorityHigh, p)
+	_, err = ParsePriority("urgent")
+	assert.EqualError(err, `invalid priority "urgent"`)
+	// Numbers outside of the priority classes are rejected
+	_, err = ParsePriority("2")
+	assert.EqualError(err, `invalid priority "2"`)
+}
+
+func TestClampPriority(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(PriorityLow, ClampPriority(-100))
+	assert.Equal(PriorityLow, ClampPriority(PriorityLow))
+	assert.Equal(PriorityNormal, ClampPriority(PriorityNormal))
+	assert.Equal(PriorityHigh, ClampPriority(PriorityHigh))
+	assert.Equal(PriorityHigh, ClampPriority(1000))
+}
+
+func TestParsePriorityPrices(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	prices, err := ParsePriorityPrices("")
+	require.Nil(err)
+	assert.Empty(prices)
+
+	prices, err = ParsePriorityPrices("low=0.5, high=3/2")
+	require.Nil(err)
+	require.Len(prices, 2)
+	assert.Equal(big.NewRat(1, 2), prices[PriorityLow])
+	assert.Equal(big.NewRat(3, 2), prices[PriorityHigh])
+
+	_, err = ParsePriorityPrices("low")
+	assert.EqualError(err, `invalid priority price "low"`)
+	_, err = ParsePriorityPrices("urgent=2")
+	assert.EqualError(err, `invalid priority "urgent"`)
+	_, err = ParsePriorityPrices("high=-1")
+	assert.EqualError(err, `invalid price multiplier "-1" for priority "high"`)
+}
+
+func TestPriorityPrice(t *testing.T) {
+	assert := assert.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	price := big.NewRat(10, 1)
+
+	// The price is unchanged without multipliers
+	assert.Equal(price, n.PriorityPrice(price, PriorityHigh))
+
+	n.PriorityPrices = map[int32]*big.Rat{PriorityHigh: big.NewRat(3, 2)}
+	assert.Equal(big.NewRat(15, 1), n.PriorityPrice(price, PriorityHigh))
+	assert.Equal(price, n.PriorityPrice(price, PriorityNormal))
+	assert.Nil(n.PriorityPrice(nil, PriorityHigh))
+}
@@ -0,0 +1,395 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/lpms/ffmpeg"
+)
+
+// ReservationFeePercent is the percentage of the regular fee of transcoding the reserved sessions for their whole time
+// window that a broadcaster prepays for a reservation. Reservations are only accepted with a prepayment
+var ReservationFeePercent int64 = 10
+
+// MaxReservationWindow is the maximum time from now until the end of a reservation
+var MaxReservationWindow = 24 * time.Hour
+
+// MaxReservedPixelsPerSender is the maximum number of pixels that the reservations of a broadcaster that did not end
+// yet are able to hold
+var MaxReservedPixelsPerSender int64 = 1 << 50
+
+// reservationBindTimeout is the time after which a session that is bound to a reservation but did not start
+// transcoding loses its binding. It matches the validity of the auth token of the session
+var reservationBindTimeout = 30 * time.Minute
+
+var ErrReservationCap = errors.New("InsufficientCapacityForReservation")
+var ErrReservationPayment = errors.New("InsufficientReservationPayment")
+var errReservationFee = errors.New("capacity reservations require a prepayment")
+var errReservationWindow = errors.New("invalid reservation window")
+var errReservationSessions = errors.New("invalid number of reserved sessions")
+var errReservationPixels = errors.New("reservations exceed the reserved pixels limit of the broadcaster")
+
+// CapacityReservation holds sessions of the orchestrator for a broadcaster during a time window. Reserved sessions
+// that the broadcaster does not use count against MaxSessions for the other broadcasters
+type CapacityReservation struct {
+	ID       string                `json:"id"`
+	Sender   ethcommon.Address     `json:"sender"`
+	Sessions int                   `json:"sessions"`
+	Profiles []ffmpeg.VideoProfile `json:"-"`
+	Start    time.Time             `json:"start"`
+	End      time.Time             `json:"end"`
+	// Number of reserved sessions that are transcoding
+	Used int `json:"used"`
+
+	// Transcoding sessions of the broadcaster that are bound to the reservation and the time they were bound
+	sessions map[ManifestID]time.Time
+	pixels   *big.Int
+	timer    *time.Timer
+}
+
+func (r *CapacityReservation) active(now time.Time) bool {
+	return !now.Before(r.Start) && now.Before(r.End)
+}
+
+// ReserveCapacity reserves sessions for a broadcaster during a time window. The payment has to cover
+// ReservationFeePercent of the fee of transcoding the reserved sessions for the whole window
+func (orch *orchestrator) ReserveCapacity(ctx context.Context, sender ethcommon.Address, payment net.Payment, sessions int,
+	profiles []ffmpeg.VideoProfile, start, end time.Time) (*CapacityReservation, error) {
+
+	if err := validateReservation(sessions, start, end, time.Now()); err != nil {
+		return nil, err
+	}
+	if ReservationFeePercent <= 0 || orch.node.Recipient == nil || orch.node.Balances == nil {
+		return nil, errReservationFee
+	}
+	price, err := orch.priceInfo(sender, "")
+	if err != nil {
+		return nil, err
+	}
+	fee := new(big.Rat).SetInt(reservationPixels(profiles, sessions, start, end))
+	fee.Mul(fee, big.NewRat(ReservationFeePercent, 100))
+	fee.Mul(fee, price)
+	if fee.Sign() <= 0 {
+		return nil, errReservationFee
+	}
+
+	id := string(RandomManifestID())
+	if payment.TicketParams == nil {
+		return nil, ErrReservationPayment
+	}
+	if err := orch.ProcessPayment(ctx, payment, ManifestID(id)); err != nil {
+		return nil, err
+	}
+	if orch.node.Balances.Balance(sender, ManifestID(id)).Cmp(fee) < 0 {
+		return nil, ErrReservationPayment
+	}
+
+	r, err := orch.node.reserveCapacity(id, sender, sessions, profiles, start, end)
+	if err != nil {
+		return nil, err
+	}
+	orch.node.Balances.Debit(sender, ManifestID(id), fee)
+	return r, nil
+}
+
+// ReservationAvailable returns whether the broadcaster has an active reservation with unused sessions
+func (orch *orchestrator) ReservationAvailable(sender ethcommon.Address) bool {
+	orch.node.segmentMutex.RLock()
+	defer orch.node.segmentMutex.RUnlock()
+	return orch.node.availableReservation(sender, time.Now()) != nil
+}
+
+// BindReservation binds a transcoding session of the broadcaster to its active reservation with the most unused
+// sessions, so that the session is not limited by MaxSessions. A session is only bound once and loses its binding
+// when it ends or when it does not start transcoding within reservationBindTimeout
+func (orch *orchestrator) BindReservation(sender ethcommon.Address, sessionID ManifestID) {
+	n := orch.node
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	if _, ok := n.reservedSessions[sessionID]; ok {
+		return
+	}
+	now := time.Now()
+	r := n.availableReservation(sender, now)
+	if r == nil {
+		return
+	}
+	n.pruneReservationBindings(r, now)
+	r.sessions[sessionID] = now
+	if n.reservedSessions == nil {
+		n.reservedSessions = make(map[ManifestID]*CapacityReservation)
+	}
+	n.reservedSessions[sessionID] = r
+}
+
+// RestoreCapacityReservations restores the reservations that did not end yet from the database
+func (n *LivepeerNode) RestoreCapacityReservations() error {
+	reservations, err := n.Database.CapacityReservations(time.Now())
+	if err != nil {
+		return err
+	}
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+	for _, dbr := range reservations {
+		if _, ok := n.reservations[dbr.ID]; ok {
+			continue
+		}
+		n.addReservation(&CapacityReservation{
+			ID:       dbr.ID,
+			Sender:   dbr.Sender,
+			Sessions: dbr.Sessions,
+			Profiles: dbr.Profiles,
+			Start:    dbr.Start,
+			End:      dbr.End,
+			pixels:   reservationPixels(dbr.Profiles, dbr.Sessions, dbr.Start, dbr.End),
+		})
+	}
+	glog.Infof("Restored %d capacity reservations", len(reservations))
+	return nil
+}
+
+// CapacityReservations returns the reservations that did not end yet ordered by start time
+func (n *LivepeerNode) CapacityReservations() []CapacityReservation {
+	n.segmentMutex.RLock()
+	defer n.segmentMutex.RUnlock()
+	res := make([]CapacityReservation, 0, len(n.reservations))
+	for _, r := range n.reservations {
+		res = append(res, CapacityReservation{
+			ID:       r.ID,
+			Sender:   r.Sender,
+			Sessions: r.Sessions,
+			Profiles: r.Profiles,
+			Start:    r.Start,
+			End:      r.End,
+			Used:     n.reservationUsed(r),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Start.Before(res[j].Start) })
+	return res
+}
+
+func (n *LivepeerNode) reserveCapacity(id string, sender ethcommon.Address, sessions int, profiles []ffmpeg.VideoProfile,
+	start, end time.Time) (*CapacityReservation, error) {
+
+	now := time.Now()
+	if err := validateReservation(sessions, start, end, now); err != nil {
+		return nil, err
+	}
+	pixels := reservationPixels(profiles, sessions, start, end)
+
+	n.segmentMutex.Lock()
+	defer n.segmentMutex.Unlock()
+
+	reserved := new(big.Int).Set(pixels)
+	for _, r := range n.reservations {
+		if r.Sender == sender {
+			reserved.Add(reserved, r.pixels)
+		}
+	}
+	if reserved.Cmp(big.NewInt(MaxReservedPixelsPerSender)) > 0 {
+		return nil, errReservationPixels
+	}
+
+	// The reserved sessions peak at the start of the window or at the start of an overlapping reservation
+	peaks := []time.Time{start}
+	if start.Before(now) {
+		peaks[0] = now
+	}
+	for _, r := range n.reservations {
+		if r.Start.After(peaks[0]) && r.Start.Before(end) {
+			peaks = append(peaks, r.Start)
+		}
+	}
+	for _, t := range peaks {
+		reserved := sessions
+		for _, r := range n.reservations {
+			if r.active(t) {
+				reserved += r.Sessions
+			}
+		}
+		if !t.After(now) {
+			// Sessions that are already transcoding keep their slots
+			reserved += len(n.SegmentChans) - len(n.reservedSegmentChans(now))
+		}
+		if reserved > MaxSessions {
+			return nil, ErrReservationCap
+		}
+	}
+
+	r := &CapacityReservation{
+		ID:       id,
+		Sender:   sender,
+		Sessions: sessions,
+		Profiles: profiles,
+		Start:    start,
+		End:      end,
+		pixels:   pixels,
+	}
+	err := n.Database.InsertCapacityReservation(&common.DBCapacityReservation{
+		ID:       id,
+		Sender:   sender,
+		Sessions: sessions,
+		Profiles: profiles,
+		Start:    start,
+		End:      end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	n.addReservation(r)
+	glog.Infof("Reserved capacity id=%s sender=%s sessions=%d start=%v end=%v", id, sender.Hex(), sessions, start, end)
+	return r, nil
+}
+
+// addReservation holds the capacity of a reservation until its window ends.
+// Caller should hold the segmentMutex lock
+func (n *LivepeerNode) addReservation(r *CapacityReservation) {
+	id := r.ID
+	r.sessions = make(map[ManifestID]time.Time)
+	r.timer = time.AfterFunc(time.Until(r.End), func() { n.releaseReservation(id) })
+	if n.reservations == nil {
+		n.reservations = make(map[string]*CapacityReservation)
+	}
+	n.reservations[id] = r
+}
+
+// validateReservation checks the number of sessions and the window of a reservation
+func validateReservation(sessions int, start, end, now time.Time) error {
+	if sessions < 1 {
+		return errReservationSessions
+	}
+	if !end.After(start) || !end.After(now) || end.After(now.Add(MaxReservationWindow)) {
+		return errReservationWindow
+	}
+	return nil
+}
+
+// reservationPixels returns the number of pixels that the reserved sessions are able to transcode during the window
+func reservationPixels(profiles []ffmpeg.VideoProfile, sessions int, start, end time.Time) *big.Int {
+	perSecond := new(big.Rat)
+	for _, p := range profiles {
+		w, h, err := ffmpeg.VideoProfileResolution(p)
+		if err != nil {
+			continue
+		}
+		fps := big.NewRat(defaultCostFramerate, 1)
+		if p.Framerate > 0 {
+			den := int64(1)
+			if p.FramerateDen > 0 {
+				den = int64(p.FramerateDen)
+			}
+			fps.SetFrac(new(big.Int).SetUint64(uint64(p.Framerate)), big.NewInt(den))
+		}
+		frame := new(big.Int).Mul(big.NewInt(int64(w)), big.NewInt(int64(h)))
+		perSecond.Add(perSecond, fps.Mul(fps, new(big.Rat).SetInt(frame)))
+	}
+	pixels := perSecond.Mul(perSecond, new(big.Rat).SetInt64(int64(end.Sub(start).Seconds())))
+	pixels.Mul(pixels, new(big.Rat).SetInt64(int64(sessions)))
+	return new(big.Int).Quo(pixels.Num(), pixels.Denom())
+}
+
+// releaseReservation removes a reservation once its window ends. Sessions that used the reservation keep transcoding
+func (n *LivepeerNode) releaseReservation(id string) {
+	n.segmentMutex.Lock()
+	r, ok := n.reservations[id]
+	if !ok {
+		n.segmentMutex.Unlock()
+		return
+	}
+	r.timer.Stop()
+	for mid := range r.sessions {
+		delete(n.reservedSessions, mid)
+	}
+	delete(n.reservations, id)
+	n.segmentMutex.Unlock()
+
+	if err := n.Database.DeleteCapacityReservation(id); err != nil {
+		glog.Errorf("Error deleting capacity reservation id=%s err=%q", id, err)
+	}
+	glog.Infof("Released capacity reservation id=%s sender=%s", id, r.Sender.Hex())
+}
+
+// pruneReservationBindings removes the bindings of sessions that did not start transcoding within
+// reservationBindTimeout. Caller should hold the segmentMutex lock
+func (n *LivepeerNode) pruneReservationBindings(r *CapacityReservation, now time.Time) {
+	for mid, bound := range r.sessions {
+		if _, ok := n.SegmentChans[mid]; ok {
+			continue
+		}
+		if now.Sub(bound) > reservationBindTimeout {
+			delete(r.sessions, mid)
+			delete(n.reservedSessions, mid)
+		}
+	}
+}
+
+// reservationUsed returns the number of reserved sessions that are transcoding.
+// Caller should hold the segmentMutex lock
+func (n *LivepeerNode) reservationUsed(r *CapacityReservation) int {
+	used := 0
+	for mid := range r.sessions {
+		if _, ok := n.SegmentChans[mid]; ok {
+			used++
+		}
+	}
+	return used
+}
+
+// reservedSegmentChans returns the transcoding sessions that use an active reservation.
+// Caller should hold the segmentMutex lock
+func (n *LivepeerNode) reservedSegmentChans(now time.Time) []ManifestID {
+	var mids []ManifestID
+	for _, r := range n.reservations {
+		if !r.active(now) {
+			continue
+		}
+		for mid := range r.sessions {
+			if _, ok := n.SegmentChans[mid]; ok {
+				mids = append(mids, mid)
+			}
+		}
+	}
+	return mids
+}
+
+// availableReservation returns the active reservation of the broadcaster with the most unused sessions or nil if there
+// is none. Caller should hold the segmentMutex lock
+func (n *LivepeerNode) availableReservation(sender ethcommon.Address, now time.Time) *CapacityReservation {
+	var best *CapacityReservation
+	bestUnused := 0
+	for _, r := range n.reservations {
+		if r.Sender != sender || !r.active(now) {
+			continue
+		}
+		if unused := r.Sessions - n.reservationUsed(r); unused > bestUnused || (unused == bestUnused && best != nil && r.ID < best.ID) {
+			best, bestUnused = r, unused
+		}
+	}
+	return best
+}
+
+// atCapacity returns whether a new transcoding session would exceed MaxSessions once the unused reserved sessions are
+// held for their broadcasters. Sessions bound to an active reservation with unused sessions are not limited by
+// MaxSessions. Caller should hold the segmentMutex lock
+func (n *LivepeerNode) atCapacity(mid ManifestID) bool {
+	now := time.Now()
+	if r, ok := n.reservedSessions[mid]; ok && r.active(now) && n.reservationUsed(r) < r.Sessions {
+		return false
+	}
+	unused := 0
+	for _, r := range n.reservations {
+		if r.active(now) {
+			if u := r.Sessions - n.reservationUsed(r); u > 0 {
+				unused += u
+			}
+		}
+	}
+	return len(n.SegmentChans)+unused >= MaxSessions
+}
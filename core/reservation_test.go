@@ -0,0 +1,310 @@
+package core
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveCapacity_Limits(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 4
+
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	now := time.Now()
+
+	_, err := n.reserveCapacity("invalid", sender, 0, nil, now, now.Add(time.Hour))
+	assert.Equal(errReservationSessions, err)
+	_, err = n.reserveCapacity("invalid", sender, 1, nil, now.Add(time.Hour), now)
+	assert.Equal(errReservationWindow, err)
+	_, err = n.reserveCapacity("invalid", sender, 1, nil, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	assert.Equal(errReservationWindow, err)
+	_, err = n.reserveCapacity("invalid", sender, 1, nil, now, now.Add(MaxReservationWindow+time.Minute))
+	assert.Equal(errReservationWindow, err)
+
+	_, err = n.reserveCapacity("a", sender, 2, nil, now.Add(time.Hour), now.Add(3*time.Hour))
+	require.Nil(err)
+	_, err = n.reserveCapacity("b", sender, 2, nil, now.Add(2*time.Hour), now.Add(4*time.Hour))
+	require.Nil(err)
+
+	// The reservations overlap from the start of "b" on
+	_, err = n.reserveCapacity("c", sender, 1, nil, now.Add(30*time.Minute), now.Add(5*time.Hour))
+	assert.Equal(ErrReservationCap, err)
+
+	// Windows that do not overlap with both reservations are able to hold more sessions
+	_, err = n.reserveCapacity("c", sender, 2, nil, now.Add(30*time.Minute), now.Add(90*time.Minute))
+	assert.Nil(err)
+	_, err = n.reserveCapacity("d", sender, 4, nil, now.Add(5*time.Hour), now.Add(6*time.Hour))
+	assert.Nil(err)
+
+	// Running sessions keep their slots
+	n.SegmentChans["running"] = make(SegmentChan)
+	n.SegmentChans["running2"] = make(SegmentChan)
+	n.SegmentChans["running3"] = make(SegmentChan)
+	_, err = n.reserveCapacity("e", sender, 2, nil, now, now.Add(10*time.Minute))
+	assert.Equal(ErrReservationCap, err)
+	_, err = n.reserveCapacity("e", sender, 1, nil, now, now.Add(10*time.Minute))
+	assert.Nil(err)
+
+	res := n.CapacityReservations()
+	require.Len(res, 5)
+	assert.Equal([]string{"e", "c", "a", "b", "d"}, []string{res[0].ID, res[1].ID, res[2].ID, res[3].ID, res[4].ID})
+}
+
+func TestReserveCapacity_Sessions(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	n, _ := NewLivepeerNode(nil, "", nil)
+	orch := NewOrchestrator(n, nil)
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 3
+
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	other := ethcommon.BytesToAddress([]byte("other"))
+	newMetadata := func(sessionID string) *SegTranscodingMetadata {
+		md := StubSegTranscodingMetadata()
+		md.AuthToken = stubAuthToken()
+		md.AuthToken.SessionId = sessionID
+		return md
+	}
+
+	_, err := n.reserveCapacity("r", sender, 2, nil, time.Now(), time.Now().Add(time.Hour))
+	require.Nil(err)
+	assert.True(orch.ReservationAvailable(sender))
+	assert.False(orch.ReservationAvailable(other))
+
+	// The unused reserved sessions are held for the broadcaster
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("other"))
+	require.Nil(err)
+	assert.Equal(ErrOrchCap, orch.CheckCapacity("other2", PriorityNormal))
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("other2"))
+	assert.Equal(ErrOrchCap, err)
+
+	// Sessions bound to the reservation are able to use it
+	orch.BindReservation(other, "other2")
+	assert.Empty(n.reservedSessions)
+	orch.BindReservation(sender, "reserved")
+	orch.BindReservation(sender, "reserved2")
+	orch.BindReservation(sender, "reserved3")
+	assert.Nil(orch.CheckCapacity("reserved", PriorityNormal))
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("reserved"))
+	require.Nil(err)
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("reserved2"))
+	require.Nil(err)
+	assert.Equal(2, n.CapacityReservations()[0].Used)
+	assert.False(orch.ReservationAvailable(sender))
+
+	// The reservation is full
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("reserved3"))
+	assert.Equal(ErrOrchCap, err)
+
+	// Ending a session frees its reserved slot
+	n.endTranscodingSession("reserved", context.TODO())
+	assert.NotContains(n.reservedSessions, ManifestID("reserved"))
+	assert.Equal(1, n.CapacityReservations()[0].Used)
+	assert.True(orch.ReservationAvailable(sender))
+	_, err = n.getSegmentChan(context.TODO(), newMetadata("reserved3"))
+	assert.Nil(err)
+
+	// Reserved sessions are not preempted
+	n.PriorityPrices = map[int32]*big.Rat{PriorityHigh: big.NewRat(2, 1)}
+	_, err = n.getSegmentChan(context.TODO(), func() *SegTranscodingMetadata {
+		md := newMetadata("high")
+		md.Priority = PriorityHigh
+		return md
+	}())
+	require.Nil(err)
+	assert.Contains(n.SegmentChans, ManifestID("reserved2"))
+	assert.Contains(n.SegmentChans, ManifestID("reserved3"))
+	assert.NotContains(n.SegmentChans, ManifestID("other"))
+}
+
+func TestReserveCapacity_Release(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	orch := NewOrchestrator(n, nil)
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	_, err := n.reserveCapacity("r", sender, 1, nil, time.Now(), time.Now().Add(50*time.Millisecond))
+	require.Nil(err)
+	orch.BindReservation(sender, "reserved")
+	assert.Equal(ErrOrchCap, orch.CheckCapacity("other", PriorityNormal))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Empty(n.CapacityReservations())
+	n.segmentMutex.RLock()
+	assert.Empty(n.reservedSessions)
+	n.segmentMutex.RUnlock()
+	assert.Nil(orch.CheckCapacity("other", PriorityNormal))
+	assert.False(orch.ReservationAvailable(sender))
+}
+
+func TestReserveCapacity_Payment(t *testing.T) {
+	assert := assert.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	n.Balances = NewAddressBalances(5 * time.Second)
+	recipient := new(pm.MockRecipient)
+	recipient.On("TxCostMultiplier", mock.Anything).Return(big.NewRat(1, 1), nil)
+	n.Recipient = recipient
+	n.SetBasePrice("default", NewFixedPrice(big.NewRat(1, 1)))
+	orch := NewOrchestrator(n, nil)
+	cap := MaxSessions
+	defer func() { MaxSessions = cap }()
+	MaxSessions = 1
+	fee := ReservationFeePercent
+	defer func() { ReservationFeePercent = fee }()
+	ReservationFeePercent = 10
+
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	profiles := StubSegTranscodingMetadata().Profiles
+	_, err := orch.ReserveCapacity(context.TODO(), sender, net.Payment{}, 1, profiles, time.Now(), time.Now().Add(time.Hour))
+	assert.Equal(ErrReservationPayment, err)
+	assert.Empty(n.CapacityReservations())
+
+	// Windows are checked before the payment
+	_, err = orch.ReserveCapacity(context.TODO(), sender, net.Payment{}, 1, profiles, time.Now(), time.Now().Add(MaxReservationWindow+time.Hour))
+	assert.Equal(errReservationWindow, err)
+
+	// Reservations are not free
+	n.SetBasePrice("default", NewFixedPrice(big.NewRat(0, 1)))
+	_, err = orch.ReserveCapacity(context.TODO(), sender, net.Payment{}, 1, profiles, time.Now(), time.Now().Add(time.Hour))
+	assert.Equal(errReservationFee, err)
+	n.SetBasePrice("default", NewFixedPrice(big.NewRat(1, 1)))
+	ReservationFeePercent = 0
+	_, err = orch.ReserveCapacity(context.TODO(), sender, net.Payment{}, 1, profiles, time.Now(), time.Now().Add(time.Hour))
+	assert.Equal(errReservationFee, err)
+	ReservationFeePercent = 10
+	n.Recipient = nil
+	_, err = orch.ReserveCapacity(context.TODO(), sender, net.Payment{}, 1, profiles, time.Now(), time.Now().Add(time.Hour))
+	assert.Equal(errReservationFee, err)
+	assert.Empty(n.CapacityReservations())
+}
+
+func TestReserveCapacity_PixelLimit(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	maxPixels := MaxReservedPixelsPerSender
+	defer func() { MaxReservedPixelsPerSender = maxPixels }()
+
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+	start := time.Now()
+	end := start.Add(time.Hour)
+	pixels := reservationPixels(profiles, 2, start, end)
+	assert.Equal(int64(256*144*30*3600*2), pixels.Int64())
+	MaxReservedPixelsPerSender = pixels.Int64() + 1
+
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	other := ethcommon.BytesToAddress([]byte("other"))
+	_, err := n.reserveCapacity("a", sender, 1, profiles, start, end)
+	require.Nil(err)
+	_, err = n.reserveCapacity("b", sender, 1, profiles, start, end)
+	require.Nil(err)
+	_, err = n.reserveCapacity("c", sender, 1, profiles, start, end)
+	assert.Equal(errReservationPixels, err)
+	// The limit applies per broadcaster
+	_, err = n.reserveCapacity("c", other, 1, profiles, start, end)
+	assert.Nil(err)
+
+	// Reservations that would overflow int64 are refused
+	huge := ffmpeg.VideoProfile{Resolution: "1000000000x1000000000", Framerate: 1 << 30}
+	_, err = n.reserveCapacity("d", other, 1<<20, []ffmpeg.VideoProfile{huge}, start, end)
+	assert.Equal(errReservationPixels, err)
+}
+
+func TestReserveCapacity_Bindings(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	n, _ := NewLivepeerNode(nil, "", nil)
+	orch := NewOrchestrator(n, nil)
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	r, err := n.reserveCapacity("r", sender, 2, nil, time.Now(), time.Now().Add(time.Hour))
+	require.Nil(err)
+
+	// Sessions are bound once
+	orch.BindReservation(sender, "a")
+	bound := r.sessions["a"]
+	orch.BindReservation(sender, "a")
+	assert.Equal(bound, r.sessions["a"])
+
+	// Sessions that did not start transcoding lose their binding after the timeout
+	r.sessions["a"] = time.Now().Add(-reservationBindTimeout - time.Second)
+	orch.BindReservation(sender, "b")
+	assert.NotContains(r.sessions, ManifestID("a"))
+	assert.NotContains(n.reservedSessions, ManifestID("a"))
+
+	// Transcoding sessions keep their binding
+	n.SegmentChans["b"] = make(SegmentChan)
+	r.sessions["b"] = time.Now().Add(-reservationBindTimeout - time.Second)
+	orch.BindReservation(sender, "c")
+	assert.Contains(r.sessions, ManifestID("b"))
+	assert.Contains(r.sessions, ManifestID("c"))
+
+	// Ending a session removes its binding
+	n.endTranscodingSession("b", context.TODO())
+	assert.NotContains(r.sessions, ManifestID("b"))
+	assert.NotContains(n.reservedSessions, ManifestID("b"))
+}
+
+func TestReserveCapacity_Restore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	n, _ := NewLivepeerNode(nil, "", dbh)
+	sender := ethcommon.BytesToAddress([]byte("sender"))
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+	start := time.Unix(time.Now().Unix(), 0)
+	_, err = n.reserveCapacity("a", sender, 2, profiles, start, start.Add(time.Hour))
+	require.Nil(err)
+	_, err = n.reserveCapacity("b", sender, 1, profiles, start, start.Add(2*time.Second))
+	require.Nil(err)
+
+	// Reservations survive restarts
+	restarted, _ := NewLivepeerNode(nil, "", dbh)
+	require.Nil(restarted.RestoreCapacityReservations())
+	res := restarted.CapacityReservations()
+	require.Len(res, 2)
+	assert.ElementsMatch(n.CapacityReservations(), res)
+	restarted.segmentMutex.RLock()
+	assert.Equal(n.reservations["a"].pixels, restarted.reservations["a"].pixels)
+	restarted.segmentMutex.RUnlock()
+
+	// Released reservations are removed
+	time.Sleep(time.Until(start.Add(2*time.Second)) + 100*time.Millisecond)
+	reservations, err := dbh.CapacityReservations(time.Now().Add(-time.Hour))
+	require.Nil(err)
+	require.Len(reservations, 1)
+	assert.Equal("a", reservations[0].ID)
+}
@@ -23,21 +23,25 @@ const (
 )
 
 type StreamParameters struct {
-	ManifestID        ManifestID
-	ExternalStreamID  string
-	SessionID         string
-	RtmpKey           string
-	Profiles          []ffmpeg.VideoProfile
-	Resolution        string
-	Format            ffmpeg.Format
-	OS                drivers.OSSession
-	RecordOS          drivers.OSSession
-	Capabilities      *Capabilities
-	VerificationFreq  uint
-	Nonce             uint64
-	Codec             ffmpeg.VideoCodec
-	PixelFormat       ffmpeg.PixelFormat
-	TimeoutMultiplier int // Used in the VOD workflow to allow us to be more lenient with timeouts
+	ManifestID             ManifestID
+	ExternalStreamID       string
+	SessionID              string
+	RtmpKey                string
+	Profiles               []ffmpeg.VideoProfile
+	Resolution             string
+	Format                 ffmpeg.Format
+	OS                     drivers.OSSession
+	RecordOS               drivers.OSSession
+	Capabilities           *Capabilities
+	VerificationFreq       uint    // Verify 1 in VerificationFreq segments when no sample rate is set
+	VerificationSampleRate float64 // Fraction of segments to verify, see verification.Policy
+	VerificationRedundancy int     // Number of orchestrators that transcode each segment in parallel
+	VerifySignatures       bool    // Whether orchestrators must compute MPEG-7 signatures for local verification
+	Nonce                  uint64
+	Codec                  ffmpeg.VideoCodec
+	PixelFormat            ffmpeg.PixelFormat
+	TimeoutMultiplier      int // Used in the VOD workflow to allow us to be more lenient with timeouts
+	Priority               int32
 }
 
 func (s *StreamParameters) StreamID() string {
@@ -66,6 +70,11 @@ type SegTranscodingMetadata struct {
 	AuthToken          *net.AuthToken
 	CalcPerceptualHash bool
 	SegmentParameters  *SegmentParameters
+	Priority           int32
+	// Whether the session may preempt sessions of a lower priority
+	Preempt bool
+	// Number of threads for software encoding or 0 for the encoder default
+	Threads int
 }
 
 func (md *SegTranscodingMetadata) Flatten() []byte {
@@ -100,6 +109,7 @@ func NetSegData(md *SegTranscodingMetadata) (*net.SegData, error) {
 		Capabilities:       md.Caps.ToNetCapabilities(),
 		AuthToken:          md.AuthToken,
 		CalcPerceptualHash: md.CalcPerceptualHash,
+		Priority:           md.Priority,
 		// Triggers failure on Os that don't know how to use FullProfiles/2/3
 		Profiles: []byte("invalid"),
 	}
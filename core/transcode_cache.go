@@ -0,0 +1,125 @@
+package core
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/livepeer/go-livepeer/monitor"
+)
+
+// TranscodeCache is a size bounded LRU cache of the transcoding results of source segments. It lets an orchestrator
+// return the results of a segment that it already transcoded, e.g. when a gateway retries a segment or several
+// gateways transcode the same source
+type TranscodeCache struct {
+	mu      sync.Mutex
+	maxSize int64
+	size    int64
+	entries map[string]*list.Element
+	lru     *list.List
+	// Percentage of the regular fee that is charged for cached results
+	feePercent int64
+}
+
+type transcodeCacheEntry struct {
+	key  string
+	data *TranscodeData
+	size int64
+}
+
+// NewTranscodeCache creates a cache that holds up to maxSize bytes of transcoded segments and charges feePercent of
+// the regular fee for the results it returns
+func NewTranscodeCache(maxSize int64, feePercent int64) *TranscodeCache {
+	return &TranscodeCache{
+		maxSize:    maxSize,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		feePercent: feePercent,
+	}
+}
+
+// transcodeCacheKey identifies the results of a segment by the hash of the source segment and the hash of the
+// parameters that change the output
+func transcodeCacheKey(md *SegTranscodingMetadata) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%+v|%v", md.Profiles, md.CalcPerceptualHash)
+	if md.SegmentParameters != nil && md.SegmentParameters.Clip != nil {
+		fmt.Fprintf(h, "|%v-%v", md.SegmentParameters.Clip.From, md.SegmentParameters.Clip.To)
+	}
+	return md.Hash.Hex() + hex.EncodeToString(h.Sum(nil))
+}
+
+func transcodeDataSize(data *TranscodeData) int64 {
+	var size int64
+	for _, seg := range data.Segments {
+		size += int64(len(seg.Data) + len(seg.PHash))
+	}
+	return size
+}
+
+// Get returns the cached results of a segment or nil if they are not cached
+func (c *TranscodeCache) Get(md *SegTranscodingMetadata) *TranscodeData {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[transcodeCacheKey(md)]
+	if !ok {
+		if monitor.Enabled {
+			monitor.TranscodeCacheMiss()
+		}
+		return nil
+	}
+	c.lru.MoveToFront(el)
+	if monitor.Enabled {
+		monitor.TranscodeCacheHit()
+	}
+	return el.Value.(*transcodeCacheEntry).data
+}
+
+// Add caches the results of a segment and evicts the least recently used results to stay within the maximum size.
+// Results that are larger than the maximum size are not cached
+func (c *TranscodeCache) Add(md *SegTranscodingMetadata, data *TranscodeData) {
+	if c == nil {
+		return
+	}
+	size := transcodeDataSize(data)
+	if size > c.maxSize {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := transcodeCacheKey(md)
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	c.entries[key] = c.lru.PushFront(&transcodeCacheEntry{key: key, data: data, size: size})
+	c.size += size
+	for c.size > c.maxSize {
+		c.remove(c.lru.Back())
+	}
+	if monitor.Enabled {
+		monitor.TranscodeCacheSize(c.size)
+	}
+}
+
+// Caller should hold the lock
+func (c *TranscodeCache) remove(el *list.Element) {
+	entry := c.lru.Remove(el).(*transcodeCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.size
+}
+
+// FeePixels returns the number of pixels to charge for cached results that contain the given number of pixels
+func (c *TranscodeCache) FeePixels(pixels int64) int64 {
+	if c == nil {
+		return pixels
+	}
+	return pixels * c.feePercent / 100
+}
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func cacheTestData(size int) *TranscodeData {
+	return &TranscodeData{Segments: []*TranscodedSegmentData{{Data: make([]byte, size)}}}
+}
+
+func TestTranscodeCache(t *testing.T) {
+	assert := assert.New(t)
+
+	c := NewTranscodeCache(100, 100)
+	p := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+	md1 := &SegTranscodingMetadata{Hash: ethcommon.BytesToHash([]byte("foo")), Profiles: p}
+	md2 := &SegTranscodingMetadata{Hash: ethcommon.BytesToHash([]byte("bar")), Profiles: p}
+	md3 := &SegTranscodingMetadata{Hash: ethcommon.BytesToHash([]byte("baz")), Profiles: p}
+
+	assert.Nil(c.Get(md1))
+	d1 := cacheTestData(40)
+	c.Add(md1, d1)
+	assert.Equal(d1, c.Get(md1))
+
+	// Results are cached by source segment and profiles
+	assert.Nil(c.Get(&SegTranscodingMetadata{Hash: md1.Hash, Profiles: []ffmpeg.VideoProfile{ffmpeg.P240p30fps16x9}}))
+	otherBitrate := ffmpeg.P144p30fps16x9
+	otherBitrate.Bitrate = "1k"
+	assert.Nil(c.Get(&SegTranscodingMetadata{Hash: md1.Hash, Profiles: []ffmpeg.VideoProfile{otherBitrate}}))
+	assert.Nil(c.Get(&SegTranscodingMetadata{Hash: md1.Hash, Profiles: p, CalcPerceptualHash: true}))
+	clip := &SegmentParameters{Clip: &SegmentClip{From: 1, To: 2}}
+	assert.Nil(c.Get(&SegTranscodingMetadata{Hash: md1.Hash, Profiles: p, SegmentParameters: clip}))
+	// The other fields of the segment do not change the results
+	assert.Equal(d1, c.Get(&SegTranscodingMetadata{Hash: md1.Hash, Profiles: p, Seq: 5, ManifestID: "foo"}))
+
+	// The least recently used results are evicted
+	d2 := cacheTestData(40)
+	c.Add(md2, d2)
+	assert.Equal(d1, c.Get(md1))
+	d3 := cacheTestData(40)
+	c.Add(md3, d3)
+	assert.Nil(c.Get(md2))
+	assert.Equal(d1, c.Get(md1))
+	assert.Equal(d3, c.Get(md3))
+	assert.Equal(int64(80), c.size)
+
+	// Replacing results updates the size
+	c.Add(md3, cacheTestData(10))
+	assert.Equal(int64(50), c.size)
+	assert.Len(c.entries, 2)
+
+	// Results larger than the cache are not cached
+	c.Add(md2, cacheTestData(101))
+	assert.Nil(c.Get(md2))
+	assert.Equal(int64(50), c.size)
+}
+
+func TestTranscodeCache_FeePixels(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(1000), NewTranscodeCache(100, 100).FeePixels(1000))
+	assert.Equal(int64(250), NewTranscodeCache(100, 25).FeePixels(1000))
+	assert.Equal(int64(0), NewTranscodeCache(100, 0).FeePixels(1000))
+
+	// Caching disabled
+	var c *TranscodeCache
+	assert.Equal(int64(1000), c.FeePixels(1000))
+	assert.Nil(c.Get(&SegTranscodingMetadata{}))
+	c.Add(&SegTranscodingMetadata{}, cacheTestData(1))
+}
+
+func TestTranscodeSeg_Cache(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	p := []ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9, ffmpeg.P144p30fps16x9}
+	tr := stubTranscoderWithProfiles(p)
+	storage := drivers.NewMemoryDriver(nil).NewSession("")
+	config := transcodeConfig{LocalOS: storage, OS: storage}
+
+	n, err := NewLivepeerNode(nil, t.TempDir(), nil)
+	require.Nil(err)
+	n.Transcoder = tr
+	n.TranscodeCache = NewTranscodeCache(1024*1024, 100)
+
+	md := &SegTranscodingMetadata{Profiles: p, AuthToken: stubAuthToken(), Hash: ethcommon.BytesToHash([]byte("foo"))}
+	res := n.transcodeSeg(context.TODO(), config, StubSegment(), md)
+	require.Nil(res.Err)
+	assert.False(res.Cached)
+	assert.Equal(1, tr.SegCount)
+
+	// The segment is not transcoded again
+	cached := n.transcodeSeg(context.TODO(), config, StubSegment(), md)
+	require.Nil(cached.Err)
+	assert.True(cached.Cached)
+	assert.Equal(res.TranscodeData, cached.TranscodeData)
+	assert.Equal(storage, cached.OS)
+	assert.Equal(1, tr.SegCount)
+
+	// Failed transcodes are not cached
+	tr.FailTranscode = true
+	md2 := &SegTranscodingMetadata{Profiles: p, AuthToken: stubAuthToken(), Hash: ethcommon.BytesToHash([]byte("bar"))}
+	res = n.transcodeSeg(context.TODO(), config, StubSegment(), md2)
+	assert.NotNil(res.Err)
+	assert.Nil(n.TranscodeCache.Get(md2))
+}
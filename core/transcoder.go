@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/glog"
@@ -19,6 +20,7 @@ import (
 	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/monitor"
+	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/lpms/ffmpeg"
 )
 
@@ -52,6 +54,7 @@ func (lt *LocalTranscoder) Transcode(ctx context.Context, md *SegTranscodingMeta
 	}
 	profiles := md.Profiles
 	opts := profilesToTranscodeOptions(lt.workDir, ffmpeg.Software, profiles, md.CalcPerceptualHash, md.SegmentParameters)
+	setEncoderThreads(opts, md.Threads)
 
 	_, seqNo, parseErr := parseURI(md.Fname)
 	start := time.Now()
@@ -345,6 +348,94 @@ func TestSoftwareTranscoderCapabilities(tmpdir string) (caps []Capability, fatal
 	return caps, fatalError
 }
 
+// defaultCostFramerate is the framerate assumed for profiles that keep the framerate of the source
+const defaultCostFramerate = 30
+
+// EstimateSessionCost estimates the number of output pixels per second that transcoding a stream into profiles requires
+func EstimateSessionCost(profiles []ffmpeg.VideoProfile) int64 {
+	var cost int64
+	for _, p := range profiles {
+		w, h, err := ffmpeg.VideoProfileResolution(p)
+		if err != nil {
+			continue
+		}
+		fps := float64(defaultCostFramerate)
+		if p.Framerate > 0 {
+			fps = float64(p.Framerate)
+			if p.FramerateDen > 0 {
+				fps /= float64(p.FramerateDen)
+			}
+		}
+		cost += int64(float64(w*h) * fps)
+	}
+	return cost
+}
+
+var benchmarkProfiles = []ffmpeg.VideoProfile{ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9}
+
+const benchmarkSegments = 3
+
+// MeasurePixelThroughput measures the number of output pixels per second that the transcoder can encode by transcoding
+// a sample segment in the provided number of parallel sessions
+func MeasurePixelThroughput(transcoder Transcoder, workDir string, sessions int) (int64, error) {
+	z, err := gzip.NewReader(bytes.NewReader(testSegment_H264))
+	if err != nil {
+		return 0, err
+	}
+	seg, err := ioutil.ReadAll(z)
+	z.Close()
+	if err != nil {
+		return 0, err
+	}
+	fname := filepath.Join(workDir, "benchmarkseg.tempfile")
+	if err := ioutil.WriteFile(fname, seg, 0644); err != nil {
+		return 0, err
+	}
+	defer os.Remove(fname)
+
+	if sessions < 1 {
+		sessions = 1
+	}
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		pixels int64
+		resErr error
+	)
+	start := time.Now()
+	for i := 0; i < sessions; i++ {
+		wg.Add(1)
+		go func(sessionID string) {
+			defer wg.Done()
+			defer transcoder.EndTranscodingSession(sessionID)
+			for j := 0; j < benchmarkSegments; j++ {
+				md := &SegTranscodingMetadata{Fname: fname, Profiles: benchmarkProfiles, AuthToken: &net.AuthToken{SessionId: sessionID}}
+				td, err := transcoder.Transcode(context.Background(), md)
+				mu.Lock()
+				if err != nil {
+					resErr = err
+					mu.Unlock()
+					return
+				}
+				for _, s := range td.Segments {
+					pixels += s.Pixels
+				}
+				mu.Unlock()
+			}
+		}("benchmark-" + strconv.Itoa(i))
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if resErr != nil {
+		return 0, resErr
+	}
+	if pixels == 0 {
+		return 0, errors.New("benchmark did not produce any pixels")
+	}
+	return int64(float64(pixels) / elapsed.Seconds()), nil
+}
+
 func GetTranscoderFactoryByAccel(acceleration ffmpeg.Acceleration) (func(device string) TranscoderSession, error) {
 	switch acceleration {
 	case ffmpeg.Nvidia:
@@ -450,6 +541,19 @@ func profilesToTranscodeOptions(workDir string, accel ffmpeg.Acceleration, profi
 	return opts
 }
 
+// setEncoderThreads limits the number of threads of the video encoders. A threads value of 0 keeps the encoder default
+func setEncoderThreads(opts []ffmpeg.TranscodeOptions, threads int) {
+	if threads <= 0 {
+		return
+	}
+	for i := range opts {
+		if opts[i].VideoEncoder.Opts == nil {
+			opts[i].VideoEncoder.Opts = map[string]string{}
+		}
+		opts[i].VideoEncoder.Opts["threads"] = strconv.Itoa(threads)
+	}
+}
+
 func recoverFromPanic(retErr *error) {
 	if r := recover(); r != nil {
 		err, ok := r.(error)
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+)
+
+// TranscoderLeaseTimeout is how long a pull mode remote transcoder has to return the results of a segment it leased
+// before the segment is queued again. It is capped at half of the time the orchestrator waits for the results of the
+// segment so that an expired segment can be leased again before the orchestrator stops waiting for it
+var TranscoderLeaseTimeout = common.HTTPTimeout / 2
+
+// queuedJob is a segment for a pull mode remote transcoder along with how long a lease of the segment lasts
+type queuedJob struct {
+	*net.NotifySegment
+	leaseTimeout time.Duration
+}
+
+// transcoderJobQueue holds the segments assigned to a pull mode remote transcoder until the transcoder leases them
+type transcoderJobQueue struct {
+	mu      sync.Mutex
+	pending []*queuedJob
+	// Lease expiration timers by task ID
+	leases map[int64]*time.Timer
+	// Closed and replaced when a segment is queued to wake up the waiting leases
+	queued chan struct{}
+	// Closed once the transcoder disconnects
+	closed       chan struct{}
+	leaseTimeout time.Duration
+}
+
+func newTranscoderJobQueue(leaseTimeout time.Duration) *transcoderJobQueue {
+	return &transcoderJobQueue{
+		leases:       make(map[int64]*time.Timer),
+		queued:       make(chan struct{}),
+		closed:       make(chan struct{}),
+		leaseTimeout: leaseTimeout,
+	}
+}
+
+// push queues a segment whose results are awaited for resultsTimeout
+func (q *transcoderJobQueue) push(job *net.NotifySegment, resultsTimeout time.Duration) {
+	leaseTimeout := q.leaseTimeout
+	if resultsTimeout/2 < leaseTimeout {
+		leaseTimeout = resultsTimeout / 2
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, &queuedJob{NotifySegment: job, leaseTimeout: leaseTimeout})
+	q.notify()
+}
+
+// Caller should hold the lock
+func (q *transcoderJobQueue) notify() {
+	close(q.queued)
+	q.queued = make(chan struct{})
+}
+
+// lease waits until a segment is queued and returns it or returns nil if ctx is done or the queue is closed first.
+// The segment is queued again if it is not removed before the lease expires
+func (q *transcoderJobQueue) lease(ctx context.Context) *net.NotifySegment {
+	for {
+		q.mu.Lock()
+		if len(q.pending) > 0 {
+			job := q.pending[0]
+			q.pending = q.pending[1:]
+			q.leases[job.TaskId] = time.AfterFunc(job.leaseTimeout, func() { q.expire(job) })
+			q.mu.Unlock()
+			return job.NotifySegment
+		}
+		queued := q.queued
+		q.mu.Unlock()
+
+		select {
+		case <-queued:
+		case <-q.closed:
+			return nil
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (q *transcoderJobQueue) expire(job *queuedJob) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if _, ok := q.leases[job.TaskId]; !ok {
+		// The segment was removed after the timer fired
+		return
+	}
+	delete(q.leases, job.TaskId)
+	glog.Warningf("Lease expired for taskId=%d url=%s, queueing it again", job.TaskId, job.Url)
+	q.pending = append([]*queuedJob{job}, q.pending...)
+	q.notify()
+}
+
+// close drops the queued segments and wakes up the leases and the transcode calls that wait for the segments so
+// that the segments are transcoded by another transcoder
+func (q *transcoderJobQueue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	select {
+	case <-q.closed:
+		return
+	default:
+	}
+	for taskID, timer := range q.leases {
+		timer.Stop()
+		delete(q.leases, taskID)
+	}
+	q.pending = nil
+	close(q.closed)
+}
+
+// remove drops a segment from the queue once its results are received or the orchestrator stops waiting for them
+func (q *transcoderJobQueue) remove(taskID int64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if timer, ok := q.leases[taskID]; ok {
+		timer.Stop()
+		delete(q.leases, taskID)
+	}
+	for i, job := range q.pending {
+		if job.TaskId == taskID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// LeaseTranscoderJob waits for a segment to be assigned to the pull mode remote transcoder with the given ID and
+// returns it or returns nil if ctx is done first
+func (rtm *RemoteTranscoderManager) LeaseTranscoderJob(ctx context.Context, id string) (*net.NotifySegment, error) {
+	rtm.RTmutex.Lock()
+	var jobs *transcoderJobQueue
+	for _, t := range rtm.liveTranscoders {
+		if t.id == id && t.jobs != nil {
+			jobs = t.jobs
+			break
+		}
+	}
+	rtm.RTmutex.Unlock()
+
+	if jobs == nil {
+		return nil, ErrTranscoderNotFound
+	}
+	return jobs.lease(ctx), nil
+}
@@ -0,0 +1,240 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscoderJobQueue(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q := newTranscoderJobQueue(time.Minute)
+
+	// Returns nil if no segment is queued before ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Nil(q.lease(ctx))
+
+	// Segments are leased in order
+	q.push(&net.NotifySegment{TaskId: 1}, time.Minute)
+	q.push(&net.NotifySegment{TaskId: 2}, time.Minute)
+	job := q.lease(context.Background())
+	require.NotNil(job)
+	assert.Equal(int64(1), job.TaskId)
+	assert.Len(q.leases, 1)
+
+	// Waiting leases are woken up when a segment is queued
+	leased := make(chan *net.NotifySegment, 2)
+	for i := 0; i < 2; i++ {
+		go func() { leased <- q.lease(context.Background()) }()
+	}
+	job = <-leased
+	require.NotNil(job)
+	assert.Equal(int64(2), job.TaskId)
+	q.push(&net.NotifySegment{TaskId: 3}, time.Minute)
+	select {
+	case job = <-leased:
+		assert.Equal(int64(3), job.TaskId)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the lease")
+	}
+
+	// Removed segments are not leased again
+	q.remove(1)
+	q.remove(2)
+	q.remove(3)
+	assert.Empty(q.leases)
+	q.push(&net.NotifySegment{TaskId: 4}, time.Minute)
+	q.remove(4)
+	assert.Empty(q.pending)
+}
+
+func TestTranscoderJobQueue_LeaseExpires(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The lease is capped at half of the time the results are awaited
+	q := newTranscoderJobQueue(time.Minute)
+	q.push(&net.NotifySegment{TaskId: 1}, 20*time.Millisecond)
+	q.push(&net.NotifySegment{TaskId: 2}, time.Minute)
+	job := q.lease(context.Background())
+	require.NotNil(job)
+	assert.Equal(int64(1), job.TaskId)
+
+	// The segment is queued again ahead of the other segments once its lease expires
+	time.Sleep(50 * time.Millisecond)
+	q.mu.Lock()
+	require.Len(q.pending, 2)
+	assert.Equal(int64(1), q.pending[0].TaskId)
+	assert.Empty(q.leases)
+	q.mu.Unlock()
+
+	job = q.lease(context.Background())
+	require.NotNil(job)
+	assert.Equal(int64(1), job.TaskId)
+	q.remove(1)
+	time.Sleep(50 * time.Millisecond)
+	q.mu.Lock()
+	assert.Len(q.pending, 1)
+	q.mu.Unlock()
+}
+
+func TestTranscoderJobQueue_Close(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q := newTranscoderJobQueue(time.Minute)
+	q.push(&net.NotifySegment{TaskId: 1}, time.Minute)
+	q.push(&net.NotifySegment{TaskId: 2}, time.Minute)
+	require.NotNil(q.lease(context.Background()))
+
+	// Waiting leases return once the queue is closed
+	leased := make(chan *net.NotifySegment, 2)
+	require.NotNil(q.lease(context.Background()))
+	go func() { leased <- q.lease(context.Background()) }()
+	q.close()
+	select {
+	case job := <-leased:
+		assert.Nil(job)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the lease")
+	}
+	assert.Empty(q.pending)
+	assert.Empty(q.leases)
+
+	// Closing again is a noop
+	q.close()
+	assert.Nil(q.lease(context.Background()))
+}
+
+func TestRemoteTranscoder_Pull(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { m.Manage(strm, "foo", true, 5, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+
+	_, err := m.LeaseTranscoderJob(context.Background(), "bar")
+	assert.Equal(ErrTranscoderNotFound, err)
+
+	type result struct {
+		res *TranscodeData
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := m.Transcode(context.Background(), &SegTranscodingMetadata{Fname: "foo.ts", AuthToken: stubAuthToken()})
+		done <- result{res, err}
+	}()
+
+	// The segment is leased instead of being sent on the stream
+	job, err := m.LeaseTranscoderJob(context.Background(), "foo")
+	require.Nil(err)
+	require.NotNil(job)
+	assert.Equal("foo.ts", job.Url)
+
+	m.transcoderResults(job.TaskId, &RemoteTranscoderResult{TranscodeData: &TranscodeData{Segments: []*TranscodedSegmentData{{Data: []byte("asdf")}}}})
+	select {
+	case r := <-done:
+		require.Nil(r.err)
+		assert.Equal("asdf", string(r.res.Segments[0].Data))
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the results")
+	}
+
+	// Results of the segment that arrive late are dropped
+	m.transcoderResults(job.TaskId, &RemoteTranscoderResult{})
+	tc := m.liveTranscoders[strm]
+	assert.Empty(tc.jobs.pending)
+	assert.Empty(tc.jobs.leases)
+}
+
+func TestRemoteTranscoder_PullTimeout(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldTimeout := common.HTTPTimeout
+	defer func() { common.HTTPTimeout = oldTimeout }()
+	common.HTTPTimeout = 50 * time.Millisecond
+
+	m := NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { m.Manage(strm, "foo", true, 5, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.Transcode(context.Background(), &SegTranscodingMetadata{Fname: "foo.ts", AuthToken: stubAuthToken()})
+		done <- err
+	}()
+
+	// The segment is queued again once the lease expires and Transcode keeps waiting
+	job, err := m.LeaseTranscoderJob(context.Background(), "foo")
+	require.Nil(err)
+	require.NotNil(job)
+	job2, err := m.LeaseTranscoderJob(context.Background(), "foo")
+	require.Nil(err)
+	require.NotNil(job2)
+	assert.Equal(job.TaskId, job2.TaskId)
+
+	// The transcoder is kept when the results time out
+	select {
+	case err := <-done:
+		assert.Equal(RemoteTranscoderFatalError{ErrRemoteTranscoderTimeout}, err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the segment to time out")
+	}
+	m.RTmutex.Lock()
+	assert.NotNil(m.liveTranscoders[strm])
+	m.RTmutex.Unlock()
+}
+
+func TestRemoteTranscoder_PullDisconnect(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	m := NewRemoteTranscoderManager()
+	m.reconnectGracePeriod = 0
+	strm := &common.StubServerStream{}
+	go func() { m.Manage(strm, "foo", true, 5, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	pull := m.liveTranscoders[strm]
+
+	type result struct {
+		res *TranscodeData
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		res, err := m.Transcode(context.Background(), &SegTranscodingMetadata{Fname: "foo.ts", AuthToken: stubAuthToken()})
+		done <- result{res, err}
+	}()
+	job, err := m.LeaseTranscoderJob(context.Background(), "foo")
+	require.Nil(err)
+	require.NotNil(job)
+
+	// The segment is retried on another transcoder once the pull mode transcoder disconnects
+	other := &StubTranscoderServer{manager: m}
+	go func() { m.Manage(other, "bar", false, 5, nil) }()
+	time.Sleep(1 * time.Millisecond) // allow the manager to activate
+	pull.done()
+	select {
+	case r := <-done:
+		require.Nil(r.err)
+		assert.Equal("asdf", string(r.res.Segments[0].Data))
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the results")
+	}
+	m.RTmutex.Lock()
+	assert.Nil(m.liveTranscoders[strm])
+	m.RTmutex.Unlock()
+}
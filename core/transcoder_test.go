@@ -5,7 +5,9 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/net"
@@ -221,6 +223,25 @@ func TestProfilesToTranscodeOptions(t *testing.T) {
 	}
 }
 
+func TestSetEncoderThreads(t *testing.T) {
+	assert := assert.New(t)
+
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P240p30fps16x9}
+	opts := profilesToTranscodeOptions("foo", ffmpeg.Software, profiles, false, nil)
+
+	// The encoder default is kept without a thread count
+	setEncoderThreads(opts, 0)
+	for _, o := range opts {
+		assert.Nil(o.VideoEncoder.Opts)
+	}
+
+	setEncoderThreads(opts, 3)
+	for _, o := range opts {
+		assert.Equal("", o.VideoEncoder.Name)
+		assert.Equal(map[string]string{"threads": "3"}, o.VideoEncoder.Opts)
+	}
+}
+
 func TestAudioCopy(t *testing.T) {
 	assert := assert.New(t)
 	dir := t.TempDir()
@@ -306,3 +327,112 @@ func TestRecoverFromPanic_WithError(t *testing.T) {
 
 	assert.Equal(NewUnrecoverableError(sampleErr), err)
 }
+
+func TestEstimateSessionCost(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(0), EstimateSessionCost(nil))
+	assert.Equal(int64(1280*720*30), EstimateSessionCost([]ffmpeg.VideoProfile{ffmpeg.P720p30fps16x9}))
+	assert.Equal(int64(1280*720*60+640*360*30), EstimateSessionCost([]ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9, ffmpeg.P360p30fps16x9}))
+
+	// Profiles that keep the source framerate are assumed to be 30fps
+	assert.Equal(int64(256*144*30), EstimateSessionCost([]ffmpeg.VideoProfile{{Resolution: "256x144"}}))
+	// Fractional framerates
+	assert.Equal(int64(256*144*30000/1001), EstimateSessionCost([]ffmpeg.VideoProfile{{Resolution: "256x144", Framerate: 30000, FramerateDen: 1001}}))
+	// Profiles without a valid resolution are not counted
+	assert.Equal(int64(0), EstimateSessionCost([]ffmpeg.VideoProfile{{Resolution: "foo"}}))
+}
+
+type pixelsTranscoder struct {
+	pixels   int64
+	err      error
+	sessions map[string]int
+	ended    []string
+	// Number of segments being transcoded and the maximum number of segments that were transcoded at once
+	inFlight    int
+	maxInFlight int
+	mu          sync.Mutex
+}
+
+func (t *pixelsTranscoder) Transcode(ctx context.Context, md *SegTranscodingMetadata) (*TranscodeData, error) {
+	t.mu.Lock()
+	t.sessions[md.AuthToken.SessionId]++
+	if t.err != nil {
+		t.mu.Unlock()
+		return nil, t.err
+	}
+	t.inFlight++
+	if t.inFlight > t.maxInFlight {
+		t.maxInFlight = t.inFlight
+	}
+	t.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	t.mu.Lock()
+	t.inFlight--
+	t.mu.Unlock()
+	segments := make([]*TranscodedSegmentData, len(md.Profiles))
+	for i := range md.Profiles {
+		segments[i] = &TranscodedSegmentData{Pixels: t.pixels}
+	}
+	return &TranscodeData{Segments: segments}, nil
+}
+
+func (t *pixelsTranscoder) EndTranscodingSession(sessionId string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ended = append(t.ended, sessionId)
+}
+
+func TestMeasurePixelThroughput(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	workDir := t.TempDir()
+
+	tr := &pixelsTranscoder{pixels: 1000, sessions: make(map[string]int)}
+	pixelCapacity, err := MeasurePixelThroughput(tr, workDir, 2)
+	require.Nil(err)
+	assert.Greater(pixelCapacity, int64(0))
+	// Every benchmark session transcodes the sample segment several times and is ended
+	assert.Len(tr.sessions, 2)
+	for _, count := range tr.sessions {
+		assert.Equal(benchmarkSegments, count)
+	}
+	assert.Len(tr.ended, 2)
+	// The sessions transcode in parallel
+	assert.Equal(2, tr.maxInFlight)
+	// The sample segment is removed
+	files, err := ioutil.ReadDir(workDir)
+	require.Nil(err)
+	assert.Empty(files)
+
+	tr = &pixelsTranscoder{sessions: make(map[string]int)}
+	_, err = MeasurePixelThroughput(tr, workDir, 1)
+	assert.EqualError(err, "benchmark did not produce any pixels")
+
+	tr = &pixelsTranscoder{err: ErrTranscode, sessions: make(map[string]int)}
+	_, err = MeasurePixelThroughput(tr, workDir, 1)
+	assert.Equal(ErrTranscode, err)
+}
+
+func TestMeasurePixelThroughput_Software(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping transcoder benchmark in short mode")
+	}
+	assert := assert.New(t)
+	require := require.New(t)
+	ffmpeg.InitFFmpeg()
+	workDir := t.TempDir()
+
+	single, err := MeasurePixelThroughput(NewLocalTranscoder(workDir), workDir, 1)
+	require.Nil(err)
+	assert.Greater(single, int64(0))
+
+	// Sessions run in parallel on the CPU pool, one per slot
+	ct := NewCPUTranscoder(NewLocalTranscoder(workDir), []int{0, 1}, 1, false)
+	require.Equal(2, ct.Slots())
+	parallel, err := MeasurePixelThroughput(ct, workDir, ct.Slots())
+	require.Nil(err)
+	assert.Greater(parallel, int64(0))
+}
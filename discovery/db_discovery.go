@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	ethcommon "github.com/ethereum/go-ethereum/common"
@@ -16,6 +17,7 @@ import (
 	lpTypes "github.com/livepeer/go-livepeer/eth/types"
 	"github.com/livepeer/go-livepeer/net"
 	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-livepeer/server"
 
 	"github.com/golang/glog"
 )
@@ -25,10 +27,20 @@ var getTicker = func() *time.Ticker {
 	return time.NewTicker(cacheRefreshInterval)
 }
 
+// OrchInfoCacheTTL is how long the capabilities and price advertised by an
+// orchestrator are cached to skip querying incompatible orchestrators during
+// discovery; 0 disables the cache
+var OrchInfoCacheTTL time.Duration
+
 type ticketParamsValidator interface {
 	ValidateTicketParams(ticketParams *pm.TicketParams) error
 }
 
+type orchInfoStore interface {
+	UpdateOrchInfo(info *common.DBOrchInfo) error
+	OrchInfos(expiresAfter time.Time) ([]*common.DBOrchInfo, error)
+}
+
 type DBOrchestratorPoolCache struct {
 	store                 common.OrchestratorStore
 	lpEth                 eth.LivepeerEthClient
@@ -36,6 +48,8 @@ type DBOrchestratorPoolCache struct {
 	rm                    common.RoundsManager
 	bcast                 common.Broadcaster
 	orchBlacklist         []string
+	infoStore             orchInfoStore
+	refreshing            int32
 }
 
 func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm common.RoundsManager, orchBlacklist []string) (*DBOrchestratorPoolCache, error) {
@@ -51,6 +65,9 @@ func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm
 		bcast:                 core.NewBroadcaster(node),
 		orchBlacklist:         orchBlacklist,
 	}
+	if OrchInfoCacheTTL > 0 {
+		dbo.infoStore = node.Database
+	}
 
 	if err := dbo.cacheTranscoderPool(); err != nil {
 		return nil, err
@@ -68,23 +85,97 @@ func NewDBOrchestratorPoolCache(ctx context.Context, node *core.LivepeerNode, rm
 }
 
 func (dbo *DBOrchestratorPoolCache) getURLs() ([]*url.URL, error) {
-	orchs, err := dbo.store.SelectOrchs(
+	orchs, err := dbo.selectActiveOrchs()
+	if err != nil || len(orchs) <= 0 {
+		return nil, err
+	}
+	return orchURLs(orchs), nil
+}
+
+func (dbo *DBOrchestratorPoolCache) selectActiveOrchs() ([]*common.DBOrch, error) {
+	return dbo.store.SelectOrchs(
 		&common.DBOrchFilter{
 			CurrentRound:   dbo.rm.LastInitializedRound(),
 			UpdatedLastDay: true,
+			ExcludeDenied:  true,
 		},
 	)
-	if err != nil || len(orchs) <= 0 {
-		return nil, err
-	}
+}
 
+func orchURLs(orchs []*common.DBOrch) []*url.URL {
 	var uris []*url.URL
 	for _, orch := range orchs {
 		if uri, err := url.Parse(orch.ServiceURI); err == nil {
 			uris = append(uris, uri)
 		}
 	}
-	return uris, nil
+	return uris
+}
+
+// getCandidateURLs returns the URLs of the orchestrators to query for a job.
+// With the info cache enabled, the orchestrators whose cached info does not
+// match the job's capabilities or max price are skipped.
+func (dbo *DBOrchestratorPoolCache) getCandidateURLs(ctx context.Context, caps common.CapabilityComparator) ([]*url.URL, error) {
+	orchs, err := dbo.selectActiveOrchs()
+	if err != nil || len(orchs) <= 0 {
+		return nil, err
+	}
+	if dbo.infoStore != nil {
+		orchs = dbo.filterByCachedInfo(ctx, orchs, caps)
+	}
+	return orchURLs(orchs), nil
+}
+
+func (dbo *DBOrchestratorPoolCache) filterByCachedInfo(ctx context.Context, orchs []*common.DBOrch, caps common.CapabilityComparator) []*common.DBOrch {
+	infos, err := dbo.infoStore.OrchInfos(time.Now())
+	if err != nil {
+		clog.Errorf(ctx, "Unable to read cached orchestrator infos err=%q", err)
+		return orchs
+	}
+	cached := make(map[ethcommon.Address]*common.DBOrchInfo, len(infos))
+	for _, info := range infos {
+		cached[info.Orchestrator] = info
+	}
+
+	legacyCapsOnly := caps.LegacyOnly()
+	maxPrice := server.BroadcastCfg.MaxPrice()
+	var compatible, candidates, uncached []*common.DBOrch
+	for _, orch := range orchs {
+		info, ok := cached[ethcommon.HexToAddress(orch.EthereumAddr)]
+		if !ok {
+			// Orchestrators without fresh info are queried and refreshed
+			uncached = append(uncached, orch)
+			compatible = append(compatible, orch)
+			candidates = append(candidates, orch)
+			continue
+		}
+		if !capabilitiesCompatible(caps, legacyCapsOnly, info.Capabilities) {
+			continue
+		}
+		compatible = append(compatible, orch)
+		if maxPrice != nil {
+			if price, err := common.RatPriceInfo(info.PriceInfo); err == nil && price != nil && price.Cmp(maxPrice) > 0 {
+				continue
+			}
+		}
+		candidates = append(candidates, orch)
+	}
+
+	if len(uncached) > 0 && atomic.CompareAndSwapInt32(&dbo.refreshing, 0, 1) {
+		go func() {
+			defer atomic.StoreInt32(&dbo.refreshing, 0)
+			dbo.cacheOrchInfos(uncached)
+		}()
+	}
+
+	clog.V(common.DEBUG).Infof(ctx, "Filtered orchestrators by cached info orchs=%d compatible=%d candidates=%d uncached=%d",
+		len(orchs), len(compatible), len(candidates), len(uncached))
+	if len(candidates) == 0 {
+		// Like the max price filter of the selection, use all compatible
+		// orchestrators if none is below the max price
+		return compatible
+	}
+	return candidates
 }
 
 func (dbo *DBOrchestratorPoolCache) GetInfos() []common.OrchestratorLocalInfo {
@@ -99,7 +190,7 @@ func (dbo *DBOrchestratorPoolCache) GetInfos() []common.OrchestratorLocalInfo {
 func (dbo *DBOrchestratorPoolCache) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
 	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
 
-	uris, err := dbo.getURLs()
+	uris, err := dbo.getCandidateURLs(ctx, caps)
 	if err != nil || len(uris) <= 0 {
 		return nil, err
 	}
@@ -149,6 +240,7 @@ func (dbo *DBOrchestratorPoolCache) Size() int {
 		&common.DBOrchFilter{
 			CurrentRound:   dbo.rm.LastInitializedRound(),
 			UpdatedLastDay: true,
+			ExcludeDenied:  true,
 		},
 	)
 	return count
@@ -261,7 +353,13 @@ func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 	if err != nil {
 		return fmt.Errorf("could not retrieve orchestrators from DB: %v", err)
 	}
+	dbo.cacheOrchInfos(orchs)
+	return nil
+}
 
+// cacheOrchInfos fetches the info of the orchestrators to update their price in
+// the DB along with the info cache, if enabled
+func (dbo *DBOrchestratorPoolCache) cacheOrchInfos(orchs []*common.DBOrch) {
 	resc, errc := make(chan *common.DBOrch, len(orchs)), make(chan error, len(orchs))
 	ctx, cancel := context.WithTimeout(context.Background(), getOrchestratorsTimeoutLoop)
 	defer cancel()
@@ -303,6 +401,17 @@ func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 			errc <- err
 			return
 		}
+		if dbo.infoStore != nil {
+			err := dbo.infoStore.UpdateOrchInfo(&common.DBOrchInfo{
+				Orchestrator: ethcommon.HexToAddress(dbOrch.EthereumAddr),
+				Capabilities: info.Capabilities,
+				PriceInfo:    info.PriceInfo,
+				ExpiresAt:    time.Now().Add(OrchInfoCacheTTL),
+			})
+			if err != nil {
+				glog.Errorf("Unable to cache orchestrator info orch=%v err=%q", info.GetTranscoder(), err)
+			}
+		}
 		resc <- dbOrch
 	}
 
@@ -325,11 +434,9 @@ func (dbo *DBOrchestratorPoolCache) cacheDBOrchs() error {
 			glog.Errorln(err)
 		case <-ctx.Done():
 			glog.Info("Done fetching orch info for orchestrators, context timeout")
-			return nil
+			return
 		}
 	}
-
-	return nil
 }
 
 func parseURI(addr string) (*url.URL, error) {
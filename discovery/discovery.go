@@ -94,16 +94,7 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 		if o.pred != nil && !o.pred(info) {
 			return false
 		}
-		// Legacy features already have support on the orchestrator.
-		// Capabilities can be omitted in this case for older orchestrators.
-		// Otherwise, capabilities are required to be present.
-		if info.Capabilities == nil {
-			if legacyCapsOnly {
-				return true
-			}
-			return false
-		}
-		return caps.CompatibleWith(info.Capabilities)
+		return capabilitiesCompatible(caps, legacyCapsOnly, info.Capabilities)
 	}
 	getOrchInfo := func(ctx context.Context, od common.OrchestratorDescriptor, infoCh chan common.OrchestratorDescriptor, errCh chan error) {
 		info, err := serverGetOrchInfo(ctx, o.bcast, od.LocalInfo.URL)
@@ -121,7 +112,8 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 		errCh <- err
 	}
 
-	var ods common.OrchestratorDescriptors
+	latency := Latency
+	var ods, farInfos common.OrchestratorDescriptors
 	suspendedInfos := newSuspensionQueue()
 	timedOut := false
 	nbResp := 0
@@ -142,8 +134,13 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 	for nbResp < numAvailableOrchs && len(ods) < numOrchestrators && !timedOut {
 		select {
 		case od := <-odCh:
+			latency.observe(od)
 			if penalty := suspender.Suspended(od.RemoteInfo.Transcoder); penalty == 0 {
-				ods = append(ods, od)
+				if latency.isFar(od.LocalInfo.URL) {
+					farInfos = append(farInfos, od)
+				} else {
+					ods = append(ods, od)
+				}
 			} else {
 				heap.Push(suspendedInfos, &suspension{od.RemoteInfo, &od, penalty})
 			}
@@ -168,6 +165,14 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 	}
 	cancel()
 
+	// prefer the orchestrators closest to the gateway, and consider the ones
+	// that are far if we have an insufficient number of nearby ones
+	latency.sortByRTT(ods)
+	latency.sortByRTT(farInfos)
+	for i := 0; i < len(farInfos) && len(ods) < numOrchestrators; i++ {
+		ods = append(ods, farInfos[i])
+	}
+
 	// consider suspended orchestrators if we have an insufficient number of non-suspended ones
 	if len(ods) < numOrchestrators {
 		diff := numOrchestrators - len(ods)
@@ -182,6 +187,21 @@ func (o *orchestratorPool) GetOrchestrators(ctx context.Context, numOrchestrator
 	return ods, nil
 }
 
+// capabilitiesCompatible returns whether the capabilities advertised by an
+// orchestrator are compatible with the job's capabilities
+func capabilitiesCompatible(caps common.CapabilityComparator, legacyCapsOnly bool, orchCaps *net.Capabilities) bool {
+	// Legacy features already have support on the orchestrator.
+	// Capabilities can be omitted in this case for older orchestrators.
+	// Otherwise, capabilities are required to be present.
+	if orchCaps == nil {
+		if legacyCapsOnly {
+			return true
+		}
+		return false
+	}
+	return caps.CompatibleWith(orchCaps)
+}
+
 func (o *orchestratorPool) Size() int {
 	return len(o.infos)
 }
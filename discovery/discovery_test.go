@@ -11,6 +11,7 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -1629,3 +1630,108 @@ func TestOrchestratorPool_Capabilities(t *testing.T) {
 	assert.Len(infos, 1)
 	assert.Equal(i4, infos[0].RemoteInfo)
 }
+
+func TestCachedPool_GetOrchestrators_OrchInfoCache(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldTTL := OrchInfoCacheTTL
+	defer func() { OrchInfoCacheTTL = oldTTL }()
+	OrchInfoCacheTTL = time.Hour
+	defer server.BroadcastCfg.SetMaxPrice(nil)
+
+	compatible := &net.Capabilities{Bitstring: capCompatString}
+	incompatible := &net.Capabilities{Bitstring: []uint64{1}}
+	addresses := []string{"https://127.0.0.1:8936", "https://127.0.0.1:8937", "https://127.0.0.1:8938"}
+	responses := map[string]*net.OrchestratorInfo{
+		addresses[0]: {Transcoder: addresses[0], Capabilities: compatible, PriceInfo: &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1}},
+		addresses[1]: {Transcoder: addresses[1], Capabilities: incompatible, PriceInfo: &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1}},
+		addresses[2]: {Transcoder: addresses[2], Capabilities: compatible, PriceInfo: &net.PriceInfo{PricePerUnit: 5, PixelsPerUnit: 1}},
+	}
+	var mu sync.Mutex
+	calls := map[string]int{}
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(ctx context.Context, bcast common.Broadcaster, orchestratorServer *url.URL) (*net.OrchestratorInfo, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[orchestratorServer.String()]++
+		info := *responses[orchestratorServer.String()]
+		info.Address = pm.RandBytes(20)
+		return &info, nil
+	}
+	queried := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var res []string
+		for addr, n := range calls {
+			if n > 0 {
+				res = append(res, addr)
+			}
+		}
+		calls = map[string]int{}
+		return res
+	}
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	sender := &pm.MockSender{}
+	sender.On("ValidateTicketParams", mock.Anything).Return(nil)
+	node := &core.LivepeerNode{
+		Database: dbh,
+		Eth:      &eth.StubClient{Orchestrators: StubOrchestrators(addresses)},
+		Sender:   sender,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool, err := NewDBOrchestratorPoolCache(ctx, node, &stubRoundsManager{}, []string{})
+	require.NoError(err)
+
+	// The infos of all orchestrators are cached on creation
+	infos, err := dbh.OrchInfos(time.Now())
+	require.Nil(err)
+	assert.Len(infos, 3)
+	assert.ElementsMatch(addresses, queried())
+
+	// Orchestrators with incompatible capabilities are not queried
+	caps := newStubCapabilities()
+	caps.isLegacy = false
+	ods, err := pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	assert.Len(ods, 2)
+	assert.ElementsMatch([]string{addresses[0], addresses[2]}, queried())
+
+	// Nor the ones above the max price
+	server.BroadcastCfg.SetMaxPrice(core.NewFixedPrice(big.NewRat(2, 1)))
+	ods, err = pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	require.Len(ods, 1)
+	assert.Equal(addresses[0], ods[0].RemoteInfo.Transcoder)
+	assert.Equal([]string{addresses[0]}, queried())
+
+	// Unless no compatible orchestrator is below the max price
+	server.BroadcastCfg.SetMaxPrice(core.NewFixedPrice(big.NewRat(1, 2)))
+	_, err = pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	assert.ElementsMatch([]string{addresses[0], addresses[2]}, queried())
+
+	// Orchestrators without fresh info are queried and refreshed in the background
+	server.BroadcastCfg.SetMaxPrice(nil)
+	require.Nil(dbh.UpdateOrchInfo(&common.DBOrchInfo{
+		Orchestrator: ethcommon.BytesToAddress([]byte(addresses[1])),
+		ExpiresAt:    time.Now().Add(-time.Second),
+	}))
+	_, err = pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	assert.Eventually(func() bool {
+		infos, err := dbh.OrchInfos(time.Now())
+		return err == nil && len(infos) == 3
+	}, time.Second, 10*time.Millisecond)
+	assert.Eventually(func() bool { return atomic.LoadInt32(&pool.refreshing) == 0 }, time.Second, 10*time.Millisecond)
+	queried()
+	_, err = pool.GetOrchestrators(context.TODO(), 3, newStubSuspender(), caps, common.ScoreAtLeast(0))
+	require.Nil(err)
+	assert.ElementsMatch([]string{addresses[0], addresses[2]}, queried())
+}
@@ -0,0 +1,208 @@
+package discovery
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+// Success rate below which an orchestrator is treated as suspended
+var gossipMinSuccessRate = 0.5
+
+// Weighted number of segments needed before the success rate is considered
+var gossipMinAttempts = 10.0
+
+// GossipSource returns the latest views of the gateway's peers
+type GossipSource interface {
+	Views() []*net.GossipView
+}
+
+type gossipPool struct {
+	local  common.OrchestratorPool
+	source GossipSource
+	// Weight of a peer's view relative to the gateway's own observations
+	weight        float64
+	bcast         common.Broadcaster
+	orchBlacklist []string
+}
+
+// NewGossipPool merges the orchestrators and observations gossiped by peers
+// into the local pool. Each peer counts for weight; an orchestrator only known
+// to peers is used as an untrusted orchestrator once peers with a total weight
+// of at least 1 report it.
+func NewGossipPool(bcast common.Broadcaster, local common.OrchestratorPool, source GossipSource, weight float64,
+	orchBlacklist []string) *gossipPool {
+
+	return &gossipPool{local: local, source: source, weight: weight, bcast: bcast, orchBlacklist: orchBlacklist}
+}
+
+// gossipedInfos returns the orchestrators reported by enough peers that are
+// not part of the local pool
+func (g *gossipPool) gossipedInfos() []common.OrchestratorLocalInfo {
+	known := make(map[string]bool)
+	for _, info := range g.local.GetInfos() {
+		known[info.URL.String()] = true
+	}
+	trust := make(map[string]float64)
+	var order []string
+	for _, view := range g.source.Views() {
+		for _, orch := range view.Orchestrators {
+			if known[orch] {
+				continue
+			}
+			if _, ok := trust[orch]; !ok {
+				order = append(order, orch)
+			}
+			trust[orch] += g.weight
+		}
+	}
+	var infos []common.OrchestratorLocalInfo
+	for _, orch := range order {
+		if trust[orch] < 1 {
+			continue
+		}
+		uri, err := url.ParseRequestURI(orch)
+		if err != nil {
+			glog.Errorf("Unable to parse gossiped orchestrator url=%q err=%q", orch, err)
+			continue
+		}
+		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: common.Score_Untrusted})
+	}
+	return infos
+}
+
+func (g *gossipPool) GetInfos() []common.OrchestratorLocalInfo {
+	return append(g.local.GetInfos(), g.gossipedInfos()...)
+}
+
+func (g *gossipPool) Size() int {
+	return len(g.GetInfos())
+}
+
+func (g *gossipPool) SizeWith(scorePred common.ScorePred) int {
+	size := g.local.SizeWith(scorePred)
+	if scorePred(common.Score_Untrusted) {
+		size += len(g.gossipedInfos())
+	}
+	return size
+}
+
+func (g *gossipPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
+	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
+
+	suspender = newGossipSuspender(suspender, g.source.Views(), g.weight)
+	gossiped := g.gossipedInfos()
+	if len(gossiped) == 0 {
+		return g.local.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+	}
+
+	type result struct {
+		ods common.OrchestratorDescriptors
+		err error
+	}
+	remoteCh := make(chan result, 1)
+	go func() {
+		pool := &orchestratorPool{infos: gossiped, bcast: g.bcast, orchBlacklist: g.orchBlacklist}
+		ods, err := pool.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+		remoteCh <- result{ods, err}
+	}()
+	local, err := g.local.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+	remote := <-remoteCh
+	if err != nil {
+		if remote.err != nil {
+			return nil, err
+		}
+		local = nil
+	}
+
+	// Prefer the orchestrators of the local pool, and the non-suspended
+	// orchestrators over the suspended ones
+	var ods, suspended common.OrchestratorDescriptors
+	for _, od := range append(local, remote.ods...) {
+		if suspender.Suspended(od.RemoteInfo.Transcoder) > 0 {
+			suspended = append(suspended, od)
+		} else {
+			ods = append(ods, od)
+		}
+	}
+	ods = append(ods, suspended...)
+	if len(ods) > numOrchestrators {
+		ods = ods[:numOrchestrators]
+	}
+	return ods, nil
+}
+
+// gossipSuspender extends the gateway's suspensions with the suspensions and
+// success rates reported by peers. The segments reported in a view count less
+// the older the view is.
+type gossipSuspender struct {
+	suspender common.Suspender
+	suspended map[string]bool
+	attempts  map[string]float64
+	successes map[string]float64
+}
+
+func newGossipSuspender(suspender common.Suspender, views []*net.GossipView, weight float64) *gossipSuspender {
+	s := &gossipSuspender{
+		suspender: suspender,
+		suspended: make(map[string]bool),
+		attempts:  make(map[string]float64),
+		successes: make(map[string]float64),
+	}
+	s.add(server.OrchStats.Stats(), 1)
+	suspensions := make(map[string]float64)
+	now := time.Now()
+	for _, view := range views {
+		s.add(view.Stats, weight*viewDecay(view, now))
+		for _, st := range view.Stats {
+			if st.SuspendedUntil > now.Unix() {
+				suspensions[st.Transcoder] += weight
+			}
+		}
+	}
+	for transcoder, w := range suspensions {
+		if w >= 1 {
+			s.suspended[transcoder] = true
+		}
+	}
+	return s
+}
+
+// viewDecay returns the factor applied to the segments of a view, halved for
+// every half-life elapsed since the view was created
+func viewDecay(view *net.GossipView, now time.Time) float64 {
+	if server.GossipStatsHalfLife <= 0 {
+		return 1
+	}
+	age := now.Sub(time.UnixMilli(view.Timestamp))
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(server.GossipStatsHalfLife))
+}
+
+func (s *gossipSuspender) add(stats []*net.GossipStats, weight float64) {
+	for _, st := range stats {
+		s.attempts[st.Transcoder] += weight * float64(st.Attempts)
+		s.successes[st.Transcoder] += weight * float64(st.Successes)
+	}
+}
+
+func (s *gossipSuspender) Suspended(orch string) int {
+	if penalty := s.suspender.Suspended(orch); penalty > 0 {
+		return penalty
+	}
+	if s.suspended[orch] {
+		return 1
+	}
+	if attempts := s.attempts[orch]; attempts >= gossipMinAttempts && s.successes[orch]/attempts < gossipMinSuccessRate {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,124 @@
+package discovery
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubGossipSource struct {
+	views []*net.GossipView
+}
+
+func (s *stubGossipSource) Views() []*net.GossipView {
+	return s.views
+}
+
+func TestGossipPool_GossipedInfos(t *testing.T) {
+	assert := assert.New(t)
+
+	local := NewOrchestratorPool(nil, mustParseURLs(t, "https://127.0.0.1:8935"), common.Score_Trusted, nil)
+	source := &stubGossipSource{views: []*net.GossipView{
+		{Orchestrators: []string{"https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937"}},
+		{Orchestrators: []string{"https://127.0.0.1:8936"}},
+	}}
+
+	// Orchestrators need to be reported by peers with a total weight of 1
+	// and are untrusted
+	pool := NewGossipPool(nil, local, source, 0.5, nil)
+	infos := pool.GetInfos()
+	require.Len(t, infos, 2)
+	assert.Equal("https://127.0.0.1:8935", infos[0].URL.String())
+	assert.Equal("https://127.0.0.1:8936", infos[1].URL.String())
+	assert.Equal(float32(common.Score_Untrusted), infos[1].Score)
+	assert.Equal(2, pool.Size())
+	assert.Equal(1, pool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
+	assert.Equal(1, pool.SizeWith(common.ScoreEqualTo(common.Score_Untrusted)))
+
+	pool = NewGossipPool(nil, local, source, 1, nil)
+	assert.Equal(3, pool.Size())
+}
+
+func TestGossipPool_GetOrchestrators(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(c context.Context, b common.Broadcaster, s *url.URL) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{Transcoder: s.String()}, nil
+	}
+
+	local := NewOrchestratorPool(nil, mustParseURLs(t, "https://127.0.0.1:8935", "https://127.0.0.1:8936"), common.Score_Trusted, nil)
+	source := &stubGossipSource{views: []*net.GossipView{
+		{
+			Orchestrators: []string{"https://127.0.0.1:8937"},
+			Stats: []*net.GossipStats{
+				{Transcoder: "https://127.0.0.1:8936", SuspendedUntil: time.Now().Add(time.Minute).Unix()},
+			},
+		},
+	}}
+	pool := NewGossipPool(nil, local, source, 1, nil)
+
+	transcoders := func(numOrchs int) []string {
+		ods, err := pool.GetOrchestrators(context.Background(), numOrchs, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+		require.Nil(err)
+		var res []string
+		for _, od := range ods {
+			res = append(res, od.RemoteInfo.Transcoder)
+		}
+		return res
+	}
+
+	// Local orchestrators come first and the ones suspended by peers last
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8937", "https://127.0.0.1:8936"}, transcoders(3))
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8937"}, transcoders(2))
+}
+
+func TestGossipSuspender(t *testing.T) {
+	assert := assert.New(t)
+
+	now := time.Now()
+	until := now.Add(time.Minute).Unix()
+	views := []*net.GossipView{
+		{Timestamp: now.UnixMilli(), Stats: []*net.GossipStats{
+			{Transcoder: "a", SuspendedUntil: until},
+			{Transcoder: "b", SuspendedUntil: until},
+			{Transcoder: "c", Attempts: 40, Successes: 4},
+			{Transcoder: "d", Attempts: 10, Successes: 2},
+			{Transcoder: "e", SuspendedUntil: time.Now().Add(-time.Minute).Unix()},
+		}},
+		{Timestamp: now.UnixMilli(), Stats: []*net.GossipStats{
+			{Transcoder: "a", SuspendedUntil: until},
+		}},
+		// Segments of older views count less
+		{Timestamp: now.Add(-2 * server.GossipStatsHalfLife).UnixMilli(), Stats: []*net.GossipStats{
+			{Transcoder: "g", Attempts: 40, Successes: 0},
+			{Transcoder: "h", Attempts: 160, Successes: 0},
+		}},
+	}
+	local := newStubSuspender()
+	local.list["f"] = 3
+	s := newGossipSuspender(local, views, 0.5)
+
+	// Suspended by peers with a total weight of 1
+	assert.Equal(1, s.Suspended("a"))
+	assert.Equal(0, s.Suspended("b"))
+	// Low success rate over enough weighted attempts
+	assert.Equal(1, s.Suspended("c"))
+	assert.Equal(0, s.Suspended("d"))
+	// Expired suspensions are ignored
+	assert.Equal(0, s.Suspended("e"))
+	// Local suspensions take precedence
+	assert.Equal(3, s.Suspended("f"))
+	// Decayed segments of an old view
+	assert.Equal(0, s.Suspended("g"))
+	assert.Equal(1, s.Suspended("h"))
+}
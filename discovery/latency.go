@@ -0,0 +1,297 @@
+package discovery
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	gonet "net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+var serverPingOrch = server.PingOrchestrator
+
+// Latency keeps the gateway's round trip time to orchestrators. When set,
+// discovery prefers the orchestrators closest to the gateway.
+var Latency *LatencyMonitor
+
+// Weight of a new sample in the smoothed round trip time
+const rttSmoothing = 0.3
+
+// Round trip time at which the latency score of an orchestrator drops to 0
+var maxScoredRTT = time.Second
+
+// LatencyMonitor measures the gateway's round trip time to orchestrators with
+// the Ping RPC and locates orchestrators with the region tags of the registry
+// or a GeoIP table. Both are combined into a local performance score.
+type LatencyMonitor struct {
+	// Orchestrators with a higher round trip time are only used when there
+	// aren't enough nearby orchestrators; 0 disables the cutoff
+	MaxRTT time.Duration
+
+	region    string
+	geo       GeoIPTable
+	perfScore *common.PerfScore
+
+	mu         sync.RWMutex
+	rtts       map[string]time.Duration
+	tags       map[string][]string
+	geoRegions map[string]string
+	addrs      map[string]ethcommon.Address
+}
+
+// NewLatencyMonitor creates a monitor for a gateway deployed in region. The
+// local scores of orchestrators are written to perfScore, if set.
+func NewLatencyMonitor(region string, geo GeoIPTable, perfScore *common.PerfScore, maxRTT time.Duration) *LatencyMonitor {
+	return &LatencyMonitor{
+		MaxRTT:     maxRTT,
+		region:     strings.ToUpper(region),
+		geo:        geo,
+		perfScore:  perfScore,
+		rtts:       make(map[string]time.Duration),
+		tags:       make(map[string][]string),
+		geoRegions: make(map[string]string),
+		addrs:      make(map[string]ethcommon.Address),
+	}
+}
+
+// Start pings the orchestrators of the pool every interval until ctx is done
+func (m *LatencyMonitor) Start(ctx context.Context, pool common.OrchestratorPool, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			m.probe(ctx, pool.GetInfos())
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (m *LatencyMonitor) probe(ctx context.Context, infos []common.OrchestratorLocalInfo) {
+	var wg sync.WaitGroup
+	for _, info := range infos {
+		wg.Add(1)
+		go func(uri *url.URL) {
+			defer wg.Done()
+			rtt, err := serverPingOrch(ctx, uri)
+			if err != nil {
+				glog.V(common.DEBUG).Infof("Unable to ping orchestrator orch=%v err=%q", uri, err)
+			}
+			m.record(uri, rtt, err)
+			if len(m.geo) > 0 {
+				m.locate(ctx, uri)
+			}
+		}(info.URL)
+	}
+	wg.Wait()
+	m.updatePerfScore()
+}
+
+// record updates the smoothed round trip time to an orchestrator. The round
+// trip time of unreachable orchestrators is forgotten.
+func (m *LatencyMonitor) record(uri *url.URL, rtt time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := uri.String()
+	if err != nil {
+		delete(m.rtts, key)
+		return
+	}
+	if prev, ok := m.rtts[key]; ok {
+		rtt = time.Duration(rttSmoothing*float64(rtt) + (1-rttSmoothing)*float64(prev))
+	}
+	m.rtts[key] = rtt
+}
+
+func (m *LatencyMonitor) locate(ctx context.Context, uri *url.URL) {
+	ips, err := gonet.DefaultResolver.LookupIP(ctx, "ip", uri.Hostname())
+	if err != nil || len(ips) == 0 {
+		return
+	}
+	if region := m.geo.Region(ips[0]); region != "" {
+		m.mu.Lock()
+		m.geoRegions[uri.String()] = region
+		m.mu.Unlock()
+	}
+}
+
+// RTT returns the smoothed round trip time to an orchestrator, if known
+func (m *LatencyMonitor) RTT(uri *url.URL) (time.Duration, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rtt, ok := m.rtts[uri.String()]
+	return rtt, ok
+}
+
+// SetRegionTags replaces the region tags of orchestrators, keyed by URL.
+// Tags take precedence over the GeoIP table.
+func (m *LatencyMonitor) SetRegionTags(tags map[string][]string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.tags = tags
+	m.mu.Unlock()
+	m.updatePerfScore()
+}
+
+// observe learns the ETH address of an orchestrator from its discovery response
+func (m *LatencyMonitor) observe(od common.OrchestratorDescriptor) {
+	if m == nil || od.RemoteInfo == nil || len(od.RemoteInfo.Address) == 0 {
+		return
+	}
+	key := od.LocalInfo.URL.String()
+	addr := ethcommon.BytesToAddress(od.RemoteInfo.Address)
+	m.mu.Lock()
+	known := m.addrs[key] == addr
+	m.addrs[key] = addr
+	m.mu.Unlock()
+	if !known {
+		m.updatePerfScore()
+	}
+}
+
+// isFar returns whether the orchestrator is known to be further than MaxRTT
+func (m *LatencyMonitor) isFar(uri *url.URL) bool {
+	if m == nil || m.MaxRTT <= 0 {
+		return false
+	}
+	rtt, ok := m.RTT(uri)
+	return ok && rtt > m.MaxRTT
+}
+
+// sortByRTT orders orchestrators from the closest to the furthest, followed by
+// the orchestrators whose round trip time is unknown
+func (m *LatencyMonitor) sortByRTT(ods common.OrchestratorDescriptors) {
+	if m == nil {
+		return
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sort.SliceStable(ods, func(i, j int) bool {
+		ri, iok := m.rtts[ods[i].LocalInfo.URL.String()]
+		rj, jok := m.rtts[ods[j].LocalInfo.URL.String()]
+		if iok != jok {
+			return iok
+		}
+		return ri < rj
+	})
+}
+
+// localScore averages the latency score and the region score of an
+// orchestrator. The region score is 1 if the orchestrator is located in the
+// gateway's region and 0 otherwise. The caller must hold mu.
+func (m *LatencyMonitor) localScore(key string) (float64, bool) {
+	var sum float64
+	var n int
+	if rtt, ok := m.rtts[key]; ok {
+		sum += math.Max(0, 1-float64(rtt)/float64(maxScoredRTT))
+		n++
+	}
+	regions := m.tags[key]
+	if len(regions) == 0 && m.geoRegions[key] != "" {
+		regions = []string{m.geoRegions[key]}
+	}
+	if m.region != "" && len(regions) > 0 {
+		for _, region := range regions {
+			if strings.ToUpper(region) == m.region {
+				sum++
+				break
+			}
+		}
+		n++
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+func (m *LatencyMonitor) updatePerfScore() {
+	if m.perfScore == nil {
+		return
+	}
+	scores := make(map[ethcommon.Address]float64)
+	m.mu.RLock()
+	for key, addr := range m.addrs {
+		if score, ok := m.localScore(key); ok {
+			scores[addr] = score
+		}
+	}
+	m.mu.RUnlock()
+
+	m.perfScore.Mu.Lock()
+	m.perfScore.Local = scores
+	m.perfScore.Mu.Unlock()
+}
+
+// GeoIPTable maps networks to the region they are located in
+type GeoIPTable []geoIPNetwork
+
+type geoIPNetwork struct {
+	network *gonet.IPNet
+	region  string
+}
+
+// LoadGeoIPTable reads a GeoIP table from a CSV file, see ParseGeoIPTable
+func LoadGeoIPTable(path string) (GeoIPTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseGeoIPTable(f)
+}
+
+// ParseGeoIPTable parses lines of `network,region` where network is in CIDR
+// notation. Blank lines, comments starting with # and a header are skipped.
+func ParseGeoIPTable(r io.Reader) (GeoIPTable, error) {
+	var table GeoIPTable
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, ",")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid GeoIP entry line=%d", line)
+		}
+		_, network, err := gonet.ParseCIDR(strings.TrimSpace(fields[0]))
+		if err != nil {
+			if line == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("invalid GeoIP network line=%d err=%w", line, err)
+		}
+		table = append(table, geoIPNetwork{network: network, region: strings.TrimSpace(fields[1])})
+	}
+	return table, scanner.Err()
+}
+
+// Region returns the region of the most specific network containing ip
+func (t GeoIPTable) Region(ip gonet.IP) string {
+	var region string
+	best := -1
+	for _, n := range t {
+		if ones, _ := n.network.Mask.Size(); ones > best && n.network.Contains(ip) {
+			region, best = n.region, ones
+		}
+	}
+	return region
+}
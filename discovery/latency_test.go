@@ -0,0 +1,191 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	gonet "net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stubPings(t *testing.T, rtts map[string]time.Duration) {
+	oldPing := serverPingOrch
+	t.Cleanup(func() { serverPingOrch = oldPing })
+	serverPingOrch = func(ctx context.Context, uri *url.URL) (time.Duration, error) {
+		rtt, ok := rtts[uri.String()]
+		if !ok {
+			return 0, errors.New("unreachable")
+		}
+		return rtt, nil
+	}
+}
+
+func mustParseURLs(t *testing.T, addrs ...string) []*url.URL {
+	var uris []*url.URL
+	for _, addr := range addrs {
+		uri, err := url.Parse(addr)
+		require.Nil(t, err)
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+func TestLatencyMonitor_Probe(t *testing.T) {
+	assert := assert.New(t)
+
+	uris := mustParseURLs(t, "https://127.0.0.1:8935", "https://127.0.0.1:8936")
+	rtts := map[string]time.Duration{uris[0].String(): 100 * time.Millisecond}
+	stubPings(t, rtts)
+
+	m := NewLatencyMonitor("", nil, nil, 0)
+	pool := NewOrchestratorPool(nil, uris, common.Score_Trusted, nil)
+	m.probe(context.Background(), pool.GetInfos())
+	rtt, ok := m.RTT(uris[0])
+	assert.True(ok)
+	assert.Equal(100*time.Millisecond, rtt)
+	_, ok = m.RTT(uris[1])
+	assert.False(ok)
+
+	// Round trip times are smoothed
+	rtts[uris[0].String()] = 200 * time.Millisecond
+	m.probe(context.Background(), pool.GetInfos())
+	rtt, _ = m.RTT(uris[0])
+	assert.Equal(130*time.Millisecond, rtt)
+
+	// And forgotten when the orchestrator becomes unreachable
+	delete(rtts, uris[0].String())
+	m.probe(context.Background(), pool.GetInfos())
+	_, ok = m.RTT(uris[0])
+	assert.False(ok)
+}
+
+func TestLatencyMonitor_SortAndFar(t *testing.T) {
+	assert := assert.New(t)
+
+	uris := mustParseURLs(t, "https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937")
+	m := NewLatencyMonitor("", nil, nil, 150*time.Millisecond)
+	m.record(uris[0], 200*time.Millisecond, nil)
+	m.record(uris[1], 20*time.Millisecond, nil)
+
+	var ods common.OrchestratorDescriptors
+	for _, uri := range uris {
+		ods = append(ods, common.OrchestratorDescriptor{LocalInfo: &common.OrchestratorLocalInfo{URL: uri}})
+	}
+	m.sortByRTT(ods)
+	assert.Equal(uris[1], ods[0].LocalInfo.URL)
+	assert.Equal(uris[0], ods[1].LocalInfo.URL)
+	assert.Equal(uris[2], ods[2].LocalInfo.URL)
+
+	assert.True(m.isFar(uris[0]))
+	assert.False(m.isFar(uris[1]))
+	// Orchestrators with unknown round trip time aren't far
+	assert.False(m.isFar(uris[2]))
+
+	// Nil monitors do nothing
+	var nilMonitor *LatencyMonitor
+	assert.False(nilMonitor.isFar(uris[0]))
+	nilMonitor.sortByRTT(ods)
+	nilMonitor.SetRegionTags(nil)
+	nilMonitor.observe(ods[0])
+}
+
+func TestLatencyMonitor_GetOrchestrators(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	uris := mustParseURLs(t, "https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937")
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(c context.Context, b common.Broadcaster, s *url.URL) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{Transcoder: s.String()}, nil
+	}
+	oldLatency := Latency
+	defer func() { Latency = oldLatency }()
+	Latency = NewLatencyMonitor("", nil, nil, 150*time.Millisecond)
+	Latency.record(uris[0], 300*time.Millisecond, nil)
+	Latency.record(uris[1], 100*time.Millisecond, nil)
+	Latency.record(uris[2], 50*time.Millisecond, nil)
+
+	pool := NewOrchestratorPool(nil, uris, common.Score_Trusted, nil)
+	transcoders := func(numOrchs int) []string {
+		ods, err := pool.GetOrchestrators(context.Background(), numOrchs, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+		require.Nil(err)
+		var res []string
+		for _, od := range ods {
+			res = append(res, od.RemoteInfo.Transcoder)
+		}
+		return res
+	}
+
+	// Nearby orchestrators come first
+	assert.Equal([]string{uris[2].String(), uris[1].String()}, transcoders(2))
+
+	// Far orchestrators are only used if there aren't enough nearby ones
+	assert.Equal([]string{uris[2].String(), uris[1].String(), uris[0].String()}, transcoders(3))
+}
+
+func TestLatencyMonitor_PerfScore(t *testing.T) {
+	assert := assert.New(t)
+
+	uris := mustParseURLs(t, "https://127.0.0.1:8935", "https://10.0.0.1:8935", "https://127.0.0.1:8937")
+	addrs := []ethcommon.Address{{1}, {2}, {3}}
+	geo, err := ParseGeoIPTable(strings.NewReader("10.0.0.0/8,MDW\n"))
+	assert.Nil(err)
+	perfScore := &common.PerfScore{Scores: map[ethcommon.Address]float64{addrs[0]: 0.5}}
+	m := NewLatencyMonitor("fra", geo, perfScore, 0)
+	for i, uri := range uris {
+		m.observe(common.OrchestratorDescriptor{
+			LocalInfo:  &common.OrchestratorLocalInfo{URL: uri},
+			RemoteInfo: &net.OrchestratorInfo{Address: addrs[i].Bytes()},
+		})
+	}
+
+	// Registry tags take precedence over the GeoIP table
+	m.SetRegionTags(map[string][]string{uris[0].String(): {"FRA"}})
+	m.record(uris[0], 200*time.Millisecond, nil)
+	m.record(uris[1], 100*time.Millisecond, nil)
+	m.locate(context.Background(), uris[1])
+	m.updatePerfScore()
+
+	perfScore.Mu.Lock()
+	defer perfScore.Mu.Unlock()
+	// Latency score of 0.8 and region score of 1, combined with the performance stats
+	assert.InDelta(0.45, perfScore.Score(addrs[0]), 1e-9)
+	// Latency score of 0.9 and region score of 0
+	assert.InDelta(0.45, perfScore.Score(addrs[1]), 1e-9)
+	// No hints at all
+	_, ok := perfScore.Local[addrs[2]]
+	assert.False(ok)
+	assert.Equal(0.0, perfScore.Score(addrs[2]))
+}
+
+func TestGeoIPTable(t *testing.T) {
+	assert := assert.New(t)
+
+	table, err := ParseGeoIPTable(strings.NewReader(`network,region
+# comment
+
+10.0.0.0/8,MDW
+10.1.0.0/16,FRA
+2001:db8::/32,LAX
+`))
+	assert.Nil(err)
+	assert.Len(table, 3)
+	assert.Equal("MDW", table.Region(gonet.ParseIP("10.2.0.1")))
+	assert.Equal("FRA", table.Region(gonet.ParseIP("10.1.0.1")))
+	assert.Equal("LAX", table.Region(gonet.ParseIP("2001:db8::1")))
+	assert.Equal("", table.Region(gonet.ParseIP("192.168.0.1")))
+
+	_, err = ParseGeoIPTable(strings.NewReader("10.0.0.0/8,MDW\nnot a network,FRA\n"))
+	assert.EqualError(err, `invalid GeoIP network line=2 err=invalid CIDR address: not a network`)
+	_, err = ParseGeoIPTable(strings.NewReader("10.0.0.0/8\n"))
+	assert.EqualError(err, "invalid GeoIP entry line=1")
+}
@@ -0,0 +1,280 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	lpcrypto "github.com/livepeer/go-livepeer/crypto"
+	"github.com/livepeer/go-livepeer/server"
+)
+
+var ErrRegistrySignature = errors.New("invalid registry signature")
+var ErrRegistryExpired = errors.New("registry expired")
+var ErrRegistryVersion = errors.New("stale registry version")
+
+// RegistryOrchestrator is an orchestrator entry of a registry document
+type RegistryOrchestrator struct {
+	URL     string   `json:"url"`
+	Regions []string `json:"regions,omitempty"`
+	// Price hint in wei per pixels; orchestrators whose hint exceeds the max price are not queried
+	PricePerUnit  int64   `json:"pricePerUnit,omitempty"`
+	PixelsPerUnit int64   `json:"pixelsPerUnit,omitempty"`
+	Score         float32 `json:"score,omitempty"`
+}
+
+// Registry is a versioned list of orchestrators. The version must increase
+// with every update of the list.
+type Registry struct {
+	Version       uint64                 `json:"version"`
+	Expires       *time.Time             `json:"expires,omitempty"`
+	Orchestrators []RegistryOrchestrator `json:"orchestrators"`
+}
+
+func (reg *Registry) expired(now time.Time) bool {
+	return reg.Expires != nil && now.After(*reg.Expires)
+}
+
+// SignedRegistry is the document served at a registry URL. The signature
+// is an ETH signature by the trusted key over the Keccak256 hash of the
+// registry exactly as serialized in the document.
+type SignedRegistry struct {
+	Registry  json.RawMessage `json:"registry"`
+	Signature string          `json:"signature"`
+}
+
+// ParseRegistry verifies that the document is signed by signer and decodes
+// the registry it carries
+func ParseRegistry(body []byte, signer ethcommon.Address) (*Registry, error) {
+	var doc SignedRegistry
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, err
+	}
+	sig, err := hex.DecodeString(strings.TrimPrefix(doc.Signature, "0x"))
+	if err != nil || !lpcrypto.VerifySig(signer, crypto.Keccak256(doc.Registry), sig) {
+		return nil, ErrRegistrySignature
+	}
+	var reg Registry
+	if err := json.Unmarshal(doc.Registry, &reg); err != nil {
+		return nil, err
+	}
+	if reg.expired(time.Now()) {
+		return nil, ErrRegistryExpired
+	}
+	return &reg, nil
+}
+
+type registryPool struct {
+	pool          *orchestratorPool
+	registry      *Registry
+	minVersion    uint64
+	prices        map[string]*big.Rat
+	registryURL   *url.URL
+	signer        ethcommon.Address
+	etag          string
+	db            *common.DB
+	mu            *sync.RWMutex
+	bcast         common.Broadcaster
+	orchBlacklist []string
+}
+
+// NewRegistryPool creates an orchestrator pool from the registry served at
+// registryURL, which must be signed by signer. The registry is polled for
+// updates until ctx is done. The last accepted version is stored in db so
+// that an older registry is not accepted after a restart.
+func NewRegistryPool(ctx context.Context, bcast common.Broadcaster, db *common.DB, registryURL *url.URL, signer ethcommon.Address,
+	orchBlacklist []string) *registryPool {
+
+	minVersion, err := db.RegistryVersion(registryURL.String())
+	if err != nil {
+		glog.Errorf("Unable to load orchestrator registry version url=%s err=%q", registryURL, err)
+	}
+	p := &registryPool{
+		pool:          &orchestratorPool{bcast: bcast, orchBlacklist: orchBlacklist},
+		minVersion:    minVersion,
+		registryURL:   registryURL,
+		signer:        signer,
+		db:            db,
+		mu:            &sync.RWMutex{},
+		bcast:         bcast,
+		orchBlacklist: orchBlacklist,
+	}
+	if err := p.refresh(); err != nil {
+		glog.Errorf("Unable to fetch orchestrator registry url=%s err=%q", registryURL, err)
+	}
+	go p.pollRegistry(ctx)
+	return p
+}
+
+func (r *registryPool) pollRegistry(ctx context.Context) {
+	ticker := time.NewTicker(common.RegistryDiscoveryRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.refresh(); err != nil {
+				glog.Errorf("Unable to refresh orchestrator registry url=%s err=%q", r.registryURL, err)
+			}
+		}
+	}
+}
+
+// refresh fetches the registry and replaces the pool if the registry is
+// valid and newer than the current one
+func (r *registryPool) refresh() error {
+	r.mu.RLock()
+	etag := r.etag
+	r.mu.RUnlock()
+
+	body, etag, err := fetchRegistry(r.registryURL, etag)
+	if err != nil || body == nil {
+		// A nil body without error means the registry is not modified
+		return err
+	}
+	reg, err := ParseRegistry(body, r.signer)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.registry != nil && reg.Version <= r.registry.Version {
+		if reg.Version == r.registry.Version {
+			r.etag = etag
+			return nil
+		}
+		return fmt.Errorf("%w version=%d current=%d", ErrRegistryVersion, reg.Version, r.registry.Version)
+	}
+	if reg.Version < r.minVersion {
+		return fmt.Errorf("%w version=%d accepted=%d", ErrRegistryVersion, reg.Version, r.minVersion)
+	}
+	if err := r.db.SetRegistryVersion(r.registryURL.String(), reg.Version); err != nil {
+		return err
+	}
+
+	infos := make([]common.OrchestratorLocalInfo, 0, len(reg.Orchestrators))
+	prices := make(map[string]*big.Rat)
+	tags := make(map[string][]string)
+	for _, o := range reg.Orchestrators {
+		uri, err := url.ParseRequestURI(o.URL)
+		if err != nil {
+			glog.Errorf("Unable to parse registry orchestrator url=%q err=%q", o.URL, err)
+			continue
+		}
+		infos = append(infos, common.OrchestratorLocalInfo{URL: uri, Score: o.Score})
+		if o.PricePerUnit > 0 && o.PixelsPerUnit > 0 {
+			prices[uri.String()] = big.NewRat(o.PricePerUnit, o.PixelsPerUnit)
+		}
+		if len(o.Regions) > 0 {
+			tags[uri.String()] = o.Regions
+		}
+	}
+	glog.Infof("Updated orchestrator registry version=%d orchestrators=%d", reg.Version, len(infos))
+	r.pool = &orchestratorPool{infos: infos, bcast: r.bcast, orchBlacklist: r.orchBlacklist}
+	r.registry = reg
+	r.minVersion = reg.Version
+	r.prices = prices
+	r.etag = etag
+	Latency.SetRegionTags(tags)
+	return nil
+}
+
+// Registry returns the registry currently in use, if any
+func (r *registryPool) Registry() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.registry
+}
+
+// current returns the pool and price hints of the registry in use. Once the
+// registry expires the pool is empty until a newer registry is fetched.
+func (r *registryPool) current() (*orchestratorPool, map[string]*big.Rat) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.registry != nil && r.registry.expired(time.Now()) {
+		glog.V(common.DEBUG).Infof("Orchestrator registry expired version=%d expires=%v", r.registry.Version, r.registry.Expires)
+		return &orchestratorPool{bcast: r.bcast, orchBlacklist: r.orchBlacklist}, nil
+	}
+	return r.pool, r.prices
+}
+
+func (r *registryPool) GetInfos() []common.OrchestratorLocalInfo {
+	pool, _ := r.current()
+	return pool.GetInfos()
+}
+
+func (r *registryPool) Size() int {
+	return len(r.GetInfos())
+}
+
+func (r *registryPool) SizeWith(scorePred common.ScorePred) int {
+	pool, _ := r.current()
+	return pool.SizeWith(scorePred)
+}
+
+func (r *registryPool) GetOrchestrators(ctx context.Context, numOrchestrators int, suspender common.Suspender, caps common.CapabilityComparator,
+	scorePred common.ScorePred) (common.OrchestratorDescriptors, error) {
+
+	pool, prices := r.current()
+
+	// Skip the orchestrators whose price hint exceeds the max price
+	if maxPrice := server.BroadcastCfg.MaxPrice(); maxPrice != nil && len(prices) > 0 {
+		infos := make([]common.OrchestratorLocalInfo, 0, len(pool.infos))
+		for _, info := range pool.infos {
+			if price, ok := prices[info.URL.String()]; ok && price.Cmp(maxPrice) > 0 {
+				continue
+			}
+			infos = append(infos, info)
+		}
+		pool = &orchestratorPool{infos: infos, bcast: pool.bcast, orchBlacklist: pool.orchBlacklist}
+	}
+
+	return pool.GetOrchestrators(ctx, numOrchestrators, suspender, caps, scorePred)
+}
+
+// fetchRegistry fetches the registry document. A nil body is returned when the
+// document matches etag.
+func fetchRegistry(registryURL *url.URL, etag string) ([]byte, string, error) {
+	var httpc = &http.Client{
+		Timeout: 3 * time.Second,
+	}
+	req, err := http.NewRequest("GET", registryURL.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := httpc.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, etag, nil
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status=%d body=%q", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, resp.Header.Get("ETag"), nil
+}
@@ -0,0 +1,310 @@
+package discovery
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signRegistry(t *testing.T, key *ecdsa.PrivateKey, reg *Registry) []byte {
+	raw, err := json.Marshal(reg)
+	require.Nil(t, err)
+	sig, err := crypto.Sign(accounts.TextHash(crypto.Keccak256(raw)), key)
+	require.Nil(t, err)
+	sig[64] += 27
+	body, err := json.Marshal(&SignedRegistry{Registry: raw, Signature: "0x" + hex.EncodeToString(sig)})
+	require.Nil(t, err)
+	return body
+}
+
+// registryServer serves the latest registry document with its version as ETag
+type registryServer struct {
+	mu       sync.Mutex
+	body     []byte
+	etag     string
+	requests int
+	notMod   int
+}
+
+func (s *registryServer) set(body []byte, etag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.etag = body, etag
+}
+
+func (s *registryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+	if s.etag != "" && r.Header.Get("If-None-Match") == s.etag {
+		s.notMod++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Write(s.body)
+}
+
+func TestParseRegistry(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.Nil(err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	reg := &Registry{Version: 3, Orchestrators: []RegistryOrchestrator{{
+		URL:           "https://127.0.0.1:8935",
+		Regions:       []string{"FRA"},
+		PricePerUnit:  10,
+		PixelsPerUnit: 1,
+		Score:         common.Score_Trusted,
+	}}}
+	body := signRegistry(t, key, reg)
+	parsed, err := ParseRegistry(body, signer)
+	require.Nil(err)
+	assert.Equal(reg, parsed)
+
+	// Documents signed by another key are rejected
+	_, err = ParseRegistry(body, ethcommon.BytesToAddress([]byte("other")))
+	assert.Equal(ErrRegistrySignature, err)
+
+	// As are documents whose registry was changed after signing
+	var doc SignedRegistry
+	require.Nil(json.Unmarshal(body, &doc))
+	doc.Registry = json.RawMessage(`{"version":4,"orchestrators":[]}`)
+	tampered, err := json.Marshal(&doc)
+	require.Nil(err)
+	_, err = ParseRegistry(tampered, signer)
+	assert.Equal(ErrRegistrySignature, err)
+
+	// Or that are not signed
+	doc.Signature = ""
+	unsigned, err := json.Marshal(&doc)
+	require.Nil(err)
+	_, err = ParseRegistry(unsigned, signer)
+	assert.Equal(ErrRegistrySignature, err)
+
+	// Expired documents are rejected
+	expired := time.Now().Add(-time.Minute)
+	reg.Expires = &expired
+	_, err = ParseRegistry(signRegistry(t, key, reg), signer)
+	assert.Equal(ErrRegistryExpired, err)
+
+	_, err = ParseRegistry([]byte("not json"), signer)
+	assert.NotNil(err)
+}
+
+func TestRegistryPool_Refresh(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.Nil(err)
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	srv := &registryServer{}
+	srv.set(signRegistry(t, key, &Registry{Version: 2, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8935", Score: common.Score_Trusted},
+		{URL: "not a url"},
+		{URL: "https://127.0.0.1:8936"},
+	}}), "v2")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	registryURL, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	pool := NewRegistryPool(ctx, nil, dbh, registryURL, signer, nil)
+	require.NotNil(pool.Registry())
+	assert.Equal(uint64(2), pool.Registry().Version)
+	assert.Equal(2, pool.Size())
+	assert.Equal(1, pool.SizeWith(common.ScoreAtLeast(common.Score_Trusted)))
+	assert.Equal(1, pool.SizeWith(common.ScoreEqualTo(common.Score_Untrusted)))
+
+	// Unmodified registries are not fetched again
+	require.Nil(pool.refresh())
+	assert.Equal(2, srv.requests)
+	assert.Equal(1, srv.notMod)
+
+	// Registries signed by another key are ignored
+	other, err := crypto.GenerateKey()
+	require.Nil(err)
+	srv.set(signRegistry(t, other, &Registry{Version: 3}), "v3")
+	assert.Equal(ErrRegistrySignature, pool.refresh())
+	assert.Equal(2, pool.Size())
+
+	// Older registries are ignored
+	srv.set(signRegistry(t, key, &Registry{Version: 1}), "v1")
+	assert.True(errors.Is(pool.refresh(), ErrRegistryVersion))
+	assert.Equal(uint64(2), pool.Registry().Version)
+	assert.Equal(2, pool.Size())
+
+	// Newer registries replace the pool
+	srv.set(signRegistry(t, key, &Registry{Version: 3, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8937"},
+	}}), "v3")
+	require.Nil(pool.refresh())
+	assert.Equal(uint64(3), pool.Registry().Version)
+	infos := pool.GetInfos()
+	require.Len(infos, 1)
+	assert.Equal("https://127.0.0.1:8937", infos[0].URL.String())
+
+	// The accepted version is persisted, so older registries are ignored after a restart
+	version, err := dbh.RegistryVersion(registryURL.String())
+	require.Nil(err)
+	assert.Equal(uint64(3), version)
+	srv.set(signRegistry(t, key, &Registry{Version: 2, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8935"},
+	}}), "v2")
+	restarted := NewRegistryPool(ctx, nil, dbh, registryURL, signer, nil)
+	assert.Nil(restarted.Registry())
+	assert.Equal(0, restarted.Size())
+	assert.True(errors.Is(restarted.refresh(), ErrRegistryVersion))
+
+	// The registry in use is reloaded after a restart
+	srv.set(signRegistry(t, key, &Registry{Version: 3, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8937"},
+	}}), "v3")
+	require.Nil(restarted.refresh())
+	assert.Equal(uint64(3), restarted.Registry().Version)
+	assert.Equal(1, restarted.Size())
+
+	// The registry is polled
+	oldInterval := common.RegistryDiscoveryRefreshInterval
+	defer func() { common.RegistryDiscoveryRefreshInterval = oldInterval }()
+	common.RegistryDiscoveryRefreshInterval = 10 * time.Millisecond
+	srv.set(signRegistry(t, key, &Registry{Version: 4}), "v4")
+	polled := NewRegistryPool(ctx, nil, nil, registryURL, signer, nil)
+	srv.set(signRegistry(t, key, &Registry{Version: 5, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8938"},
+	}}), "v5")
+	assert.Eventually(func() bool { return polled.Size() == 1 }, time.Second, 10*time.Millisecond)
+}
+
+func TestRegistryPool_Expires(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.Nil(err)
+
+	expires := time.Now().Add(time.Hour)
+	srv := &registryServer{}
+	srv.set(signRegistry(t, key, &Registry{Version: 1, Expires: &expires, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8935", PricePerUnit: 1, PixelsPerUnit: 1},
+	}}), "v1")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	registryURL, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewRegistryPool(ctx, nil, nil, registryURL, crypto.PubkeyToAddress(key.PublicKey), nil)
+	assert.Equal(1, pool.Size())
+	assert.Equal(1, pool.SizeWith(common.ScoreAtLeast(0)))
+
+	// The registry in use is not used anymore once it expires
+	expired := time.Now().Add(-time.Minute)
+	pool.registry.Expires = &expired
+	assert.Equal(0, pool.Size())
+	assert.Equal(0, pool.SizeWith(common.ScoreAtLeast(0)))
+	assert.Empty(pool.GetInfos())
+	ods, err := pool.GetOrchestrators(context.Background(), 1, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(err)
+	assert.Empty(ods)
+}
+
+func TestRegistryPool_Unavailable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+	registryURL, err := url.Parse(ts.URL)
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewRegistryPool(ctx, nil, nil, registryURL, ethcommon.Address{}, nil)
+	assert.Nil(t, pool.Registry())
+	assert.Equal(t, 0, pool.Size())
+	assert.Equal(t, 0, pool.SizeWith(common.ScoreAtLeast(0)))
+
+	ods, err := pool.GetOrchestrators(context.Background(), 1, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+	assert.Nil(t, err)
+	assert.Len(t, ods, 0)
+}
+
+func TestRegistryPool_PriceHints(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	key, err := crypto.GenerateKey()
+	require.Nil(err)
+	srv := &registryServer{}
+	srv.set(signRegistry(t, key, &Registry{Version: 1, Orchestrators: []RegistryOrchestrator{
+		{URL: "https://127.0.0.1:8935", PricePerUnit: 1, PixelsPerUnit: 1},
+		{URL: "https://127.0.0.1:8936", PricePerUnit: 5, PixelsPerUnit: 1},
+		{URL: "https://127.0.0.1:8937"},
+	}}), "v1")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+	registryURL, err := url.Parse(ts.URL)
+	require.Nil(err)
+
+	oldOrchInfo := serverGetOrchInfo
+	defer func() { serverGetOrchInfo = oldOrchInfo }()
+	serverGetOrchInfo = func(c context.Context, b common.Broadcaster, s *url.URL) (*net.OrchestratorInfo, error) {
+		return &net.OrchestratorInfo{Transcoder: s.String()}, nil
+	}
+	defer server.BroadcastCfg.SetMaxPrice(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	pool := NewRegistryPool(ctx, nil, nil, registryURL, crypto.PubkeyToAddress(key.PublicKey), nil)
+
+	transcoders := func() []string {
+		ods, err := pool.GetOrchestrators(context.Background(), 3, newStubSuspender(), newStubCapabilities(), common.ScoreAtLeast(0))
+		require.Nil(err)
+		var res []string
+		for _, od := range ods {
+			res = append(res, od.RemoteInfo.Transcoder)
+		}
+		sort.Strings(res)
+		return res
+	}
+
+	// All orchestrators are queried without a max price
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8936", "https://127.0.0.1:8937"}, transcoders())
+
+	// Orchestrators whose price hint exceeds the max price are skipped, as long as they have a hint
+	server.BroadcastCfg.SetMaxPrice(core.NewFixedPrice(big.NewRat(2, 1)))
+	assert.Equal([]string{"https://127.0.0.1:8935", "https://127.0.0.1:8937"}, transcoders())
+}
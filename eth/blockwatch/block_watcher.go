@@ -49,6 +49,7 @@ type Config struct {
 	WithLogs            bool
 	Topics              []common.Hash
 	Client              Client
+	Checkpoints         CheckpointStore
 }
 
 // Watcher maintains a consistent representation of the latest `blockRetentionLimit` blocks,
@@ -66,6 +67,7 @@ type Watcher struct {
 	ticker              *time.Ticker
 	withLogs            bool
 	topics              []common.Hash
+	checkpoints         CheckpointStore
 	sync.RWMutex
 }
 
@@ -81,6 +83,7 @@ func New(config Config) *Watcher {
 		client:              config.Client,
 		withLogs:            config.WithLogs,
 		topics:              config.Topics,
+		checkpoints:         config.Checkpoints,
 	}
 	return bs
 }
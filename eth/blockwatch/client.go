@@ -45,6 +45,12 @@ func NewRPCClient(rpcURL string, requestTimeout time.Duration) (*RPCClient, erro
 	return &RPCClient{rpcClient: rpcClient, client: ethClient, requestTimeout: requestTimeout}, nil
 }
 
+// NewRPCClientWithClient returns a new Client for fetching Ethereum blocks over an existing
+// rpc.Client, i.e. an in-process connection to a simulated chain.
+func NewRPCClientWithClient(rpcClient *rpc.Client, requestTimeout time.Duration) *RPCClient {
+	return &RPCClient{rpcClient: rpcClient, client: ethclient.NewClient(rpcClient), requestTimeout: requestTimeout}
+}
+
 type getHeaderResponse struct {
 	Hash          common.Hash `json:"hash"`
 	ParentHash    common.Hash `json:"parentHash"`
@@ -0,0 +1,165 @@
+package blockwatch
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+)
+
+// CheckpointStore is an interface for a store that persists the progress of event replays
+// so that an interrupted replay can be resumed
+type CheckpointStore interface {
+	ReplayCheckpoint(name string) (*big.Int, error)
+	SetReplayCheckpoint(name string, block *big.Int) error
+}
+
+// ReplayHandler handles the events for a batch of replayed blocks. Events are sorted by block
+// number and the logs of each block are sorted by log index.
+type ReplayHandler func(events []*Event) error
+
+// Replay fetches the logs matching the Watcher's topics for the block range [from, to] and passes them
+// to handler as Added events in batches of at most maxBlocksInGetLogsQuery blocks. Replays do not
+// touch the retained blocks and do not emit events to subscribers.
+// Only blocks that are deeper than the block retention limit are replayed since more recent blocks can
+// still be reorged. Those blocks are delivered by the Watcher's subscription as Added and Removed events,
+// so to is lowered to the latest block minus the block retention limit if necessary.
+// If a CheckpointStore is configured, the last block of each batch is stored under name once handler
+// returns without error and a later replay with the same name resumes after the checkpoint.
+// If the replay continues from the latest retained block, or no block is retained yet, the last replayed
+// block becomes the latest retained block so that BackfillEvents resumes after it.
+// Replay returns the last block that was handled, which is nil if no block was handled.
+func (w *Watcher) Replay(ctx context.Context, name string, from, to *big.Int, handler ReplayHandler) (*big.Int, error) {
+	if from == nil || to == nil {
+		return nil, errors.New("must provide a block range")
+	}
+	if from.Cmp(to) > 0 {
+		return nil, errors.New("invalid block range, from is greater than to")
+	}
+
+	latest, err := w.client.HeaderByNumber(nil)
+	if err != nil {
+		return nil, err
+	}
+	end := to.Int64()
+	if safe := latest.Number.Int64() - int64(w.blockRetentionLimit); end > safe {
+		end = safe
+	}
+
+	start := from.Int64()
+	if w.checkpoints != nil {
+		checkpoint, err := w.checkpoints.ReplayCheckpoint(name)
+		if err != nil {
+			return nil, err
+		}
+		if checkpoint != nil && checkpoint.Int64() >= start {
+			glog.Infof("Resuming replay name=%v from checkpoint block=%v", name, checkpoint)
+			start = checkpoint.Int64() + 1
+		}
+	}
+
+	var last *big.Int
+	for fromBlock := start; fromBlock <= end; fromBlock += int64(maxBlocksInGetLogsQuery) {
+		toBlock := fromBlock + int64(maxBlocksInGetLogsQuery) - 1
+		if toBlock > end {
+			toBlock = end
+		}
+
+		select {
+		case <-ctx.Done():
+			return last, ctx.Err()
+		default:
+		}
+
+		logs, err := w.filterLogsRecursively(int(fromBlock), int(toBlock), nil)
+		if err != nil {
+			return last, err
+		}
+
+		if err := handler(groupLogsByBlock(logs)); err != nil {
+			return last, err
+		}
+
+		last = big.NewInt(toBlock)
+		if w.checkpoints != nil {
+			if err := w.checkpoints.SetReplayCheckpoint(name, last); err != nil {
+				return last, err
+			}
+		}
+		glog.V(6).Infof("Replayed blocks name=%v from=%v to=%v logs=%v", name, fromBlock, toBlock, len(logs))
+	}
+
+	if last != nil {
+		if err := w.retainReplayedBlock(start, last); err != nil {
+			return last, err
+		}
+	}
+
+	return last, nil
+}
+
+// retainReplayedBlock replaces the retained blocks with the last replayed block if the replay started
+// right after the latest retained block or if no block is retained
+func (w *Watcher) retainReplayedBlock(start int64, last *big.Int) error {
+	latest, err := w.stack.Peek()
+	if err != nil {
+		return err
+	}
+	if latest != nil && (latest.Number.Int64()+1 < start || latest.Number.Cmp(last) >= 0) {
+		return nil
+	}
+
+	header, err := w.client.HeaderByNumber(last)
+	if err != nil {
+		return err
+	}
+	headers, err := w.InspectRetainedBlocks()
+	if err != nil {
+		return err
+	}
+	for range headers {
+		if _, err := w.stack.Pop(); err != nil {
+			return err
+		}
+	}
+	return w.stack.Push(header)
+}
+
+// groupLogsByBlock groups logs into Added events with one event per block
+func groupLogsByBlock(logs []types.Log) []*Event {
+	hashToBlockHeader := map[common.Hash]*MiniHeader{}
+	headers := []*MiniHeader{}
+	for _, log := range logs {
+		header, ok := hashToBlockHeader[log.BlockHash]
+		if !ok {
+			header = &MiniHeader{
+				Hash:   log.BlockHash,
+				Number: new(big.Int).SetUint64(log.BlockNumber),
+				Logs:   []types.Log{},
+			}
+			hashToBlockHeader[log.BlockHash] = header
+			headers = append(headers, header)
+		}
+		header.Logs = append(header.Logs, log)
+	}
+
+	sort.SliceStable(headers, func(i, j int) bool {
+		return headers[i].Number.Cmp(headers[j].Number) < 0
+	})
+
+	events := make([]*Event, len(headers))
+	for i, header := range headers {
+		sort.SliceStable(header.Logs, func(i, j int) bool {
+			return header.Logs[i].Index < header.Logs[j].Index
+		})
+		events[i] = &Event{
+			Type:        Added,
+			BlockHeader: header,
+		}
+	}
+	return events
+}
@@ -0,0 +1,256 @@
+package blockwatch
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubCheckpointStore struct {
+	checkpoints map[string]*big.Int
+	err         error
+}
+
+func newStubCheckpointStore() *stubCheckpointStore {
+	return &stubCheckpointStore{checkpoints: make(map[string]*big.Int)}
+}
+
+func (s *stubCheckpointStore) ReplayCheckpoint(name string) (*big.Int, error) {
+	return s.checkpoints[name], s.err
+}
+
+func (s *stubCheckpointStore) SetReplayCheckpoint(name string, block *big.Int) error {
+	s.checkpoints[name] = block
+	return s.err
+}
+
+// replayClient is a fakeLogClient that also returns the chain head
+type replayClient struct {
+	*fakeLogClient
+	head int64
+}
+
+func (c *replayClient) HeaderByNumber(number *big.Int) (*MiniHeader, error) {
+	if number != nil {
+		return &MiniHeader{Number: number, Hash: common.BigToHash(number)}, nil
+	}
+	return &MiniHeader{Number: big.NewInt(c.head)}, nil
+}
+
+func newReplayWatcher(fc *fakeLogClient, head int64, checkpoints CheckpointStore) *Watcher {
+	cfg := config
+	cfg.Store = &stubMiniHeaderStore{}
+	cfg.Client = &replayClient{fakeLogClient: fc, head: head}
+	cfg.Checkpoints = checkpoints
+	return New(cfg)
+}
+
+func replayLog(block uint64, index uint) types.Log {
+	return types.Log{
+		Address:     logStub.Address,
+		Topics:      logStub.Topics,
+		BlockNumber: block,
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+		Index:       index,
+	}
+}
+
+func TestReplay_InvalidRange(t *testing.T) {
+	assert := assert.New(t)
+
+	w := New(config)
+
+	_, err := w.Replay(context.Background(), "foo", nil, big.NewInt(1), func([]*Event) error { return nil })
+	assert.EqualError(err, "must provide a block range")
+
+	_, err = w.Replay(context.Background(), "foo", big.NewInt(2), big.NewInt(1), func([]*Event) error { return nil })
+	assert.EqualError(err, "invalid block range, from is greater than to")
+}
+
+func TestReplay_GroupsLogsByBlock(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	from := 10
+	to := from + maxBlocksInGetLogsQuery + 10
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(from, from+maxBlocksInGetLogsQuery-1): {
+			Logs: []types.Log{replayLog(12, 3), replayLog(11, 0), replayLog(12, 1)},
+		},
+		aRange(from+maxBlocksInGetLogsQuery, to): {
+			Logs: []types.Log{replayLog(uint64(to), 0)},
+		},
+	})
+	require.NoError(err)
+
+	checkpoints := newStubCheckpointStore()
+	w := newReplayWatcher(fakeLogClient, int64(to+config.BlockRetentionLimit), checkpoints)
+
+	var batches [][]*Event
+	last, err := w.Replay(context.Background(), "foo", big.NewInt(int64(from)), big.NewInt(int64(to)), func(events []*Event) error {
+		batches = append(batches, events)
+		return nil
+	})
+	require.NoError(err)
+	assert.Equal(big.NewInt(int64(to)), last)
+	assert.Equal(big.NewInt(int64(to)), checkpoints.checkpoints["foo"])
+	assert.Equal(2, fakeLogClient.Count())
+
+	require.Len(batches, 2)
+	require.Len(batches[0], 2)
+	assert.Equal(Added, batches[0][0].Type)
+	assert.Equal(big.NewInt(11), batches[0][0].BlockHeader.Number)
+	assert.Len(batches[0][0].BlockHeader.Logs, 1)
+	assert.Equal(big.NewInt(12), batches[0][1].BlockHeader.Number)
+	require.Len(batches[0][1].BlockHeader.Logs, 2)
+	assert.Equal(uint(1), batches[0][1].BlockHeader.Logs[0].Index)
+	assert.Equal(uint(3), batches[0][1].BlockHeader.Logs[1].Index)
+	require.Len(batches[1], 1)
+	assert.Equal(big.NewInt(int64(to)), batches[1][0].BlockHeader.Number)
+}
+
+func TestReplay_ResumesFromCheckpoint(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(16, 20): {Logs: []types.Log{replayLog(18, 0)}},
+	})
+	require.NoError(err)
+
+	checkpoints := newStubCheckpointStore()
+	checkpoints.checkpoints["foo"] = big.NewInt(15)
+	w := newReplayWatcher(fakeLogClient, 100, checkpoints)
+
+	var events []*Event
+	last, err := w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func(evs []*Event) error {
+		events = append(events, evs...)
+		return nil
+	})
+	require.NoError(err)
+	assert.Equal(big.NewInt(20), last)
+	require.Len(events, 1)
+	assert.Equal(big.NewInt(18), events[0].BlockHeader.Number)
+
+	// Nothing left to replay
+	last, err = w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func(evs []*Event) error {
+		t.Fatal("handler should not be called")
+		return nil
+	})
+	require.NoError(err)
+	assert.Nil(last)
+	assert.Equal(1, fakeLogClient.Count())
+}
+
+func TestReplay_SkipsUnsafeBlocks(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	// The head is 25 and the last 10 blocks can still be reorged so only blocks up to 15 are replayed
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(10, 15): {Logs: []types.Log{replayLog(12, 0)}},
+	})
+	require.NoError(err)
+
+	w := newReplayWatcher(fakeLogClient, 25, nil)
+
+	last, err := w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func([]*Event) error { return nil })
+	require.NoError(err)
+	assert.Equal(big.NewInt(15), last)
+	assert.Equal(1, fakeLogClient.Count())
+
+	// Nothing is safe to replay
+	w = newReplayWatcher(fakeLogClient, 15, nil)
+	last, err = w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func([]*Event) error {
+		t.Fatal("handler should not be called")
+		return nil
+	})
+	require.NoError(err)
+	assert.Nil(last)
+}
+
+func TestReplay_HandlerError(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(10, 20): {Logs: []types.Log{replayLog(18, 0)}},
+	})
+	require.NoError(err)
+
+	checkpoints := newStubCheckpointStore()
+	w := newReplayWatcher(fakeLogClient, 100, checkpoints)
+
+	last, err := w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func([]*Event) error {
+		return errors.New("handler error")
+	})
+	assert.EqualError(err, "handler error")
+	assert.Nil(last)
+	assert.Nil(checkpoints.checkpoints["foo"])
+}
+
+func TestReplay_ContextCanceled(t *testing.T) {
+	assert := assert.New(t)
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{})
+	require.NoError(t, err)
+
+	w := newReplayWatcher(fakeLogClient, 100, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	last, err := w.Replay(ctx, "foo", big.NewInt(10), big.NewInt(20), func([]*Event) error { return nil })
+	assert.Equal(context.Canceled, err)
+	assert.Nil(last)
+	assert.Equal(0, fakeLogClient.Count())
+}
+
+func TestReplay_RetainsLastReplayedBlock(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	fakeLogClient, err := newFakeLogClient(map[string]filterLogsResponse{
+		aRange(10, 15): {Logs: []types.Log{}},
+	})
+	require.NoError(err)
+	replay := func(w *Watcher) {
+		_, err := w.Replay(context.Background(), "foo", big.NewInt(10), big.NewInt(20), func([]*Event) error { return nil })
+		require.NoError(err)
+	}
+	latest := func(w *Watcher) *big.Int {
+		h, err := w.stack.Peek()
+		require.NoError(err)
+		require.NotNil(h)
+		return h.Number
+	}
+
+	// No retained block, so backfill resumes after the replayed blocks
+	w := newReplayWatcher(fakeLogClient, 25, nil)
+	replay(w)
+	assert.Equal(big.NewInt(15), latest(w))
+
+	// The replay continues from the retained block
+	w = newReplayWatcher(fakeLogClient, 25, nil)
+	require.NoError(w.stack.Push(&MiniHeader{Number: big.NewInt(9)}))
+	replay(w)
+	assert.Equal(big.NewInt(15), latest(w))
+
+	// Blocks between the retained block and the replay are still backfilled
+	w = newReplayWatcher(fakeLogClient, 25, nil)
+	require.NoError(w.stack.Push(&MiniHeader{Number: big.NewInt(5)}))
+	replay(w)
+	assert.Equal(big.NewInt(5), latest(w))
+
+	// Retained blocks past the replay are kept
+	w = newReplayWatcher(fakeLogClient, 25, nil)
+	require.NoError(w.stack.Push(&MiniHeader{Number: big.NewInt(18)}))
+	replay(w)
+	assert.Equal(big.NewInt(18), latest(w))
+}
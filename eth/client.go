@@ -12,6 +12,7 @@ package eth
 //go:generate abigen --abi protocol/abi/Minter.json --pkg contracts --type Minter --out contracts/minter.go
 //go:generate abigen --abi protocol/abi/LivepeerTokenFaucet.json --pkg contracts --type LivepeerTokenFaucet --out contracts/livepeerTokenFaucet.go
 //go:generate abigen --abi protocol/abi/Poll.json --pkg contracts --type Poll --out contracts/poll.go
+//go:generate abigen --abi protocol/abi/PollCreator.json --pkg contracts --type PollCreator --out contracts/pollCreator.go
 import (
 	"context"
 	"fmt"
@@ -69,6 +70,7 @@ type LivepeerEthClient interface {
 	// Staking
 	Transcoder(blockRewardCut, feeShare *big.Int) (*types.Transaction, error)
 	Reward() (*types.Transaction, error)
+	RewardWithGasPrice(gasPrice *big.Int) (*types.Transaction, error)
 	Bond(amount *big.Int, toAddr ethcommon.Address) (*types.Transaction, error)
 	Rebond(unbondingLockID *big.Int) (*types.Transaction, error)
 	RebondFromUnbonded(toAddr ethcommon.Address, unbondingLockID *big.Int) (*types.Transaction, error)
@@ -85,6 +87,8 @@ type LivepeerEthClient interface {
 	TranscoderPool() ([]*lpTypes.Transcoder, error)
 	IsActiveTranscoder() (bool, error)
 	GetTotalBonded() (*big.Int, error)
+	GetTotalBondedAt(block *big.Int) (*big.Int, error)
+	VotingStake(addr ethcommon.Address, block *big.Int) (ethcommon.Address, *big.Int, error)
 	GetTranscoderPoolSize() (*big.Int, error)
 
 	// TicketBroker
@@ -403,6 +407,13 @@ func (c *client) callOpts() *bind.CallOpts {
 	}
 }
 
+// callOptsAt returns the options to call a contract with its state at block or at the latest block if block is nil
+func (c *client) callOptsAt(block *big.Int) *bind.CallOpts {
+	opts := c.callOpts()
+	opts.BlockNumber = block
+	return opts
+}
+
 func newEthRpcContext() context.Context {
 	ctx, _ := context.WithTimeout(context.Background(), ethRpcTimeout)
 	return ctx
@@ -761,6 +772,43 @@ func (c *client) GetTotalBonded() (*big.Int, error) {
 	return c.bondingManager.GetTotalBonded(c.callOpts())
 }
 
+// GetTotalBondedAt returns the total bonded stake at block, which requires the historical state of the block
+func (c *client) GetTotalBondedAt(block *big.Int) (*big.Int, error) {
+	return c.bondingManager.GetTotalBonded(c.callOptsAt(block))
+}
+
+// VotingStake returns the delegate of addr and the stake that addr votes with at block or at the latest block if
+// block is nil. An orchestrator votes with the total stake delegated to it and a delegator with its pending stake
+func (c *client) VotingStake(addr ethcommon.Address, block *big.Int) (ethcommon.Address, *big.Int, error) {
+	d, err := c.bondingManager.GetDelegator(c.callOptsAt(block), addr)
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+
+	if d.DelegateAddress == addr {
+		stake, err := c.bondingManager.TranscoderTotalStake(c.callOptsAt(block), addr)
+		if err != nil {
+			return ethcommon.Address{}, nil, err
+		}
+		return addr, stake, nil
+	}
+
+	round, err := c.roundsManager.CurrentRound(c.callOptsAt(block))
+	if err != nil {
+		return ethcommon.Address{}, nil, err
+	}
+	stake, err := c.bondingManager.PendingStake(c.callOptsAt(block), addr, round)
+	if err != nil {
+		if err.Error() != "abi: unmarshalling empty output" {
+			return ethcommon.Address{}, nil, err
+		}
+		// The pending stake cannot be computed so fall back to the bonded amount
+		stake = d.BondedAmount
+	}
+
+	return d.DelegateAddress, stake, nil
+}
+
 func (c *client) PendingStake(delegator ethcommon.Address, endRound *big.Int) (*big.Int, error) {
 	return c.bondingManager.PendingStake(c.callOpts(), delegator, endRound)
 }
@@ -987,6 +1035,12 @@ func (c *client) Vote(pollAddr ethcommon.Address, choiceID *big.Int) (*types.Tra
 }
 
 func (c *client) Reward() (*types.Transaction, error) {
+	return c.RewardWithGasPrice(nil)
+}
+
+// RewardWithGasPrice calls reward with the given gas price, capped at the max gas price of the node.
+// The default gas price is used if gasPrice is nil
+func (c *client) RewardWithGasPrice(gasPrice *big.Int) (*types.Transaction, error) {
 	addr := c.accountManager.Account().Address
 
 	tr, err := c.GetTranscoder(addr)
@@ -1031,7 +1085,73 @@ func (c *client) Reward() (*types.Transaction, error) {
 
 	hints := simulateTranscoderPoolUpdate(addr, reward.Add(reward, tr.DelegatedStake), transcoders, len(transcoders) == int(maxSize.Int64()))
 
-	return c.bondingManager.RewardWithHint(c.transactOpts(), hints.PosPrev, hints.PosNext)
+	opts := c.transactOpts()
+	if gasPrice != nil {
+		if err := c.setGasPrice(opts, gasPrice); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.bondingManager.RewardWithHint(opts, hints.PosPrev, hints.PosNext)
+}
+
+// setGasPrice sets the gas price of a transaction, capped at the max gas price of the node
+func (c *client) setGasPrice(opts *bind.TransactOpts, gasPrice *big.Int) error {
+	c.transOptsMu.RLock()
+	maxGasPrice := c.transOpts.GasPrice
+	if maxGasPrice == nil {
+		maxGasPrice = c.transOpts.GasFeeCap
+	}
+	c.transOptsMu.RUnlock()
+
+	ctx := context.Background()
+	head, err := c.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if head.BaseFee == nil {
+		// legacy tx, not London ready
+		if maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+			gasPrice = maxGasPrice
+		}
+		opts.GasPrice = gasPrice
+		return nil
+	}
+
+	// dynamic tx
+	tip, err := c.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return err
+	}
+	var suggested *big.Int
+	if gpm := c.backend.GasPriceMonitor(); gpm != nil {
+		suggested = gpm.GasPrice()
+	}
+	opts.GasPrice = nil
+	opts.GasFeeCap, opts.GasTipCap = dynamicFeeCaps(gasPrice, suggested, tip, maxGasPrice)
+	return nil
+}
+
+// dynamicFeeCaps returns the fee cap and the tip cap of a dynamic tx with the given gas price. The suggested tip is
+// raised by the same factor as gasPrice is above the suggested gas price, so that a bumped replacement also raises
+// its tip. The fee cap is capped at maxGasPrice and the tip at the fee cap.
+func dynamicFeeCaps(gasPrice, suggestedGasPrice, suggestedTip, maxGasPrice *big.Int) (*big.Int, *big.Int) {
+	tip := new(big.Int).Set(suggestedTip)
+	if suggestedGasPrice != nil && suggestedGasPrice.Sign() > 0 && gasPrice.Cmp(suggestedGasPrice) > 0 {
+		// round up so that the tip is raised by at least the bump
+		tip.Mul(tip, gasPrice)
+		tip.Add(tip, new(big.Int).Sub(suggestedGasPrice, big.NewInt(1)))
+		tip.Div(tip, suggestedGasPrice)
+	}
+
+	feeCap := gasPrice
+	if maxGasPrice != nil && feeCap.Cmp(maxGasPrice) > 0 {
+		feeCap = maxGasPrice
+	}
+	if tip.Cmp(feeCap) > 0 {
+		tip = new(big.Int).Set(feeCap)
+	}
+	return feeCap, tip
 }
 
 func (c *client) WithdrawFees(addr ethcommon.Address, amount *big.Int) (*types.Transaction, error) {
@@ -161,3 +161,36 @@ func TestFindTranscoderHints(t *testing.T) {
 	assert.Equal(hints.PosPrev, ethcommon.HexToAddress("bbb"))
 	assert.Equal(hints.PosNext, ethcommon.HexToAddress("ddd"))
 }
+
+func TestDynamicFeeCaps(t *testing.T) {
+	assert := assert.New(t)
+
+	// Without a bump the suggested tip is used
+	feeCap, tip := dynamicFeeCaps(big.NewInt(100), big.NewInt(100), big.NewInt(10), nil)
+	assert.Equal(big.NewInt(100), feeCap)
+	assert.Equal(big.NewInt(10), tip)
+
+	// A bumped gas price raises the tip by the same factor
+	feeCap, tip = dynamicFeeCaps(big.NewInt(110), big.NewInt(100), big.NewInt(10), nil)
+	assert.Equal(big.NewInt(110), feeCap)
+	assert.Equal(big.NewInt(11), tip)
+
+	// rounded up
+	feeCap, tip = dynamicFeeCaps(big.NewInt(110), big.NewInt(100), big.NewInt(3), nil)
+	assert.Equal(big.NewInt(110), feeCap)
+	assert.Equal(big.NewInt(4), tip)
+
+	// The fee cap is capped at the max gas price and the tip at the fee cap
+	feeCap, tip = dynamicFeeCaps(big.NewInt(200), big.NewInt(100), big.NewInt(60), big.NewInt(120))
+	assert.Equal(big.NewInt(120), feeCap)
+	assert.Equal(big.NewInt(120), tip)
+
+	feeCap, tip = dynamicFeeCaps(big.NewInt(50), big.NewInt(100), big.NewInt(80), big.NewInt(120))
+	assert.Equal(big.NewInt(50), feeCap)
+	assert.Equal(big.NewInt(50), tip)
+
+	// Without a suggested gas price the suggested tip is kept
+	feeCap, tip = dynamicFeeCaps(big.NewInt(110), nil, big.NewInt(10), nil)
+	assert.Equal(big.NewInt(110), feeCap)
+	assert.Equal(big.NewInt(10), tip)
+}
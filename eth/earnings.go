@@ -0,0 +1,285 @@
+package eth
+
+import (
+	"errors"
+	"math"
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+)
+
+// Number of L1 blocks per year assuming 12 second blocks
+const blocksPerYear = 2628000
+
+// MaxEarningsRounds is the maximum number of rounds covered by an earnings history or a yield projection
+const MaxEarningsRounds = 100
+
+var (
+	// cumulativeFactorDivisor is the precision of the cumulative reward and fee factors of earnings pools
+	cumulativeFactorDivisor = new(big.Int).Exp(big.NewInt(10), big.NewInt(27), nil)
+
+	ErrInvalidRoundRange = errors.New("invalid round range")
+)
+
+// DelegatorRoundEarnings is the reward and fee accrual of a delegator for a single round
+type DelegatorRoundEarnings struct {
+	Round *big.Int
+	// Stake at the end of the round
+	Stake  *big.Int
+	Reward *big.Int
+	Fees   *big.Int
+	// RewardCalled is true if the delegate called reward in the round
+	RewardCalled bool
+}
+
+// DelegatorEarningsHistory is the reward and fee accrual of a delegator over a range of rounds
+type DelegatorEarningsHistory struct {
+	Delegator   ethcommon.Address
+	Delegate    ethcommon.Address
+	StartRound  *big.Int
+	EndRound    *big.Int
+	TotalReward *big.Int
+	TotalFees   *big.Int
+	Rounds      []*DelegatorRoundEarnings
+}
+
+// OrchestratorYield is the projected annualized yield of delegating to an orchestrator
+type OrchestratorYield struct {
+	Address        ethcommon.Address
+	RewardCut      *big.Int
+	FeeShare       *big.Int
+	DelegatedStake *big.Int
+	// RewardCallRate is the fraction of the sampled rounds in which the orchestrator called reward
+	RewardCallRate float64
+	// RewardYield is the projected annual percentage increase of the stake of a delegator from rewards
+	RewardYield float64
+	// FeeYield is the projected amount of ETH earned per year for each LPT delegated
+	FeeYield float64
+	Rounds   int
+}
+
+// cumulativeFactors are the cumulative reward and fee factors of an earnings pool
+type cumulativeFactors struct {
+	reward *big.Int
+	fees   *big.Int
+}
+
+// earningsPoolFactors returns the cumulative factors for the earnings pools of addr for the rounds [start, end].
+// The pools for rounds in which reward was not called and no fees were received do not store factors, so the factors
+// of the latest preceding round are used for them
+func earningsPoolFactors(client LivepeerEthClient, addr ethcommon.Address, start, end int64) ([]*cumulativeFactors, []bool, error) {
+	var prev *cumulativeFactors
+	// Look back for the factors preceding the range
+	for round := start - 1; round >= 0 && round >= start-MaxEarningsRounds; round-- {
+		tp, err := client.GetTranscoderEarningsPoolForRound(addr, big.NewInt(round))
+		if err != nil {
+			return nil, nil, err
+		}
+		if tp.CumulativeRewardFactor != nil && tp.CumulativeRewardFactor.Sign() > 0 {
+			prev = &cumulativeFactors{reward: tp.CumulativeRewardFactor, fees: tp.CumulativeFeeFactor}
+			break
+		}
+	}
+	if prev == nil {
+		prev = &cumulativeFactors{reward: cumulativeFactorDivisor, fees: big.NewInt(0)}
+	}
+
+	factors := []*cumulativeFactors{prev}
+	updated := []bool{false}
+	for round := start; round <= end; round++ {
+		tp, err := client.GetTranscoderEarningsPoolForRound(addr, big.NewInt(round))
+		if err != nil {
+			return nil, nil, err
+		}
+		if tp.CumulativeRewardFactor == nil || tp.CumulativeRewardFactor.Sign() == 0 {
+			factors = append(factors, prev)
+			updated = append(updated, false)
+			continue
+		}
+
+		cur := &cumulativeFactors{reward: tp.CumulativeRewardFactor, fees: tp.CumulativeFeeFactor}
+		if cur.fees == nil {
+			cur.fees = prev.fees
+		}
+		updated = append(updated, cur.reward.Cmp(prev.reward) > 0)
+		factors = append(factors, cur)
+		prev = cur
+	}
+
+	return factors, updated, nil
+}
+
+// DelegatorEarnings computes the per-round reward and fee accrual of delegator for the rounds [startRound, endRound]
+// from the earnings pools of its delegate. Rounds before the bond round of the delegator are not included. The accrual
+// is derived from the stake of the delegator at its last claim round, which assumes that the stake only changed
+// through rewards since the bond round
+func DelegatorEarnings(client LivepeerEthClient, delegator ethcommon.Address, startRound, endRound *big.Int) (*DelegatorEarningsHistory, error) {
+	if startRound == nil || endRound == nil || startRound.Cmp(endRound) > 0 {
+		return nil, ErrInvalidRoundRange
+	}
+
+	d, err := client.GetDelegator(delegator)
+	if err != nil {
+		return nil, err
+	}
+
+	start := startRound.Int64()
+	if d.StartRound != nil && d.StartRound.Int64() > start {
+		start = d.StartRound.Int64()
+	}
+	end := endRound.Int64()
+
+	history := &DelegatorEarningsHistory{
+		Delegator:   delegator,
+		Delegate:    d.DelegateAddress,
+		StartRound:  big.NewInt(start),
+		EndRound:    endRound,
+		TotalReward: big.NewInt(0),
+		TotalFees:   big.NewInt(0),
+		Rounds:      []*DelegatorRoundEarnings{},
+	}
+	// A delegator that never bonded does not have a last claim round
+	if start > end || IsNullAddress(d.DelegateAddress) || d.BondedAmount == nil || d.BondedAmount.Sign() == 0 || d.LastClaimRound == nil {
+		return history, nil
+	}
+	if end-start+1 > MaxEarningsRounds {
+		return nil, ErrInvalidRoundRange
+	}
+
+	// The bonded amount of the delegator is its stake as of the last claim round
+	claimFactors, _, err := earningsPoolFactors(client, d.DelegateAddress, d.LastClaimRound.Int64(), d.LastClaimRound.Int64())
+	if err != nil {
+		return nil, err
+	}
+	claim := claimFactors[len(claimFactors)-1]
+
+	factors, updated, err := earningsPoolFactors(client, d.DelegateAddress, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	stakeAt := func(f *cumulativeFactors) *big.Int {
+		return percOf(d.BondedAmount, f.reward, claim.reward)
+	}
+
+	for i := 1; i < len(factors); i++ {
+		stake := stakeAt(factors[i])
+		reward := new(big.Int).Sub(stake, stakeAt(factors[i-1]))
+		fees := percOf(d.BondedAmount, new(big.Int).Sub(factors[i].fees, factors[i-1].fees), claim.reward)
+
+		history.Rounds = append(history.Rounds, &DelegatorRoundEarnings{
+			Round:        big.NewInt(start + int64(i-1)),
+			Stake:        stake,
+			Reward:       reward,
+			Fees:         fees,
+			RewardCalled: updated[i],
+		})
+		history.TotalReward.Add(history.TotalReward, reward)
+		history.TotalFees.Add(history.TotalFees, fees)
+	}
+
+	return history, nil
+}
+
+// YieldParams are the protocol-wide values used to project the yields of all orchestrators in a round
+type YieldParams struct {
+	RoundLength *big.Int
+	TotalSupply *big.Int
+	Inflation   *big.Int
+	TotalBonded *big.Int
+}
+
+// FetchYieldParams fetches the protocol-wide values used to project orchestrator yields
+func FetchYieldParams(client LivepeerEthClient) (*YieldParams, error) {
+	roundLength, err := client.RoundLength()
+	if err != nil {
+		return nil, err
+	}
+	totalSupply, err := client.TotalSupply()
+	if err != nil {
+		return nil, err
+	}
+	inflation, err := client.Inflation()
+	if err != nil {
+		return nil, err
+	}
+	totalBonded, err := client.GetTotalBonded()
+	if err != nil {
+		return nil, err
+	}
+	return &YieldParams{
+		RoundLength: roundLength,
+		TotalSupply: totalSupply,
+		Inflation:   inflation,
+		TotalBonded: totalBonded,
+	}, nil
+}
+
+// ProjectOrchestratorYield estimates the annualized yield of delegating to orchestrator t based on its reward cut and
+// the fraction of the last rounds before currentRound in which it called reward. The fee yield is extrapolated from the
+// fees received by delegators in those rounds
+func ProjectOrchestratorYield(client LivepeerEthClient, params *YieldParams, t *lpTypes.Transcoder, currentRound *big.Int, rounds int) (*OrchestratorYield, error) {
+	if rounds <= 0 || rounds > MaxEarningsRounds {
+		return nil, ErrInvalidRoundRange
+	}
+
+	end := currentRound.Int64() - 1
+	start := end - int64(rounds) + 1
+	if start < 1 {
+		start = 1
+	}
+
+	y := &OrchestratorYield{
+		Address:        t.Address,
+		RewardCut:      t.RewardCut,
+		FeeShare:       t.FeeShare,
+		DelegatedStake: t.DelegatedStake,
+	}
+	if start > end {
+		return y, nil
+	}
+
+	factors, updated, err := earningsPoolFactors(client, t.Address, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	called := 0
+	for _, u := range updated[1:] {
+		if u {
+			called++
+		}
+	}
+	y.Rounds = len(updated) - 1
+	y.RewardCallRate = float64(called) / float64(y.Rounds)
+
+	if params.RoundLength.Sign() == 0 {
+		return y, nil
+	}
+	roundsPerYear := float64(blocksPerYear) / float64(params.RoundLength.Int64())
+
+	// Rewards minted in a round are distributed pro-rata to the total bonded stake
+	if params.TotalBonded.Sign() > 0 {
+		mintable := new(big.Float).Quo(new(big.Float).SetInt(new(big.Int).Mul(params.TotalSupply, params.Inflation)), big.NewFloat(percDivisorMinter))
+		perStake, _ := new(big.Float).Quo(mintable, new(big.Float).SetInt(params.TotalBonded)).Float64()
+		delegatorShare := 1 - ToPerc(t.RewardCut)/100
+		perRound := perStake * delegatorShare * y.RewardCallRate
+		y.RewardYield = (math.Pow(1+perRound, roundsPerYear) - 1) * 100
+	}
+
+	// Fees received per unit of stake over the sampled rounds
+	first, last := factors[0], factors[len(factors)-1]
+	feesPerStake, _ := new(big.Float).Quo(
+		new(big.Float).SetInt(new(big.Int).Sub(last.fees, first.fees)),
+		new(big.Float).SetInt(first.reward),
+	).Float64()
+	y.FeeYield = feesPerStake * roundsPerYear / float64(y.Rounds)
+
+	return y, nil
+}
+
+// percOf returns a * b / c
+func percOf(a, b, c *big.Int) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(a, b), c)
+}
@@ -0,0 +1,187 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type earningsStubClient struct {
+	StubClient
+	delegator   *lpTypes.Delegator
+	pools       map[int64]*lpTypes.TokenPools
+	poolErr     error
+	roundLength *big.Int
+	totalSupply *big.Int
+	inflation   *big.Int
+	totalBonded *big.Int
+}
+
+func (c *earningsStubClient) GetDelegator(addr ethcommon.Address) (*lpTypes.Delegator, error) {
+	return c.delegator, nil
+}
+
+func (c *earningsStubClient) GetTranscoderEarningsPoolForRound(addr ethcommon.Address, round *big.Int) (*lpTypes.TokenPools, error) {
+	if c.poolErr != nil {
+		return nil, c.poolErr
+	}
+	if tp, ok := c.pools[round.Int64()]; ok {
+		return tp, nil
+	}
+	return &lpTypes.TokenPools{CumulativeRewardFactor: big.NewInt(0), CumulativeFeeFactor: big.NewInt(0)}, nil
+}
+
+func (c *earningsStubClient) RoundLength() (*big.Int, error)    { return c.roundLength, nil }
+func (c *earningsStubClient) TotalSupply() (*big.Int, error)    { return c.totalSupply, nil }
+func (c *earningsStubClient) Inflation() (*big.Int, error)      { return c.inflation, nil }
+func (c *earningsStubClient) GetTotalBonded() (*big.Int, error) { return c.totalBonded, nil }
+
+// factor returns f * 10^27 / 100
+func factor(f int64) *big.Int {
+	return new(big.Int).Div(new(big.Int).Mul(big.NewInt(f), cumulativeFactorDivisor), big.NewInt(100))
+}
+
+func newEarningsStubClient(delegate ethcommon.Address) *earningsStubClient {
+	return &earningsStubClient{
+		delegator: &lpTypes.Delegator{
+			BondedAmount:    big.NewInt(1100),
+			DelegateAddress: delegate,
+			StartRound:      big.NewInt(10),
+			LastClaimRound:  big.NewInt(11),
+		},
+		pools: map[int64]*lpTypes.TokenPools{
+			10: {CumulativeRewardFactor: factor(100), CumulativeFeeFactor: big.NewInt(0)},
+			11: {CumulativeRewardFactor: factor(110), CumulativeFeeFactor: factor(50)},
+			// Reward not called in round 12
+			13: {CumulativeRewardFactor: factor(121), CumulativeFeeFactor: factor(60)},
+		},
+		// One round per year
+		roundLength: big.NewInt(blocksPerYear),
+		totalSupply: big.NewInt(1000),
+		// 10% inflation
+		inflation:   big.NewInt(100000000),
+		totalBonded: big.NewInt(1000),
+	}
+}
+
+func TestDelegatorEarnings(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	delegate := pm.RandAddress()
+	client := newEarningsStubClient(delegate)
+
+	// Rounds before the bond round are skipped and rounds up to the last claim round are included
+	history, err := DelegatorEarnings(client, pm.RandAddress(), big.NewInt(5), big.NewInt(13))
+	require.Nil(err)
+	assert.Equal(delegate, history.Delegate)
+	assert.Equal(big.NewInt(10), history.StartRound)
+	require.Len(history.Rounds, 4)
+
+	r := history.Rounds[0]
+	assert.Equal(big.NewInt(10), r.Round)
+	assert.Equal(big.NewInt(1000), r.Stake)
+	assert.Zero(r.Reward.Sign())
+	assert.Zero(r.Fees.Sign())
+	assert.False(r.RewardCalled)
+
+	r = history.Rounds[1]
+	assert.Equal(big.NewInt(11), r.Round)
+	assert.Equal(big.NewInt(1100), r.Stake)
+	assert.Equal(big.NewInt(100), r.Reward)
+	assert.Equal(big.NewInt(500), r.Fees)
+	assert.True(r.RewardCalled)
+
+	r = history.Rounds[2]
+	assert.Equal(big.NewInt(12), r.Round)
+	assert.Equal(big.NewInt(1100), r.Stake)
+	assert.Zero(r.Reward.Sign())
+	assert.Zero(r.Fees.Sign())
+	assert.False(r.RewardCalled)
+
+	r = history.Rounds[3]
+	assert.Equal(big.NewInt(13), r.Round)
+	assert.Equal(big.NewInt(1210), r.Stake)
+	assert.Equal(big.NewInt(110), r.Reward)
+	assert.Equal(big.NewInt(100), r.Fees)
+	assert.True(r.RewardCalled)
+
+	assert.Equal(big.NewInt(210), history.TotalReward)
+	assert.Equal(big.NewInt(600), history.TotalFees)
+
+	// Only rounds before the bond round
+	history, err = DelegatorEarnings(client, pm.RandAddress(), big.NewInt(5), big.NewInt(9))
+	require.Nil(err)
+	assert.Empty(history.Rounds)
+
+	// Delegator that never bonded
+	client.delegator = &lpTypes.Delegator{BondedAmount: big.NewInt(1000), DelegateAddress: delegate}
+	history, err = DelegatorEarnings(client, pm.RandAddress(), big.NewInt(11), big.NewInt(13))
+	require.Nil(err)
+	assert.Empty(history.Rounds)
+	assert.Equal(big.NewInt(11), history.StartRound)
+
+	// Unbonded delegator
+	client.delegator = newEarningsStubClient(delegate).delegator
+	client.delegator.BondedAmount = big.NewInt(0)
+	history, err = DelegatorEarnings(client, pm.RandAddress(), big.NewInt(11), big.NewInt(13))
+	require.Nil(err)
+	assert.Empty(history.Rounds)
+	assert.Equal(big.NewInt(0), history.TotalReward)
+}
+
+func TestDelegatorEarnings_Errors(t *testing.T) {
+	assert := assert.New(t)
+
+	client := newEarningsStubClient(pm.RandAddress())
+
+	_, err := DelegatorEarnings(client, pm.RandAddress(), big.NewInt(13), big.NewInt(11))
+	assert.Equal(ErrInvalidRoundRange, err)
+
+	_, err = DelegatorEarnings(client, pm.RandAddress(), big.NewInt(11), big.NewInt(11+MaxEarningsRounds))
+	assert.Equal(ErrInvalidRoundRange, err)
+
+	client.poolErr = errors.New("pool error")
+	_, err = DelegatorEarnings(client, pm.RandAddress(), big.NewInt(11), big.NewInt(13))
+	assert.EqualError(err, "pool error")
+}
+
+func TestProjectOrchestratorYield(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	orch := &lpTypes.Transcoder{
+		Address:        pm.RandAddress(),
+		RewardCut:      FromPerc(10),
+		FeeShare:       FromPerc(50),
+		DelegatedStake: big.NewInt(1000),
+	}
+	client := newEarningsStubClient(orch.Address)
+	params, err := FetchYieldParams(client)
+	require.Nil(err)
+
+	y, err := ProjectOrchestratorYield(client, params, orch, big.NewInt(14), 3)
+	require.Nil(err)
+	assert.Equal(orch.Address, y.Address)
+	assert.Equal(3, y.Rounds)
+	assert.InDelta(2.0/3.0, y.RewardCallRate, 1e-9)
+	// 10% of the supply is minted per round, 90% goes to delegators and reward is called in 2/3 of the rounds
+	assert.InDelta(6.0, y.RewardYield, 1e-9)
+	// 0.6 ETH per LPT over 3 rounds
+	assert.InDelta(0.2, y.FeeYield, 1e-9)
+
+	_, err = ProjectOrchestratorYield(client, params, orch, big.NewInt(14), 0)
+	assert.Equal(ErrInvalidRoundRange, err)
+
+	// No completed rounds
+	y, err = ProjectOrchestratorYield(client, params, orch, big.NewInt(1), 3)
+	require.Nil(err)
+	assert.Equal(0, y.Rounds)
+	assert.Equal(0.0, y.RewardYield)
+}
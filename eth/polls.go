@@ -0,0 +1,130 @@
+package eth
+
+import (
+	"math/big"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	lpTypes "github.com/livepeer/go-livepeer/eth/types"
+)
+
+const (
+	PollActive   = "active"
+	PollPassed   = "passed"
+	PollRejected = "rejected"
+)
+
+// PollInfo is the state of a poll and the tally of the votes cast in it
+type PollInfo struct {
+	Address        ethcommon.Address
+	Proposal       string
+	EndBlock       int64
+	Quorum         int64
+	Quota          int64
+	Status         string
+	Yes            *big.Int
+	No             *big.Int
+	TotalVoteStake *big.Int
+	Voters         int
+	// Estimate is set if the tally is weighted by the current stake of the voters instead of their stake at the end
+	// of the poll, which is the case for active polls and for polls tallied without the historical state of the chain
+	Estimate bool
+	// Vote is the choice of the node's account or nil if it did not vote
+	Vote *int64
+}
+
+// PollTally is the stake weighted tally of the votes cast in a poll
+type PollTally struct {
+	Yes *big.Int
+	No  *big.Int
+	// Voters is the number of accounts that voted for a valid choice
+	Voters int
+}
+
+// Total returns the stake that voted in the poll
+func (t *PollTally) Total() *big.Int {
+	return new(big.Int).Add(t.Yes, t.No)
+}
+
+// TallyPoll weights the votes cast in a poll by the stake of the voters at block or by their current stake if block is
+// nil. The final tally of a poll is taken by the PollWatcher at the last block before the L1 block passed the end block
+// of the poll. An orchestrator votes with its total delegated stake, but the vote of a delegator overrides the vote of
+// its orchestrator for the stake of the delegator
+func TallyPoll(client LivepeerEthClient, votes []*common.DBPollVote, block *big.Int) (*PollTally, error) {
+	choices := make(map[ethcommon.Address]lpTypes.VoteChoice)
+	for _, v := range votes {
+		if block != nil && v.Block > block.Int64() {
+			continue
+		}
+		choice := lpTypes.VoteChoice(v.ChoiceID)
+		if choice.IsValid() {
+			choices[v.Voter] = choice
+		}
+	}
+
+	weights := make(map[ethcommon.Address]*big.Int)
+	overrides := make(map[ethcommon.Address]*big.Int)
+	for voter := range choices {
+		delegate, stake, err := client.VotingStake(voter, block)
+		if err != nil {
+			return nil, err
+		}
+		if stake == nil || stake.Sign() < 0 {
+			stake = big.NewInt(0)
+		}
+		weights[voter] = stake
+
+		if delegate == voter {
+			continue
+		}
+		if _, ok := choices[delegate]; ok && stake.Sign() > 0 {
+			if overrides[delegate] == nil {
+				overrides[delegate] = big.NewInt(0)
+			}
+			overrides[delegate].Add(overrides[delegate], stake)
+		}
+	}
+
+	tally := &PollTally{Yes: big.NewInt(0), No: big.NewInt(0)}
+	for voter, choice := range choices {
+		weight := weights[voter]
+		if o, ok := overrides[voter]; ok {
+			weight = new(big.Int).Sub(weight, o)
+			if weight.Sign() < 0 {
+				weight = big.NewInt(0)
+			}
+		}
+
+		switch choice {
+		case lpTypes.Yes:
+			tally.Yes.Add(tally.Yes, weight)
+		case lpTypes.No:
+			tally.No.Add(tally.No, weight)
+		}
+		tally.Voters++
+	}
+
+	return tally, nil
+}
+
+// PollStatus returns whether a poll is still active at the L1 block currentBlock and otherwise whether it passed.
+// A poll passes if the stake that voted reaches the quorum of totalBonded and the stake that voted "Yes" exceeds the
+// quota of the stake that voted
+func PollStatus(poll *common.DBPoll, tally *PollTally, totalBonded, currentBlock *big.Int) string {
+	if currentBlock != nil && currentBlock.Int64() <= poll.EndBlock {
+		return PollActive
+	}
+
+	total := tally.Total()
+	quorum := percOf(totalBonded, big.NewInt(poll.Quorum), big.NewInt(percDivisor))
+	if total.Sign() == 0 || total.Cmp(quorum) < 0 {
+		return PollRejected
+	}
+
+	quota := percOf(total, big.NewInt(poll.Quota), big.NewInt(percDivisor))
+	if tally.Yes.Cmp(quota) <= 0 {
+		return PollRejected
+	}
+
+	return PollPassed
+}
@@ -0,0 +1,92 @@
+package eth
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTallyPoll(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	orch := pm.RandAddress()
+	delegator := pm.RandAddress()
+	otherDelegator := pm.RandAddress()
+	otherOrch := pm.RandAddress()
+
+	block := big.NewInt(1000)
+	client := &MockClient{}
+	client.On("VotingStake", orch, block).Return(orch, big.NewInt(1000), nil)
+	client.On("VotingStake", delegator, block).Return(orch, big.NewInt(300), nil)
+	client.On("VotingStake", otherDelegator, block).Return(otherOrch, big.NewInt(50), nil)
+
+	votes := []*common.DBPollVote{
+		{Voter: orch, ChoiceID: 0, Block: 900},
+		{Voter: delegator, ChoiceID: 1, Block: 910},
+		{Voter: otherDelegator, ChoiceID: 0, Block: 1000},
+		// Invalid choices are not counted
+		{Voter: otherOrch, ChoiceID: 5, Block: 920},
+	}
+
+	tally, err := TallyPoll(client, votes, block)
+	require.Nil(err)
+	// The vote of the delegator overrides the vote of its orchestrator for its stake
+	assert.Equal(big.NewInt(750), tally.Yes)
+	assert.Equal(big.NewInt(300), tally.No)
+	assert.Equal(big.NewInt(1050), tally.Total())
+	assert.Equal(3, tally.Voters)
+
+	// Votes cast after the block are not counted
+	tally, err = TallyPoll(client, append(votes, &common.DBPollVote{Voter: delegator, ChoiceID: 0, Block: 1001}), block)
+	require.Nil(err)
+	assert.Equal(big.NewInt(750), tally.Yes)
+	assert.Equal(big.NewInt(300), tally.No)
+
+	// The current stake is used without a block
+	client.On("VotingStake", orch, (*big.Int)(nil)).Return(orch, big.NewInt(2000), nil)
+	tally, err = TallyPoll(client, votes[:1], nil)
+	require.Nil(err)
+	assert.Equal(big.NewInt(2000), tally.Yes)
+
+	// No votes
+	tally, err = TallyPoll(client, nil, block)
+	require.Nil(err)
+	assert.Zero(tally.Total().Sign())
+	assert.Equal(0, tally.Voters)
+
+	errClient := &MockClient{}
+	errClient.On("VotingStake", orch, block).Return(ethcommon.Address{}, nil, errors.New("VotingStake error"))
+	_, err = TallyPoll(errClient, votes[:1], block)
+	assert.EqualError(err, "VotingStake error")
+}
+
+func TestPollStatus(t *testing.T) {
+	assert := assert.New(t)
+
+	// 33.33% quorum and 50% quota
+	poll := &common.DBPoll{EndBlock: 100, Quorum: 333300, Quota: 500000}
+	totalBonded := big.NewInt(1000)
+	tally := &PollTally{Yes: big.NewInt(300), No: big.NewInt(100)}
+
+	assert.Equal(PollActive, PollStatus(poll, tally, totalBonded, big.NewInt(100)))
+	assert.Equal(PollPassed, PollStatus(poll, tally, totalBonded, big.NewInt(101)))
+
+	// Quorum not reached
+	tally = &PollTally{Yes: big.NewInt(300), No: big.NewInt(0)}
+	assert.Equal(PollRejected, PollStatus(poll, tally, totalBonded, big.NewInt(101)))
+
+	// Quota not exceeded
+	tally = &PollTally{Yes: big.NewInt(200), No: big.NewInt(200)}
+	assert.Equal(PollRejected, PollStatus(poll, tally, totalBonded, big.NewInt(101)))
+
+	// No votes
+	tally = &PollTally{Yes: big.NewInt(0), No: big.NewInt(0)}
+	assert.Equal(PollRejected, PollStatus(poll, tally, big.NewInt(0), big.NewInt(101)))
+}
@@ -3,10 +3,14 @@ package eth
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/monitor"
 )
 
@@ -15,19 +19,86 @@ var (
 	ErrRewardServiceStopped = fmt.Errorf("reward service already stopped")
 )
 
+// RewardStatus describes the outcome of the reward call for a round
+type RewardStatus string
+
+const (
+	// RewardCalled indicates that reward was called before the deadline of the round
+	RewardCalled RewardStatus = "called"
+	// RewardLate indicates that reward was called after the deadline of the round
+	RewardLate RewardStatus = "late"
+	// RewardFailed indicates that all attempts to call reward for the round failed
+	RewardFailed RewardStatus = "failed"
+	// RewardMissed indicates that the round ended without a successful reward call
+	RewardMissed RewardStatus = "missed"
+	// RewardSkipped indicates that reward was not called because the node was not in the active set
+	RewardSkipped RewardStatus = "skipped"
+)
+
+// RewardEvent is emitted by the RewardService whenever the outcome of the reward call for a round changes
+type RewardEvent struct {
+	Round    *big.Int
+	Status   RewardStatus
+	Block    *big.Int
+	TxHash   ethcommon.Hash
+	Attempts int
+	Error    string
+}
+
+// RewardPolicy configures when the RewardService submits reward transactions.
+// The zero value calls reward as soon as a new round is detected without retries
+type RewardPolicy struct {
+	// MaxGasPrice is the gas price above which the reward call is deferred until the deadline. Nil disables waiting for gas
+	MaxGasPrice *big.Int
+	// DeadlineBlocks is the number of L1 blocks after the start of the round after which reward is called regardless of the gas price
+	DeadlineBlocks *big.Int
+	// MaxRetries is the number of times a failed reward call is retried within a round
+	MaxRetries int
+	// GasPriceBump is the percentage by which the gas price of the reward transaction is raised on each retry
+	GasPriceBump uint64
+}
+
+type gasPriceGetter interface {
+	GasPrice() *big.Int
+}
+
+// rewardRound tracks the progress of the reward call for a single round
+type rewardRound struct {
+	number      *big.Int
+	deadline    *big.Int
+	maxGasPrice *big.Int
+	gasPrice    *big.Int
+	attempts    int
+	lastErr     string
+	active      bool
+	done        bool
+	rewarded    bool
+}
+
 type RewardService struct {
 	client       LivepeerEthClient
 	working      bool
 	cancelWorker context.CancelFunc
 	tw           timeWatcher
+	db           *common.DB
+	gpm          gasPriceGetter
+	policy       RewardPolicy
+	round        *rewardRound
+	feed         event.Feed
 	mu           sync.Mutex
 }
 
-func NewRewardService(client LivepeerEthClient, tw timeWatcher) *RewardService {
-	return &RewardService{
+func NewRewardService(client LivepeerEthClient, tw timeWatcher, db *common.DB, policy RewardPolicy) *RewardService {
+	s := &RewardService{
 		client: client,
 		tw:     tw,
+		db:     db,
+		policy: policy,
+	}
+	if client != nil && client.Backend() != nil {
+		s.gpm = client.Backend().GasPriceMonitor()
 	}
+	return s
 }
 
 func (s *RewardService) Start(ctx context.Context) error {
@@ -42,27 +113,46 @@ func (s *RewardService) Start(ctx context.Context) error {
 	sub := s.tw.SubscribeRounds(roundSink)
 	defer sub.Unsubscribe()
 
+	l1BlockSink := make(chan *big.Int, 10)
+	l1BlockSub := s.tw.SubscribeL1Blocks(l1BlockSink)
+	defer l1BlockSub.Unsubscribe()
+
 	s.working = true
 	defer func() {
 		s.working = false
 	}()
 
+	// Track the current round so that a node started mid-round still calls reward on the next L1 block
+	if s.tw.LastInitializedRound() != nil {
+		s.startRound()
+	}
+
+	// Reward calls are made by a single worker so that a pending transaction is not resubmitted
+	// concurrently. Events that arrive while a call is in flight are coalesced
+	newRound := make(chan struct{}, 1)
+	newBlock := make(chan struct{}, 1)
+	go s.rewardWorker(cancelCtx, newRound, newBlock)
+
 	for {
 		select {
 		case err := <-sub.Err():
 			if err != nil {
 				glog.Errorf("Round subscription error err=%q", err)
 			}
+		case err := <-l1BlockSub.Err():
+			if err != nil {
+				glog.Errorf("L1 Block subscription error err=%q", err)
+			}
 		case <-roundSink:
-			go func() {
-				err := s.tryReward()
-				if err != nil {
-					glog.Errorf("Error trying to call reward for round %v err=%q", s.tw.LastInitializedRound(), err)
-					if monitor.Enabled {
-						monitor.RewardCallError(err.Error())
-					}
-				}
-			}()
+			select {
+			case newRound <- struct{}{}:
+			default:
+			}
+		case <-l1BlockSink:
+			select {
+			case newBlock <- struct{}{}:
+			default:
+			}
 		case <-cancelCtx.Done():
 			glog.V(5).Infof("Reward service done")
 			return nil
@@ -85,31 +175,180 @@ func (s *RewardService) IsWorking() bool {
 	return s.working
 }
 
+// SubscribeRewardEvents subscribes to the outcomes of reward calls
+func (s *RewardService) SubscribeRewardEvents(sink chan<- *RewardEvent) event.Subscription {
+	return s.feed.Subscribe(sink)
+}
+
+func (s *RewardService) rewardWorker(ctx context.Context, newRound, newBlock <-chan struct{}) {
+	for {
+		select {
+		case <-newRound:
+			s.startRound()
+		case <-newBlock:
+		case <-ctx.Done():
+			return
+		}
+		s.handleRewardErr(s.tryReward())
+	}
+}
+
+func (s *RewardService) handleRewardErr(err error) {
+	if err == nil {
+		return
+	}
+	glog.Errorf("Error trying to call reward for round %v err=%q", s.tw.LastInitializedRound(), err)
+	if monitor.Enabled {
+		monitor.RewardCallError(err.Error())
+	}
+}
+
+// startRound begins tracking the reward call for the last initialized round. If the previous round
+// ended without a successful reward call while the node was active, the round is reported as missed
+func (s *RewardService) startRound() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev := s.round
+	if prev != nil && prev.active && !prev.rewarded {
+		glog.Warningf("Missed reward call for round %v attempts=%v", prev.number, prev.attempts)
+		s.report(&RewardEvent{Round: prev.number, Status: RewardMissed, Block: s.tw.LastSeenL1Block(), Attempts: prev.attempts, Error: prev.lastErr})
+		if monitor.Enabled {
+			monitor.RewardCallMissed()
+		}
+	}
+
+	round := &rewardRound{
+		number:      s.tw.LastInitializedRound(),
+		maxGasPrice: s.policy.MaxGasPrice,
+	}
+	if s.policy.DeadlineBlocks != nil {
+		if start := s.tw.CurrentRoundStartL1Block(); start != nil {
+			round.deadline = new(big.Int).Add(start, s.policy.DeadlineBlocks)
+		}
+	}
+	s.round = round
+}
+
 func (s *RewardService) tryReward() error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	currentRound := s.tw.LastInitializedRound()
+	round := s.round
+	if round == nil || round.done {
+		return nil
+	}
+
+	block := s.tw.LastSeenL1Block()
+	if s.shouldWaitForGas(round, block) {
+		return nil
+	}
 
 	t, err := s.client.GetTranscoder(s.client.Account().Address)
 	if err != nil {
 		return err
 	}
 
-	if t.LastRewardRound.Cmp(currentRound) == -1 && t.Active {
-		tx, err := s.client.Reward()
-		if err != nil {
-			return err
-		}
+	if !t.Active {
+		round.done = true
+		glog.V(common.DEBUG).Infof("Not calling reward for round %v, node is not in the active set", round.number)
+		s.report(&RewardEvent{Round: round.number, Status: RewardSkipped, Block: block})
+		return nil
+	}
+	round.active = true
 
-		if err := s.client.CheckTx(tx); err != nil {
-			return err
+	if t.LastRewardRound.Cmp(round.number) >= 0 {
+		// Reward was already called for this round
+		round.done = true
+		round.rewarded = true
+		return nil
+	}
+
+	round.attempts++
+	var tx *types.Transaction
+	if round.gasPrice != nil {
+		tx, err = s.client.RewardWithGasPrice(round.gasPrice)
+	} else {
+		tx, err = s.client.Reward()
+	}
+	if err == nil {
+		err = s.client.CheckTx(tx)
+	}
+	if err != nil {
+		round.lastErr = err.Error()
+		if round.attempts > s.policy.MaxRetries {
+			round.done = true
+			s.report(&RewardEvent{Round: round.number, Status: RewardFailed, Block: block, Attempts: round.attempts, Error: err.Error()})
+		} else {
+			s.bumpGasPrice(round)
 		}
+		return err
+	}
 
-		glog.Infof("Called reward for round %v", currentRound)
+	round.done = true
+	round.rewarded = true
 
-		return nil
+	status := RewardCalled
+	if round.deadline != nil && block != nil && block.Cmp(round.deadline) > 0 {
+		status = RewardLate
 	}
+	s.report(&RewardEvent{Round: round.number, Status: status, Block: block, TxHash: tx.Hash(), Attempts: round.attempts})
+
+	glog.Infof("Called reward for round %v", round.number)
 
 	return nil
 }
+
+// shouldWaitForGas returns whether the reward call should be deferred because the gas price is above
+// the threshold of the round and the deadline has not been reached yet
+func (s *RewardService) shouldWaitForGas(round *rewardRound, block *big.Int) bool {
+	if round.maxGasPrice == nil || s.gpm == nil {
+		return false
+	}
+	if round.deadline != nil && block != nil && block.Cmp(round.deadline) >= 0 {
+		return false
+	}
+
+	gasPrice := s.gpm.GasPrice()
+	if gasPrice == nil || gasPrice.Cmp(round.maxGasPrice) <= 0 {
+		return false
+	}
+
+	glog.V(common.DEBUG).Infof("Deferring reward call for round %v gasPrice=%v maxGasPrice=%v deadline=%v", round.number, gasPrice, round.maxGasPrice, round.deadline)
+	return true
+}
+
+// bumpGasPrice raises the gas price of the next reward transaction of the round by the bump of the policy
+func (s *RewardService) bumpGasPrice(round *rewardRound) {
+	if s.policy.GasPriceBump == 0 {
+		return
+	}
+	gasPrice := round.gasPrice
+	if gasPrice == nil && s.gpm != nil {
+		gasPrice = s.gpm.GasPrice()
+	}
+	if gasPrice == nil {
+		return
+	}
+	round.gasPrice = applyPriceBump(gasPrice, s.policy.GasPriceBump)
+}
+
+func (s *RewardService) report(ev *RewardEvent) {
+	call := &common.DBRewardCall{
+		Round:    ev.Round.Int64(),
+		Status:   string(ev.Status),
+		Attempts: ev.Attempts,
+		Error:    ev.Error,
+	}
+	if ev.Block != nil {
+		call.Block = ev.Block.Int64()
+	}
+	if ev.TxHash != (ethcommon.Hash{}) {
+		call.TxHash = ev.TxHash.Hex()
+	}
+	if err := s.db.UpdateRewardCall(call); err != nil {
+		glog.Errorf("Error storing reward call for round %v err=%q", ev.Round, err)
+	}
+
+	s.feed.Send(ev)
+}
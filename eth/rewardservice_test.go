@@ -2,14 +2,16 @@ package eth
 
 import (
 	"context"
-	ethcommon "github.com/ethereum/go-ethereum/common"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
 	lpTypes "github.com/livepeer/go-livepeer/eth/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -91,7 +93,7 @@ func TestRewardService_ReceiveRoundEvent_TryReward(t *testing.T) {
 		LastRewardRound: big.NewInt(1),
 		Active:          true,
 	}, nil)
-	eth.On("Reward").Return(&types.Transaction{}, nil).Times(1)
+	eth.On("Reward").Return(types.NewTx(&types.LegacyTx{}), nil).Times(1)
 	eth.On("CheckTx").Return(nil).Times(1)
 	eth.On("GetTranscoderEarningsPoolForRound").Return(&lpTypes.TokenPools{}, nil)
 
@@ -110,7 +112,7 @@ func TestRewardService_ReceiveRoundEvent_TryReward(t *testing.T) {
 	assert.Equal(int64(1), infoLogsAfter-infoLogsBefore)
 
 	// Test for transaction time out error
-	eth.On("Reward").Return(&types.Transaction{}, nil).Once()
+	eth.On("Reward").Return(types.NewTx(&types.LegacyTx{}), nil).Once()
 	eth.On("CheckTx").Return(context.DeadlineExceeded).Once()
 
 	errorLogsBefore = glog.Stats.Error.Lines()
@@ -127,3 +129,230 @@ func TestRewardService_ReceiveRoundEvent_TryReward(t *testing.T) {
 	assert.Equal(int64(1), errorLogsAfter-errorLogsBefore)
 	assert.Equal(int64(0), infoLogsAfter-infoLogsBefore)
 }
+
+type stubGasPriceGetter struct {
+	gasPrice *big.Int
+}
+
+func (g *stubGasPriceGetter) GasPrice() *big.Int {
+	return g.gasPrice
+}
+
+func TestRewardService_TryReward_WaitsForGasUntilDeadline(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	eth := &MockClient{}
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+	eth.On("Reward").Return(types.NewTx(&types.LegacyTx{}), nil)
+	eth.On("CheckTx").Return(nil)
+
+	tw := &stubTimeWatcher{
+		lastInitializedRound:   big.NewInt(100),
+		currentRoundStartBlock: big.NewInt(1000),
+		lastBlock:              big.NewInt(1000),
+	}
+	gpm := &stubGasPriceGetter{gasPrice: big.NewInt(20)}
+	rs := &RewardService{
+		client: eth,
+		tw:     tw,
+		gpm:    gpm,
+		policy: RewardPolicy{
+			MaxGasPrice:    big.NewInt(10),
+			DeadlineBlocks: big.NewInt(5),
+		},
+	}
+	events := make(chan *RewardEvent, 10)
+	sub := rs.SubscribeRewardEvents(events)
+	defer sub.Unsubscribe()
+
+	rs.startRound()
+
+	// Gas price above threshold before the deadline defers the call
+	require.Nil(rs.tryReward())
+	eth.AssertNotCalled(t, "Reward")
+
+	// Gas price above threshold at the deadline calls reward
+	tw.lastBlock = big.NewInt(1005)
+	require.Nil(rs.tryReward())
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+	ev := <-events
+	assert.Equal(RewardCalled, ev.Status)
+	assert.Equal(big.NewInt(100), ev.Round)
+	assert.Equal(1, ev.Attempts)
+
+	// Round is done, no further calls
+	require.Nil(rs.tryReward())
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+
+	// Next round, gas price below threshold calls reward immediately
+	tw.lastInitializedRound = big.NewInt(101)
+	tw.currentRoundStartBlock = big.NewInt(1010)
+	tw.lastBlock = big.NewInt(1010)
+	gpm.gasPrice = big.NewInt(5)
+	rs.startRound()
+	require.Nil(rs.tryReward())
+	eth.AssertNumberOfCalls(t, "Reward", 2)
+	ev = <-events
+	assert.Equal(RewardCalled, ev.Status)
+	assert.Equal(big.NewInt(101), ev.Round)
+}
+
+func TestRewardService_TryReward_LateCall(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	eth := &MockClient{}
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+	eth.On("Reward").Return(types.NewTx(&types.LegacyTx{}), nil)
+	eth.On("CheckTx").Return(nil)
+
+	tw := &stubTimeWatcher{
+		lastInitializedRound:   big.NewInt(100),
+		currentRoundStartBlock: big.NewInt(1000),
+		lastBlock:              big.NewInt(1010),
+	}
+	rs := &RewardService{
+		client: eth,
+		tw:     tw,
+		policy: RewardPolicy{DeadlineBlocks: big.NewInt(5)},
+	}
+	events := make(chan *RewardEvent, 10)
+	sub := rs.SubscribeRewardEvents(events)
+	defer sub.Unsubscribe()
+
+	rs.startRound()
+	require.Nil(rs.tryReward())
+	ev := <-events
+	assert.Equal(RewardLate, ev.Status)
+	assert.Equal(big.NewInt(1010), ev.Block)
+}
+
+func TestRewardService_TryReward_RetriesWithBumpedGasPrice(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	eth := &MockClient{}
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          true,
+	}, nil)
+	eth.On("Reward").Return(types.NewTx(&types.LegacyTx{}), nil)
+	eth.On("RewardWithGasPrice", big.NewInt(11)).Return(types.NewTx(&types.LegacyTx{}), nil)
+	eth.On("RewardWithGasPrice", big.NewInt(13)).Return(types.NewTx(&types.LegacyTx{}), nil)
+	expErr := errors.New("CheckTx error")
+	eth.On("CheckTx").Return(expErr)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	tw := &stubTimeWatcher{
+		lastInitializedRound: big.NewInt(100),
+		lastBlock:            big.NewInt(1000),
+	}
+	rs := &RewardService{
+		client: eth,
+		tw:     tw,
+		db:     dbh,
+		gpm:    &stubGasPriceGetter{gasPrice: big.NewInt(10)},
+		policy: RewardPolicy{
+			MaxGasPrice:  big.NewInt(100),
+			MaxRetries:   2,
+			GasPriceBump: 10,
+		},
+	}
+	events := make(chan *RewardEvent, 10)
+	sub := rs.SubscribeRewardEvents(events)
+	defer sub.Unsubscribe()
+
+	rs.startRound()
+
+	// The first attempt uses the default gas price and retries bump the gas price of the transaction
+	assert.EqualError(rs.tryReward(), expErr.Error())
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+	assert.Equal(big.NewInt(11), rs.round.gasPrice)
+	assert.EqualError(rs.tryReward(), expErr.Error())
+	eth.AssertCalled(t, "RewardWithGasPrice", big.NewInt(11))
+	assert.Equal(big.NewInt(13), rs.round.gasPrice)
+	assert.EqualError(rs.tryReward(), expErr.Error())
+	eth.AssertCalled(t, "RewardWithGasPrice", big.NewInt(13))
+	eth.AssertNumberOfCalls(t, "Reward", 1)
+	eth.AssertNumberOfCalls(t, "RewardWithGasPrice", 2)
+	// The waiting threshold is unchanged
+	assert.Equal(big.NewInt(100), rs.round.maxGasPrice)
+
+	ev := <-events
+	assert.Equal(RewardFailed, ev.Status)
+	assert.Equal(3, ev.Attempts)
+	assert.Equal(expErr.Error(), ev.Error)
+
+	// Retries exhausted
+	require.Nil(rs.tryReward())
+	eth.AssertNumberOfCalls(t, "RewardWithGasPrice", 2)
+
+	// The failed round is reported as missed when the next round starts
+	tw.lastInitializedRound = big.NewInt(101)
+	tw.lastBlock = big.NewInt(1100)
+	rs.startRound()
+	ev = <-events
+	assert.Equal(RewardMissed, ev.Status)
+	assert.Equal(big.NewInt(100), ev.Round)
+
+	// and the failure is kept
+	calls, err := dbh.RewardCalls(10)
+	require.Nil(err)
+	require.Len(calls, 2)
+	assert.Equal(&common.DBRewardCall{Round: 100, Status: "missed", Block: 1100, Attempts: 3, Error: expErr.Error()}, calls[0])
+	assert.Equal(&common.DBRewardCall{Round: 100, Status: "failed", Block: 1000, Attempts: 3, Error: expErr.Error()}, calls[1])
+}
+
+func TestRewardService_TryReward_SkipsWhenNotActive(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	eth := &MockClient{}
+	addr := ethcommon.Address{}
+	eth.On("Account").Return(accounts.Account{Address: addr})
+	eth.On("GetTranscoder", addr).Return(&lpTypes.Transcoder{
+		LastRewardRound: big.NewInt(1),
+		Active:          false,
+	}, nil)
+
+	tw := &stubTimeWatcher{lastInitializedRound: big.NewInt(100)}
+	rs := &RewardService{
+		client: eth,
+		tw:     tw,
+	}
+	events := make(chan *RewardEvent, 10)
+	sub := rs.SubscribeRewardEvents(events)
+	defer sub.Unsubscribe()
+
+	rs.startRound()
+	require.Nil(rs.tryReward())
+	eth.AssertNotCalled(t, "Reward")
+	ev := <-events
+	assert.Equal(RewardSkipped, ev.Status)
+
+	// An inactive round is not reported as missed
+	tw.lastInitializedRound = big.NewInt(101)
+	rs.startRound()
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected reward event %+v", ev)
+	default:
+	}
+}
@@ -0,0 +1,21 @@
+#!/usr/bin/env bash
+# Copies the bytecode of the protocol contracts from the build artifacts of a protocol repository checkout.
+# The BondingManager artifact must come from a deployment so that its library references are linked.
+set -euo pipefail
+
+protocol=${1:?usage: vendor.sh <path to the protocol repository>}
+dir=$(cd "$(dirname "$0")" && pwd)
+
+for name in Controller LivepeerToken LivepeerTokenFaucet ServiceRegistry BondingManager TicketBroker RoundsManager Minter; do
+  artifact=$(find "$protocol/deployments" "$protocol/artifacts" -name "$name.json" -not -path "*.dbg.json" 2>/dev/null | head -n 1)
+  if [ -z "$artifact" ]; then
+    echo "missing artifact for $name" >&2
+    exit 1
+  fi
+  bytecode=$(jq -r .bytecode "$artifact")
+  if [[ "$bytecode" == *__* ]]; then
+    echo "unlinked library reference in the bytecode of $name from $artifact" >&2
+    exit 1
+  fi
+  jq -n --arg bytecode "$bytecode" '{bytecode: $bytecode}' >"$dir/$name.json"
+done
@@ -0,0 +1,319 @@
+package simulator
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/eth/contracts"
+)
+
+// The build artifacts of the protocol contracts vendored from the protocol repository, see artifacts/README.md
+//
+//go:embed artifacts
+var vendoredArtifacts embed.FS
+
+// ContractArtifacts contains the deployment bytecode of the protocol contracts.
+// Library references in the bytecode must already be linked
+type ContractArtifacts struct {
+	Controller          []byte
+	LivepeerToken       []byte
+	LivepeerTokenFaucet []byte
+	ServiceRegistry     []byte
+	BondingManager      []byte
+	TicketBroker        []byte
+	RoundsManager       []byte
+	Minter              []byte
+}
+
+// ProtocolParams are the parameters used to configure the protocol contracts after deployment
+type ProtocolParams struct {
+	RoundLength          *big.Int
+	RoundLockAmount      *big.Int
+	UnbondingPeriod      uint64
+	NumActiveTranscoders *big.Int
+	UnlockPeriod         *big.Int
+	TicketValidityPeriod *big.Int
+	Inflation            *big.Int
+	InflationChange      *big.Int
+	TargetBondingRate    *big.Int
+	FaucetSupply         *big.Int
+	FaucetRequestAmount  *big.Int
+	FaucetRequestWait    *big.Int
+}
+
+// DefaultProtocolParams returns parameters that keep rounds and unbonding short for tests
+func DefaultProtocolParams() ProtocolParams {
+	return ProtocolParams{
+		RoundLength:          big.NewInt(20),
+		RoundLockAmount:      big.NewInt(100000),
+		UnbondingPeriod:      2,
+		NumActiveTranscoders: big.NewInt(10),
+		UnlockPeriod:         big.NewInt(2),
+		TicketValidityPeriod: big.NewInt(2),
+		Inflation:            big.NewInt(137),
+		InflationChange:      big.NewInt(3),
+		TargetBondingRate:    big.NewInt(500000),
+		FaucetSupply:         new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18)),
+		FaucetRequestAmount:  new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)),
+		FaucetRequestWait:    big.NewInt(0),
+	}
+}
+
+// Protocol contains the addresses of the deployed protocol contracts
+type Protocol struct {
+	Controller          ethcommon.Address
+	LivepeerToken       ethcommon.Address
+	LivepeerTokenFaucet ethcommon.Address
+	ServiceRegistry     ethcommon.Address
+	BondingManager      ethcommon.Address
+	TicketBroker        ethcommon.Address
+	RoundsManager       ethcommon.Address
+	Minter              ethcommon.Address
+}
+
+// DeployProtocol deploys the protocol contracts, registers them in the Controller, configures them
+// with params and unpauses the Controller
+func (s *Simulator) DeployProtocol(artifacts ContractArtifacts, params ProtocolParams) (*Protocol, error) {
+	if err := artifacts.Validate(); err != nil {
+		return nil, err
+	}
+
+	p := &Protocol{}
+
+	deploy := func(name string, md *bind.MetaData, bytecode []byte, params ...interface{}) (ethcommon.Address, error) {
+		parsed, err := md.GetAbi()
+		if err != nil {
+			return ethcommon.Address{}, err
+		}
+		addr, err := s.Deploy(*parsed, bytecode, params...)
+		if err != nil {
+			return ethcommon.Address{}, fmt.Errorf("failed to deploy %v: %w", name, err)
+		}
+		glog.V(6).Infof("Deployed %v at %v", name, addr.Hex())
+		return addr, nil
+	}
+
+	var err error
+	if p.Controller, err = deploy("Controller", contracts.ControllerMetaData, artifacts.Controller); err != nil {
+		return nil, err
+	}
+	if p.LivepeerToken, err = deploy("LivepeerToken", contracts.LivepeerTokenMetaData, artifacts.LivepeerToken); err != nil {
+		return nil, err
+	}
+	if p.LivepeerTokenFaucet, err = deploy("LivepeerTokenFaucet", contracts.LivepeerTokenFaucetMetaData, artifacts.LivepeerTokenFaucet, p.LivepeerToken, params.FaucetRequestAmount, params.FaucetRequestWait); err != nil {
+		return nil, err
+	}
+	if p.ServiceRegistry, err = deploy("ServiceRegistry", contracts.ServiceRegistryMetaData, artifacts.ServiceRegistry, p.Controller); err != nil {
+		return nil, err
+	}
+	if p.BondingManager, err = deploy("BondingManager", contracts.BondingManagerMetaData, artifacts.BondingManager, p.Controller); err != nil {
+		return nil, err
+	}
+	if p.TicketBroker, err = deploy("TicketBroker", contracts.TicketBrokerMetaData, artifacts.TicketBroker, p.Controller); err != nil {
+		return nil, err
+	}
+	if p.RoundsManager, err = deploy("RoundsManager", contracts.RoundsManagerMetaData, artifacts.RoundsManager, p.Controller); err != nil {
+		return nil, err
+	}
+	if p.Minter, err = deploy("Minter", contracts.MinterMetaData, artifacts.Minter, p.Controller, params.Inflation, params.InflationChange, params.TargetBondingRate); err != nil {
+		return nil, err
+	}
+
+	if err := s.registerContracts(p); err != nil {
+		return nil, err
+	}
+
+	if err := s.configureProtocol(p, params); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (s *Simulator) registerContracts(p *Protocol) error {
+	controller, err := contracts.NewController(p.Controller, s.client)
+	if err != nil {
+		return err
+	}
+
+	registry := []struct {
+		name string
+		addr ethcommon.Address
+	}{
+		{"LivepeerToken", p.LivepeerToken},
+		{"LivepeerTokenFaucet", p.LivepeerTokenFaucet},
+		{"ServiceRegistry", p.ServiceRegistry},
+		{"BondingManager", p.BondingManager},
+		{"TicketBroker", p.TicketBroker},
+		{"RoundsManager", p.RoundsManager},
+		{"Minter", p.Minter},
+	}
+	for _, c := range registry {
+		id := crypto.Keccak256Hash([]byte(c.name))
+		err := s.Transact(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return controller.SetContractInfo(opts, id, c.addr, [20]byte{})
+		})
+		if err != nil {
+			return fmt.Errorf("failed to register %v: %w", c.name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Simulator) configureProtocol(p *Protocol, params ProtocolParams) error {
+	controller, err := contracts.NewController(p.Controller, s.client)
+	if err != nil {
+		return err
+	}
+	token, err := contracts.NewLivepeerToken(p.LivepeerToken, s.client)
+	if err != nil {
+		return err
+	}
+	bondingManager, err := contracts.NewBondingManager(p.BondingManager, s.client)
+	if err != nil {
+		return err
+	}
+	broker, err := contracts.NewTicketBroker(p.TicketBroker, s.client)
+	if err != nil {
+		return err
+	}
+	roundsManager, err := contracts.NewRoundsManager(p.RoundsManager, s.client)
+	if err != nil {
+		return err
+	}
+
+	steps := []struct {
+		desc string
+		fn   func(opts *bind.TransactOpts) (*types.Transaction, error)
+	}{
+		{"set round length", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return roundsManager.SetRoundLength(opts, params.RoundLength)
+		}},
+		{"set round lock amount", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return roundsManager.SetRoundLockAmount(opts, params.RoundLockAmount)
+		}},
+		{"set unbonding period", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return bondingManager.SetUnbondingPeriod(opts, params.UnbondingPeriod)
+		}},
+		{"set number of active transcoders", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return bondingManager.SetNumActiveTranscoders(opts, params.NumActiveTranscoders)
+		}},
+		{"set unlock period", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return broker.SetUnlockPeriod(opts, params.UnlockPeriod)
+		}},
+		{"set ticket validity period", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return broker.SetTicketValidityPeriod(opts, params.TicketValidityPeriod)
+		}},
+		{"fund faucet", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return token.Mint(opts, p.LivepeerTokenFaucet, params.FaucetSupply)
+		}},
+		// The Minter mints the inflationary rewards so it has to own the token
+		{"transfer token ownership to minter", func(opts *bind.TransactOpts) (*types.Transaction, error) {
+			return token.TransferOwnership(opts, p.Minter)
+		}},
+	}
+	for _, step := range steps {
+		if err := s.Transact(step.fn); err != nil {
+			return fmt.Errorf("failed to %v: %w", step.desc, err)
+		}
+	}
+
+	paused, err := controller.Paused(&bind.CallOpts{})
+	if err != nil {
+		return err
+	}
+	if !paused {
+		return nil
+	}
+	if err := s.Transact(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return controller.Unpause(opts)
+	}); err != nil {
+		return fmt.Errorf("failed to unpause controller: %w", err)
+	}
+
+	return nil
+}
+
+// Validate returns an error if the bytecode of a protocol contract is missing
+func (a ContractArtifacts) Validate() error {
+	var missing []string
+	for _, c := range a.contracts() {
+		if len(*c.bytecode) == 0 {
+			missing = append(missing, c.name)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing bytecode for %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// ProtocolArtifacts returns the build artifacts of the protocol contracts vendored in the artifacts directory.
+// The returned error wraps fs.ErrNotExist if the artifact of a contract is not vendored
+func ProtocolArtifacts() (ContractArtifacts, error) {
+	dir, err := fs.Sub(vendoredArtifacts, "artifacts")
+	if err != nil {
+		return ContractArtifacts{}, err
+	}
+	return loadContractArtifacts(dir)
+}
+
+// LoadContractArtifacts reads the bytecode of the protocol contracts from the JSON build artifacts in dir.
+// The artifact of each contract is expected at <dir>/<contract name>.json with the bytecode in the "bytecode" field
+func LoadContractArtifacts(dir string) (ContractArtifacts, error) {
+	return loadContractArtifacts(os.DirFS(dir))
+}
+
+func loadContractArtifacts(dir fs.FS) (ContractArtifacts, error) {
+	var a ContractArtifacts
+	for _, c := range a.contracts() {
+		data, err := fs.ReadFile(dir, c.name+".json")
+		if err != nil {
+			return ContractArtifacts{}, err
+		}
+
+		var artifact struct {
+			Bytecode string `json:"bytecode"`
+		}
+		if err := json.Unmarshal(data, &artifact); err != nil {
+			return ContractArtifacts{}, fmt.Errorf("invalid artifact for %v: %w", c.name, err)
+		}
+
+		bytecode, err := hexutil.Decode(artifact.Bytecode)
+		if err != nil {
+			return ContractArtifacts{}, fmt.Errorf("invalid bytecode for %v: %w", c.name, err)
+		}
+		*c.bytecode = bytecode
+	}
+	return a, nil
+}
+
+type namedBytecode struct {
+	name     string
+	bytecode *[]byte
+}
+
+func (a *ContractArtifacts) contracts() []namedBytecode {
+	return []namedBytecode{
+		{"Controller", &a.Controller},
+		{"LivepeerToken", &a.LivepeerToken},
+		{"LivepeerTokenFaucet", &a.LivepeerTokenFaucet},
+		{"ServiceRegistry", &a.ServiceRegistry},
+		{"BondingManager", &a.BondingManager},
+		{"TicketBroker", &a.TicketBroker},
+		{"RoundsManager", &a.RoundsManager},
+		{"Minter", &a.Minter},
+	}
+}
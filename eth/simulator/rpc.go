@@ -0,0 +1,283 @@
+package simulator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ethAPI serves the subset of the eth JSON-RPC namespace that is used by ethclient.Client, the eth client
+// and the block watcher on top of the simulated backend
+type ethAPI struct {
+	sim *Simulator
+}
+
+// netAPI serves the net JSON-RPC namespace
+type netAPI struct {
+	chainID *big.Int
+}
+
+// callArgs are the arguments of eth_call and eth_estimateGas
+type callArgs struct {
+	From                 *common.Address `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  *hexutil.Uint64 `json:"gas"`
+	GasPrice             *hexutil.Big    `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Data                 *hexutil.Bytes  `json:"data"`
+	Input                *hexutil.Bytes  `json:"input"`
+}
+
+func (api *netAPI) Version() string {
+	return api.chainID.String()
+}
+
+func (api *ethAPI) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.sim.chainID)
+}
+
+func (api *ethAPI) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(api.sim.backend.Blockchain().CurrentBlock().Number.Uint64())
+}
+
+func (api *ethAPI) GasPrice(ctx context.Context) (*hexutil.Big, error) {
+	price, err := api.sim.backend.SuggestGasPrice(ctx)
+	return (*hexutil.Big)(price), err
+}
+
+func (api *ethAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tip, err := api.sim.backend.SuggestGasTipCap(ctx)
+	return (*hexutil.Big)(tip), err
+}
+
+func (api *ethAPI) GetBalance(ctx context.Context, addr common.Address, blockNrOrHash rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	num, err := api.blockNumber(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := api.sim.backend.BalanceAt(ctx, addr, num)
+	return (*hexutil.Big)(balance), err
+}
+
+func (api *ethAPI) GetCode(ctx context.Context, addr common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if isPending(blockNrOrHash) {
+		return api.sim.backend.PendingCodeAt(ctx, addr)
+	}
+	num, err := api.blockNumber(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return api.sim.backend.CodeAt(ctx, addr, num)
+}
+
+func (api *ethAPI) GetTransactionCount(ctx context.Context, addr common.Address, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	if isPending(blockNrOrHash) {
+		nonce, err := api.sim.backend.PendingNonceAt(ctx, addr)
+		return hexutil.Uint64(nonce), err
+	}
+	num, err := api.blockNumber(blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	nonce, err := api.sim.backend.NonceAt(ctx, addr, num)
+	return hexutil.Uint64(nonce), err
+}
+
+func (api *ethAPI) GetBlockByNumber(number rpc.BlockNumber, fullTx bool) (map[string]interface{}, error) {
+	chain := api.sim.backend.Blockchain()
+	var block *types.Block
+	if number < 0 {
+		// Blocks are mined as soon as transactions are received so there is no pending block
+		block = chain.GetBlockByHash(chain.CurrentBlock().Hash())
+	} else {
+		block = chain.GetBlockByNumber(uint64(number))
+	}
+	if block == nil {
+		return nil, nil
+	}
+	return api.marshalBlock(block, fullTx)
+}
+
+func (api *ethAPI) GetBlockByHash(hash common.Hash, fullTx bool) (map[string]interface{}, error) {
+	block := api.sim.backend.Blockchain().GetBlockByHash(hash)
+	if block == nil {
+		return nil, nil
+	}
+	return api.marshalBlock(block, fullTx)
+}
+
+func (api *ethAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) (map[string]interface{}, error) {
+	tx, pending, err := api.sim.backend.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return api.marshalTx(tx, nil)
+	}
+	receipt, err := api.sim.backend.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	return api.marshalTx(tx, receipt)
+}
+
+func (api *ethAPI) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := api.sim.backend.TransactionReceipt(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	return receipt, err
+}
+
+func (api *ethAPI) Call(ctx context.Context, args callArgs, blockNrOrHash rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	if isPending(blockNrOrHash) {
+		return api.sim.backend.PendingCallContract(ctx, args.toCallMsg())
+	}
+	num, err := api.blockNumber(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return api.sim.backend.CallContract(ctx, args.toCallMsg(), num)
+}
+
+func (api *ethAPI) EstimateGas(ctx context.Context, args callArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	gas, err := api.sim.backend.EstimateGas(ctx, args.toCallMsg())
+	return hexutil.Uint64(gas), err
+}
+
+func (api *ethAPI) SendRawTransaction(ctx context.Context, input hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.sim.mine(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+func (api *ethAPI) GetLogs(ctx context.Context, crit filters.FilterCriteria) ([]types.Log, error) {
+	logs, err := api.sim.backend.FilterLogs(ctx, ethereum.FilterQuery(crit))
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []types.Log{}
+	}
+	return logs, nil
+}
+
+// blockNumber returns the number of the block referenced by blockNrOrHash or nil for the latest block
+func (api *ethAPI) blockNumber(blockNrOrHash rpc.BlockNumberOrHash) (*big.Int, error) {
+	if hash, ok := blockNrOrHash.Hash(); ok {
+		header := api.sim.backend.Blockchain().GetHeaderByHash(hash)
+		if header == nil {
+			return nil, ethereum.NotFound
+		}
+		return header.Number, nil
+	}
+	if num, ok := blockNrOrHash.Number(); ok && num >= 0 {
+		return big.NewInt(num.Int64()), nil
+	}
+	return nil, nil
+}
+
+func (api *ethAPI) marshalBlock(block *types.Block, fullTx bool) (map[string]interface{}, error) {
+	fields, err := toFields(block.Header())
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]interface{}, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if !fullTx {
+			txs[i] = tx.Hash()
+			continue
+		}
+		receipt, err := api.sim.backend.TransactionReceipt(context.Background(), tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		if txs[i], err = api.marshalTx(tx, receipt); err != nil {
+			return nil, err
+		}
+	}
+	fields["transactions"] = txs
+	fields["uncles"] = []common.Hash{}
+	fields["size"] = hexutil.Uint64(block.Size())
+
+	return fields, nil
+}
+
+// marshalTx returns the RPC representation of tx, including the block it was included in if it has a receipt
+func (api *ethAPI) marshalTx(tx *types.Transaction, receipt *types.Receipt) (map[string]interface{}, error) {
+	fields, err := toFields(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(api.sim.chainID), tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = from
+
+	if receipt != nil {
+		fields["blockHash"] = receipt.BlockHash
+		fields["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+		fields["transactionIndex"] = hexutil.Uint64(receipt.TransactionIndex)
+	}
+
+	return fields, nil
+}
+
+func (args callArgs) toCallMsg() ethereum.CallMsg {
+	msg := ethereum.CallMsg{To: args.To}
+	if args.From != nil {
+		msg.From = *args.From
+	}
+	if args.Gas != nil {
+		msg.Gas = uint64(*args.Gas)
+	}
+	msg.GasPrice = (*big.Int)(args.GasPrice)
+	msg.GasFeeCap = (*big.Int)(args.MaxFeePerGas)
+	msg.GasTipCap = (*big.Int)(args.MaxPriorityFeePerGas)
+	msg.Value = (*big.Int)(args.Value)
+	if args.Input != nil {
+		msg.Data = *args.Input
+	} else if args.Data != nil {
+		msg.Data = *args.Data
+	}
+	return msg
+}
+
+func isPending(blockNrOrHash rpc.BlockNumberOrHash) bool {
+	num, ok := blockNrOrHash.Number()
+	return ok && num == rpc.PendingBlockNumber
+}
+
+// toFields returns the JSON fields of v
+func toFields(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,349 @@
+/*
+Package simulator runs an in-process Ethereum chain that can be used to exercise the eth client
+and the watchers end to end without a network connection.
+
+The chain is go-ethereum's simulated backend, served over an in-process JSON-RPC connection so that
+the eth client and the block watcher use the same code paths as against a remote node. A block is
+mined for every transaction that is received.
+The bindings in eth/contracts only contain the contract ABIs so the deployment bytecode of the
+protocol contracts has to be provided as ContractArtifacts, either from the artifacts vendored in
+the artifacts directory with ProtocolArtifacts or from other build artifacts of the protocol
+repository with LoadContractArtifacts.
+*/
+package simulator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/eth"
+)
+
+const (
+	genesisGasLimit = 30000000
+	txTimeout       = 30 * time.Second
+)
+
+var (
+	// Balance of the account that deploys the contracts
+	deployerBalance = new(big.Int).Mul(big.NewInt(1000000), big.NewInt(1e18))
+	// Balance of the accounts created with NewAccount
+	accountBalance = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+
+	ErrSimulatorClosed = errors.New("simulator closed")
+)
+
+// Simulator is an in-process Ethereum chain
+type Simulator struct {
+	backend   *backends.SimulatedBackend
+	server    *rpc.Server
+	rpcClient *rpc.Client
+	client    *ethclient.Client
+	chainID   *big.Int
+	// Serializes mining so that every transaction is mined in its own block
+	mineMu sync.Mutex
+
+	deployer     *ecdsa.PrivateKey
+	deployerAddr ethcommon.Address
+	// Serializes transactions sent by the deployer so nonces are not reused
+	deployerMu sync.Mutex
+
+	closers []func()
+	closed  bool
+	mu      sync.Mutex
+}
+
+// New starts an in-process chain with a funded deployer account
+func New() (*Simulator, error) {
+	deployer, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	deployerAddr := crypto.PubkeyToAddress(deployer.PublicKey)
+
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		deployerAddr: {Balance: deployerBalance},
+	}, genesisGasLimit)
+
+	s := &Simulator{
+		backend:      backend,
+		server:       rpc.NewServer(),
+		chainID:      backend.Blockchain().Config().ChainID,
+		deployer:     deployer,
+		deployerAddr: deployerAddr,
+	}
+	if err := s.server.RegisterName("eth", &ethAPI{sim: s}); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	if err := s.server.RegisterName("net", &netAPI{chainID: s.chainID}); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	s.rpcClient = rpc.DialInProc(s.server)
+	s.client = ethclient.NewClient(s.rpcClient)
+
+	glog.V(6).Infof("Started simulated chain chainID=%v", s.chainID)
+
+	return s, nil
+}
+
+// Close stops the clients created by the simulator and shuts down the chain
+func (s *Simulator) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrSimulatorClosed
+	}
+	s.closed = true
+
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+	s.client.Close()
+	s.server.Stop()
+	return s.backend.Close()
+}
+
+// Client returns an ethclient.Client connected to the chain
+func (s *Simulator) Client() *ethclient.Client {
+	return s.client
+}
+
+// RPCClient returns the in-process JSON-RPC connection to the chain which can be used by clients
+// that make raw RPC calls, such as blockwatch.NewRPCClientWithClient
+func (s *Simulator) RPCClient() *rpc.Client {
+	return s.rpcClient
+}
+
+// ChainID returns the chain ID of the chain
+func (s *Simulator) ChainID() *big.Int {
+	return new(big.Int).Set(s.chainID)
+}
+
+// Deployer returns the address of the account used to deploy and configure contracts
+func (s *Simulator) Deployer() ethcommon.Address {
+	return s.deployerAddr
+}
+
+// BlockNumber returns the number of the latest block
+func (s *Simulator) BlockNumber() (*big.Int, error) {
+	blk, err := s.client.BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetUint64(blk), nil
+}
+
+// AdvanceBlocks mines n empty blocks
+func (s *Simulator) AdvanceBlocks(n int) error {
+	s.mineMu.Lock()
+	defer s.mineMu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.backend.Commit()
+	}
+	return nil
+}
+
+// Fund transfers amount wei from the deployer to addr
+func (s *Simulator) Fund(addr ethcommon.Address, amount *big.Int) error {
+	return s.Transact(func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return s.transfer(opts, addr, amount)
+	})
+}
+
+// NewAccount creates a funded account with an empty passphrase in the keystore at keystoreDir
+func (s *Simulator) NewAccount(keystoreDir string) (accounts.Account, error) {
+	ks := keystore.NewKeyStore(keystoreDir, keystore.LightScryptN, keystore.LightScryptP)
+	acct, err := ks.NewAccount("")
+	if err != nil {
+		return accounts.Account{}, err
+	}
+
+	if err := s.Fund(acct.Address, accountBalance); err != nil {
+		return accounts.Account{}, err
+	}
+
+	return acct, nil
+}
+
+// NewLivepeerEthClient returns a LivepeerEthClient for the account addr in the keystore at keystoreDir that uses
+// the protocol contracts registered in the Controller at controllerAddr. The client is stopped when the simulator is closed.
+func (s *Simulator) NewLivepeerEthClient(keystoreDir string, addr ethcommon.Address, controllerAddr ethcommon.Address) (eth.LivepeerEthClient, error) {
+	gpm := eth.NewGasPriceMonitor(s.client, time.Second, big.NewInt(0), nil)
+	if _, err := gpm.Start(context.Background()); err != nil {
+		return nil, err
+	}
+
+	am, err := eth.NewAccountManager(addr, keystoreDir, s.chainID, "")
+	if err != nil {
+		gpm.Stop()
+		return nil, err
+	}
+	if err := am.Unlock(""); err != nil {
+		gpm.Stop()
+		return nil, err
+	}
+
+	tm := eth.NewTransactionManager(s.client, gpm, am, txTimeout, 0)
+	go tm.Start()
+
+	s.mu.Lock()
+	s.closers = append(s.closers, tm.Stop, func() { gpm.Stop() })
+	s.mu.Unlock()
+
+	client, err := eth.NewClient(eth.LivepeerEthClientConfig{
+		AccountManager:     am,
+		ControllerAddr:     controllerAddr,
+		EthClient:          s.client,
+		GasPriceMonitor:    gpm,
+		TransactionManager: tm,
+		Signer:             types.LatestSignerForChainID(s.chainID),
+		CheckTxTimeout:     txTimeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.SetGasInfo(0); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// Deploy deploys a contract from the deployer account and waits until it is mined
+func (s *Simulator) Deploy(contractABI abi.ABI, bytecode []byte, params ...interface{}) (ethcommon.Address, error) {
+	if len(bytecode) == 0 {
+		return ethcommon.Address{}, errors.New("missing contract bytecode")
+	}
+
+	s.deployerMu.Lock()
+	defer s.deployerMu.Unlock()
+
+	opts, err := s.transactOpts()
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	_, tx, _, err := bind.DeployContract(opts, contractABI, bytecode, s.client, params...)
+	if err != nil {
+		return ethcommon.Address{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), txTimeout)
+	defer cancel()
+	return bind.WaitDeployed(ctx, s.client, tx)
+}
+
+// Transact sends the transaction created by fn from the deployer account and waits until it is mined successfully
+func (s *Simulator) Transact(fn func(opts *bind.TransactOpts) (*types.Transaction, error)) error {
+	s.deployerMu.Lock()
+	defer s.deployerMu.Unlock()
+
+	opts, err := s.transactOpts()
+	if err != nil {
+		return err
+	}
+
+	tx, err := fn(opts)
+	if err != nil {
+		return err
+	}
+
+	return s.CheckTx(tx)
+}
+
+// CheckTx waits until tx is mined and returns an error if it failed. Transactions are mined before they are
+// returned to the sender so it has to be used instead of LivepeerEthClient.CheckTx, which can miss the receipt
+// if it is published by the TransactionManager before CheckTx subscribes to it
+func (s *Simulator) CheckTx(tx *types.Transaction) error {
+	ctx, cancel := context.WithTimeout(context.Background(), txTimeout)
+	defer cancel()
+	receipt, err := bind.WaitMined(ctx, s.client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction failed txHash=%v", tx.Hash().Hex())
+	}
+	return nil
+}
+
+// mine mines a block with tx
+func (s *Simulator) mine(ctx context.Context, tx *types.Transaction) (err error) {
+	s.mineMu.Lock()
+	defer s.mineMu.Unlock()
+
+	// The simulated backend panics if the transaction cannot be applied, i.e. if its fee cap is below the base fee
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invalid transaction: %v", r)
+		}
+	}()
+
+	if err := s.backend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	s.backend.Commit()
+	return nil
+}
+
+func (s *Simulator) transactOpts() (*bind.TransactOpts, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(s.deployer, s.chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = context.Background()
+	return opts, nil
+}
+
+func (s *Simulator) transfer(opts *bind.TransactOpts, to ethcommon.Address, amount *big.Int) (*types.Transaction, error) {
+	ctx := context.Background()
+	nonce, err := s.client.PendingNonceAt(ctx, opts.From)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   s.chainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       21000,
+		To:        &to,
+		Value:     amount,
+	})
+	signed, err := opts.Signer(opts.From, tx)
+	if err != nil {
+		return nil, err
+	}
+	return signed, s.client.SendTransaction(ctx, signed)
+}
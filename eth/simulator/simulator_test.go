@@ -0,0 +1,444 @@
+package simulator
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/eth/watchers"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Init code for a contract that returns 42 for any call
+var answerBytecode = hexutil.MustDecode("0x600a600c600039600a6000f3602a60005260206000f3")
+
+func TestSimulator(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, err := New()
+	require.Nil(err)
+	defer sim.Close()
+
+	assert.Equal(int64(1337), sim.ChainID().Int64())
+	assert.NotNil(sim.RPCClient())
+
+	// Deployer is funded in the genesis block
+	balance, err := sim.Client().BalanceAt(context.Background(), sim.Deployer(), nil)
+	require.Nil(err)
+	assert.True(balance.Sign() > 0)
+
+	// Accounts are funded on creation
+	acct, err := sim.NewAccount(t.TempDir())
+	require.Nil(err)
+	balance, err = sim.Client().BalanceAt(context.Background(), acct.Address, nil)
+	require.Nil(err)
+	assert.Equal(accountBalance, balance)
+
+	// Mine blocks on demand
+	start, err := sim.BlockNumber()
+	require.Nil(err)
+	require.Nil(sim.AdvanceBlocks(3))
+	end, err := sim.BlockNumber()
+	require.Nil(err)
+	assert.Equal(int64(3), new(big.Int).Sub(end, start).Int64())
+
+	// Deploy and call a contract
+	addr, err := sim.Deploy(abi.ABI{}, answerBytecode)
+	require.Nil(err)
+	res, err := sim.Client().CallContract(context.Background(), ethereum.CallMsg{To: &addr}, nil)
+	require.Nil(err)
+	assert.Equal(int64(42), new(big.Int).SetBytes(res).Int64())
+
+	_, err = sim.Deploy(abi.ABI{}, nil)
+	assert.EqualError(err, "missing contract bytecode")
+
+	require.Nil(sim.Close())
+	assert.Equal(ErrSimulatorClosed, sim.Close())
+}
+
+func TestDeployProtocol_MissingArtifacts(t *testing.T) {
+	sim, err := New()
+	require.Nil(t, err)
+	defer sim.Close()
+
+	_, err = sim.DeployProtocol(ContractArtifacts{Controller: answerBytecode}, DefaultProtocolParams())
+	assert.EqualError(t, err, "missing bytecode for LivepeerToken, LivepeerTokenFaucet, ServiceRegistry, BondingManager, TicketBroker, RoundsManager, Minter")
+}
+
+func TestLoadContractArtifacts(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	_, err := LoadContractArtifacts(dir)
+	assert.NotNil(err)
+
+	for _, c := range (&ContractArtifacts{}).contracts() {
+		require.Nil(t, os.WriteFile(dir+"/"+c.name+".json", []byte(`{"bytecode":"0x6001"}`), 0644))
+	}
+	a, err := LoadContractArtifacts(dir)
+	require.Nil(t, err)
+	assert.Nil(a.Validate())
+	assert.Equal([]byte{0x60, 0x01}, a.BondingManager)
+
+	require.Nil(t, os.WriteFile(dir+"/Minter.json", []byte(`{"bytecode":"foo"}`), 0644))
+	_, err = LoadContractArtifacts(dir)
+	assert.Contains(err.Error(), "invalid bytecode for Minter")
+}
+
+// protocolArtifacts returns the build artifacts of the protocol contracts from the directory provided with the
+// LP_PROTOCOL_ARTIFACTS environment variable, or the vendored artifacts otherwise. The test is skipped if the
+// artifacts are not vendored
+func protocolArtifacts(t *testing.T) ContractArtifacts {
+	if dir := os.Getenv("LP_PROTOCOL_ARTIFACTS"); dir != "" {
+		artifacts, err := LoadContractArtifacts(dir)
+		require.Nil(t, err)
+		return artifacts
+	}
+	artifacts, err := ProtocolArtifacts()
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skipf("protocol artifacts are not vendored, see artifacts/README.md: %v", err)
+	}
+	require.Nil(t, err)
+	return artifacts
+}
+
+func newProtocolClient(t *testing.T, artifacts ContractArtifacts) (*Simulator, *Protocol, eth.LivepeerEthClient) {
+	sim, err := New()
+	require.Nil(t, err)
+	t.Cleanup(func() { sim.Close() })
+
+	protocol, err := sim.DeployProtocol(artifacts, DefaultProtocolParams())
+	require.Nil(t, err)
+
+	return sim, protocol, newClient(t, sim, protocol)
+}
+
+// newClient returns a client for a new funded account
+func newClient(t *testing.T, sim *Simulator, protocol *Protocol) eth.LivepeerEthClient {
+	keystoreDir := t.TempDir()
+	acct, err := sim.NewAccount(keystoreDir)
+	require.Nil(t, err)
+	client, err := sim.NewLivepeerEthClient(keystoreDir, acct.Address, protocol.Controller)
+	require.Nil(t, err)
+	return client
+}
+
+// initializeRound advances to the next round and initializes it
+func initializeRound(t *testing.T, sim *Simulator, client eth.LivepeerEthClient) {
+	require.Nil(t, sim.AdvanceBlocks(int(DefaultProtocolParams().RoundLength.Int64())))
+	tx, err := client.InitializeRound()
+	require.Nil(t, err)
+	require.Nil(t, sim.CheckTx(tx))
+}
+
+// bondOrchestrator bonds the faucet tokens of the client's account to itself and registers it as an active
+// orchestrator
+func bondOrchestrator(t *testing.T, sim *Simulator, client eth.LivepeerEthClient) {
+	require := require.New(t)
+	params := DefaultProtocolParams()
+	addr := client.Account().Address
+
+	tx, err := client.Request()
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	initializeRound(t, sim, client)
+
+	tx, err = client.Bond(params.FaucetRequestAmount, addr)
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	tx, err = client.Transcoder(eth.FromPerc(10), eth.FromPerc(50))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+
+	// The orchestrator joins the active set in the next round
+	initializeRound(t, sim, client)
+}
+
+func TestProtocolArtifacts(t *testing.T) {
+	artifacts := protocolArtifacts(t)
+	assert.Nil(t, artifacts.Validate())
+}
+
+func TestLivepeerEthClient_Rounds(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, _, client := newProtocolClient(t, protocolArtifacts(t))
+	params := DefaultProtocolParams()
+
+	roundLength, err := client.RoundLength()
+	require.Nil(err)
+	assert.Equal(params.RoundLength, roundLength)
+
+	require.Nil(sim.AdvanceBlocks(int(params.RoundLength.Int64())))
+	initialized, err := client.CurrentRoundInitialized()
+	require.Nil(err)
+	assert.False(initialized)
+
+	tx, err := client.InitializeRound()
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+
+	round, err := client.CurrentRound()
+	require.Nil(err)
+	lastInitialized, err := client.LastInitializedRound()
+	require.Nil(err)
+	assert.Equal(round, lastInitialized)
+	startBlock, err := client.CurrentRoundStartBlock()
+	require.Nil(err)
+	assert.Equal(new(big.Int).Mul(round, params.RoundLength), startBlock)
+
+	// The block hash of the round is the hash of the block before the one the round was initialized in
+	receipt, err := sim.Client().TransactionReceipt(context.Background(), tx.Hash())
+	require.Nil(err)
+	prev, err := sim.Client().HeaderByNumber(context.Background(), new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1)))
+	require.Nil(err)
+	hash, err := client.BlockHashForRound(round)
+	require.Nil(err)
+	assert.Equal(prev.Hash(), ethcommon.Hash(hash))
+
+	_, err = client.InitializeRound()
+	assert.EqualError(err, "ErrRoundInitialized")
+}
+
+func TestLivepeerEthClient_Tickets(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, _, client := newProtocolClient(t, protocolArtifacts(t))
+	sender := client.Account().Address
+
+	tx, err := client.FundDepositAndReserve(big.NewInt(1000), big.NewInt(500))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	tx, err = client.FundDeposit(big.NewInt(100))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+
+	info, err := client.GetSenderInfo(sender)
+	require.Nil(err)
+	assert.Equal(big.NewInt(1100), info.Deposit)
+	assert.Equal(big.NewInt(500), info.Reserve.FundsRemaining)
+	assert.Zero(info.WithdrawRound.Sign())
+	assert.Zero(info.Reserve.ClaimedInCurrentRound.Sign())
+}
+
+func TestWatchers(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, protocol, client := newProtocolClient(t, protocolArtifacts(t))
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	bw := blockwatch.New(blockwatch.Config{
+		Store:               dbh,
+		PollingInterval:     50 * time.Millisecond,
+		StartBlockDepth:     rpc.LatestBlockNumber,
+		BlockRetentionLimit: 10,
+		WithLogs:            true,
+		Topics:              watchers.FilterTopics(),
+		Client:              blockwatch.NewRPCClientWithClient(sim.RPCClient(), 5*time.Second),
+		Checkpoints:         dbh,
+	})
+
+	tw, err := watchers.NewTimeWatcher(protocol.RoundsManager, bw, client)
+	require.Nil(err)
+	go tw.Watch()
+	defer tw.Stop()
+
+	sw, err := watchers.NewSenderWatcher(protocol.TicketBroker, bw, client, tw)
+	require.Nil(err)
+	go sw.Watch()
+	defer sw.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go bw.Watch(ctx)
+	// Wait for the block watcher to retain the head so that the following blocks are backfilled
+	require.Eventually(func() bool {
+		h, err := bw.GetLatestBlock()
+		return err == nil && h != nil
+	}, 5*time.Second, 50*time.Millisecond)
+
+	rounds := make(chan types.Log, 10)
+	sub := tw.SubscribeRounds(rounds)
+	defer sub.Unsubscribe()
+
+	// Load the sender into the cache of the SenderWatcher before funding it
+	sender := client.Account().Address
+	info, err := sw.GetSenderInfo(sender)
+	require.Nil(err)
+	assert.Zero(info.Deposit.Sign())
+
+	tx, err := client.FundDepositAndReserve(big.NewInt(1000), big.NewInt(500))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	// The block watcher only backfills the logs once a block has been mined on top of the block of the transaction
+	require.Nil(sim.AdvanceBlocks(1))
+
+	require.Eventually(func() bool {
+		info, err := sw.GetSenderInfo(sender)
+		return err == nil && info.Deposit.Cmp(big.NewInt(1000)) == 0 && info.Reserve.FundsRemaining.Cmp(big.NewInt(500)) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+
+	require.Nil(sim.AdvanceBlocks(int(DefaultProtocolParams().RoundLength.Int64())))
+	tx, err = client.InitializeRound()
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	round, err := client.CurrentRound()
+	require.Nil(err)
+	require.Nil(sim.AdvanceBlocks(1))
+
+	select {
+	case <-rounds:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the NewRound event")
+	}
+	assert.Equal(round, tw.LastInitializedRound())
+	hash, err := client.BlockHashForRound(round)
+	require.Nil(err)
+	assert.Equal(hash, tw.LastInitializedL1BlockHash())
+
+	head, err := sim.BlockNumber()
+	require.Nil(err)
+	require.Eventually(func() bool {
+		return tw.LastSeenL1Block().Cmp(head) == 0
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestLivepeerEthClient_Bonding(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, _, client := newProtocolClient(t, protocolArtifacts(t))
+	params := DefaultProtocolParams()
+	addr := client.Account().Address
+
+	tx, err := client.Request()
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	balance, err := client.BalanceOf(addr)
+	require.Nil(err)
+	assert.Equal(params.FaucetRequestAmount, balance)
+
+	initializeRound(t, sim, client)
+
+	tx, err = client.Bond(params.FaucetRequestAmount, addr)
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	d, err := client.GetDelegator(addr)
+	require.Nil(err)
+	assert.Equal(params.FaucetRequestAmount, d.BondedAmount)
+	assert.Equal(addr, d.DelegateAddress)
+
+	tx, err = client.Transcoder(eth.FromPerc(10), eth.FromPerc(50))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	initializeRound(t, sim, client)
+	active, err := client.IsActiveTranscoder()
+	require.Nil(err)
+	assert.True(active)
+	total, err := client.GetTotalBonded()
+	require.Nil(err)
+	assert.Equal(params.FaucetRequestAmount, total)
+
+	// Unbonding creates a lock that can be withdrawn after the unbonding period
+	unbond := big.NewInt(1000)
+	tx, err = client.Unbond(unbond)
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	d, err = client.GetDelegator(addr)
+	require.Nil(err)
+	assert.Equal(new(big.Int).Sub(params.FaucetRequestAmount, unbond), d.BondedAmount)
+	lock, err := client.GetDelegatorUnbondingLock(addr, big.NewInt(0))
+	require.Nil(err)
+	assert.Equal(unbond, lock.Amount)
+
+	for i := uint64(0); i < params.UnbondingPeriod; i++ {
+		initializeRound(t, sim, client)
+	}
+	tx, err = client.WithdrawStake(big.NewInt(0))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+	balance, err = client.BalanceOf(addr)
+	require.Nil(err)
+	assert.Equal(unbond, balance)
+}
+
+func TestLivepeerEthClient_Redemption(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	sim, protocol, orch := newProtocolClient(t, protocolArtifacts(t))
+	bondOrchestrator(t, sim, orch)
+
+	sender := newClient(t, sim, protocol)
+	tx, err := sender.FundDepositAndReserve(big.NewInt(1000), big.NewInt(500))
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+
+	round, err := orch.CurrentRound()
+	require.Nil(err)
+	blkHash, err := orch.BlockHashForRound(round)
+	require.Nil(err)
+	recipientRand := big.NewInt(1234)
+	ticket := &pm.Ticket{
+		Recipient: orch.Account().Address,
+		Sender:    sender.Account().Address,
+		FaceValue: big.NewInt(300),
+		// Every ticket wins
+		WinProb:                new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)),
+		SenderNonce:            1,
+		RecipientRandHash:      crypto.Keccak256Hash(ethcommon.LeftPadBytes(recipientRand.Bytes(), 32)),
+		CreationRound:          round.Int64(),
+		CreationRoundBlockHash: ethcommon.Hash(blkHash),
+		ParamsExpirationBlock:  big.NewInt(0),
+	}
+	sig, err := sender.Sign(ticket.Hash().Bytes())
+	require.Nil(err)
+
+	used, err := orch.IsUsedTicket(ticket)
+	require.Nil(err)
+	assert.False(used)
+
+	tx, err = orch.RedeemWinningTicket(ticket, sig, recipientRand)
+	require.Nil(err)
+	require.Nil(sim.CheckTx(tx))
+
+	// The face value is drawn from the deposit of the sender
+	info, err := orch.GetSenderInfo(sender.Account().Address)
+	require.Nil(err)
+	assert.Equal(big.NewInt(700), info.Deposit)
+	assert.Equal(big.NewInt(500), info.Reserve.FundsRemaining)
+	used, err = orch.IsUsedTicket(ticket)
+	require.Nil(err)
+	assert.True(used)
+
+	// A ticket can only be redeemed once
+	tx, err = orch.RedeemWinningTicket(ticket, sig, recipientRand)
+	if err == nil {
+		err = sim.CheckTx(tx)
+	}
+	assert.NotNil(err)
+}
@@ -85,6 +85,11 @@ func (m *MockClient) GetDelegator(addr common.Address) (*lpTypes.Delegator, erro
 	return arg.(*lpTypes.Delegator), err
 }
 
+func (m *MockClient) VotingStake(addr common.Address, block *big.Int) (common.Address, *big.Int, error) {
+	args := m.Called(addr, block)
+	return args.Get(0).(common.Address), mockBigInt(args, 1), args.Error(2)
+}
+
 func (m *MockClient) GetServiceURI(addr common.Address) (string, error) {
 	args := m.Called(addr)
 	return args.Get(0).(string), args.Error(1)
@@ -100,6 +105,11 @@ func (m *MockClient) Reward() (*types.Transaction, error) {
 	return mockTransaction(args, 0), args.Error(1)
 }
 
+func (m *MockClient) RewardWithGasPrice(gasPrice *big.Int) (*types.Transaction, error) {
+	args := m.Called(gasPrice)
+	return mockTransaction(args, 0), args.Error(1)
+}
+
 func (m *MockClient) GetTranscoderEarningsPoolForRound(address common.Address, round *big.Int) (*lpTypes.TokenPools, error) {
 	args := m.Called()
 	return args.Get(0).(*lpTypes.TokenPools), args.Error(1)
@@ -316,6 +326,9 @@ func (e *StubClient) Transcoder(blockRewardCut, feeShare *big.Int) (*types.Trans
 	return nil, nil
 }
 func (e *StubClient) Reward() (*types.Transaction, error) { return nil, nil }
+func (e *StubClient) RewardWithGasPrice(gasPrice *big.Int) (*types.Transaction, error) {
+	return nil, nil
+}
 func (e *StubClient) Bond(amount *big.Int, toAddr common.Address) (*types.Transaction, error) {
 	return nil, nil
 }
@@ -360,6 +373,12 @@ func (e *StubClient) TranscoderPool() ([]*lpTypes.Transcoder, error) {
 }
 func (e *StubClient) IsActiveTranscoder() (bool, error) { return false, nil }
 func (e *StubClient) GetTotalBonded() (*big.Int, error) { return big.NewInt(0), nil }
+func (e *StubClient) GetTotalBondedAt(block *big.Int) (*big.Int, error) {
+	return e.GetTotalBonded()
+}
+func (e *StubClient) VotingStake(addr common.Address, block *big.Int) (common.Address, *big.Int, error) {
+	return common.Address{}, big.NewInt(0), nil
+}
 func (e *StubClient) GetTranscoderPoolSize() (*big.Int, error) {
 	return e.PoolSize, e.Errors["GetTranscoderPoolSize"]
 }
@@ -109,6 +109,42 @@ func (ow *OrchestratorWatcher) handleLog(log types.Log) error {
 	}
 }
 
+func (ow *OrchestratorWatcher) replayBlockEvents(events []*blockwatch.Event) error {
+	return replayLogs(events, ow.replayLog)
+}
+
+// replayLog handles a replayed log using only the data of the event instead of the current state of the
+// orchestrator. Service URIs are replayed by the ServiceRegistryWatcher
+func (ow *OrchestratorWatcher) replayLog(log types.Log) error {
+	eventName, err := ow.dec.FindEventName(log)
+	if err != nil {
+		// Noop if we cannot find the event name
+		return nil
+	}
+
+	ow.blockMu.Lock()
+	defer ow.blockMu.Unlock()
+
+	switch eventName {
+	case "TranscoderActivated":
+		var transcoderActivated contracts.BondingManagerTranscoderActivated
+		if err := ow.dec.Decode("TranscoderActivated", log, &transcoderActivated); err != nil {
+			return err
+		}
+		return ow.store.UpdateOrch(
+			&common.DBOrch{
+				EthereumAddr:      transcoderActivated.Transcoder.String(),
+				ActivationRound:   common.ToInt64(transcoderActivated.ActivationRound),
+				DeactivationRound: maxFutureRound,
+			},
+		)
+	case "TranscoderDeactivated":
+		return ow.handleTranscoderDeactivated(log)
+	default:
+		return nil
+	}
+}
+
 func (ow *OrchestratorWatcher) handleTranscoderActivated(log types.Log) error {
 	var transcoderActivated contracts.BondingManagerTranscoderActivated
 	if err := ow.dec.Decode("TranscoderActivated", log, &transcoderActivated); err != nil {
@@ -0,0 +1,339 @@
+package watchers
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/eth/contracts"
+)
+
+type pollStore interface {
+	Polls() ([]*common.DBPoll, error)
+	UpdatePoll(poll *common.DBPoll) error
+	DeletePoll(addr ethcommon.Address) error
+	UpdatePollVote(vote *common.DBPollVote) error
+	DeletePollVote(vote *common.DBPollVote) error
+	PollVotes(poll ethcommon.Address) ([]*common.DBPollVote, error)
+	UpdatePollTally(tally *common.DBPollTally) error
+	PollTally(poll ethcommon.Address) (*common.DBPollTally, error)
+}
+
+// headerGetter fetches block headers with the L1 block number at which the blocks were created
+type headerGetter interface {
+	HeaderByNumber(number *big.Int) (*blockwatch.MiniHeader, error)
+}
+
+// maxHistoricalTallyAttempts is the number of blocks at which the tally of a poll with the stake at its end block is
+// attempted before the PollWatcher falls back to a tally with the current stake
+const maxHistoricalTallyAttempts = 3
+
+// PollWatcher watches for polls created by the PollCreator and for the votes cast in them.
+// Once the L1 block passes the end block of a poll, the poll is tallied with the stake of the voters at the last block
+// created before the L1 block passed the end block and the tally is stored as the final result of the poll.
+// Reading the stake at that block requires the historical state of the chain. If it is not available the tally is
+// taken with the current stake of the voters and stored as an estimate
+type PollWatcher struct {
+	store   pollStore
+	bw      BlockWatcher
+	headers headerGetter
+	lpEth   eth.LivepeerEthClient
+	tw      l1BlockWatcher
+	dec     *EventDecoder
+	// Decoders for the Vote events of the known polls
+	polls map[ethcommon.Address]*EventDecoder
+	// End blocks of the ended polls that could not be tallied yet and the number of failed attempts to tally them
+	endBlocks map[ethcommon.Address]*big.Int
+	attempts  map[ethcommon.Address]int
+
+	quit chan struct{}
+
+	mu sync.Mutex
+}
+
+// NewPollWatcher creates a PollWatcher instance for the polls already in the store
+func NewPollWatcher(pollCreatorAddr ethcommon.Address, bw BlockWatcher, headers headerGetter, store pollStore, lpEth eth.LivepeerEthClient, tw l1BlockWatcher) (*PollWatcher, error) {
+	dec, err := NewEventDecoder(pollCreatorAddr, contracts.PollCreatorABI)
+	if err != nil {
+		return nil, err
+	}
+
+	polls, err := store.Polls()
+	if err != nil {
+		return nil, err
+	}
+
+	pw := &PollWatcher{
+		store:     store,
+		bw:        bw,
+		headers:   headers,
+		lpEth:     lpEth,
+		tw:        tw,
+		dec:       dec,
+		polls:     make(map[ethcommon.Address]*EventDecoder),
+		endBlocks: make(map[ethcommon.Address]*big.Int),
+		attempts:  make(map[ethcommon.Address]int),
+		quit:      make(chan struct{}),
+	}
+	for _, p := range polls {
+		if err := pw.addPoll(p.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	return pw, nil
+}
+
+// Watch kicks off a loop that handles events from a block subscription
+func (pw *PollWatcher) Watch() {
+	events := make(chan []*blockwatch.Event, 10)
+	sub := pw.bw.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-pw.quit:
+			return
+		case err := <-sub.Err():
+			glog.Error(err)
+		case block := <-events:
+			pw.handleBlockEvents(block)
+		}
+	}
+}
+
+// Stop watching for events
+func (pw *PollWatcher) Stop() {
+	close(pw.quit)
+}
+
+func (pw *PollWatcher) handleBlockEvents(events []*blockwatch.Event) {
+	// Latest L1 block of the handled blocks and the block that it was read from
+	var l1Block, head *big.Int
+	for _, event := range events {
+		for _, log := range event.BlockHeader.Logs {
+			if event.Type == blockwatch.Removed {
+				log.Removed = true
+			}
+			if err := pw.handleLog(log); err != nil {
+				glog.Error(err)
+			}
+		}
+		if event.Type == blockwatch.Added && event.BlockHeader.L1BlockNumber != nil {
+			if l1Block == nil || event.BlockHeader.L1BlockNumber.Cmp(l1Block) > 0 {
+				l1Block = event.BlockHeader.L1BlockNumber
+				head = event.BlockHeader.Number
+			}
+		}
+	}
+
+	// Tally after handling the logs of the blocks so that every vote cast before the end of a poll is counted.
+	// The TimeWatcher can be ahead of the blocks handled here or behind them so use the lowest of both
+	if l1Block == nil {
+		return
+	}
+	if last := pw.tw.LastSeenL1Block(); last != nil && last.Cmp(l1Block) < 0 {
+		l1Block = last
+	}
+	if err := pw.tallyEndedPolls(l1Block, head); err != nil {
+		glog.Error(err)
+	}
+}
+
+// tallyEndedPolls stores the tally of the polls that ended before l1Block and have not been tallied yet.
+// The end block of a poll is searched between the block at which it was created and head
+func (pw *PollWatcher) tallyEndedPolls(l1Block, head *big.Int) error {
+	if l1Block == nil || head == nil {
+		return nil
+	}
+
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	polls, err := pw.store.Polls()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range polls {
+		if l1Block.Int64() <= p.EndBlock {
+			continue
+		}
+		tally, err := pw.store.PollTally(p.Address)
+		if err != nil {
+			return err
+		}
+		if tally != nil {
+			continue
+		}
+
+		endBlock, ok := pw.endBlocks[p.Address]
+		if !ok {
+			if endBlock, err = pw.endBlock(p, head); err != nil {
+				return fmt.Errorf("failed to find end block of poll=%v: %v", p.Address.Hex(), err)
+			}
+			pw.endBlocks[p.Address] = endBlock
+		}
+
+		votes, err := pw.store.PollVotes(p.Address)
+		if err != nil {
+			return err
+		}
+		t, totalBonded, err := pw.tally(votes, endBlock)
+		estimate := false
+		if err != nil {
+			pw.attempts[p.Address]++
+			if pw.attempts[p.Address] < maxHistoricalTallyAttempts {
+				return fmt.Errorf("failed to tally poll=%v at block=%v: %v", p.Address.Hex(), endBlock, err)
+			}
+			glog.Warningf("Failed to tally poll=%v at block=%v, tallying with the current stake instead, the node needs the historical state of the chain for a final tally err=%q", p.Address.Hex(), endBlock, err)
+			if t, totalBonded, err = pw.tally(votes, nil); err != nil {
+				return fmt.Errorf("failed to tally poll=%v: %v", p.Address.Hex(), err)
+			}
+			estimate = true
+		}
+
+		err = pw.store.UpdatePollTally(&common.DBPollTally{
+			Poll:        p.Address,
+			Yes:         t.Yes,
+			No:          t.No,
+			Voters:      int64(t.Voters),
+			TotalBonded: totalBonded,
+			L1Block:     l1Block.Int64(),
+			Block:       endBlock.Int64(),
+			Estimate:    estimate,
+		})
+		if err != nil {
+			return err
+		}
+		delete(pw.endBlocks, p.Address)
+		delete(pw.attempts, p.Address)
+		glog.Infof("Tallied poll=%v at block=%v yes=%v no=%v estimate=%v", p.Address.Hex(), endBlock, t.Yes, t.No, estimate)
+	}
+
+	return nil
+}
+
+// tally returns the tally of the votes and the total bonded stake at block or at the latest block if block is nil
+func (pw *PollWatcher) tally(votes []*common.DBPollVote, block *big.Int) (*eth.PollTally, *big.Int, error) {
+	t, err := eth.TallyPoll(pw.lpEth, votes, block)
+	if err != nil {
+		return nil, nil, err
+	}
+	totalBonded, err := pw.lpEth.GetTotalBondedAt(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, totalBonded, nil
+}
+
+// endBlock returns the last block up to head that was created before the L1 block passed the end block of poll
+func (pw *PollWatcher) endBlock(poll *common.DBPoll, head *big.Int) (*big.Int, error) {
+	lo, hi := poll.CreatedBlock, head.Int64()
+	for lo < hi {
+		mid := lo + (hi-lo+1)/2
+		header, err := pw.headers.HeaderByNumber(big.NewInt(mid))
+		if err != nil {
+			return nil, err
+		}
+		if header.L1BlockNumber != nil && header.L1BlockNumber.Int64() <= poll.EndBlock {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return big.NewInt(lo), nil
+}
+
+func (pw *PollWatcher) replayBlockEvents(events []*blockwatch.Event) error {
+	return replayLogs(events, pw.handleLog)
+}
+
+func (pw *PollWatcher) handleLog(log types.Log) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	if eventName, err := pw.dec.FindEventName(log); err == nil {
+		if eventName == "PollCreated" {
+			return pw.handlePollCreated(log)
+		}
+		return nil
+	}
+
+	dec, ok := pw.polls[log.Address]
+	if !ok {
+		// Noop if the log is not emitted by a known poll
+		return nil
+	}
+	if eventName, err := dec.FindEventName(log); err != nil || eventName != "Vote" {
+		return nil
+	}
+
+	var vote contracts.PollVote
+	if err := dec.Decode("Vote", log, &vote); err != nil {
+		return fmt.Errorf("failed to decode Vote event: %v", err)
+	}
+
+	dbVote := &common.DBPollVote{
+		Poll:     log.Address,
+		Voter:    vote.Voter,
+		ChoiceID: vote.ChoiceID.Int64(),
+		Block:    int64(log.BlockNumber),
+	}
+	if log.Removed {
+		if err := pw.store.DeletePollVote(dbVote); err != nil {
+			return processEventError("Vote", true, err)
+		}
+		return nil
+	}
+	if err := pw.store.UpdatePollVote(dbVote); err != nil {
+		return processEventError("Vote", false, err)
+	}
+	return nil
+}
+
+func (pw *PollWatcher) handlePollCreated(log types.Log) error {
+	var pollCreated contracts.PollCreatorPollCreated
+	if err := pw.dec.Decode("PollCreated", log, &pollCreated); err != nil {
+		return fmt.Errorf("failed to decode PollCreated event: %v", err)
+	}
+
+	if log.Removed {
+		delete(pw.polls, pollCreated.Poll)
+		delete(pw.endBlocks, pollCreated.Poll)
+		delete(pw.attempts, pollCreated.Poll)
+		if err := pw.store.DeletePoll(pollCreated.Poll); err != nil {
+			return processEventError("PollCreated", true, err)
+		}
+		return nil
+	}
+
+	err := pw.store.UpdatePoll(&common.DBPoll{
+		Address:      pollCreated.Poll,
+		Proposal:     string(pollCreated.Proposal),
+		EndBlock:     common.ToInt64(pollCreated.EndBlock),
+		Quorum:       common.ToInt64(pollCreated.Quorum),
+		Quota:        common.ToInt64(pollCreated.Quota),
+		CreatedBlock: int64(log.BlockNumber),
+	})
+	if err != nil {
+		return processEventError("PollCreated", false, err)
+	}
+
+	return pw.addPoll(pollCreated.Poll)
+}
+
+func (pw *PollWatcher) addPoll(addr ethcommon.Address) error {
+	dec, err := NewEventDecoder(addr, contracts.PollABI)
+	if err != nil {
+		return err
+	}
+	pw.polls[addr] = dec
+	return nil
+}
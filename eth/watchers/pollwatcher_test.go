@@ -0,0 +1,227 @@
+package watchers
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollWatcher_HandleLog(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	store := newStubPollStore()
+	pw, err := NewPollWatcher(stubPollCreatorAddr, &stubBlockWatcher{}, &stubHeaderGetter{}, store, &eth.StubClient{}, &stubL1BlockWatcher{})
+	require.Nil(err)
+
+	// Votes for unknown polls are ignored
+	require.Nil(pw.handleLog(newStubVoteLog(0)))
+	assert.Empty(store.votes)
+
+	require.Nil(pw.handleLog(newStubPollCreatedLog()))
+	poll := store.polls[stubPoll]
+	require.NotNil(poll)
+	assert.Equal(stubProposal, poll.Proposal)
+	assert.Equal(stubPollEndBlock.Int64(), poll.EndBlock)
+	assert.Equal(stubPollQuorum.Int64(), poll.Quorum)
+	assert.Equal(stubPollQuota.Int64(), poll.Quota)
+	assert.Equal(int64(30), poll.CreatedBlock)
+
+	require.Nil(pw.handleLog(newStubVoteLog(1)))
+	vote := store.latestVote(stubPoll, stubVoter)
+	require.NotNil(vote)
+	assert.Equal(stubPoll, vote.Poll)
+	assert.Equal(int64(1), vote.ChoiceID)
+	assert.Equal(int64(30), vote.Block)
+
+	// A removed vote restores the vote it replaced
+	log := newStubVoteLog(0)
+	log.BlockNumber = 31
+	require.Nil(pw.handleLog(log))
+	assert.Equal(int64(0), store.latestVote(stubPoll, stubVoter).ChoiceID)
+	log.Removed = true
+	require.Nil(pw.handleLog(log))
+	vote = store.latestVote(stubPoll, stubVoter)
+	require.NotNil(vote)
+	assert.Equal(int64(1), vote.ChoiceID)
+
+	// Removed vote
+	log = newStubVoteLog(1)
+	log.Removed = true
+	require.Nil(pw.handleLog(log))
+	assert.Empty(store.votes)
+
+	// Removed poll
+	log = newStubPollCreatedLog()
+	log.Removed = true
+	require.Nil(pw.handleLog(log))
+	assert.Empty(store.polls)
+	require.Nil(pw.handleLog(newStubVoteLog(0)))
+	assert.Empty(store.votes)
+
+	// Logs from other contracts are ignored
+	log = newStubVoteLog(0)
+	log.Address = pm.RandAddress()
+	require.Nil(pw.handleLog(log))
+	assert.Empty(store.votes)
+
+	store.updateErr = errors.New("UpdatePoll error")
+	err = pw.handleLog(newStubPollCreatedLog())
+	assert.EqualError(err, "error processing added PollCreated event: UpdatePoll error")
+}
+
+func TestPollWatcher_KnownPolls(t *testing.T) {
+	store := newStubPollStore()
+	store.polls[stubPoll] = &common.DBPoll{Address: stubPoll}
+	pw, err := NewPollWatcher(stubPollCreatorAddr, &stubBlockWatcher{}, &stubHeaderGetter{}, store, &eth.StubClient{}, &stubL1BlockWatcher{})
+	require.Nil(t, err)
+
+	// Votes are tracked for polls created before the watcher
+	require.Nil(t, pw.handleLog(newStubVoteLog(0)))
+	assert.Len(t, store.votes, 1)
+}
+
+// newStubPollHeaders returns the headers of the blocks up to the default block with the L1 block passing the end
+// block of the stub poll after block 200
+func newStubPollHeaders() *stubHeaderGetter {
+	headers := &stubHeaderGetter{l1Blocks: make(map[int64]int64)}
+	for n := int64(0); n <= defaultMiniHeader().Number.Int64(); n++ {
+		headers.l1Blocks[n] = stubPollEndBlock.Int64()
+		if n > 200 {
+			headers.l1Blocks[n]++
+		}
+	}
+	return headers
+}
+
+func TestPollWatcher_TallyEndedPolls(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	orch := pm.RandAddress()
+	client := &eth.MockClient{StubClient: &eth.StubClient{}}
+	client.On("VotingStake", stubVoter, big.NewInt(200)).Return(orch, big.NewInt(100), nil)
+
+	store := newStubPollStore()
+	headers := newStubPollHeaders()
+	tw := &stubL1BlockWatcher{}
+	pw, err := NewPollWatcher(stubPollCreatorAddr, &stubBlockWatcher{}, headers, store, client, tw)
+	require.Nil(err)
+
+	ended := func(l1Block int64) []*blockwatch.Event {
+		header := defaultMiniHeader()
+		header.L1BlockNumber = big.NewInt(l1Block)
+		return []*blockwatch.Event{{Type: blockwatch.Added, BlockHeader: header}}
+	}
+
+	header := defaultMiniHeader()
+	header.L1BlockNumber = new(big.Int).Set(stubPollEndBlock)
+	header.Logs = []types.Log{newStubPollCreatedLog(), newStubVoteLog(1)}
+	tw.l1Block = header.L1BlockNumber
+	pw.handleBlockEvents([]*blockwatch.Event{{Type: blockwatch.Added, BlockHeader: header}})
+	require.Len(store.polls, 1)
+
+	// The poll is active until the L1 block passes its end block
+	assert.Empty(store.tallies)
+
+	// The TimeWatcher has not seen the next L1 block yet
+	pw.handleBlockEvents(ended(stubPollEndBlock.Int64() + 1))
+	assert.Empty(store.tallies)
+
+	// The poll is tallied with the stake at the last block before the L1 block passed its end block
+	tw.l1Block = big.NewInt(stubPollEndBlock.Int64() + 1)
+	pw.handleBlockEvents(ended(stubPollEndBlock.Int64() + 1))
+	tally := store.tallies[stubPoll]
+	require.NotNil(tally)
+	assert.Equal(big.NewInt(0), tally.Yes)
+	assert.Equal(big.NewInt(100), tally.No)
+	assert.Equal(int64(1), tally.Voters)
+	assert.Equal(big.NewInt(0), tally.TotalBonded)
+	assert.Equal(stubPollEndBlock.Int64()+1, tally.L1Block)
+	assert.Equal(int64(200), tally.Block)
+	assert.False(tally.Estimate)
+
+	// The final tally does not change with the stake of the voters
+	calls := headers.calls
+	tw.l1Block = big.NewInt(stubPollEndBlock.Int64() + 2)
+	pw.handleBlockEvents(ended(stubPollEndBlock.Int64() + 2))
+	assert.Equal(stubPollEndBlock.Int64()+1, store.tallies[stubPoll].L1Block)
+	client.AssertNumberOfCalls(t, "VotingStake", 1)
+	assert.Equal(calls, headers.calls)
+}
+
+func TestPollWatcher_TallyEndedPolls_Estimate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The historical state at the end block of the poll is not available
+	client := &eth.MockClient{StubClient: &eth.StubClient{}}
+	client.On("VotingStake", stubVoter, big.NewInt(200)).Return(ethcommon.Address{}, nil, errors.New("missing trie node"))
+	client.On("VotingStake", stubVoter, (*big.Int)(nil)).Return(pm.RandAddress(), big.NewInt(50), nil)
+
+	store := newStubPollStore()
+	headers := newStubPollHeaders()
+	l1Block := big.NewInt(stubPollEndBlock.Int64() + 1)
+	pw, err := NewPollWatcher(stubPollCreatorAddr, &stubBlockWatcher{}, headers, store, client, &stubL1BlockWatcher{l1Block: l1Block})
+	require.Nil(err)
+	require.Nil(pw.handleLog(newStubPollCreatedLog()))
+	require.Nil(pw.handleLog(newStubVoteLog(0)))
+
+	head := defaultMiniHeader().Number
+	for i := 1; i < maxHistoricalTallyAttempts; i++ {
+		err = pw.tallyEndedPolls(l1Block, head)
+		assert.Contains(err.Error(), "missing trie node")
+		assert.Empty(store.tallies)
+	}
+	// The end block is searched once
+	calls := headers.calls
+
+	require.Nil(pw.tallyEndedPolls(l1Block, head))
+	tally := store.tallies[stubPoll]
+	require.NotNil(tally)
+	assert.Equal(big.NewInt(50), tally.Yes)
+	assert.Equal(int64(200), tally.Block)
+	assert.True(tally.Estimate)
+	assert.Equal(calls, headers.calls)
+
+	// The end block of the poll cannot be found
+	store = newStubPollStore()
+	headers.err = errors.New("HeaderByNumber error")
+	pw, err = NewPollWatcher(stubPollCreatorAddr, &stubBlockWatcher{}, headers, store, client, &stubL1BlockWatcher{})
+	require.Nil(err)
+	require.Nil(pw.handleLog(newStubPollCreatedLog()))
+	err = pw.tallyEndedPolls(l1Block, head)
+	assert.Contains(err.Error(), "HeaderByNumber error")
+	assert.Empty(store.tallies)
+}
+
+func TestPollWatcherLoop(t *testing.T) {
+	assert := assert.New(t)
+
+	bw := &stubBlockWatcher{}
+	store := newStubPollStore()
+	pw, err := NewPollWatcher(stubPollCreatorAddr, bw, &stubHeaderGetter{}, store, &eth.StubClient{}, &stubL1BlockWatcher{})
+	require.Nil(t, err)
+
+	go pw.Watch()
+	defer pw.Stop()
+	time.Sleep(2 * time.Millisecond)
+
+	header := defaultMiniHeader()
+	header.Logs = append(header.Logs, newStubPollCreatedLog(), newStubVoteLog(0))
+	bw.sink <- []*blockwatch.Event{{Type: blockwatch.Added, BlockHeader: header}}
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Len(store.polls, 1)
+	assert.Len(store.votes, 1)
+}
@@ -0,0 +1,49 @@
+package watchers
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/eth/blockwatch"
+)
+
+// EventReplayer replays the events for a historical block range
+type EventReplayer interface {
+	Replay(ctx context.Context, name string, from, to *big.Int, handler blockwatch.ReplayHandler) (*big.Int, error)
+}
+
+// BlockEventHandler is implemented by watchers that can rebuild their state from replayed events.
+// Replayed events are handled using only the data of the events, so replaying a block again, e.g.
+// after an interrupted replay, leaves the same state
+type BlockEventHandler interface {
+	replayBlockEvents(events []*blockwatch.Event) error
+}
+
+// ReplayEvents replays the events for the block range [from, to] through the provided watchers in order.
+// The replay stops at the first error of a watcher so that the failed blocks are not checkpointed.
+// The TimeWatcher should not be used with ReplayEvents because historical events would overwrite its
+// view of the current round.
+// ReplayEvents returns the last block that was replayed
+func ReplayEvents(ctx context.Context, r EventReplayer, name string, from, to *big.Int, handlers ...BlockEventHandler) (*big.Int, error) {
+	return r.Replay(ctx, name, from, to, func(events []*blockwatch.Event) error {
+		for _, h := range handlers {
+			if err := h.replayBlockEvents(events); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// replayLogs passes the logs of replayed events to handle and stops at the first error
+func replayLogs(events []*blockwatch.Event, handle func(types.Log) error) error {
+	for _, event := range events {
+		for _, log := range event.BlockHeader.Logs {
+			if err := handle(log); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package watchers
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/livepeer/go-livepeer/eth"
+	"github.com/livepeer/go-livepeer/eth/blockwatch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplayEvents(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := newStubUnbondingLockStore()
+	watcherAddr := common.HexToAddress("0xF75b78571F6563e8Acf1899F682Fb10A9248CCE8")
+	watcher, err := NewUnbondingWatcher(watcherAddr, stubBondingManagerAddr, &stubBlockWatcher{}, store)
+	require.Nil(err)
+
+	unbondLog := newStubUnbondLog()
+	unbondLog.BlockNumber = 20
+	withdrawLog := newStubWithdrawStakeLog()
+	withdrawLog.BlockNumber = 24
+
+	r := &stubEventReplayer{
+		events: [][]*blockwatch.Event{
+			{
+				{Type: blockwatch.Added, BlockHeader: &blockwatch.MiniHeader{Number: big.NewInt(20), Logs: []types.Log{unbondLog}}},
+			},
+			{
+				{Type: blockwatch.Added, BlockHeader: &blockwatch.MiniHeader{Number: big.NewInt(24), Logs: []types.Log{withdrawLog}}},
+			},
+		},
+	}
+
+	last, err := ReplayEvents(context.Background(), r, "unbonding", big.NewInt(10), big.NewInt(30), watcher)
+	require.Nil(err)
+	assert.Equal(big.NewInt(30), last)
+	assert.Equal("unbonding", r.name)
+	assert.Equal(big.NewInt(10), r.from)
+	assert.Equal(big.NewInt(30), r.to)
+
+	// The unbonding lock is rebuilt synchronously
+	lock := store.Get(1)
+	require.NotNil(lock)
+	assert.Equal(big.NewInt(1457), lock.WithdrawRound)
+	assert.Equal(big.NewInt(24), lock.UsedBlock)
+
+	// Replaying the same blocks again leaves the same state
+	_, err = ReplayEvents(context.Background(), r, "unbonding", big.NewInt(10), big.NewInt(30), watcher)
+	require.Nil(err)
+	lock = store.Get(1)
+	require.NotNil(lock)
+	assert.Equal(big.NewInt(24), lock.UsedBlock)
+
+	// Errors of the watchers are returned
+	store.insertErr = errors.New("insert error")
+	_, err = ReplayEvents(context.Background(), r, "unbonding", big.NewInt(10), big.NewInt(30), watcher)
+	assert.EqualError(err, "error processing added Unbond event: insert error")
+	store.insertErr = nil
+
+	// Replay error
+	r.err = errors.New("replay error")
+	last, err = ReplayEvents(context.Background(), r, "unbonding", big.NewInt(10), big.NewInt(30), watcher)
+	assert.EqualError(err, "replay error")
+	assert.Nil(last)
+}
+
+func TestReplayEvents_OrchestratorsFromEventData(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	store := &stubOrchestratorStore{}
+	// The current state of the orchestrator is not read during a replay
+	lpEth := &eth.StubClient{Err: errors.New("unexpected RPC call")}
+	ow, err := NewOrchestratorWatcher(stubBondingManagerAddr, &stubBlockWatcher{}, store, lpEth, &stubTimeWatcher{})
+	require.Nil(err)
+
+	r := &stubEventReplayer{
+		events: [][]*blockwatch.Event{
+			{
+				{Type: blockwatch.Added, BlockHeader: &blockwatch.MiniHeader{Number: big.NewInt(20), Logs: []types.Log{newStubTranscoderActivatedLog()}}},
+			},
+		},
+	}
+	_, err = ReplayEvents(context.Background(), r, "orchestrators", big.NewInt(10), big.NewInt(30), ow)
+	require.Nil(err)
+	assert.Equal(stubTranscoder.String(), store.ethereumAddr)
+	assert.Equal(stubActivationRound.Int64(), store.activationRound)
+	assert.Equal(maxFutureRound, store.deactivationRound)
+	assert.Empty(store.serviceURI)
+}
@@ -160,6 +160,69 @@ func (sw *SenderWatcher) handleBlockEvents(events []*blockwatch.Event) {
 	}
 }
 
+// replayBlockEvents rebuilds the state of the senders of replayed events. The cached deposit, reserve and
+// claimed reserve of a sender already reflect the chain state when they were loaded, so instead of applying
+// the events again they are dropped and reloaded from the chain on next use
+func (sw *SenderWatcher) replayBlockEvents(events []*blockwatch.Event) error {
+	return replayLogs(events, func(log types.Log) error {
+		sender, ok, err := sw.eventSender(log)
+		if err != nil || !ok {
+			return err
+		}
+		sw.mu.RLock()
+		_, cached := sw.senders[sender]
+		sw.mu.RUnlock()
+		sw.Clear(sender)
+		if cached {
+			sw.reserveChangeFeed.Send(sender)
+		}
+		return nil
+	})
+}
+
+// eventSender returns the sender whose deposit or reserve is changed by a TicketBroker event
+func (sw *SenderWatcher) eventSender(log types.Log) (ethcommon.Address, bool, error) {
+	eventName, err := sw.dec.FindEventName(log)
+	if err != nil {
+		// Noop if we cannot find the event name
+		return ethcommon.Address{}, false, nil
+	}
+
+	var sender ethcommon.Address
+	switch eventName {
+	case "DepositFunded":
+		var ev contracts.TicketBrokerDepositFunded
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.Sender
+	case "ReserveFunded":
+		var ev contracts.TicketBrokerReserveFunded
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.ReserveHolder
+	case "Withdrawal":
+		var ev contracts.TicketBrokerWithdrawal
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.Sender
+	case "WinningTicketTransfer":
+		var ev contracts.TicketBrokerWinningTicketTransfer
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.Sender
+	case "Unlock":
+		var ev contracts.TicketBrokerUnlock
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.Sender
+	case "UnlockCancelled":
+		var ev contracts.TicketBrokerUnlockCancelled
+		err = sw.dec.Decode(eventName, log, &ev)
+		sender = ev.Sender
+	default:
+		return ethcommon.Address{}, false, nil
+	}
+	if err != nil {
+		return ethcommon.Address{}, false, fmt.Errorf("failed to decode %v event: %v", eventName, err)
+	}
+	return sender, true, nil
+}
+
 func (sw *SenderWatcher) handleLog(log types.Log) error {
 	eventName, err := sw.dec.FindEventName(log)
 	if err != nil {
@@ -780,3 +780,51 @@ func TestSubscribeReserveChange(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestSenderWatcher_ReplayBlockEvents(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	lpEth := &eth.StubClient{
+		SenderInfo: &pm.SenderInfo{
+			Deposit: big.NewInt(10),
+			Reserve: &pm.ReserveInfo{
+				FundsRemaining:        big.NewInt(5),
+				ClaimedInCurrentRound: big.NewInt(0),
+			},
+		},
+	}
+	sw, err := NewSenderWatcher(stubTicketBrokerAddr, &stubBlockWatcher{}, lpEth, &stubTimeWatcher{})
+	require.Nil(err)
+
+	sink := make(chan ethcommon.Address, 10)
+	sub := sw.SubscribeReserveChange(sink)
+	defer sub.Unsubscribe()
+
+	header := defaultMiniHeader()
+	header.Logs = append(header.Logs, newStubDepositFundedLog())
+	events := []*blockwatch.Event{{Type: blockwatch.Added, BlockHeader: header}}
+
+	// Senders that aren't cached are left to be loaded on demand
+	require.Nil(sw.replayBlockEvents(events))
+	_, ok := sw.senders[stubSender]
+	assert.False(ok)
+	assert.Len(sink, 0)
+
+	// The state of cached senders is reloaded from the chain instead of applying the events again,
+	// so replaying the same blocks more than once leaves the same state
+	sw.setSenderInfo(stubSender, &pm.SenderInfo{
+		Deposit: big.NewInt(1),
+		Reserve: &pm.ReserveInfo{FundsRemaining: big.NewInt(1), ClaimedInCurrentRound: big.NewInt(0)},
+	})
+	sw.claimedReserve[stubSender] = big.NewInt(1)
+	for i := 0; i < 2; i++ {
+		require.Nil(sw.replayBlockEvents(events))
+		info, err := sw.GetSenderInfo(stubSender)
+		require.Nil(err)
+		assert.Zero(info.Deposit.Cmp(big.NewInt(10)))
+		assert.Zero(info.Reserve.FundsRemaining.Cmp(big.NewInt(5)))
+		_, ok = sw.claimedReserve[stubSender]
+		assert.False(ok)
+	}
+	assert.Equal(stubSender, <-sink)
+}
@@ -69,6 +69,10 @@ func (srw *ServiceRegistryWatcher) handleBlockEvents(events []*blockwatch.Event)
 	}
 }
 
+func (srw *ServiceRegistryWatcher) replayBlockEvents(events []*blockwatch.Event) error {
+	return replayLogs(events, srw.handleLog)
+}
+
 func (srw *ServiceRegistryWatcher) handleLog(log types.Log) error {
 	eventName, err := srw.dec.FindEventName(log)
 	if err != nil {
@@ -1,8 +1,10 @@
 package watchers
 
 import (
+	"context"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	ethcommon "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -25,6 +27,14 @@ var stubBondingManagerAddr = ethcommon.HexToAddress("0x511bc4556d823ae99630ae8de
 var stubRoundsManagerAddr = ethcommon.HexToAddress("0xc1F9BB72216E5ecDc97e248F65E14df1fE46600a")
 var stubTicketBrokerAddr = ethcommon.HexToAddress("0x9d6d492bD500DA5B33cf95A5d610a73360FcaAa0")
 var stubServiceRegistryAddr = ethcommon.HexToAddress("0xF55C0B3c82be82EEBa1557d3Db1bdb55FDF46a4b")
+var stubPollCreatorAddr = ethcommon.HexToAddress("0x2A4A2D26c0a6FE2DFe30C8e6bE4bBa3A0F1B8B9b")
+
+var stubPoll = pm.RandAddress()
+var stubVoter = pm.RandAddress()
+var stubProposal = "QmWBPdeDCi8uxQrUyTV38xwaeYxgmjQmx1Zkiw4vgQhj7x"
+var stubPollEndBlock = big.NewInt(1000)
+var stubPollQuorum = big.NewInt(333300)
+var stubPollQuota = big.NewInt(500000)
 
 func newStubBaseLog() types.Log {
 	return types.Log{
@@ -252,6 +262,43 @@ func newStubServiceURIUpdateLog() types.Log {
 	return log
 }
 
+func newStubPollCreatedLog() types.Log {
+	log := newStubBaseLog()
+	log.Address = stubPollCreatorAddr
+	poll := ethcommon.LeftPadBytes(stubPoll.Bytes(), 32)
+	var pollTopic ethcommon.Hash
+	copy(pollTopic[:], poll[:])
+	log.Topics = []ethcommon.Hash{
+		crypto.Keccak256Hash([]byte("PollCreated(address,bytes,uint256,uint256,uint256)")),
+		pollTopic,
+	}
+
+	var data []byte
+	proposal := []byte(stubProposal)
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(128).Bytes(), 32)...)
+	data = append(data, ethcommon.LeftPadBytes(stubPollEndBlock.Bytes(), 32)...)
+	data = append(data, ethcommon.LeftPadBytes(stubPollQuorum.Bytes(), 32)...)
+	data = append(data, ethcommon.LeftPadBytes(stubPollQuota.Bytes(), 32)...)
+	data = append(data, ethcommon.LeftPadBytes(big.NewInt(int64(len(proposal))).Bytes(), 32)...)
+	data = append(data, ethcommon.RightPadBytes(proposal, 64)...)
+	log.Data = data
+	return log
+}
+
+func newStubVoteLog(choiceID int64) types.Log {
+	log := newStubBaseLog()
+	log.Address = stubPoll
+	voter := ethcommon.LeftPadBytes(stubVoter.Bytes(), 32)
+	var voterTopic ethcommon.Hash
+	copy(voterTopic[:], voter[:])
+	log.Topics = []ethcommon.Hash{
+		crypto.Keccak256Hash([]byte("Vote(address,uint256)")),
+		voterTopic,
+	}
+	log.Data = ethcommon.LeftPadBytes(big.NewInt(choiceID).Bytes(), 32)
+	return log
+}
+
 type stubSubscription struct {
 	errCh        <-chan error
 	unsubscribed bool
@@ -398,3 +445,157 @@ func (s *stubOrchestratorStore) SelectOrchs(filter *common.DBOrchFilter) ([]*com
 		common.NewDBOrch(s.ethereumAddr, s.serviceURI, 0, s.activationRound, s.deactivationRound, s.stake),
 	}, nil
 }
+
+type stubPollStore struct {
+	polls map[ethcommon.Address]*common.DBPoll
+	// votes holds the history of the votes of each voter
+	votes     map[ethcommon.Address][]*common.DBPollVote
+	tallies   map[ethcommon.Address]*common.DBPollTally
+	updateErr error
+}
+
+func newStubPollStore() *stubPollStore {
+	return &stubPollStore{
+		polls:   make(map[ethcommon.Address]*common.DBPoll),
+		votes:   make(map[ethcommon.Address][]*common.DBPollVote),
+		tallies: make(map[ethcommon.Address]*common.DBPollTally),
+	}
+}
+
+func (s *stubPollStore) Polls() ([]*common.DBPoll, error) {
+	polls := []*common.DBPoll{}
+	for _, p := range s.polls {
+		polls = append(polls, p)
+	}
+	return polls, nil
+}
+
+func (s *stubPollStore) UpdatePoll(poll *common.DBPoll) error {
+	if s.updateErr != nil {
+		return s.updateErr
+	}
+	s.polls[poll.Address] = poll
+	return nil
+}
+
+func (s *stubPollStore) DeletePoll(addr ethcommon.Address) error {
+	delete(s.polls, addr)
+	delete(s.tallies, addr)
+	for voter, votes := range s.votes {
+		var kept []*common.DBPollVote
+		for _, v := range votes {
+			if v.Poll != addr {
+				kept = append(kept, v)
+			}
+		}
+		s.setVotes(voter, kept)
+	}
+	return nil
+}
+
+func (s *stubPollStore) UpdatePollVote(vote *common.DBPollVote) error {
+	if s.updateErr != nil {
+		return s.updateErr
+	}
+	s.votes[vote.Voter] = append(s.votes[vote.Voter], vote)
+	return nil
+}
+
+func (s *stubPollStore) DeletePollVote(vote *common.DBPollVote) error {
+	var kept []*common.DBPollVote
+	for _, v := range s.votes[vote.Voter] {
+		if v.Poll != vote.Poll || v.Block != vote.Block {
+			kept = append(kept, v)
+		}
+	}
+	s.setVotes(vote.Voter, kept)
+	return nil
+}
+
+func (s *stubPollStore) setVotes(voter ethcommon.Address, votes []*common.DBPollVote) {
+	if len(votes) == 0 {
+		delete(s.votes, voter)
+		return
+	}
+	s.votes[voter] = votes
+}
+
+// latestVote returns the latest vote of a voter in a poll
+func (s *stubPollStore) latestVote(poll, voter ethcommon.Address) *common.DBPollVote {
+	var latest *common.DBPollVote
+	for _, v := range s.votes[voter] {
+		if v.Poll == poll && (latest == nil || v.Block >= latest.Block) {
+			latest = v
+		}
+	}
+	return latest
+}
+
+func (s *stubPollStore) PollVotes(poll ethcommon.Address) ([]*common.DBPollVote, error) {
+	votes := []*common.DBPollVote{}
+	for voter := range s.votes {
+		if v := s.latestVote(poll, voter); v != nil {
+			votes = append(votes, v)
+		}
+	}
+	return votes, nil
+}
+
+func (s *stubPollStore) UpdatePollTally(tally *common.DBPollTally) error {
+	s.tallies[tally.Poll] = tally
+	return nil
+}
+
+func (s *stubPollStore) PollTally(poll ethcommon.Address) (*common.DBPollTally, error) {
+	return s.tallies[poll], nil
+}
+
+type stubHeaderGetter struct {
+	// L1 block numbers of the blocks by block number
+	l1Blocks map[int64]int64
+	err      error
+	calls    int
+}
+
+func (h *stubHeaderGetter) HeaderByNumber(number *big.Int) (*blockwatch.MiniHeader, error) {
+	h.calls++
+	if h.err != nil {
+		return nil, h.err
+	}
+	l1Block, ok := h.l1Blocks[number.Int64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return &blockwatch.MiniHeader{Number: number, L1BlockNumber: big.NewInt(l1Block)}, nil
+}
+
+type stubL1BlockWatcher struct {
+	l1Block *big.Int
+}
+
+func (tw *stubL1BlockWatcher) LastSeenL1Block() *big.Int {
+	return tw.l1Block
+}
+
+type stubEventReplayer struct {
+	events [][]*blockwatch.Event
+	name   string
+	from   *big.Int
+	to     *big.Int
+	err    error
+}
+
+func (r *stubEventReplayer) Replay(ctx context.Context, name string, from, to *big.Int, handler blockwatch.ReplayHandler) (*big.Int, error) {
+	r.name = name
+	r.from = from
+	r.to = to
+	if r.err != nil {
+		return nil, r.err
+	}
+	for _, events := range r.events {
+		if err := handler(events); err != nil {
+			return nil, err
+		}
+	}
+	return to, nil
+}
@@ -19,6 +19,8 @@ var eventSignatures = []string{
 	"TranscoderActivated(address,uint256)",
 	"TranscoderDeactivated(address,uint256)",
 	"ServiceURIUpdate(address,string)",
+	"PollCreated(address,bytes,uint256,uint256,uint256)",
+	"Vote(address,uint256)",
 }
 
 // FilterTopics returns a list of topics to be used when filtering logs
@@ -1,6 +1,8 @@
 package watchers
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/livepeer/go-livepeer/eth/blockwatch"
@@ -14,3 +16,7 @@ type BlockWatcher interface {
 type timeWatcher interface {
 	SubscribeRounds(sink chan<- types.Log) event.Subscription
 }
+
+type l1BlockWatcher interface {
+	LastSeenL1Block() *big.Int
+}
@@ -82,6 +82,27 @@ func (w *UnbondingWatcher) handleBlockEvents(events []*blockwatch.Event) {
 	}
 }
 
+func (w *UnbondingWatcher) replayBlockEvents(events []*blockwatch.Event) error {
+	return replayLogs(events, w.replayLog)
+}
+
+// replayLog handles a replayed log. The unbonding lock of an Unbond event is recreated so that the
+// event can be replayed more than once
+func (w *UnbondingWatcher) replayLog(log types.Log) error {
+	if eventName, err := w.dec.FindEventName(log); err == nil && eventName == "Unbond" {
+		var unbondEvent contracts.BondingManagerUnbond
+		if err := w.dec.Decode("Unbond", log, &unbondEvent); err != nil {
+			return fmt.Errorf("failed to decode Unbond event: %v", err)
+		}
+		if unbondEvent.Delegator == w.addr {
+			if err := w.store.DeleteUnbondingLock(unbondEvent.UnbondingLockId, unbondEvent.Delegator); err != nil {
+				return processEventError("Unbond", false, err)
+			}
+		}
+	}
+	return w.handleLog(log)
+}
+
 func (w *UnbondingWatcher) handleLog(log types.Log) error {
 	eventName, err := w.dec.FindEventName(log)
 	if err != nil {
@@ -174,11 +174,21 @@ type (
 		mTranscodingPrice      *stats.Float64Measure
 
 		// Metrics for calling rewards
-		mRewardCallError *stats.Int64Measure
+		mRewardCallError  *stats.Int64Measure
+		mRewardCallMissed *stats.Int64Measure
 
 		// Metrics for pixel accounting
 		mMilPixelsProcessed *stats.Float64Measure
 
+		// Metrics for the transcode result cache
+		mTranscodeCacheHits   *stats.Int64Measure
+		mTranscodeCacheMisses *stats.Int64Measure
+		mTranscodeCacheSize   *stats.Int64Measure
+
+		// Metrics for segment racing
+		mSegmentsRaced        *stats.Int64Measure
+		mRaceDuplicateSegment *stats.Int64Measure
+
 		// Metrics for fast verification
 		mFastVerificationDone                   *stats.Int64Measure
 		mFastVerificationFailed                 *stats.Int64Measure
@@ -323,10 +333,20 @@ func InitCensus(nodeType NodeType, version string) {
 
 	// Metrics for calling rewards
 	census.mRewardCallError = stats.Int64("reward_call_errors", "RewardCallError", "tot")
+	census.mRewardCallMissed = stats.Int64("reward_call_missed", "RewardCallMissed", "tot")
 
 	// Metrics for pixel accounting
 	census.mMilPixelsProcessed = stats.Float64("mil_pixels_processed", "MilPixelsProcessed", "mil pixels")
 
+	// Metrics for the transcode result cache
+	census.mTranscodeCacheHits = stats.Int64("transcode_cache_hits", "TranscodeCacheHits", "tot")
+	census.mTranscodeCacheMisses = stats.Int64("transcode_cache_misses", "TranscodeCacheMisses", "tot")
+	census.mTranscodeCacheSize = stats.Int64("transcode_cache_size_bytes", "TranscodeCacheSize", "bytes")
+
+	// Metrics for segment racing
+	census.mSegmentsRaced = stats.Int64("segments_raced", "SegmentsRaced", "tot")
+	census.mRaceDuplicateSegment = stats.Int64("race_duplicate_segments", "RaceDuplicateSegments", "tot")
+
 	// Metrics for fast verification
 	census.mFastVerificationDone = stats.Int64("fast_verification_done", "FastVerificationDone", "tot")
 	census.mFastVerificationFailed = stats.Int64("fast_verification_failed", "FastVerificationFailed", "tot")
@@ -786,6 +806,45 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTagsWithManifestIDAndIP,
 			Aggregation: view.Sum(),
 		},
+
+		// Metrics for the transcode result cache
+		{
+			Name:        "transcode_cache_hits",
+			Measure:     census.mTranscodeCacheHits,
+			Description: "Segments whose transcoding results were found in the cache",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "transcode_cache_misses",
+			Measure:     census.mTranscodeCacheMisses,
+			Description: "Segments whose transcoding results were not found in the cache",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "transcode_cache_size_bytes",
+			Measure:     census.mTranscodeCacheSize,
+			Description: "Size of the transcoding results in the cache",
+			TagKeys:     baseTags,
+			Aggregation: view.LastValue(),
+		},
+
+		// Metrics for segment racing
+		{
+			Name:        "segments_raced",
+			Measure:     census.mSegmentsRaced,
+			Description: "Segments sent to several orchestrators to use the first results",
+			TagKeys:     baseTagsWithManifestID,
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        "race_duplicate_segments",
+			Measure:     census.mRaceDuplicateSegment,
+			Description: "Duplicate submissions of raced segments",
+			TagKeys:     baseTagsWithManifestID,
+			Aggregation: view.Sum(),
+		},
 		{
 			Name:        "suggested_gas_price",
 			Measure:     census.mSuggestedGasPrice,
@@ -809,6 +868,13 @@ func InitCensus(nodeType NodeType, version string) {
 			TagKeys:     baseTags,
 			Aggregation: view.Sum(),
 		},
+		{
+			Name:        "reward_call_missed",
+			Measure:     census.mRewardCallMissed,
+			Description: "Rounds that ended without a successful reward call",
+			TagKeys:     baseTags,
+			Aggregation: view.Sum(),
+		},
 
 		// Metrics for fast verification
 		{
@@ -1670,6 +1736,27 @@ func MilPixelsProcessed(ctx context.Context, milPixels float64) {
 	}
 }
 
+func TranscodeCacheHit() {
+	stats.Record(census.ctx, census.mTranscodeCacheHits.M(1))
+}
+
+func TranscodeCacheMiss() {
+	stats.Record(census.ctx, census.mTranscodeCacheMisses.M(1))
+}
+
+// TranscodeCacheSize records the size of the transcoding results in the cache
+func TranscodeCacheSize(bytes int64) {
+	stats.Record(census.ctx, census.mTranscodeCacheSize.M(bytes))
+}
+
+// SegmentRaced records a segment that was raced on the given number of sessions
+func SegmentRaced(ctx context.Context, sessions int) {
+	if err := stats.RecordWithTags(census.ctx, manifestIDTag(ctx),
+		census.mSegmentsRaced.M(1), census.mRaceDuplicateSegment.M(int64(sessions-1))); err != nil {
+		clog.Errorf(ctx, "Error recording metrics err=%q", err)
+	}
+}
+
 // SuggestedGasPrice records the last suggested gas price
 func SuggestedGasPrice(gasPrice *big.Int) {
 	stats.Record(census.ctx, census.mSuggestedGasPrice.M(wei2gwei(gasPrice)))
@@ -1705,6 +1792,10 @@ func RewardCallError(sender string) {
 	}
 }
 
+func RewardCallMissed() {
+	stats.Record(census.ctx, census.mRewardCallMissed.M(1))
+}
+
 // Convert wei to gwei
 func wei2gwei(wei *big.Int) float64 {
 	gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(float64(gweiConversionFactor))).Float64()
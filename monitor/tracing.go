@@ -0,0 +1,149 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/livepeer/go-livepeer"
+
+// Keys of the log context that are added to spans
+var spanLogKeys = []string{"manifestID", "sessionID", "nonce", "seqNo", "orchSessionID", "orchestrator"}
+
+// TracingConfig configures where the spans of segments are exported to
+type TracingConfig struct {
+	// OTLP gRPC collector endpoint as host:port; an http:// URL disables TLS
+	OTLPEndpoint string
+	// File that spans are written to as JSON, one span per line
+	File string
+	// Fraction of the segments that are traced
+	SampleRate float64
+}
+
+// InitTracing sets up the exporter of spans and the propagation of the trace
+// context between nodes. The returned function flushes the pending spans.
+func InitTracing(ctx context.Context, nodeType NodeType, version string, cfg TracingConfig) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var closer io.Closer
+	switch {
+	case cfg.OTLPEndpoint != "" && cfg.File != "":
+		return nil, errors.New("only one of the OTLP endpoint and the trace file can be set")
+	case cfg.OTLPEndpoint != "":
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)}
+		if u, err := url.Parse(cfg.OTLPEndpoint); err == nil && u.Host != "" {
+			opts = []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(u.Host)}
+			if u.Scheme == "http" {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+		}
+		exp, err := otlptracegrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("error creating OTLP exporter: %w", err)
+		}
+		exporter = exp
+	case cfg.File != "":
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		exporter, closer = exp, f
+	default:
+		return nil, errors.New("missing OTLP endpoint or trace file")
+	}
+
+	res := resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("livepeer"),
+		semconv.ServiceVersion(version),
+		semconv.ServiceInstanceID(NodeID),
+		attribute.String("livepeer.node_type", string(nodeType)),
+	)
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRate))),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+// StartSpan starts a span that is tagged with the stream and segment of the
+// log context. Spans are dropped unless tracing is initialized.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	for _, key := range spanLogKeys {
+		if val := clog.GetVal(ctx, key); val != "" {
+			if key == "seqNo" || key == "nonce" {
+				if n, err := strconv.ParseInt(val, 10, 64); err == nil {
+					attrs = append(attrs, attribute.Int64(key, n))
+					continue
+				}
+			}
+			attrs = append(attrs, attribute.String(key, val))
+		}
+	}
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends a span, recording err if set
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// InjectTraceHeaders adds the trace context of ctx to the headers of a request
+func InjectTraceHeaders(ctx context.Context, header http.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractTraceHeaders returns ctx with the trace context of a request's headers
+func ExtractTraceHeaders(ctx context.Context, header http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// TraceContext returns the trace context of ctx to propagate it in messages
+func TraceContext(ctx context.Context) map[string]string {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	if len(carrier) == 0 {
+		return nil
+	}
+	return carrier
+}
+
+// WithTraceContext returns ctx with the trace context propagated in a message
+func WithTraceContext(ctx context.Context, traceContext map[string]string) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(traceContext))
+}
@@ -0,0 +1,117 @@
+package monitor
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func resetTracing(t *testing.T) {
+	tp, prop := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tp)
+		otel.SetTextMapPropagator(prop)
+	})
+}
+
+func TestInitTracing_File(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	resetTracing(t)
+
+	file := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := InitTracing(context.Background(), Broadcaster, "0.0.0", TracingConfig{File: file, SampleRate: 1})
+	require.Nil(err)
+
+	ctx := clog.AddManifestID(context.Background(), "mid")
+	ctx = clog.AddSeqNo(ctx, 7)
+	ctx, parent := StartSpan(ctx, "processSegment")
+	_, child := StartSpan(ctx, "SubmitSegment")
+	EndSpan(child, errors.New("submit error"))
+	EndSpan(parent, nil)
+	require.Nil(shutdown(context.Background()))
+
+	f, err := os.Open(file)
+	require.Nil(err)
+	defer f.Close()
+	type span struct {
+		Name        string
+		SpanContext struct{ TraceID string }
+		Parent      struct{ SpanID string }
+		Status      struct{ Code string }
+		Attributes  []struct {
+			Key   string
+			Value struct{ Value interface{} }
+		}
+	}
+	var spans []span
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s span
+		require.Nil(json.Unmarshal(scanner.Bytes(), &s))
+		spans = append(spans, s)
+	}
+	require.Len(spans, 2)
+	assert.Equal("SubmitSegment", spans[0].Name)
+	assert.Equal("Error", spans[0].Status.Code)
+	assert.Equal("processSegment", spans[1].Name)
+	assert.Equal(spans[1].SpanContext.TraceID, spans[0].SpanContext.TraceID)
+	attrs := make(map[string]interface{})
+	for _, a := range spans[1].Attributes {
+		attrs[a.Key] = a.Value.Value
+	}
+	assert.Equal("mid", attrs["manifestID"])
+	assert.Equal(float64(7), attrs["seqNo"])
+}
+
+func TestInitTracing_Errors(t *testing.T) {
+	resetTracing(t)
+
+	_, err := InitTracing(context.Background(), Broadcaster, "0.0.0", TracingConfig{})
+	assert.EqualError(t, err, "missing OTLP endpoint or trace file")
+	_, err = InitTracing(context.Background(), Broadcaster, "0.0.0", TracingConfig{OTLPEndpoint: "localhost:4317", File: "traces.json"})
+	assert.EqualError(t, err, "only one of the OTLP endpoint and the trace file can be set")
+}
+
+func TestTracePropagation(t *testing.T) {
+	assert := assert.New(t)
+	resetTracing(t)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	// Through HTTP headers
+	header := http.Header{}
+	InjectTraceHeaders(ctx, header)
+	assert.Equal("00-0102030405060708090a0b0c0d0e0f10-0102030405060708-01", header.Get("traceparent"))
+	sc := trace.SpanContextFromContext(ExtractTraceHeaders(context.Background(), header))
+	assert.Equal(traceID, sc.TraceID())
+	assert.True(sc.IsRemote())
+
+	// Through messages
+	traceContext := TraceContext(ctx)
+	sc = trace.SpanContextFromContext(WithTraceContext(context.Background(), traceContext))
+	assert.Equal(spanID, sc.SpanID())
+
+	// Nothing to propagate without a span
+	assert.Nil(TraceContext(context.Background()))
+	assert.False(trace.SpanContextFromContext(WithTraceContext(context.Background(), nil)).IsValid())
+}
@@ -821,7 +821,9 @@ type SegData struct {
 	// Transcoding parameters specific to this segment
 	SegmentParameters *SegParameters `protobuf:"bytes,37,opt,name=segment_parameters,json=segmentParameters,proto3" json:"segment_parameters,omitempty"`
 	// Force HW Session Reinit
-	ForceSessionReinit   bool     `protobuf:"varint,38,opt,name=ForceSessionReinit,proto3" json:"ForceSessionReinit,omitempty"`
+	ForceSessionReinit bool `protobuf:"varint,38,opt,name=ForceSessionReinit,proto3" json:"ForceSessionReinit,omitempty"`
+	// Priority class of the stream. Higher priority streams are scheduled first
+	Priority             int32    `protobuf:"varint,39,opt,name=priority,proto3" json:"priority,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -957,6 +959,13 @@ func (m *SegData) GetForceSessionReinit() bool {
 	return false
 }
 
+func (m *SegData) GetPriority() int32 {
+	if m != nil {
+		return m.Priority
+	}
+	return 0
+}
+
 type SegParameters struct {
 	// Start timestamp from which to start encoding
 	// Milliseconds, from start of the file
@@ -1370,10 +1379,16 @@ type RegisterRequest struct {
 	// Transcoder capacity
 	Capacity int64 `protobuf:"varint,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
 	// Transcoder capabilities
-	Capabilities         *Capabilities `protobuf:"bytes,3,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
-	XXX_unrecognized     []byte        `json:"-"`
-	XXX_sizecache        int32         `json:"-"`
+	Capabilities *Capabilities `protobuf:"bytes,3,opt,name=capabilities,proto3" json:"capabilities,omitempty"`
+	// Number of output pixels per second the transcoder can encode
+	PixelCapacity int64 `protobuf:"varint,4,opt,name=pixelCapacity,proto3" json:"pixelCapacity,omitempty"`
+	// Stable identifier of the transcoder that is used to reattach its sessions when it reconnects
+	Id string `protobuf:"bytes,5,opt,name=id,proto3" json:"id,omitempty"`
+	// Transcoder leases segments from the orchestrator over HTTP instead of receiving them on the stream
+	Pull                 bool     `protobuf:"varint,6,opt,name=pull,proto3" json:"pull,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *RegisterRequest) Reset()         { *m = RegisterRequest{} }
@@ -1422,6 +1437,27 @@ func (m *RegisterRequest) GetCapabilities() *Capabilities {
 	return nil
 }
 
+func (m *RegisterRequest) GetPixelCapacity() int64 {
+	if m != nil {
+		return m.PixelCapacity
+	}
+	return 0
+}
+
+func (m *RegisterRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RegisterRequest) GetPull() bool {
+	if m != nil {
+		return m.Pull
+	}
+	return false
+}
+
 // Sent by the orchestrator to the transcoder
 type NotifySegment struct {
 	// URL of the segment to transcode.
@@ -1432,10 +1468,12 @@ type NotifySegment struct {
 	TaskId int64 `protobuf:"varint,16,opt,name=taskId,proto3" json:"taskId,omitempty"`
 	// Deprecated by fullProfiles. Set of presets to transcode into.
 	// Should be set to an invalid value to induce failures
-	Profiles             []byte   `protobuf:"bytes,17,opt,name=profiles,proto3" json:"profiles,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Profiles []byte `protobuf:"bytes,17,opt,name=profiles,proto3" json:"profiles,omitempty"`
+	// Trace context of the segment, propagated to the transcoder
+	TraceContext         map[string]string `protobuf:"bytes,18,rep,name=traceContext,proto3" json:"traceContext,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *NotifySegment) Reset()         { *m = NotifySegment{} }
@@ -1491,6 +1529,13 @@ func (m *NotifySegment) GetProfiles() []byte {
 	return nil
 }
 
+func (m *NotifySegment) GetTraceContext() map[string]string {
+	if m != nil {
+		return m.TraceContext
+	}
+	return nil
+}
+
 // Required parameters for probabilistic micropayment tickets
 type TicketParams struct {
 	// ETH address of the recipient
@@ -1768,6 +1813,178 @@ func (m *Payment) GetExpectedPrice() *PriceInfo {
 	return nil
 }
 
+// Sent by the broadcaster to reserve sessions on the orchestrator for a time window
+type CapacityReservationRequest struct {
+	// Ethereum address of the broadcaster
+	Address []byte `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	// Broadcaster's signature over the digest of the request
+	Sig []byte `protobuf:"bytes,2,opt,name=sig,proto3" json:"sig,omitempty"`
+	// Number of sessions to reserve
+	Sessions int32 `protobuf:"varint,3,opt,name=sessions,proto3" json:"sessions,omitempty"`
+	// Transcoding profiles of the reserved sessions
+	Profiles []*VideoProfile `protobuf:"bytes,4,rep,name=profiles,proto3" json:"profiles,omitempty"`
+	// Unix time in seconds when the reservation starts
+	Start int64 `protobuf:"varint,5,opt,name=start,proto3" json:"start,omitempty"`
+	// Unix time in seconds when the reservation ends
+	End int64 `protobuf:"varint,6,opt,name=end,proto3" json:"end,omitempty"`
+	// Prepayment for the reservation
+	Payment *Payment `protobuf:"bytes,7,opt,name=payment,proto3" json:"payment,omitempty"`
+	// Random nonce that makes the signature of the request unique
+	Nonce                uint64   `protobuf:"varint,8,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CapacityReservationRequest) Reset()         { *m = CapacityReservationRequest{} }
+func (m *CapacityReservationRequest) String() string { return proto.CompactTextString(m) }
+func (*CapacityReservationRequest) ProtoMessage()    {}
+func (*CapacityReservationRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_034e29c79f9ba827, []int{22}
+}
+
+func (m *CapacityReservationRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CapacityReservationRequest.Unmarshal(m, b)
+}
+func (m *CapacityReservationRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CapacityReservationRequest.Marshal(b, m, deterministic)
+}
+func (m *CapacityReservationRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CapacityReservationRequest.Merge(m, src)
+}
+func (m *CapacityReservationRequest) XXX_Size() int {
+	return xxx_messageInfo_CapacityReservationRequest.Size(m)
+}
+func (m *CapacityReservationRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CapacityReservationRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CapacityReservationRequest proto.InternalMessageInfo
+
+func (m *CapacityReservationRequest) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *CapacityReservationRequest) GetSig() []byte {
+	if m != nil {
+		return m.Sig
+	}
+	return nil
+}
+
+func (m *CapacityReservationRequest) GetSessions() int32 {
+	if m != nil {
+		return m.Sessions
+	}
+	return 0
+}
+
+func (m *CapacityReservationRequest) GetProfiles() []*VideoProfile {
+	if m != nil {
+		return m.Profiles
+	}
+	return nil
+}
+
+func (m *CapacityReservationRequest) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *CapacityReservationRequest) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
+func (m *CapacityReservationRequest) GetPayment() *Payment {
+	if m != nil {
+		return m.Payment
+	}
+	return nil
+}
+
+func (m *CapacityReservationRequest) GetNonce() uint64 {
+	if m != nil {
+		return m.Nonce
+	}
+	return 0
+}
+
+// Sent by the orchestrator to confirm a reservation
+type CapacityReservation struct {
+	// Identifier of the reservation
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Number of reserved sessions
+	Sessions int32 `protobuf:"varint,2,opt,name=sessions,proto3" json:"sessions,omitempty"`
+	// Unix time in seconds when the reservation starts
+	Start int64 `protobuf:"varint,3,opt,name=start,proto3" json:"start,omitempty"`
+	// Unix time in seconds when the reservation ends
+	End                  int64    `protobuf:"varint,4,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CapacityReservation) Reset()         { *m = CapacityReservation{} }
+func (m *CapacityReservation) String() string { return proto.CompactTextString(m) }
+func (*CapacityReservation) ProtoMessage()    {}
+func (*CapacityReservation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_034e29c79f9ba827, []int{23}
+}
+
+func (m *CapacityReservation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CapacityReservation.Unmarshal(m, b)
+}
+func (m *CapacityReservation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CapacityReservation.Marshal(b, m, deterministic)
+}
+func (m *CapacityReservation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CapacityReservation.Merge(m, src)
+}
+func (m *CapacityReservation) XXX_Size() int {
+	return xxx_messageInfo_CapacityReservation.Size(m)
+}
+func (m *CapacityReservation) XXX_DiscardUnknown() {
+	xxx_messageInfo_CapacityReservation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CapacityReservation proto.InternalMessageInfo
+
+func (m *CapacityReservation) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CapacityReservation) GetSessions() int32 {
+	if m != nil {
+		return m.Sessions
+	}
+	return 0
+}
+
+func (m *CapacityReservation) GetStart() int64 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *CapacityReservation) GetEnd() int64 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterEnum("net.OSInfo_StorageType", OSInfo_StorageType_name, OSInfo_StorageType_value)
 	proto.RegisterEnum("net.VideoProfile_Format", VideoProfile_Format_name, VideoProfile_Format_value)
@@ -1794,10 +2011,13 @@ func init() {
 	proto.RegisterType((*TranscodeResult)(nil), "net.TranscodeResult")
 	proto.RegisterType((*RegisterRequest)(nil), "net.RegisterRequest")
 	proto.RegisterType((*NotifySegment)(nil), "net.NotifySegment")
+	proto.RegisterMapType((map[string]string)(nil), "net.NotifySegment.TraceContextEntry")
 	proto.RegisterType((*TicketParams)(nil), "net.TicketParams")
 	proto.RegisterType((*TicketSenderParams)(nil), "net.TicketSenderParams")
 	proto.RegisterType((*TicketExpirationParams)(nil), "net.TicketExpirationParams")
 	proto.RegisterType((*Payment)(nil), "net.Payment")
+	proto.RegisterType((*CapacityReservationRequest)(nil), "net.CapacityReservationRequest")
+	proto.RegisterType((*CapacityReservation)(nil), "net.CapacityReservation")
 }
 
 func init() {
@@ -1805,125 +2025,138 @@ func init() {
 }
 
 var fileDescriptor_034e29c79f9ba827 = []byte{
-	// 1911 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xef, 0x6e, 0x1b, 0xc7,
-	0x11, 0x17, 0xff, 0x88, 0x7f, 0x86, 0xa4, 0x74, 0x5c, 0x4b, 0xca, 0x49, 0xb1, 0x53, 0xf9, 0x12,
-	0x07, 0xca, 0x07, 0x2b, 0x06, 0x25, 0xbb, 0x71, 0x81, 0xa2, 0xa5, 0x24, 0x5a, 0x62, 0x60, 0x49,
-	0xc4, 0x52, 0x36, 0xd0, 0x7e, 0x28, 0x7b, 0xba, 0x5b, 0x92, 0x57, 0x91, 0x7b, 0xe7, 0xbd, 0x65,
-	0x6c, 0x05, 0x7d, 0x81, 0x3e, 0x42, 0xfb, 0xa5, 0x40, 0x81, 0xbe, 0x47, 0x1f, 0xa0, 0x0f, 0x50,
-	0xf4, 0x39, 0xfa, 0x00, 0xc5, 0xce, 0xee, 0x1d, 0x8f, 0xa2, 0x92, 0x18, 0xf9, 0xb6, 0xf3, 0x9b,
-	0xd9, 0xd9, 0xd9, 0x99, 0x9d, 0x3f, 0x77, 0x60, 0x71, 0x26, 0xbf, 0x9e, 0x44, 0x03, 0x11, 0x79,
-	0xfb, 0x91, 0x08, 0x65, 0x48, 0x0a, 0x9c, 0x49, 0x67, 0x17, 0x2a, 0xbd, 0x80, 0x8f, 0x7a, 0x21,
-	0x1f, 0x91, 0x0d, 0x58, 0xfd, 0xce, 0x9d, 0xcc, 0x98, 0x9d, 0xdb, 0xcd, 0xed, 0xd5, 0xa9, 0x26,
-	0x9c, 0x73, 0x78, 0xd8, 0xe1, 0xfe, 0x95, 0x70, 0x79, 0xec, 0x85, 0x7e, 0xc0, 0x47, 0x7d, 0x16,
-	0xc7, 0x41, 0xc8, 0x29, 0x7b, 0x37, 0x63, 0xb1, 0x24, 0x4f, 0x01, 0xdc, 0x99, 0x1c, 0x0f, 0x64,
-	0x78, 0xc3, 0x38, 0x6e, 0xad, 0xb5, 0xd6, 0xf6, 0x39, 0x93, 0xfb, 0xed, 0x99, 0x1c, 0x5f, 0x29,
-	0x94, 0x56, 0xdd, 0x64, 0xe9, 0xfc, 0x02, 0x1e, 0xfd, 0x80, 0xba, 0x38, 0x0a, 0x79, 0xcc, 0x9c,
-	0x36, 0x3c, 0xb8, 0x14, 0xde, 0x98, 0xc5, 0x52, 0xb8, 0x32, 0x14, 0xc9, 0x31, 0x36, 0x94, 0x5d,
-	0xdf, 0x17, 0x2c, 0x8e, 0x8d, 0x79, 0x09, 0x49, 0x2c, 0x28, 0xc4, 0xc1, 0xc8, 0xce, 0x23, 0xaa,
-	0x96, 0xce, 0x5f, 0x73, 0x50, 0xba, 0xec, 0x77, 0xf9, 0x30, 0x24, 0x2f, 0xa1, 0x16, 0xcb, 0x50,
-	0xb8, 0x23, 0x76, 0x75, 0x1b, 0xe9, 0x9b, 0xad, 0xb5, 0x3e, 0x41, 0xf3, 0xb4, 0xc4, 0x7e, 0x7f,
-	0xce, 0xa6, 0x59, 0x59, 0xf2, 0x04, 0x4a, 0xf1, 0x41, 0xc0, 0x87, 0xa1, 0x6d, 0xe1, 0xa5, 0x1a,
-	0xb8, 0xab, 0x7f, 0xa0, 0xf7, 0x51, 0xc3, 0x74, 0x9e, 0x42, 0x2d, 0xa3, 0x82, 0x00, 0x94, 0x4e,
-	0xba, 0xb4, 0x73, 0x7c, 0x65, 0xad, 0x90, 0x12, 0xe4, 0xfb, 0x07, 0x56, 0x4e, 0x61, 0xa7, 0x97,
-	0x97, 0xa7, 0xaf, 0x3b, 0x56, 0xde, 0xf9, 0x47, 0x0e, 0x2a, 0x89, 0x0e, 0x42, 0xa0, 0x38, 0x0e,
-	0x63, 0x89, 0x66, 0x55, 0x29, 0xae, 0xd5, 0x75, 0x6e, 0xd8, 0x2d, 0x5e, 0xa7, 0x4a, 0xd5, 0x92,
-	0x6c, 0x41, 0x29, 0x0a, 0x27, 0x81, 0x77, 0x6b, 0x17, 0x10, 0x34, 0x14, 0x79, 0x08, 0xd5, 0x38,
-	0x18, 0x71, 0x57, 0xce, 0x04, 0xb3, 0x8b, 0xc8, 0x9a, 0x03, 0xe4, 0x33, 0x00, 0x4f, 0x30, 0x9f,
-	0x71, 0x19, 0xb8, 0x13, 0x7b, 0x15, 0xd9, 0x19, 0x84, 0xec, 0x40, 0xe5, 0x43, 0x7b, 0xfa, 0xfd,
-	0x89, 0x2b, 0x99, 0x5d, 0x42, 0x6e, 0x4a, 0x3b, 0x6f, 0xa0, 0xda, 0x13, 0x81, 0xc7, 0xd0, 0x48,
-	0x07, 0xea, 0x91, 0x22, 0x7a, 0x4c, 0xbc, 0xe1, 0x81, 0x36, 0xb6, 0x40, 0x17, 0x30, 0xf2, 0x05,
-	0x34, 0xa2, 0xe0, 0x03, 0x9b, 0xc4, 0x89, 0x50, 0x1e, 0x85, 0x16, 0x41, 0xe7, 0xbf, 0x79, 0xa8,
-	0x1f, 0xbb, 0x91, 0x7b, 0x1d, 0x4c, 0x02, 0x19, 0xb0, 0x58, 0xdd, 0xe0, 0x3a, 0x90, 0xb1, 0x14,
-	0x01, 0x1f, 0xd9, 0xb9, 0xdd, 0xc2, 0x5e, 0x91, 0xce, 0x01, 0xb2, 0x0b, 0xb5, 0xa9, 0xcb, 0x7d,
-	0xf5, 0x0a, 0x02, 0x16, 0xdb, 0x79, 0xe4, 0x67, 0x21, 0xd2, 0x06, 0xf0, 0xdc, 0xc8, 0xf5, 0x50,
-	0x9b, 0x5d, 0xd8, 0x2d, 0xec, 0xd5, 0x5a, 0x8f, 0x31, 0x4c, 0xd9, 0x63, 0x90, 0xd0, 0x32, 0x1d,
-	0x2e, 0xc5, 0x2d, 0xcd, 0x6c, 0x52, 0xef, 0xea, 0x3b, 0x26, 0xd4, 0x0b, 0x34, 0x2e, 0x4c, 0x48,
-	0xf2, 0x1b, 0xa8, 0x79, 0x21, 0x57, 0xcf, 0x30, 0xe0, 0x32, 0x46, 0x0f, 0xd6, 0x5a, 0x8f, 0xee,
-	0xd1, 0x3e, 0x17, 0xa2, 0xd9, 0x1d, 0x3b, 0xbf, 0x86, 0xf5, 0x3b, 0x27, 0x27, 0xc1, 0x55, 0x2e,
-	0x6c, 0xe8, 0xe0, 0xa6, 0x49, 0x97, 0x47, 0x4c, 0x13, 0xbf, 0xca, 0x7f, 0x93, 0xdb, 0x79, 0x0a,
-	0xb5, 0x8c, 0x6a, 0x15, 0xcf, 0x69, 0xc0, 0xdf, 0x1a, 0x5b, 0xf5, 0x8b, 0xc9, 0x20, 0xce, 0xbf,
-	0xf2, 0x60, 0x65, 0x13, 0x07, 0x63, 0xf7, 0x19, 0x80, 0x34, 0xa9, 0xc6, 0x44, 0xb2, 0x69, 0x8e,
-	0x90, 0x17, 0xd0, 0x90, 0x81, 0x77, 0xc3, 0xe4, 0x20, 0x72, 0x85, 0x3b, 0x8d, 0xd1, 0x8a, 0x5a,
-	0xab, 0x89, 0xb7, 0xbc, 0x42, 0x4e, 0x0f, 0x19, 0xb4, 0x2e, 0x33, 0x94, 0x4a, 0x7a, 0x8c, 0xff,
-	0x00, 0xf3, 0xa3, 0x90, 0x49, 0xfa, 0xf4, 0xdd, 0xd0, 0x6a, 0x94, 0x3e, 0xa1, 0x4c, 0xf2, 0x16,
-	0x17, 0x93, 0xf7, 0x39, 0xd4, 0xbd, 0x8c, 0x33, 0x8d, 0x97, 0x9b, 0x4b, 0x5e, 0xa6, 0x0b, 0x62,
-	0x77, 0x8a, 0x4e, 0xe9, 0x27, 0x8a, 0x0e, 0x79, 0x02, 0x65, 0x93, 0xd9, 0xf6, 0x2e, 0x3e, 0x92,
-	0x5a, 0xa6, 0x02, 0xd0, 0x84, 0xe7, 0xfc, 0x11, 0xaa, 0xe9, 0x76, 0x15, 0x98, 0x79, 0x49, 0xab,
-	0x53, 0x4d, 0x90, 0x47, 0x00, 0xb1, 0x2e, 0x58, 0x83, 0xc0, 0x37, 0x49, 0x5a, 0x35, 0x48, 0xd7,
-	0x57, 0xfe, 0x66, 0x1f, 0xa2, 0x40, 0xb8, 0x52, 0x05, 0xa9, 0x80, 0x49, 0x90, 0x41, 0x9c, 0xff,
-	0x15, 0xa1, 0xdc, 0x67, 0xa3, 0x13, 0x57, 0xba, 0x18, 0x50, 0x97, 0x07, 0x43, 0x16, 0xcb, 0xae,
-	0x6f, 0x4e, 0xc9, 0x20, 0x58, 0xd7, 0xd8, 0x3b, 0x93, 0x49, 0x6a, 0x89, 0xe5, 0xc2, 0x8d, 0xc7,
-	0xa8, 0xb7, 0x4e, 0x71, 0xad, 0xd2, 0x38, 0x12, 0xe1, 0x30, 0x98, 0xb0, 0xc4, 0xb7, 0x29, 0x9d,
-	0x54, 0xc6, 0xd5, 0xb4, 0x32, 0x2a, 0x69, 0x7f, 0x66, 0xac, 0x53, 0x5e, 0x5b, 0xa5, 0x29, 0xbd,
-	0x14, 0x8a, 0xf2, 0xcf, 0x09, 0x45, 0xe5, 0xa7, 0x42, 0xf1, 0x0c, 0x36, 0x3c, 0x77, 0xe2, 0x0d,
-	0x22, 0x26, 0x3c, 0x16, 0xc9, 0x99, 0x3b, 0x19, 0xe0, 0x9d, 0x60, 0x37, 0xb7, 0x57, 0xa1, 0x44,
-	0xf1, 0x7a, 0x29, 0xeb, 0x4c, 0xdd, 0xf0, 0xe3, 0x82, 0xa7, 0xcc, 0x1f, 0xce, 0x26, 0x93, 0x5e,
-	0xe2, 0x8c, 0xc7, 0x28, 0xab, 0xcd, 0x7f, 0x1b, 0xf8, 0x2c, 0x34, 0x1c, 0xba, 0x20, 0x46, 0x7e,
-	0x09, 0x8d, 0x2c, 0xdd, 0xb2, 0x9d, 0x1f, 0xda, 0xb7, 0x28, 0x77, 0x77, 0xe3, 0x81, 0xfd, 0xf9,
-	0x47, 0x6d, 0x3c, 0x20, 0x6d, 0x20, 0x31, 0x1b, 0x4d, 0x19, 0x37, 0x49, 0xc7, 0x24, 0x13, 0xb1,
-	0xfd, 0x04, 0x1d, 0x47, 0x74, 0x8f, 0x61, 0xa3, 0x5e, 0xca, 0xa1, 0x4d, 0x23, 0x3d, 0x87, 0xc8,
-	0x3e, 0x90, 0x57, 0xa1, 0xf0, 0x58, 0xda, 0x3b, 0x03, 0x55, 0x73, 0xbf, 0xd4, 0x2e, 0x5c, 0xe6,
-	0x38, 0x07, 0xd0, 0x58, 0xd0, 0xa9, 0x5e, 0xd2, 0x50, 0x84, 0x53, 0x7c, 0x75, 0x45, 0x8a, 0x6b,
-	0xb2, 0x06, 0x79, 0x19, 0xe2, 0x73, 0x2b, 0xd2, 0xbc, 0x0c, 0x9d, 0x7f, 0xaf, 0x42, 0x3d, 0x7b,
-	0x0f, 0xb5, 0x89, 0xbb, 0x53, 0x86, 0xed, 0xb0, 0x4a, 0x71, 0xad, 0xb2, 0xe4, 0x7d, 0xe0, 0xcb,
-	0xb1, 0xdd, 0xc4, 0xd7, 0xa4, 0x09, 0xd5, 0xb1, 0xc6, 0x2c, 0x18, 0x8d, 0xa5, 0x4d, 0x10, 0x36,
-	0x94, 0xaa, 0x03, 0xd7, 0x81, 0x2a, 0x4f, 0xcc, 0x7e, 0x80, 0x8c, 0x84, 0x54, 0x4f, 0x75, 0x18,
-	0xc5, 0xf6, 0x86, 0x2e, 0x8c, 0xc3, 0x28, 0x26, 0xcf, 0xa0, 0x34, 0x0c, 0xc5, 0xd4, 0x95, 0xf6,
-	0x26, 0x36, 0x6d, 0x7b, 0xc9, 0xb1, 0xfb, 0xaf, 0x90, 0x4f, 0x8d, 0x9c, 0x3a, 0x75, 0x18, 0xc5,
-	0x27, 0x8c, 0xdb, 0x5b, 0xa8, 0xc6, 0x50, 0xe4, 0x00, 0xca, 0x26, 0x25, 0xec, 0x4f, 0x50, 0xd5,
-	0xf6, 0xb2, 0xaa, 0x24, 0x56, 0x89, 0xa4, 0x32, 0x68, 0x14, 0x46, 0xb6, 0x8d, 0x66, 0xaa, 0x25,
-	0x79, 0x01, 0x65, 0xc6, 0x75, 0x21, 0xdd, 0x46, 0x35, 0x0f, 0x97, 0xd5, 0x20, 0x71, 0x1c, 0xfa,
-	0xcc, 0xa3, 0x89, 0x30, 0x36, 0xe2, 0x70, 0x12, 0x8a, 0x13, 0x16, 0xc9, 0xb1, 0xbd, 0x83, 0x0a,
-	0x33, 0x08, 0x39, 0x85, 0xba, 0x37, 0x16, 0xe1, 0xd4, 0xd5, 0xd7, 0xb1, 0x3f, 0x45, 0xe5, 0x9f,
-	0x2f, 0x2b, 0x3f, 0x46, 0xa9, 0xfe, 0xec, 0x3a, 0x76, 0xa7, 0xd1, 0x24, 0xe0, 0x23, 0xba, 0xb0,
-	0x51, 0x79, 0xf7, 0xdd, 0xcc, 0x9d, 0x04, 0xf2, 0xd6, 0x7e, 0x88, 0x0e, 0x48, 0x48, 0xe7, 0x11,
-	0x94, 0x8c, 0x0c, 0x40, 0xe9, 0xbc, 0xd7, 0x39, 0xbd, 0xea, 0x5b, 0x2b, 0xa4, 0x0c, 0x85, 0xf3,
-	0xde, 0xa1, 0x95, 0x73, 0xfe, 0x04, 0xe5, 0x24, 0xc6, 0x0f, 0x60, 0xbd, 0x73, 0x71, 0x7c, 0x79,
-	0xd2, 0xa1, 0x83, 0x93, 0xce, 0xab, 0xf6, 0x9b, 0xd7, 0x6a, 0x8e, 0x69, 0x42, 0xe3, 0xac, 0xf5,
-	0xe2, 0x70, 0x70, 0xd4, 0xee, 0x77, 0x5e, 0x77, 0x2f, 0x3a, 0x56, 0x8e, 0x34, 0xa0, 0x8a, 0xd0,
-	0x79, 0xbb, 0x7b, 0x61, 0xe5, 0x53, 0xf2, 0xac, 0x7b, 0x7a, 0x66, 0x15, 0xc8, 0x36, 0x6c, 0x22,
-	0x79, 0x7c, 0x79, 0xd1, 0xbf, 0xa2, 0xed, 0xee, 0x45, 0xe7, 0x44, 0xb3, 0x8a, 0x4e, 0x0b, 0x60,
-	0xee, 0x24, 0x52, 0x81, 0xa2, 0x12, 0xb4, 0x56, 0xcc, 0xea, 0xb9, 0x95, 0x53, 0x66, 0xbd, 0xed,
-	0x7d, 0x63, 0xe5, 0xf5, 0xe2, 0xa5, 0x55, 0x70, 0x8e, 0xa1, 0xb9, 0x74, 0x77, 0xb2, 0x06, 0x70,
-	0x7c, 0x46, 0x2f, 0xcf, 0xdb, 0x83, 0xc3, 0xd6, 0x33, 0x6b, 0x65, 0x81, 0x6e, 0x59, 0xb9, 0x2c,
-	0x7d, 0x78, 0x68, 0xe5, 0x9d, 0x77, 0xb0, 0x99, 0x4c, 0x9d, 0xcc, 0xef, 0xeb, 0x94, 0xc2, 0x3a,
-	0x6c, 0x41, 0x61, 0x26, 0x26, 0xa6, 0x39, 0xaa, 0x25, 0x0e, 0x5c, 0x38, 0xb8, 0x98, 0xe2, 0x6b,
-	0x28, 0xb2, 0x0f, 0x0f, 0xee, 0x94, 0xad, 0x81, 0xda, 0xa9, 0xa7, 0xb2, 0x66, 0xb4, 0x50, 0xb6,
-	0xde, 0x88, 0x89, 0xf3, 0x3b, 0x68, 0xa4, 0x47, 0xe2, 0x51, 0x2f, 0xa0, 0x62, 0x92, 0x39, 0xc6,
-	0x71, 0xa7, 0xd6, 0xda, 0xd1, 0x9d, 0xf6, 0x3e, 0xc3, 0x68, 0x2a, 0x7b, 0xcf, 0x88, 0xfb, 0xb7,
-	0x1c, 0xac, 0xa7, 0xbb, 0x28, 0x8b, 0x67, 0x13, 0x99, 0x34, 0x8c, 0xdc, 0xbc, 0x61, 0x6c, 0xc1,
-	0x2a, 0x13, 0x22, 0x14, 0xba, 0x51, 0x9d, 0xad, 0x50, 0x4d, 0x92, 0x3d, 0x28, 0xfa, 0xae, 0x74,
-	0x4d, 0xe3, 0x26, 0x8b, 0x36, 0xa8, 0xb3, 0xcf, 0x56, 0x28, 0x4a, 0x90, 0xaf, 0xa0, 0x98, 0x19,
-	0x81, 0x37, 0x75, 0xe5, 0xbd, 0x33, 0x65, 0x50, 0x14, 0x39, 0xaa, 0x40, 0x49, 0xa0, 0x21, 0xce,
-	0x9f, 0x61, 0x9d, 0xb2, 0x51, 0x10, 0x4b, 0x96, 0x8e, 0xef, 0x5b, 0x50, 0x8a, 0x99, 0x27, 0x58,
-	0x32, 0xeb, 0x1a, 0x4a, 0x35, 0x24, 0x33, 0x8c, 0xdd, 0x1a, 0x67, 0xa7, 0xf4, 0x52, 0x43, 0x2a,
-	0x7c, 0x54, 0x43, 0x72, 0xfe, 0x92, 0x83, 0xc6, 0x45, 0x28, 0x83, 0xe1, 0xad, 0x71, 0xe6, 0x3d,
-	0x11, 0xfe, 0x12, 0xca, 0xb1, 0x6e, 0xc3, 0x46, 0x6b, 0x3d, 0x29, 0xbc, 0xe8, 0xf9, 0x84, 0xa9,
-	0xcc, 0x96, 0x6e, 0x7c, 0xd3, 0xf5, 0xd1, 0x01, 0x05, 0x6a, 0xa8, 0x85, 0xae, 0xdb, 0x5c, 0xec,
-	0xba, 0xdf, 0x16, 0x2b, 0x79, 0xab, 0xf0, 0x6d, 0xb1, 0xf2, 0xd8, 0x72, 0x9c, 0xbf, 0xe7, 0xa1,
-	0x9e, 0x1d, 0xa3, 0xd4, 0xc4, 0x2b, 0x98, 0x17, 0x44, 0x01, 0xe3, 0xd2, 0xf4, 0xfc, 0x39, 0xa0,
-	0xa6, 0x8b, 0xa1, 0xeb, 0xb1, 0xc1, 0x7c, 0x22, 0xac, 0xd3, 0xaa, 0x42, 0xde, 0x2a, 0x80, 0x6c,
-	0x43, 0xe5, 0x7d, 0xc0, 0x07, 0x91, 0x08, 0xaf, 0xcd, 0x0c, 0x50, 0x7e, 0x1f, 0xf0, 0x9e, 0x08,
-	0xaf, 0xd5, 0xd3, 0x4c, 0xd5, 0x0c, 0x84, 0xcb, 0x7d, 0xdd, 0x55, 0xf5, 0x44, 0xd0, 0x4c, 0x59,
-	0xd4, 0xe5, 0x3e, 0x36, 0x55, 0x02, 0xc5, 0x98, 0x31, 0xdf, 0xcc, 0x06, 0xb8, 0x26, 0x5f, 0x81,
-	0x35, 0x1f, 0x55, 0x06, 0xd7, 0x93, 0xd0, 0xbb, 0xc1, 0x21, 0xa1, 0x4e, 0xd7, 0xe7, 0xf8, 0x91,
-	0x82, 0xc9, 0x19, 0x34, 0x33, 0xa2, 0x66, 0x76, 0xd4, 0x03, 0xc3, 0xa7, 0x99, 0xd9, 0xb1, 0x93,
-	0xca, 0x98, 0x29, 0x32, 0x73, 0x80, 0x46, 0x9c, 0x2e, 0x10, 0x2d, 0xdb, 0x67, 0xdc, 0x67, 0xc2,
-	0xb8, 0xe9, 0x31, 0xd4, 0x63, 0xa4, 0x07, 0x3c, 0xe4, 0x1e, 0x33, 0x03, 0x73, 0x4d, 0x63, 0x17,
-	0x0a, 0xba, 0x27, 0x27, 0xbe, 0x87, 0xad, 0xfb, 0x8f, 0x25, 0x4f, 0x60, 0xcd, 0x13, 0x4c, 0x1b,
-	0x2b, 0xc2, 0x19, 0xf7, 0x4d, 0x92, 0x34, 0x12, 0x94, 0x2a, 0x90, 0xbc, 0x84, 0xed, 0x45, 0x31,
-	0xed, 0x04, 0xed, 0x4a, 0x7d, 0xd0, 0xd6, 0xc2, 0x0e, 0x74, 0x86, 0xf2, 0xa7, 0xf3, 0xcf, 0x3c,
-	0x94, 0x7b, 0xee, 0x2d, 0x3e, 0xb7, 0xa5, 0xa1, 0x3a, 0xf7, 0x71, 0x43, 0x35, 0xe6, 0x88, 0xba,
-	0xa0, 0x39, 0xcb, 0x50, 0xf7, 0x3b, 0xbb, 0xf0, 0x33, 0x9c, 0x4d, 0xba, 0xb0, 0x61, 0x2c, 0x33,
-	0xde, 0x35, 0xca, 0x8a, 0x58, 0x8b, 0x3e, 0xc9, 0x28, 0xcb, 0x46, 0x83, 0x12, 0xb9, 0x1c, 0xa1,
-	0xe7, 0xb0, 0xc6, 0x3e, 0x44, 0xcc, 0x93, 0xcc, 0x1f, 0xe0, 0xa0, 0x6f, 0x46, 0xf7, 0xbb, 0x5f,
-	0x01, 0x8d, 0x44, 0x0a, 0xa1, 0xd6, 0x7f, 0x72, 0x50, 0xcf, 0xd6, 0x0f, 0x72, 0x04, 0xeb, 0xa7,
-	0x4c, 0x2e, 0x40, 0xf6, 0x52, 0x95, 0x31, 0x55, 0x64, 0xe7, 0xfe, 0xfa, 0x43, 0xfe, 0x00, 0x9b,
-	0xf7, 0xfe, 0x53, 0x20, 0xfa, 0x5b, 0xf0, 0xc7, 0x7e, 0x5f, 0xec, 0x38, 0x3f, 0x26, 0xa2, 0x7f,
-	0x49, 0x90, 0x2f, 0xa0, 0xd8, 0x53, 0x2d, 0x47, 0xff, 0x01, 0x48, 0xfe, 0x97, 0xec, 0x2c, 0x92,
-	0xad, 0x0b, 0x80, 0xab, 0xf9, 0x97, 0xd5, 0x6f, 0x81, 0x24, 0x35, 0x30, 0x83, 0x6e, 0xe0, 0x96,
-	0x3b, 0xc5, 0x71, 0x47, 0x17, 0xe0, 0x85, 0x9a, 0xf5, 0x2c, 0x77, 0x54, 0xfe, 0xfd, 0xea, 0xfe,
-	0xd7, 0x9c, 0xc9, 0xeb, 0x12, 0xfe, 0xaf, 0x39, 0xf8, 0x7f, 0x00, 0x00, 0x00, 0xff, 0xff, 0x8c,
-	0x55, 0x8b, 0x9a, 0xc3, 0x11, 0x00, 0x00,
+	// 2117 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x58, 0xcd, 0x72, 0xdb, 0xc8,
+	0xf1, 0x17, 0x09, 0x7e, 0x36, 0x49, 0x09, 0x1a, 0xdb, 0x32, 0xcc, 0xb5, 0x77, 0x65, 0xac, 0xbd,
+	0x7f, 0xef, 0xc1, 0x5a, 0x17, 0x65, 0xfb, 0xbf, 0x4e, 0x55, 0x6a, 0x23, 0x53, 0xb4, 0xc4, 0x2d,
+	0x5b, 0x62, 0x0d, 0x65, 0x57, 0x25, 0x87, 0x30, 0x10, 0x30, 0xa4, 0x26, 0xa6, 0x00, 0x78, 0x30,
+	0xb4, 0xa5, 0x7d, 0x8a, 0x1c, 0x72, 0x49, 0x2e, 0xa9, 0x4a, 0x55, 0xf2, 0x1c, 0x39, 0xe5, 0x94,
+	0x27, 0xc8, 0x9b, 0xe4, 0x96, 0x9a, 0x9e, 0x01, 0x08, 0x8a, 0xf4, 0xae, 0xb3, 0x27, 0x4e, 0x7f,
+	0x4c, 0x4f, 0x4f, 0xf7, 0x74, 0xf7, 0x8f, 0x00, 0x3b, 0x64, 0xf2, 0x9b, 0x69, 0x3c, 0x12, 0xb1,
+	0xbf, 0x13, 0x8b, 0x48, 0x46, 0xc4, 0x0a, 0x99, 0x74, 0xb7, 0xa1, 0x36, 0xe0, 0xe1, 0x64, 0x10,
+	0x85, 0x13, 0x72, 0x1d, 0xca, 0xef, 0xbd, 0xe9, 0x8c, 0x39, 0x85, 0xed, 0xc2, 0x83, 0x26, 0xd5,
+	0x84, 0xfb, 0x0a, 0x6e, 0xf7, 0xc2, 0xe0, 0x44, 0x78, 0x61, 0xe2, 0x47, 0x01, 0x0f, 0x27, 0x43,
+	0x96, 0x24, 0x3c, 0x0a, 0x29, 0x7b, 0x37, 0x63, 0x89, 0x24, 0x0f, 0x01, 0xbc, 0x99, 0x3c, 0x1b,
+	0xc9, 0xe8, 0x2d, 0x0b, 0x71, 0x6b, 0xa3, 0xb3, 0xbe, 0x13, 0x32, 0xb9, 0xb3, 0x37, 0x93, 0x67,
+	0x27, 0x8a, 0x4b, 0xeb, 0x5e, 0xba, 0x74, 0xbf, 0x80, 0x3b, 0x1f, 0x31, 0x97, 0xc4, 0x51, 0x98,
+	0x30, 0x77, 0x0f, 0xae, 0x1d, 0x0b, 0xff, 0x8c, 0x25, 0x52, 0x78, 0x32, 0x12, 0xe9, 0x31, 0x0e,
+	0x54, 0xbd, 0x20, 0x10, 0x2c, 0x49, 0x8c, 0x7b, 0x29, 0x49, 0x6c, 0xb0, 0x12, 0x3e, 0x71, 0x8a,
+	0xc8, 0x55, 0x4b, 0xf7, 0x4f, 0x05, 0xa8, 0x1c, 0x0f, 0xfb, 0xe1, 0x38, 0x22, 0xcf, 0xa0, 0x91,
+	0xc8, 0x48, 0x78, 0x13, 0x76, 0x72, 0x19, 0xeb, 0x9b, 0xad, 0x77, 0x6e, 0xa2, 0x7b, 0x5a, 0x63,
+	0x67, 0x38, 0x17, 0xd3, 0xbc, 0x2e, 0xb9, 0x0f, 0x95, 0x64, 0x97, 0x87, 0xe3, 0xc8, 0xb1, 0xf1,
+	0x52, 0x2d, 0xdc, 0x35, 0xdc, 0xd5, 0xfb, 0xa8, 0x11, 0xba, 0x0f, 0xa1, 0x91, 0x33, 0x41, 0x00,
+	0x2a, 0xfb, 0x7d, 0xda, 0xeb, 0x9e, 0xd8, 0x6b, 0xa4, 0x02, 0xc5, 0xe1, 0xae, 0x5d, 0x50, 0xbc,
+	0x83, 0xe3, 0xe3, 0x83, 0x97, 0x3d, 0xbb, 0xe8, 0xfe, 0xb5, 0x00, 0xb5, 0xd4, 0x06, 0x21, 0x50,
+	0x3a, 0x8b, 0x12, 0x89, 0x6e, 0xd5, 0x29, 0xae, 0xd5, 0x75, 0xde, 0xb2, 0x4b, 0xbc, 0x4e, 0x9d,
+	0xaa, 0x25, 0xd9, 0x82, 0x4a, 0x1c, 0x4d, 0xb9, 0x7f, 0xe9, 0x58, 0xc8, 0x34, 0x14, 0xb9, 0x0d,
+	0xf5, 0x84, 0x4f, 0x42, 0x4f, 0xce, 0x04, 0x73, 0x4a, 0x28, 0x9a, 0x33, 0xc8, 0xe7, 0x00, 0xbe,
+	0x60, 0x01, 0x0b, 0x25, 0xf7, 0xa6, 0x4e, 0x19, 0xc5, 0x39, 0x0e, 0x69, 0x43, 0xed, 0x62, 0xef,
+	0xfc, 0x87, 0x7d, 0x4f, 0x32, 0xa7, 0x82, 0xd2, 0x8c, 0x76, 0x5f, 0x43, 0x7d, 0x20, 0xb8, 0xcf,
+	0xd0, 0x49, 0x17, 0x9a, 0xb1, 0x22, 0x06, 0x4c, 0xbc, 0x0e, 0xb9, 0x76, 0xd6, 0xa2, 0x0b, 0x3c,
+	0x72, 0x0f, 0x5a, 0x31, 0xbf, 0x60, 0xd3, 0x24, 0x55, 0x2a, 0xa2, 0xd2, 0x22, 0xd3, 0xfd, 0x77,
+	0x11, 0x9a, 0x5d, 0x2f, 0xf6, 0x4e, 0xf9, 0x94, 0x4b, 0xce, 0x12, 0x75, 0x83, 0x53, 0x2e, 0x13,
+	0x29, 0x78, 0x38, 0x71, 0x0a, 0xdb, 0xd6, 0x83, 0x12, 0x9d, 0x33, 0xc8, 0x36, 0x34, 0xce, 0xbd,
+	0x30, 0x50, 0xaf, 0x80, 0xb3, 0xc4, 0x29, 0xa2, 0x3c, 0xcf, 0x22, 0x7b, 0x00, 0xbe, 0x17, 0x7b,
+	0x3e, 0x5a, 0x73, 0xac, 0x6d, 0xeb, 0x41, 0xa3, 0x73, 0x17, 0xd3, 0x94, 0x3f, 0x66, 0xa7, 0x9b,
+	0xe9, 0xf4, 0x42, 0x29, 0x2e, 0x69, 0x6e, 0x93, 0x7a, 0x57, 0xef, 0x99, 0x50, 0x2f, 0xd0, 0x84,
+	0x30, 0x25, 0xc9, 0x77, 0xd0, 0xf0, 0xa3, 0x50, 0x3d, 0x43, 0x1e, 0xca, 0x04, 0x23, 0xd8, 0xe8,
+	0xdc, 0x59, 0x61, 0x7d, 0xae, 0x44, 0xf3, 0x3b, 0xda, 0xbf, 0x84, 0x8d, 0x2b, 0x27, 0xa7, 0xc9,
+	0x55, 0x21, 0x6c, 0xe9, 0xe4, 0x66, 0x45, 0x57, 0x44, 0x9e, 0x26, 0x7e, 0x51, 0xfc, 0xb6, 0xd0,
+	0x7e, 0x08, 0x8d, 0x9c, 0x69, 0x95, 0xcf, 0x73, 0x1e, 0xbe, 0x31, 0xbe, 0xea, 0x17, 0x93, 0xe3,
+	0xb8, 0xff, 0x28, 0x82, 0x9d, 0x2f, 0x1c, 0xcc, 0xdd, 0xe7, 0x00, 0xd2, 0x94, 0x1a, 0x13, 0xe9,
+	0xa6, 0x39, 0x87, 0x3c, 0x85, 0x96, 0xe4, 0xfe, 0x5b, 0x26, 0x47, 0xb1, 0x27, 0xbc, 0xf3, 0x04,
+	0xbd, 0x68, 0x74, 0x36, 0xf1, 0x96, 0x27, 0x28, 0x19, 0xa0, 0x80, 0x36, 0x65, 0x8e, 0x52, 0x45,
+	0x8f, 0xf9, 0x1f, 0x61, 0x7d, 0x58, 0xb9, 0xa2, 0xcf, 0xde, 0x0d, 0xad, 0xc7, 0xe9, 0x32, 0x5f,
+	0xbc, 0xa5, 0xc5, 0xe2, 0x7d, 0x02, 0x4d, 0x3f, 0x17, 0x4c, 0xa7, 0x9c, 0x3b, 0x3f, 0x1f, 0x65,
+	0xba, 0xa0, 0x76, 0xa5, 0xe9, 0x54, 0x7e, 0xa2, 0xe9, 0x90, 0xfb, 0x50, 0x35, 0x95, 0xed, 0x6c,
+	0xe3, 0x23, 0x69, 0xe4, 0x3a, 0x00, 0x4d, 0x65, 0xee, 0xef, 0xa0, 0x9e, 0x6d, 0x57, 0x89, 0x99,
+	0xb7, 0xb4, 0x26, 0xd5, 0x04, 0xb9, 0x03, 0x90, 0xe8, 0x86, 0x35, 0xe2, 0x81, 0x29, 0xd2, 0xba,
+	0xe1, 0xf4, 0x03, 0x15, 0x6f, 0x76, 0x11, 0x73, 0xe1, 0x49, 0x95, 0x24, 0x0b, 0x8b, 0x20, 0xc7,
+	0x71, 0xff, 0x50, 0x86, 0xea, 0x90, 0x4d, 0xf6, 0x3d, 0xe9, 0x61, 0x42, 0xbd, 0x90, 0x8f, 0x59,
+	0x22, 0xfb, 0x81, 0x39, 0x25, 0xc7, 0xc1, 0xbe, 0xc6, 0xde, 0x99, 0x4a, 0x52, 0x4b, 0x6c, 0x17,
+	0x5e, 0x72, 0x86, 0x76, 0x9b, 0x14, 0xd7, 0xaa, 0x8c, 0x63, 0x11, 0x8d, 0xf9, 0x94, 0xa5, 0xb1,
+	0xcd, 0xe8, 0xb4, 0x33, 0x96, 0xb3, 0xce, 0xa8, 0xb4, 0x83, 0x99, 0xf1, 0x4e, 0x45, 0xad, 0x4c,
+	0x33, 0x7a, 0x29, 0x15, 0xd5, 0x9f, 0x93, 0x8a, 0xda, 0x4f, 0xa5, 0xe2, 0x11, 0x5c, 0xf7, 0xbd,
+	0xa9, 0x3f, 0x8a, 0x99, 0xf0, 0x59, 0x2c, 0x67, 0xde, 0x74, 0x84, 0x77, 0x82, 0xed, 0xc2, 0x83,
+	0x1a, 0x25, 0x4a, 0x36, 0xc8, 0x44, 0x87, 0xea, 0x86, 0x9f, 0x96, 0x3c, 0xe5, 0xfe, 0x78, 0x36,
+	0x9d, 0x0e, 0xd2, 0x60, 0xdc, 0xdd, 0xb6, 0x32, 0xf7, 0xdf, 0xf0, 0x80, 0x45, 0x46, 0x42, 0x17,
+	0xd4, 0xc8, 0xff, 0x43, 0x2b, 0x4f, 0x77, 0x1c, 0xf7, 0x63, 0xfb, 0x16, 0xf5, 0xae, 0x6e, 0xdc,
+	0x75, 0xbe, 0xfc, 0xa4, 0x8d, 0xbb, 0x64, 0x0f, 0x48, 0xc2, 0x26, 0xe7, 0x2c, 0x34, 0x45, 0xc7,
+	0x24, 0x13, 0x89, 0x73, 0x1f, 0x03, 0x47, 0xf4, 0x8c, 0x61, 0x93, 0x41, 0x26, 0xa1, 0x9b, 0x46,
+	0x7b, 0xce, 0x22, 0x3b, 0x40, 0x5e, 0x44, 0xc2, 0x67, 0xd9, 0xec, 0xe4, 0xaa, 0xe7, 0x7e, 0xa5,
+	0x43, 0xb8, 0x2c, 0xd1, 0x8f, 0x84, 0x47, 0x82, 0xcb, 0x4b, 0xe7, 0xff, 0x74, 0xda, 0x53, 0xda,
+	0xdd, 0x85, 0xd6, 0xc2, 0x79, 0xea, 0x95, 0x8d, 0x45, 0x74, 0x8e, 0x2f, 0xb2, 0x44, 0x71, 0x4d,
+	0xd6, 0xa1, 0x28, 0x23, 0x7c, 0x8a, 0x25, 0x5a, 0x94, 0x91, 0xfb, 0xaf, 0x32, 0x34, 0xf3, 0x77,
+	0x54, 0x9b, 0x42, 0xef, 0x9c, 0xe1, 0xa8, 0xac, 0x53, 0x5c, 0xab, 0x0a, 0xfa, 0xc0, 0x03, 0x79,
+	0xe6, 0x6c, 0xe2, 0x91, 0x9a, 0x50, 0xd3, 0xec, 0x8c, 0xf1, 0xc9, 0x99, 0x74, 0x08, 0xb2, 0x0d,
+	0xa5, 0x7a, 0xc4, 0x29, 0x57, 0xad, 0x8b, 0x39, 0xd7, 0x50, 0x90, 0x92, 0xea, 0x19, 0x8f, 0xe3,
+	0xc4, 0xb9, 0xae, 0x9b, 0xe6, 0x38, 0x4e, 0xc8, 0x23, 0xa8, 0x8c, 0x23, 0x71, 0xee, 0x49, 0xe7,
+	0x06, 0x0e, 0x74, 0x67, 0x29, 0xe8, 0x3b, 0x2f, 0x50, 0x4e, 0x8d, 0x9e, 0x3a, 0x75, 0x1c, 0x27,
+	0xfb, 0x2c, 0x74, 0xb6, 0xd0, 0x8c, 0xa1, 0xc8, 0x2e, 0x54, 0x4d, 0xb9, 0x38, 0x37, 0xd1, 0xd4,
+	0xad, 0x65, 0x53, 0xe6, 0x97, 0xa6, 0x9a, 0xca, 0xa1, 0x49, 0x14, 0x3b, 0x0e, 0xba, 0xa9, 0x96,
+	0xe4, 0x29, 0x54, 0x59, 0xa8, 0x9b, 0xec, 0x2d, 0x34, 0x73, 0x7b, 0xd9, 0x0c, 0x12, 0xdd, 0x28,
+	0x60, 0x3e, 0x4d, 0x95, 0x71, 0x48, 0x47, 0xd3, 0x48, 0xec, 0xb3, 0x58, 0x9e, 0x39, 0x6d, 0x34,
+	0x98, 0xe3, 0x90, 0x03, 0x68, 0xfa, 0x67, 0x22, 0x3a, 0xf7, 0xf4, 0x75, 0x9c, 0xcf, 0xd0, 0xf8,
+	0x97, 0xcb, 0xc6, 0xbb, 0xa8, 0x35, 0x9c, 0x9d, 0x26, 0xde, 0x79, 0x3c, 0xe5, 0xe1, 0x84, 0x2e,
+	0x6c, 0x54, 0xd1, 0x7d, 0x37, 0xf3, 0xa6, 0xea, 0x01, 0xdc, 0xc6, 0x00, 0xa4, 0xa4, 0x7b, 0x07,
+	0x2a, 0x46, 0x07, 0xa0, 0xf2, 0x6a, 0xd0, 0x3b, 0x38, 0x19, 0xda, 0x6b, 0xa4, 0x0a, 0xd6, 0xab,
+	0xc1, 0x63, 0xbb, 0xe0, 0xfe, 0x1e, 0xaa, 0x69, 0x8e, 0xaf, 0xc1, 0x46, 0xef, 0xa8, 0x7b, 0xbc,
+	0xdf, 0xa3, 0xa3, 0xfd, 0xde, 0x8b, 0xbd, 0xd7, 0x2f, 0x15, 0xc6, 0xd9, 0x84, 0xd6, 0x61, 0xe7,
+	0xe9, 0xe3, 0xd1, 0xf3, 0xbd, 0x61, 0xef, 0x65, 0xff, 0xa8, 0x67, 0x17, 0x48, 0x0b, 0xea, 0xc8,
+	0x7a, 0xb5, 0xd7, 0x3f, 0xb2, 0x8b, 0x19, 0x79, 0xd8, 0x3f, 0x38, 0xb4, 0x2d, 0x72, 0x0b, 0x6e,
+	0x20, 0xd9, 0x3d, 0x3e, 0x1a, 0x9e, 0xd0, 0xbd, 0xfe, 0x51, 0x6f, 0x5f, 0x8b, 0x4a, 0x6e, 0x07,
+	0x60, 0x1e, 0x24, 0x52, 0x83, 0x92, 0x52, 0xb4, 0xd7, 0xcc, 0xea, 0x89, 0x5d, 0x50, 0x6e, 0xbd,
+	0x19, 0x7c, 0x6b, 0x17, 0xf5, 0xe2, 0x99, 0x6d, 0xb9, 0x5d, 0xd8, 0x5c, 0xba, 0x3b, 0x59, 0x07,
+	0xe8, 0x1e, 0xd2, 0xe3, 0x57, 0x7b, 0xa3, 0xc7, 0x9d, 0x47, 0xf6, 0xda, 0x02, 0xdd, 0xb1, 0x0b,
+	0x79, 0xfa, 0xf1, 0x63, 0xbb, 0xe8, 0xbe, 0x83, 0x1b, 0x29, 0x22, 0x65, 0xc1, 0x50, 0x97, 0x1b,
+	0xf6, 0x68, 0x1b, 0xac, 0x99, 0x98, 0x9a, 0xc1, 0xa9, 0x96, 0x08, 0xc6, 0x10, 0xd4, 0x98, 0xc6,
+	0x6c, 0x28, 0xb2, 0x03, 0xd7, 0xae, 0xb4, 0xb4, 0x91, 0xda, 0xa9, 0x11, 0xdb, 0x66, 0xbc, 0xd0,
+	0xd2, 0x5e, 0x8b, 0xa9, 0xfb, 0x6b, 0x68, 0x65, 0x47, 0xe2, 0x51, 0x4f, 0xa1, 0x66, 0x0a, 0x3d,
+	0x41, 0x28, 0xd4, 0xe8, 0xb4, 0xf5, 0x14, 0x5e, 0xe5, 0x18, 0xcd, 0x74, 0x57, 0xc0, 0xdf, 0x3f,
+	0x17, 0x60, 0x23, 0xdb, 0x45, 0x59, 0x32, 0x9b, 0xca, 0x74, 0x98, 0x14, 0xe6, 0xc3, 0x64, 0x0b,
+	0xca, 0x4c, 0x88, 0x48, 0xe8, 0x21, 0x76, 0xb8, 0x46, 0x35, 0x49, 0x1e, 0x40, 0x29, 0xf0, 0xa4,
+	0xe7, 0x58, 0xb9, 0x86, 0xb4, 0xe0, 0xe9, 0xe1, 0x1a, 0x45, 0x0d, 0xf2, 0x35, 0x94, 0x72, 0xf0,
+	0xf8, 0x86, 0xee, 0xca, 0x57, 0x10, 0x08, 0x45, 0x95, 0xe7, 0x35, 0xa8, 0x08, 0x74, 0xc4, 0xfd,
+	0x67, 0x01, 0x36, 0x28, 0x9b, 0xf0, 0x44, 0xb2, 0x0c, 0xdb, 0x6f, 0x41, 0x25, 0x61, 0xbe, 0x60,
+	0x29, 0x10, 0x36, 0x94, 0x6a, 0x5b, 0x06, 0xa9, 0x5d, 0x9a, 0x68, 0x67, 0xf4, 0xd2, 0xb4, 0xb2,
+	0x3e, 0x6d, 0x5a, 0xa5, 0x40, 0xb5, 0x9b, 0xda, 0x2d, 0xe5, 0x80, 0x6a, 0xca, 0x54, 0xed, 0x8e,
+	0x07, 0x06, 0x33, 0x17, 0x79, 0xa0, 0xba, 0x5b, 0x3c, 0x9b, 0x4e, 0x71, 0x64, 0xd6, 0x28, 0xae,
+	0xdd, 0x3f, 0x16, 0xa1, 0x75, 0x14, 0x49, 0x3e, 0xbe, 0x34, 0x79, 0x59, 0xf1, 0x58, 0xbe, 0x82,
+	0x6a, 0xa2, 0xa7, 0xbd, 0xf1, 0xaf, 0x99, 0xf6, 0x77, 0x4c, 0x62, 0x2a, 0x54, 0x01, 0x90, 0x5e,
+	0xf2, 0xb6, 0x1f, 0x60, 0x2c, 0x2d, 0x6a, 0xa8, 0x85, 0xe1, 0xbe, 0x79, 0x65, 0xb8, 0x1f, 0x42,
+	0x53, 0x0a, 0xcf, 0x67, 0xdd, 0x28, 0x94, 0xec, 0x42, 0x75, 0x53, 0xf5, 0x66, 0xee, 0xe1, 0x01,
+	0x0b, 0x7e, 0xed, 0x9c, 0xe4, 0xd4, 0x34, 0x00, 0x5e, 0xd8, 0xd9, 0xfe, 0x0e, 0x36, 0x97, 0x54,
+	0xf2, 0x48, 0xb5, 0xbe, 0x02, 0xa9, 0xd6, 0x73, 0x48, 0xf5, 0xfb, 0x52, 0xad, 0x68, 0x5b, 0xdf,
+	0x97, 0x6a, 0x77, 0x6d, 0xd7, 0xfd, 0x4b, 0x11, 0x9a, 0x79, 0xe0, 0xa8, 0x30, 0xbe, 0x60, 0x3e,
+	0x8f, 0x39, 0x0b, 0xa5, 0x41, 0x39, 0x73, 0x86, 0xc2, 0x53, 0x63, 0xcf, 0x67, 0xa3, 0xb9, 0xe5,
+	0x26, 0xad, 0x2b, 0xce, 0x1b, 0xc5, 0x20, 0xb7, 0xa0, 0xf6, 0x81, 0x87, 0xa3, 0x58, 0x44, 0xa7,
+	0x06, 0xf5, 0x54, 0x3f, 0xf0, 0x70, 0x20, 0xa2, 0x53, 0x55, 0x70, 0x99, 0x99, 0x91, 0xf0, 0xc2,
+	0x40, 0xe3, 0x08, 0x8d, 0x81, 0x36, 0x33, 0x11, 0xf5, 0xc2, 0x00, 0x61, 0x04, 0x81, 0x52, 0xc2,
+	0x58, 0x60, 0xd0, 0x10, 0xae, 0xc9, 0xd7, 0x60, 0xcf, 0xc1, 0xd9, 0xe8, 0x74, 0x1a, 0xf9, 0x6f,
+	0x31, 0xc7, 0x4d, 0xba, 0x31, 0xe7, 0x3f, 0x57, 0x6c, 0x72, 0x08, 0x9b, 0x39, 0x55, 0x83, 0x96,
+	0x35, 0x44, 0xfa, 0x2c, 0x87, 0x96, 0x7b, 0x99, 0x8e, 0xc1, 0xcd, 0x36, 0xbb, 0xc2, 0x71, 0xfb,
+	0x40, 0xb4, 0xee, 0x90, 0x85, 0x01, 0x13, 0x26, 0x4c, 0x77, 0xa1, 0x99, 0x20, 0x3d, 0x0a, 0xa3,
+	0xd0, 0x67, 0xe6, 0x2f, 0x42, 0x43, 0xf3, 0x8e, 0x14, 0x6b, 0x45, 0xa5, 0xff, 0x00, 0x5b, 0xab,
+	0x8f, 0x25, 0xf7, 0x61, 0xdd, 0x17, 0x4c, 0x3b, 0x2b, 0xa2, 0x59, 0x18, 0x98, 0xd2, 0x6f, 0xa5,
+	0x5c, 0xaa, 0x98, 0xe4, 0x19, 0xdc, 0x5a, 0x54, 0xd3, 0x41, 0xd0, 0xa1, 0xd4, 0x07, 0x6d, 0x2d,
+	0xec, 0xc0, 0x60, 0xa8, 0x78, 0xba, 0x7f, 0x2b, 0x42, 0x75, 0xe0, 0x5d, 0xe2, 0xcb, 0x5f, 0xfa,
+	0x1b, 0x51, 0xf8, 0xb4, 0xbf, 0x11, 0x58, 0xf8, 0xea, 0x82, 0xe6, 0x2c, 0x43, 0xad, 0x0e, 0xb6,
+	0xf5, 0x33, 0x82, 0x4d, 0xfa, 0x70, 0xdd, 0x78, 0x66, 0xa2, 0x6b, 0x8c, 0x95, 0xb0, 0x5a, 0x6e,
+	0xe6, 0x8c, 0xe5, 0xb3, 0x41, 0x89, 0x5c, 0xce, 0xd0, 0x13, 0x58, 0x67, 0x17, 0x31, 0xf3, 0x25,
+	0x0b, 0x46, 0xf8, 0xd7, 0xc6, 0x29, 0xe7, 0xc0, 0xee, 0xfc, 0x7f, 0x4f, 0x2b, 0xd5, 0x42, 0x96,
+	0xfb, 0x9f, 0x02, 0xb4, 0xd3, 0xc6, 0x42, 0x59, 0xc2, 0xc4, 0x7b, 0x1d, 0xcd, 0xff, 0xfd, 0xbb,
+	0x86, 0x6a, 0x07, 0xe6, 0xaf, 0x86, 0x8e, 0x46, 0x99, 0x66, 0x34, 0x79, 0xb8, 0xf0, 0x3f, 0xe0,
+	0x23, 0x48, 0x34, 0x53, 0x51, 0xc5, 0x9c, 0x48, 0x4f, 0x48, 0xbc, 0x83, 0x45, 0x35, 0xa1, 0x8e,
+	0x64, 0x61, 0x80, 0x25, 0x60, 0x51, 0xb5, 0x54, 0x1d, 0x2c, 0xd6, 0x49, 0x76, 0xaa, 0xb9, 0x0e,
+	0x66, 0x12, 0x4f, 0x53, 0xa1, 0xb2, 0xa7, 0xdf, 0x6d, 0x0d, 0x31, 0xa2, 0x26, 0x5c, 0x0e, 0xd7,
+	0x56, 0x5c, 0xdd, 0xb4, 0xd7, 0x42, 0xd6, 0x5e, 0xf3, 0xf7, 0x2a, 0x5e, 0xb9, 0x57, 0xe6, 0xa8,
+	0xb5, 0xc2, 0xd1, 0x52, 0xe6, 0x68, 0xe7, 0xef, 0x45, 0x68, 0xe6, 0x87, 0x0f, 0x79, 0x0e, 0x1b,
+	0x07, 0x4c, 0x2e, 0xb0, 0x9c, 0xa5, 0x11, 0x65, 0xb2, 0xd0, 0x5e, 0x3d, 0xbc, 0xc8, 0x6f, 0xe1,
+	0xc6, 0xca, 0x8f, 0x55, 0x44, 0x7f, 0x64, 0xf8, 0xb1, 0xef, 0x62, 0x6d, 0xf7, 0xc7, 0x54, 0xf4,
+	0xb7, 0x2e, 0x72, 0x0f, 0x4a, 0xea, 0xeb, 0x1b, 0xd1, 0x9f, 0x96, 0xd2, 0x0f, 0x71, 0xed, 0x45,
+	0x92, 0xbc, 0x84, 0x0d, 0x1d, 0x3d, 0x96, 0x0d, 0xa8, 0x2f, 0xb2, 0x39, 0xb7, 0xfa, 0x59, 0xb5,
+	0x9d, 0x8f, 0x29, 0x74, 0x8e, 0x00, 0x4e, 0xe6, 0x1f, 0x00, 0x7e, 0x05, 0x24, 0x9d, 0xc6, 0x39,
+	0xee, 0x75, 0xdc, 0x7d, 0x65, 0x4c, 0xb7, 0xc9, 0xf2, 0x6c, 0x79, 0x54, 0x78, 0x5e, 0xfd, 0x4d,
+	0x79, 0xe7, 0x9b, 0x90, 0xc9, 0xd3, 0x0a, 0x7e, 0x56, 0xdc, 0xfd, 0xef, 0x00, 0xe8, 0x29, 0xfa,
+	0xd2, 0x6a, 0x14, 0x00, 0x00,
 }
@@ -26,6 +26,8 @@ type OrchestratorClient interface {
 	GetOrchestrator(ctx context.Context, in *OrchestratorRequest, opts ...grpc.CallOption) (*OrchestratorInfo, error)
 	EndTranscodingSession(ctx context.Context, in *EndTranscodingSessionRequest, opts ...grpc.CallOption) (*EndTranscodingSessionResponse, error)
 	Ping(ctx context.Context, in *PingPong, opts ...grpc.CallOption) (*PingPong, error)
+	// Called by the broadcaster to reserve sessions for a time window ahead of a scheduled event.
+	ReserveCapacity(ctx context.Context, in *CapacityReservationRequest, opts ...grpc.CallOption) (*CapacityReservation, error)
 }
 
 type orchestratorClient struct {
@@ -63,6 +65,15 @@ func (c *orchestratorClient) Ping(ctx context.Context, in *PingPong, opts ...grp
 	return out, nil
 }
 
+func (c *orchestratorClient) ReserveCapacity(ctx context.Context, in *CapacityReservationRequest, opts ...grpc.CallOption) (*CapacityReservation, error) {
+	out := new(CapacityReservation)
+	err := c.cc.Invoke(ctx, "/net.Orchestrator/ReserveCapacity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrchestratorServer is the server API for Orchestrator service.
 // All implementations must embed UnimplementedOrchestratorServer
 // for forward compatibility
@@ -71,6 +82,8 @@ type OrchestratorServer interface {
 	GetOrchestrator(context.Context, *OrchestratorRequest) (*OrchestratorInfo, error)
 	EndTranscodingSession(context.Context, *EndTranscodingSessionRequest) (*EndTranscodingSessionResponse, error)
 	Ping(context.Context, *PingPong) (*PingPong, error)
+	// Called by the broadcaster to reserve sessions for a time window ahead of a scheduled event.
+	ReserveCapacity(context.Context, *CapacityReservationRequest) (*CapacityReservation, error)
 	mustEmbedUnimplementedOrchestratorServer()
 }
 
@@ -87,6 +100,9 @@ func (UnimplementedOrchestratorServer) EndTranscodingSession(context.Context, *E
 func (UnimplementedOrchestratorServer) Ping(context.Context, *PingPong) (*PingPong, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Ping not implemented")
 }
+func (UnimplementedOrchestratorServer) ReserveCapacity(context.Context, *CapacityReservationRequest) (*CapacityReservation, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReserveCapacity not implemented")
+}
 func (UnimplementedOrchestratorServer) mustEmbedUnimplementedOrchestratorServer() {}
 
 // UnsafeOrchestratorServer may be embedded to opt out of forward compatibility for this service.
@@ -154,6 +170,24 @@ func _Orchestrator_Ping_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Orchestrator_ReserveCapacity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CapacityReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrchestratorServer).ReserveCapacity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Orchestrator/ReserveCapacity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrchestratorServer).ReserveCapacity(ctx, req.(*CapacityReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Orchestrator_ServiceDesc is the grpc.ServiceDesc for Orchestrator service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -173,6 +207,10 @@ var Orchestrator_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Ping",
 			Handler:    _Orchestrator_Ping_Handler,
 		},
+		{
+			MethodName: "ReserveCapacity",
+			Handler:    _Orchestrator_ReserveCapacity_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "net/lp_rpc.proto",
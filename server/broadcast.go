@@ -41,9 +41,29 @@ var maxRefreshSessionsThreshold = 8.0
 var recordSegmentsMaxTimeout = 1 * time.Minute
 
 var Policy *verification.Policy
+
+// EvidenceStore persists evidence of segments that fail verification. Nil disables evidence collection
+var EvidenceStore verification.EvidenceStore
+
+// FastVerificationFailStore stores the segments and signatures that fail fast verification. Nil disables storage
+var FastVerificationFailStore drivers.OSSession
 var BroadcastCfg = &BroadcastConfig{}
 var MaxAttempts = 3
 
+// LadderSplit is the number of orchestrators that the rendition ladder of a segment is split across. Each
+// orchestrator transcodes its part of the ladder in parallel and is paid for it separately
+var LadderSplit = 1
+
+// RaceSessions is the number of orchestrators that each segment is sent to when racing. The first valid results are
+// used and the other submissions are cancelled. Untrusted orchestrators only race for segments whose results are
+// verified
+var RaceSessions = 1
+
+// RaceMaxCost bounds the cost of racing a segment to this multiple of the cost of transcoding it once. Each orchestrator
+// is paid the fee estimated for the segments it receives, including the segments of the races it loses, so this bounds
+// the payments per segment. 0 means no bound
+var RaceMaxCost = 2.0
+
 var MetadataQueue event.SimpleProducer
 var MetadataPublishTimeout = 1 * time.Second
 
@@ -419,8 +439,9 @@ func (sp *SessionPool) completeSession(sess *BroadcastSession) {
 }
 
 type BroadcastSessionsManager struct {
-	mid              core.ManifestID
-	VerificationFreq uint
+	mid                core.ManifestID
+	VerificationFreq   uint
+	VerificationPolicy *verification.Policy
 
 	// Accessing or changing any of the below requires ownership of this mutex
 	sessLock sync.Mutex
@@ -434,17 +455,62 @@ type BroadcastSessionsManager struct {
 }
 
 func (bsm *BroadcastSessionsManager) isVerificationEnabled() bool {
-	return bsm.VerificationFreq > 0
+	return bsm.verificationRedundancy() > 1
 }
 
-func (bsm *BroadcastSessionsManager) shouldSkipVerification(sessions []*BroadcastSession) bool {
+// verificationRedundancy returns the number of orchestrators that transcode each segment in parallel. Streams that
+// only set a verification frequency use 1 trusted and 2 untrusted orchestrators
+func (bsm *BroadcastSessionsManager) verificationRedundancy() int {
+	if bsm.VerificationPolicy != nil && bsm.VerificationPolicy.Redundancy > 1 {
+		return bsm.VerificationPolicy.Redundancy
+	}
+	if bsm.VerificationFreq > 0 {
+		return 3
+	}
+	return 0
+}
+
+func (bsm *BroadcastSessionsManager) shouldSkipVerification(sessions []*BroadcastSession, seg *stream.HLSSegment) bool {
 	if bsm.verifiedSession == nil {
 		return false
 	}
 	if !includesSession(sessions, bsm.verifiedSession) {
 		return false
 	}
-	return common.RandomUintUnder(bsm.VerificationFreq) != 0
+	if bsm.VerificationFreq > 0 {
+		return common.RandomUintUnder(bsm.VerificationFreq) != 0
+	}
+	return !bsm.VerificationPolicy.ShouldVerify(seg.Data)
+}
+
+// verifiesResults returns whether the results of the segment are checked by the verifier of the stream
+func (bsm *BroadcastSessionsManager) verifiesResults(seg *stream.HLSSegment) bool {
+	return bsm.VerificationPolicy != nil && bsm.VerificationPolicy.Verifier != nil && bsm.VerificationPolicy.ShouldVerify(seg.Data)
+}
+
+// needsSignatures returns whether orchestrators should compute MPEG-7 signatures of the segment for local signature
+// verification
+func (bsm *BroadcastSessionsManager) needsSignatures(seg *stream.HLSSegment) bool {
+	return bsm.VerificationPolicy.NeedsSignatures() && bsm.VerificationPolicy.ShouldVerify(seg.Data)
+}
+
+// verificationPolicy returns the verification policy of a stream, which is the node wide Policy with the sample rate
+// and redundancy of the stream. Streams that set neither use the node wide Policy
+func verificationPolicy(params *core.StreamParameters) *verification.Policy {
+	if params == nil || (params.VerificationSampleRate <= 0 && params.VerificationRedundancy <= 0) {
+		return Policy
+	}
+	policy := &verification.Policy{}
+	if Policy != nil {
+		*policy = *Policy
+	}
+	if params.VerificationSampleRate > 0 {
+		policy.SampleRate = params.VerificationSampleRate
+	}
+	if params.VerificationRedundancy > 0 {
+		policy.Redundancy = params.VerificationRedundancy
+	}
+	return policy
 }
 
 func NewSessionManager(ctx context.Context, node *core.LivepeerNode, params *core.StreamParameters, sel BroadcastSessionsSelectorFactory) *BroadcastSessionsManager {
@@ -472,10 +538,11 @@ func NewSessionManager(ctx context.Context, node *core.LivepeerNode, params *cor
 		stakeRdr = &storeStakeReader{store: node.Database}
 	}
 	bsm := &BroadcastSessionsManager{
-		mid:              params.ManifestID,
-		VerificationFreq: params.VerificationFreq,
-		trustedPool:      NewSessionPool(params.ManifestID, int(trustedPoolSize), trustedNumOrchs, susTrusted, createSessionsTrusted, NewMinLSSelector(stakeRdr, 1.0, node.SelectionAlgorithm, node.OrchPerfScore)),
-		untrustedPool:    NewSessionPool(params.ManifestID, int(untrustedPoolSize), untrustedNumOrchs, susUntrusted, createSessionsUntrusted, NewMinLSSelector(stakeRdr, 1.0, node.SelectionAlgorithm, node.OrchPerfScore)),
+		mid:                params.ManifestID,
+		VerificationFreq:   params.VerificationFreq,
+		VerificationPolicy: verificationPolicy(params),
+		trustedPool:        NewSessionPool(params.ManifestID, int(trustedPoolSize), trustedNumOrchs, susTrusted, createSessionsTrusted, NewMinLSSelector(stakeRdr, 1.0, node.SelectionAlgorithm, node.OrchPerfScore)),
+		untrustedPool:      NewSessionPool(params.ManifestID, int(untrustedPoolSize), untrustedNumOrchs, susUntrusted, createSessionsUntrusted, NewMinLSSelector(stakeRdr, 1.0, node.SelectionAlgorithm, node.OrchPerfScore)),
 	}
 	bsm.trustedPool.refreshSessions(ctx)
 	bsm.untrustedPool.refreshSessions(ctx)
@@ -483,6 +550,7 @@ func NewSessionManager(ctx context.Context, node *core.LivepeerNode, params *cor
 }
 
 func (bsm *BroadcastSessionsManager) suspendAndRemoveOrch(sess *BroadcastSession) {
+	OrchStats.recordSuspension(sess.OrchestratorInfo.GetTranscoder())
 	if sess.OrchestratorScore == common.Score_Untrusted {
 		bsm.untrustedPool.suspend(sess.OrchestratorInfo.GetTranscoder())
 		bsm.untrustedPool.removeSession(sess)
@@ -513,22 +581,38 @@ func (bs *BroadcastSession) pushSegInFlight(seg *stream.HLSSegment) {
 	bs.lock.Unlock()
 }
 
+// selectParallelSessions selects up to count sessions to transcode a segment in parallel, e.g. the parts of a split
+// rendition ladder or the racing submissions of a segment. The results of parallel sessions are not compared with the
+// results of a trusted orchestrator, so untrusted sessions are only added if untrusted is set because their results
+// are verified
+func (bsm *BroadcastSessionsManager) selectParallelSessions(ctx context.Context, count int, untrusted bool) []*BroadcastSession {
+	bsm.sessLock.Lock()
+	defer bsm.sessLock.Unlock()
+
+	sessions := bsm.trustedPool.selectSessions(ctx, count)
+	if untrusted && len(sessions) < count {
+		sessions = append(sessions, bsm.untrustedPool.selectSessions(ctx, count-len(sessions))...)
+	}
+	return sessions
+}
+
 // selects number of sessions to use according to current algorithm
-func (bsm *BroadcastSessionsManager) selectSessions(ctx context.Context) (bs []*BroadcastSession, calcPerceptualHash bool, verified bool) {
+func (bsm *BroadcastSessionsManager) selectSessions(ctx context.Context, seg *stream.HLSSegment) (bs []*BroadcastSession, calcPerceptualHash bool, verified bool) {
 	bsm.sessLock.Lock()
 	defer bsm.sessLock.Unlock()
 
 	if bsm.isVerificationEnabled() {
-		// Select 1 trusted O and 2 untrusted Os
+		// Select 1 trusted O and Redundancy - 1 untrusted Os
 		sessions := append(
 			bsm.trustedPool.selectSessions(ctx, 1),
-			bsm.untrustedPool.selectSessions(ctx, 2)...,
+			bsm.untrustedPool.selectSessions(ctx, bsm.verificationRedundancy()-1)...,
 		)
 
 		// Only return the last verified session if:
-		// - It is present in the 3 sessions returned by the selector
-		// - With probability 1 - 1/VerificationFrequency
-		if bsm.shouldSkipVerification(sessions) {
+		// - It is present in the sessions returned by the selector
+		// - The segment is not sampled for verification or, without a sample rate, with probability
+		//   1 - 1/VerificationFrequency
+		if bsm.shouldSkipVerification(sessions, seg) {
 			clog.V(common.DEBUG).Infof(ctx, "Reusing verified orch=%v", bsm.verifiedSession.OrchestratorInfo.Transcoder)
 			verified = true
 			// Mark remaining unused sessions returned by selector as complete
@@ -551,7 +635,7 @@ func (bsm *BroadcastSessionsManager) selectSessions(ctx context.Context) (bs []*
 		sessions = bsm.trustedPool.selectSessions(ctx, 1)
 	}
 
-	return sessions, false, verified
+	return sessions, bsm.needsSignatures(seg), verified
 }
 
 func (bsm *BroadcastSessionsManager) cleanup(ctx context.Context) {
@@ -633,7 +717,7 @@ func (bsm *BroadcastSessionsManager) chooseResults(ctx context.Context, seg *str
 		}
 		clog.Infof(ctx, "Hashes from url=%s and url=%s are equal=%v saveenable=%v",
 			trustedResult.TranscodeResult.Segments[segmToCheckIndex].PerceptualHashUrl,
-			untrustedResult.TranscodeResult.Segments[segmToCheckIndex].PerceptualHashUrl, equal, drivers.FailSaveEnabled())
+			untrustedResult.TranscodeResult.Segments[segmToCheckIndex].PerceptualHashUrl, equal, FastVerificationFailStore != nil)
 		vequal := false
 		if equal {
 			// download untrusted video segment
@@ -656,23 +740,17 @@ func (bsm *BroadcastSessionsManager) chooseResults(ctx context.Context, seg *str
 				if monitor.Enabled {
 					monitor.FastVerificationFailed(ctx, ouri, monitor.FVType2Error)
 				}
-				if drivers.FailSaveEnabled() {
-					go func() {
-						drivers.SavePairData2GS(trustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, trustedSegm,
-							untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, untrustedSegm, "phase2.ts", seg.Data)
-					}()
-				}
+				saveFastVerificationFailure(ctx, trustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, trustedSegm,
+					untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, untrustedSegm, "phase2.ts", seg.Data)
 
 			}
 			clog.Infof(ctx, "Video comparison from url=%s and url=%s are equal=%v saveenable=%v",
 				trustedResult.TranscodeResult.Segments[segmToCheckIndex].Url,
-				untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, vequal, drivers.FailSaveEnabled())
+				untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, vequal, FastVerificationFailStore != nil)
 
-		} else if drivers.FailSaveEnabled() {
-			go func() {
-				drivers.SavePairData2GS(trustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, trustedHash,
-					untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, untrustedHash, "phase1.hash", nil)
-			}()
+		} else {
+			saveFastVerificationFailure(ctx, trustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, trustedHash,
+				untrustedResult.TranscodeResult.Segments[segmToCheckIndex].Url, untrustedHash, "phase1.hash", nil)
 		}
 		if vequal && equal {
 			// stick to this verified orchestrator for further segments.
@@ -692,6 +770,21 @@ func (bsm *BroadcastSessionsManager) chooseResults(ctx context.Context, seg *str
 	return trustedResult.Session, trustedResult.TranscodeResult, trustedResult.Err
 }
 
+// saveFastVerificationFailure uploads the results that failed fast verification to FastVerificationFailStore, if set
+func saveFastVerificationFailure(ctx context.Context, trustedURI string, trusted []byte, untrustedURI string, untrusted []byte,
+	suffix string, src []byte) {
+
+	store := FastVerificationFailStore
+	if store == nil {
+		return
+	}
+	go func() {
+		if err := verification.SaveFailedPair(context.Background(), store, trustedURI, trusted, untrustedURI, untrusted, suffix, src); err != nil {
+			clog.Errorf(ctx, "Error saving segments that failed fast verification err=%q", err)
+		}
+	}()
+}
+
 func (bsm *BroadcastSessionsManager) collectResults(submitResultsCh chan *SubmitResult, submittedCount int) (*SubmitResult, []*SubmitResult, error) {
 	submitResults := make([]*SubmitResult, submittedCount)
 
@@ -847,7 +940,10 @@ func selectOrchestrator(ctx context.Context, n *core.LivepeerNode, params *core.
 	return sessions, nil
 }
 
-func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, segPar *core.SegmentParameters) ([]string, error) {
+func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, segPar *core.SegmentParameters) (_ []string, err error) {
+
+	ctx, span := monitor.StartSpan(ctx, "processSegment")
+	defer func() { monitor.EndSpan(span, err) }()
 
 	rtmpStrm := cxn.stream
 	nonce := cxn.nonce
@@ -955,7 +1051,7 @@ func processSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSeg
 
 	var sv *verification.SegmentVerifier
 	if Policy != nil {
-		sv = verification.NewSegmentVerifier(Policy)
+		sv = verification.NewSegmentVerifier(verificationPolicy(cxn.params))
 	}
 
 	var (
@@ -1039,7 +1135,41 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 	}(time.Now())
 
 	nonce := cxn.nonce
-	sessions, calcPerceptualHash, verified := cxn.sessManager.selectSessions(ctx)
+	var (
+		sessions           []*BroadcastSession
+		calcPerceptualHash bool
+		verified           bool
+	)
+	// Segments are not split or raced when comparing the results of orchestrators since the results of whole ladders
+	// are compared. Segments are not split either when their results are verified since the verifier checks whole
+	// ladders, so the parts of a split ladder are only transcoded by trusted orchestrators
+	split := LadderSplit > 1 && len(cxn.params.Profiles) > 1 && !cxn.sessManager.isVerificationEnabled() &&
+		!cxn.sessManager.verifiesResults(seg)
+	race := !split && RaceSessions > 1 && !cxn.sessManager.isVerificationEnabled()
+	if split || race {
+		calcPerceptualHash = cxn.sessManager.needsSignatures(seg)
+	}
+	if split {
+		parts := LadderSplit
+		if parts > len(cxn.params.Profiles) {
+			parts = len(cxn.params.Profiles)
+		}
+		sessions = cxn.sessManager.selectParallelSessions(ctx, parts, false)
+	} else if race {
+		var dropped []*BroadcastSession
+		// The results of the winner are verified if the results of the segment are verified, otherwise only trusted
+		// orchestrators race
+		untrusted := verifier != nil && cxn.sessManager.verifiesResults(seg)
+		sessions, dropped = limitRaceCost(seg, cxn.sessManager.selectParallelSessions(ctx, RaceSessions, untrusted))
+		for _, sess := range dropped {
+			cxn.sessManager.completeSession(ctx, sess, false)
+		}
+	}
+	if len(sessions) == 0 {
+		// Without trusted sessions the segment is transcoded by a single session
+		split, race = false, false
+		sessions, calcPerceptualHash, verified = cxn.sessManager.selectSessions(ctx, seg)
+	}
 	// Return early under a few circumstances:
 	// View-only (non-transcoded) streams or no sessions available
 	if len(sessions) == 0 {
@@ -1098,6 +1228,12 @@ func transcodeSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSS
 		}
 		urls, err = downloadResults(ctx, cxn, seg, sess, res, verifier)
 		return urls, info, err
+	} else if split {
+		urls, err = transcodeLadderParts(ctx, cxn, seg, name, verifier, segPar, sessions, calcPerceptualHash)
+		return urls, info, err
+	} else if race {
+		urls, err = raceSegment(ctx, cxn, seg, name, verifier, segPar, sessions, calcPerceptualHash)
+		return urls, info, err
 	} else {
 		resc := make(chan *SubmitResult, len(sessions))
 		submittedCount := 0
@@ -1150,6 +1286,273 @@ func submitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 	}
 }
 
+// raceSegment submits the segment to all the sessions and uses the first valid results. The other submissions are
+// cancelled and their sessions are returned once they finish
+func raceSegment(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, name string,
+	verifier *verification.SegmentVerifier, segPar *core.SegmentParameters, sessions []*BroadcastSession,
+	calcPerceptualHash bool) ([]string, error) {
+
+	nonce := cxn.nonce
+	submitCtx, cancel := withSubmitCancel(ctx)
+	defer cancel()
+
+	resc := make(chan *SubmitResult, len(sessions))
+	submittedCount := 0
+	for _, sess := range sessions {
+		seg2, err := prepareForTranscoding(ctx, cxn, sess, seg, name)
+		if err != nil {
+			continue
+		}
+		sess.pushSegInFlight(seg2)
+		submitMultiSession(submitCtx, sess, seg2, segPar, nonce, calcPerceptualHash, resc)
+		submittedCount++
+	}
+	if submittedCount == 0 {
+		return nil, fmt.Errorf("error: not submitted anything")
+	}
+
+	var winner *SubmitResult
+	var err error
+	received := 0
+	for winner == nil && received < submittedCount {
+		res := <-resc
+		received++
+		if res.Err == nil && res.TranscodeResult == nil {
+			res.Err = errors.New("empty response")
+		}
+		if res.Err == nil && len(res.TranscodeResult.Segments) != len(res.Session.Params.Profiles) {
+			res.Err = fmt.Errorf("error transcoding: got segments=%d for profiles=%d from %s",
+				len(res.TranscodeResult.Segments), len(res.Session.Params.Profiles), res.Session.Transcoder())
+		}
+		if res.Err == nil {
+			winner = res
+			break
+		}
+		err = res.Err
+		if isNonRetryableError(err) {
+			cxn.sessManager.completeSession(ctx, res.Session, false)
+		} else {
+			cxn.sessManager.suspendAndRemoveOrch(res.Session)
+		}
+	}
+	cancel()
+
+	// The sessions of the submissions that were cancelled because they lost the race are not suspended. Their
+	// transcoding sessions are ended so that the orchestrators stop transcoding the segment
+	go func(pending int) {
+		for i := 0; i < pending; i++ {
+			res := <-resc
+			cancelled := errors.Is(res.Err, context.Canceled)
+			if res.Err != nil && !cancelled && !isNonRetryableError(res.Err) {
+				cxn.sessManager.suspendAndRemoveOrch(res.Session)
+				continue
+			}
+			if res.Err == nil && res.TranscodeResult != nil {
+				updateSession(res.Session, res.TranscodeResult)
+			}
+			cxn.sessManager.completeSession(ctx, res.Session, cancelled)
+		}
+	}(submittedCount - received)
+
+	if winner == nil {
+		return nil, err
+	}
+	clog.V(common.DEBUG).Infof(ctx, "Won segment race orch=%s sessions=%d", winner.Session.Transcoder(), submittedCount)
+	if monitor.Enabled {
+		monitor.SegmentRaced(ctx, submittedCount)
+	}
+	return downloadResults(ctx, cxn, seg, winner.Session, winner.TranscodeResult, verifier)
+}
+
+// limitRaceCost drops the sessions that would raise the cost of racing a segment above RaceMaxCost times the cost of
+// transcoding it with the first session. Every session is paid the fee estimated for the whole segment when it is
+// submitted, including the sessions that lose the race, so the cost of a race is the sum of the fees of its sessions
+func limitRaceCost(seg *stream.HLSSegment, sessions []*BroadcastSession) (racing []*BroadcastSession, dropped []*BroadcastSession) {
+	if RaceMaxCost <= 0 || len(sessions) <= 1 {
+		return sessions, nil
+	}
+
+	fee := func(sess *BroadcastSession) (*big.Rat, error) {
+		sess.lock.RLock()
+		priceInfo := sess.OrchestratorInfo.GetPriceInfo()
+		sess.lock.RUnlock()
+		p, err := common.RatPriceInfo(priceInfo)
+		if err != nil {
+			return nil, err
+		}
+		f, err := estimateFee(seg, sess.Params.Profiles, p)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil {
+			f = new(big.Rat)
+		}
+		return f, nil
+	}
+
+	first, err := fee(sessions[0])
+	if err != nil {
+		return sessions[:1], sessions[1:]
+	}
+	maxCost := new(big.Rat).Mul(first, new(big.Rat).SetFloat64(RaceMaxCost))
+	cost := new(big.Rat).Set(first)
+	racing = []*BroadcastSession{sessions[0]}
+	for _, sess := range sessions[1:] {
+		f, err := fee(sess)
+		if err == nil && new(big.Rat).Add(cost, f).Cmp(maxCost) <= 0 {
+			cost.Add(cost, f)
+			racing = append(racing, sess)
+		} else {
+			dropped = append(dropped, sess)
+		}
+	}
+	return racing, dropped
+}
+
+// ladderPart is the part of a split rendition ladder that is transcoded by a session
+type ladderPart struct {
+	session   *BroadcastSession
+	params    *core.StreamParameters
+	submitted bool
+	res       *ReceivedTranscodeResult
+	urls      []string
+	errCode   monitor.SegmentTranscodeError
+	err       error
+}
+
+// transcodeLadderParts splits the rendition ladder of the segment across the sessions and transcodes the parts in
+// parallel. The results are only inserted into the playlist once all the parts are transcoded, in the order of the
+// ladder
+func transcodeLadderParts(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, name string,
+	verifier *verification.SegmentVerifier, segPar *core.SegmentParameters, sessions []*BroadcastSession,
+	calcPerceptualHash bool) ([]string, error) {
+
+	nonce := cxn.nonce
+	params := sessions[0].Params
+	ladder := splitLadder(params.Profiles, len(sessions))
+	parts := make([]*ladderPart, len(ladder))
+	var wg sync.WaitGroup
+	for i, profiles := range ladder {
+		partParams := *params
+		partParams.Profiles = profiles
+		part := &ladderPart{session: sessions[i], params: &partParams}
+		parts[i] = part
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			seg2, err := prepareForTranscoding(ctx, cxn, part.session, seg, name)
+			if err != nil {
+				part.err = err
+				return
+			}
+			part.session.pushSegInFlight(seg2)
+			part.submitted = true
+			// Each session only receives and pays for its own part of the ladder
+			sess := part.session.Clone()
+			sess.Params = part.params
+			part.res, part.err = SubmitSegment(ctx, sess, seg2, segPar, nonce, calcPerceptualHash, false)
+			if part.err == nil && part.res == nil {
+				part.err = errors.New("empty response")
+			}
+			if part.err == nil && len(part.res.Segments) != len(part.params.Profiles) {
+				part.err = fmt.Errorf("error transcoding: got segments=%d for profiles=%d from %s",
+					len(part.res.Segments), len(part.params.Profiles), part.session.Transcoder())
+			}
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	for _, part := range parts {
+		if part.err == nil {
+			continue
+		}
+		err = part.err
+		if !part.submitted {
+			continue
+		}
+		if isNonRetryableError(part.err) {
+			cxn.sessManager.completeSession(ctx, part.session, false)
+		} else {
+			cxn.sessManager.suspendAndRemoveOrch(part.session)
+		}
+	}
+	if err != nil {
+		// Return the sessions that transcoded their part since the segment is transcoded again
+		for _, part := range parts {
+			if part.err == nil {
+				updateSession(part.session, part.res)
+				cxn.sessManager.completeSession(ctx, part.session, false)
+			}
+		}
+		return nil, err
+	}
+
+	for _, part := range parts {
+		wg.Add(1)
+		go func(part *ladderPart) {
+			defer wg.Done()
+			part.urls, part.errCode, part.err = saveResults(ctx, cxn, seg, part.session, part.params, part.res, verifier)
+		}(part)
+	}
+	wg.Wait()
+
+	var urls []string
+	var errCode monitor.SegmentTranscodeError
+	for _, part := range parts {
+		if part.err != nil {
+			return nil, part.err
+		}
+		if errCode == "" {
+			errCode = part.errCode
+		}
+		urls = append(urls, part.urls...)
+	}
+	insertResults(ctx, cxn, seg, params.Profiles, urls)
+
+	if monitor.Enabled {
+		monitor.SegmentFullyTranscoded(ctx, nonce, seg.SeqNo, common.ProfilesNames(params.Profiles), errCode, parts[0].session.OrchestratorInfo)
+	}
+
+	clog.V(common.DEBUG).Infof(ctx, "Successfully validated segment parts=%d", len(parts))
+	return urls, nil
+}
+
+// splitLadder splits the profiles into the given number of consecutive parts with similar transcoding costs
+func splitLadder(profiles []ffmpeg.VideoProfile, parts int) [][]ffmpeg.VideoProfile {
+	if parts > len(profiles) {
+		parts = len(profiles)
+	}
+	if parts <= 1 {
+		return [][]ffmpeg.VideoProfile{profiles}
+	}
+
+	costs := make([]int64, len(profiles))
+	var total int64
+	for i, p := range profiles {
+		costs[i] = core.EstimateSessionCost([]ffmpeg.VideoProfile{p})
+		total += costs[i]
+	}
+
+	var res [][]ffmpeg.VideoProfile
+	var cost int64
+	start := 0
+	for i := range profiles {
+		cost += costs[i]
+		remainingParts := parts - len(res) - 1
+		if remainingParts == 0 {
+			break
+		}
+		// Close the part once it reaches its share of the total cost or when the remaining profiles are needed to
+		// fill the remaining parts
+		if cost*int64(parts) >= total*int64(len(res)+1) || len(profiles)-i-1 == remainingParts {
+			res = append(res, profiles[start:i+1])
+			start = i + 1
+		}
+	}
+	return append(res, profiles[start:])
+}
+
 func prepareForTranscoding(ctx context.Context, cxn *rtmpConnection, sess *BroadcastSession, seg *stream.HLSSegment,
 	name string) (*stream.HLSSegment, error) {
 
@@ -1193,7 +1596,29 @@ func prepareForTranscoding(ctx context.Context, cxn *rtmpConnection, sess *Broad
 }
 
 func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, sess *BroadcastSession, res *ReceivedTranscodeResult,
-	verifier *verification.SegmentVerifier) ([]string, error) {
+	verifier *verification.SegmentVerifier) (_ []string, err error) {
+
+	ctx, span := monitor.StartSpan(ctx, "downloadResults")
+	defer func() { monitor.EndSpan(span, err) }()
+
+	segURLs, errCode, err := saveResults(ctx, cxn, seg, sess, sess.Params, res, verifier)
+	if err != nil {
+		return nil, err
+	}
+	insertResults(ctx, cxn, seg, sess.Params.Profiles, segURLs)
+
+	if monitor.Enabled {
+		monitor.SegmentFullyTranscoded(ctx, cxn.nonce, seg.SeqNo, common.ProfilesNames(sess.Params.Profiles), errCode, sess.OrchestratorInfo)
+	}
+
+	clog.V(common.DEBUG).Infof(ctx, "Successfully validated segment")
+	return segURLs, nil
+}
+
+// saveResults downloads the results that the session transcoded for the given stream parameters, saves them to the
+// broadcaster's storage and verifies them
+func saveResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, sess *BroadcastSession, params *core.StreamParameters,
+	res *ReceivedTranscodeResult, verifier *verification.SegmentVerifier) ([]string, monitor.SegmentTranscodeError, error) {
 
 	nonce := cxn.nonce
 	// download transcoded segments from the transcoder
@@ -1228,7 +1653,7 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 		}()
 
 		bos := sess.BroadcasterOS
-		profile := sess.Params.Profiles[i]
+		profile := params.Profiles[i]
 
 		bros := cpl.GetRecordOSSession()
 		var data []byte
@@ -1325,7 +1750,7 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 	}
 	cond.L.Unlock()
 	if dlErr != nil {
-		return nil, dlErr
+		return nil, errCode, dlErr
 	}
 	updateSession(sess, res)
 	cxn.sessManager.completeSession(ctx, sess, false)
@@ -1336,16 +1761,27 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 	}
 
 	if verifier != nil {
+		vsess := sess
+		if params != sess.Params {
+			vsess = sess.Clone()
+			vsess.Params = params
+		}
 		// verify potentially can change content of segURLs
-		err := verify(verifier, cxn, sess, seg, res.TranscodeData, segURLs, segData)
+		err := verify(verifier, cxn, vsess, seg, res.TranscodeData, segURLs, segData)
 		if err != nil {
 			clog.Errorf(ctx, "Error verifying nonce=%d manifestID=%s seqNo=%d err=%q", nonce, cxn.mid, seg.SeqNo, err)
-			return nil, err
+			return nil, errCode, err
 		}
 	}
 
+	return segURLs, errCode, nil
+}
+
+// insertResults inserts the transcoded segments into the playlists of the profiles
+func insertResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSegment, profiles []ffmpeg.VideoProfile, segURLs []string) {
+	nonce := cxn.nonce
 	for i, url := range segURLs {
-		err := cpl.InsertHLSSegment(&sess.Params.Profiles[i], seg.SeqNo, url, seg.Duration)
+		err := cxn.pl.InsertHLSSegment(&profiles[i], seg.SeqNo, url, seg.Duration)
 		if err != nil {
 			// InsertHLSSegment only returns ErrSegmentAlreadyExists error
 			// Right now InsertHLSSegment call is atomic regarding transcoded segments - we either inserting
@@ -1357,13 +1793,6 @@ func downloadResults(ctx context.Context, cxn *rtmpConnection, seg *stream.HLSSe
 			}
 		}
 	}
-
-	if monitor.Enabled {
-		monitor.SegmentFullyTranscoded(ctx, nonce, seg.SeqNo, common.ProfilesNames(sess.Params.Profiles), errCode, sess.OrchestratorInfo)
-	}
-
-	clog.V(common.DEBUG).Infof(ctx, "Successfully validated segment")
-	return segURLs, nil
 }
 
 var sessionErrStrings = []string{"dial tcp", "unexpected EOF", core.ErrOrchBusy.Error(), core.ErrOrchCap.Error()}
@@ -1400,6 +1829,18 @@ func verify(verifier *verification.SegmentVerifier, cxn *rtmpConnection,
 	// The accepted params are not necessarily the same as `params` sent here.
 	// The accepted params may be from an earlier iteration if max retries hit.
 	accepted, err := verifier.Verify(params)
+	if verification.IsRetryable(err) && EvidenceStore != nil {
+		// Retryable errors mean the results failed verification; record why
+		// Fatal failures also get the orchestrator penalized by the store
+		evidence := verification.NewEvidence(params, err)
+		go func() {
+			ctx := context.Background()
+			if err := EvidenceStore.StoreEvidence(ctx, evidence); err != nil {
+				clog.Errorf(ctx, "Error storing verification evidence manifestID=%s seqNo=%d orch=%s err=%q",
+					evidence.ManifestID, evidence.SeqNo, evidence.Orchestrator.Hex(), err)
+			}
+		}()
+	}
 	if verification.IsRetryable(err) {
 		// If retryable, means tampering was detected from this O
 		// Remove the O from the working set for now
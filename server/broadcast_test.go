@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
@@ -1225,6 +1226,390 @@ func TestHLSInsertion(t *testing.T) {
 	assert.Equal(pl.profile, sess.Params.Profiles[0], "HLS profile mismatch")
 }
 
+type ladderPlaylistManager struct {
+	stubPlaylistManager
+	inserted []string
+}
+
+func (pm *ladderPlaylistManager) InsertHLSSegment(profile *ffmpeg.VideoProfile, seqNo uint64, uri string, duration float64) error {
+	pm.lock.Lock()
+	defer pm.lock.Unlock()
+	pm.inserted = append(pm.inserted, profile.Name)
+	return nil
+}
+
+func TestSplitLadder(t *testing.T) {
+	assert := assert.New(t)
+
+	ladder := []ffmpeg.VideoProfile{ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9, ffmpeg.P240p30fps16x9, ffmpeg.P144p30fps16x9}
+
+	assert.Equal([][]ffmpeg.VideoProfile{ladder}, splitLadder(ladder, 1))
+	assert.Equal([][]ffmpeg.VideoProfile{ladder}, splitLadder(ladder, 0))
+
+	// Parts have similar costs
+	assert.Equal([][]ffmpeg.VideoProfile{ladder[:1], ladder[1:]}, splitLadder(ladder, 2))
+
+	// Every part has at least one profile
+	assert.Equal([][]ffmpeg.VideoProfile{ladder[:1], ladder[1:2], ladder[2:3], ladder[3:]}, splitLadder(ladder, 4))
+	assert.Equal([][]ffmpeg.VideoProfile{ladder[:1], ladder[1:2], ladder[2:3], ladder[3:]}, splitLadder(ladder, 5))
+
+	// Profiles with equal costs are split evenly
+	ladder = []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P144p30fps16x9, ffmpeg.P144p30fps16x9, ffmpeg.P144p30fps16x9}
+	assert.Equal([][]ffmpeg.VideoProfile{ladder[:2], ladder[2:]}, splitLadder(ladder, 2))
+}
+
+func TestTranscodeSegment_SplitLadder(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldLadderSplit := LadderSplit
+	LadderSplit = 2
+	defer func() { LadderSplit = oldLadderSplit }()
+
+	ladder := []ffmpeg.VideoProfile{ffmpeg.P720p30fps16x9, ffmpeg.P360p30fps16x9, ffmpeg.P240p30fps16x9, ffmpeg.P144p30fps16x9}
+	params := &core.StreamParameters{ManifestID: core.ManifestID("foo"), Profiles: ladder}
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	failing := ""
+	// Orchestrator that drops the last rendition of its part
+	short := ""
+	newOrch := func() *BroadcastSession {
+		ts, mux := stubTLSServer()
+		t.Cleanup(ts.Close)
+		mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+			buf, err := base64.StdEncoding.DecodeString(r.Header.Get(segmentHeader))
+			require.Nil(err)
+			var segData net.SegData
+			require.Nil(proto.Unmarshal(buf, &segData))
+			md, err := coreSegMetadata(&segData)
+			require.Nil(err)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if failing == ts.URL {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var names []string
+			var segs []*net.TranscodedSegmentData
+			for _, p := range md.Profiles {
+				names = append(names, p.Name)
+				segs = append(segs, &net.TranscodedSegmentData{Url: p.Name + ".ts", Pixels: 100})
+			}
+			received[ts.URL] = names
+			if short == ts.URL {
+				segs = segs[:len(segs)-1]
+			}
+			buf, err = proto.Marshal(&net.TranscodeResult{
+				Result: &net.TranscodeResult_Data{Data: &net.TranscodeData{Segments: segs}},
+			})
+			require.Nil(err)
+			w.WriteHeader(http.StatusOK)
+			w.Write(buf)
+		})
+		sess := StubBroadcastSession(ts.URL)
+		sess.Params = params
+		return sess
+	}
+	sessions := []*BroadcastSession{newOrch(), newOrch()}
+	bsm := bsmWithSessList(sessions)
+	pl := &ladderPlaylistManager{}
+	cxn := &rtmpConnection{
+		mid:         params.ManifestID,
+		nonce:       7,
+		pl:          pl,
+		profile:     &ffmpeg.P720p30fps16x9,
+		params:      params,
+		sessManager: bsm,
+	}
+
+	// Each orchestrator transcodes its part of the ladder and the results are merged in the order of the ladder
+	urls, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Equal([]string{"P720p30fps16x9.ts", "P360p30fps16x9.ts", "P240p30fps16x9.ts", "P144p30fps16x9.ts"}, urls)
+	assert.Equal([]string{"P720p30fps16x9", "P360p30fps16x9", "P240p30fps16x9", "P144p30fps16x9"}, pl.inserted)
+	require.Len(received, 2)
+	parts := [][]string{received[sessions[0].Transcoder()], received[sessions[1].Transcoder()]}
+	assert.ElementsMatch([][]string{{"P720p30fps16x9"}, {"P360p30fps16x9", "P240p30fps16x9", "P144p30fps16x9"}}, parts)
+	assert.Equal(ladder, params.Profiles)
+
+	// Nothing is inserted if a part fails and the failing orchestrator is removed
+	pl.inserted = nil
+	failing = sessions[1].Transcoder()
+	bsm = bsmWithSessList(sessions)
+	cxn.sessManager = bsm
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	assert.NotNil(err)
+	assert.Empty(pl.inserted)
+	_, ok := bsm.trustedPool.sessMap[sessions[0].Transcoder()]
+	assert.True(ok)
+	_, ok = bsm.trustedPool.sessMap[sessions[1].Transcoder()]
+	assert.False(ok)
+
+	// Nothing is inserted if a part is missing renditions
+	failing = ""
+	short = sessions[1].Transcoder()
+	bsm = bsmWithSessList(sessions)
+	cxn.sessManager = bsm
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.NotNil(err)
+	// The part of the orchestrator depends on the order of the sessions
+	assert.Regexp(`got segments=(0 for profiles=1|2 for profiles=3)`, err.Error())
+	assert.Empty(pl.inserted)
+
+	// The parts are only transcoded by trusted orchestrators
+	short = ""
+	untrusted := newOrch()
+	untrusted.OrchestratorScore = common.Score_Untrusted
+	received = make(map[string][]string)
+	cxn.sessManager = bsmWithSessListExt(sessions[:1], []*BroadcastSession{untrusted}, false)
+	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Len(urls, 4)
+	assert.Equal(map[string][]string{sessions[0].Transcoder(): received[sessions[0].Transcoder()]}, received)
+	assert.Len(received[sessions[0].Transcoder()], 4)
+
+	// Without trusted orchestrators the whole ladder is transcoded by an untrusted orchestrator
+	received = make(map[string][]string)
+	cxn.sessManager = bsmWithSessListExt(nil, []*BroadcastSession{untrusted}, false)
+	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Len(urls, 4)
+	assert.Len(received[untrusted.Transcoder()], 4)
+
+	// Segments whose results are verified are not split
+	received = make(map[string][]string)
+	cxn.sessManager = bsmWithSessList(sessions)
+	cxn.sessManager.VerificationPolicy = &verification.Policy{Verifier: &stubVerifier{}}
+	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Len(urls, 4)
+	assert.Len(received, 1)
+}
+
+func TestVerifiesResults(t *testing.T) {
+	assert := assert.New(t)
+
+	b := BroadcastSessionsManager{}
+	seg := &stream.HLSSegment{Data: []byte("segment")}
+	assert.False(b.verifiesResults(seg))
+
+	// Only checking pixel counts does not verify the results
+	b.VerificationPolicy = &verification.Policy{}
+	assert.False(b.verifiesResults(seg))
+
+	b.VerificationPolicy.Verifier = &verification.SignatureVerifier{}
+	assert.True(b.verifiesResults(seg))
+
+	// Only the sampled segments are verified
+	b.VerificationPolicy.SampleRate = 0.5
+	for i := 0; i < 100; i++ {
+		seg := &stream.HLSSegment{Data: []byte(fmt.Sprintf("segment %d", i))}
+		assert.Equal(b.VerificationPolicy.ShouldVerify(seg.Data), b.verifiesResults(seg))
+	}
+}
+
+func TestLimitRaceCost(t *testing.T) {
+	assert := assert.New(t)
+
+	oldRaceMaxCost := RaceMaxCost
+	defer func() { RaceMaxCost = oldRaceMaxCost }()
+
+	sessWithPrice := func(price int64) *BroadcastSession {
+		sess := StubBroadcastSession(fmt.Sprintf("transcoder%d", price))
+		sess.OrchestratorInfo.PriceInfo = &net.PriceInfo{PricePerUnit: price, PixelsPerUnit: 1}
+		sess.Params.Profiles = []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+		return sess
+	}
+	sessions := []*BroadcastSession{sessWithPrice(2), sessWithPrice(3), sessWithPrice(1), sessWithPrice(4)}
+	seg := &stream.HLSSegment{Duration: 2.0}
+
+	// No bound
+	RaceMaxCost = 0
+	racing, dropped := limitRaceCost(seg, sessions)
+	assert.Equal(sessions, racing)
+	assert.Empty(dropped)
+
+	// Sessions are raced in order while the total of the fees for the segment is within the bound
+	RaceMaxCost = 2
+	racing, dropped = limitRaceCost(seg, sessions)
+	assert.Equal([]*BroadcastSession{sessions[0], sessions[2]}, racing)
+	assert.Equal([]*BroadcastSession{sessions[1], sessions[3]}, dropped)
+
+	RaceMaxCost = 3
+	racing, dropped = limitRaceCost(seg, sessions)
+	assert.Equal([]*BroadcastSession{sessions[0], sessions[1], sessions[2]}, racing)
+	assert.Equal([]*BroadcastSession{sessions[3]}, dropped)
+
+	// Free sessions are always raced
+	free := StubBroadcastSession("free")
+	free.OrchestratorInfo.PriceInfo = nil
+	RaceMaxCost = 1
+	racing, dropped = limitRaceCost(seg, []*BroadcastSession{sessions[0], free, sessions[2]})
+	assert.Equal([]*BroadcastSession{sessions[0], free}, racing)
+	assert.Equal([]*BroadcastSession{sessions[2]}, dropped)
+
+	// The fees are estimated for the profiles of the sessions, so a lower price can cost more
+	ladder := sessWithPrice(1)
+	ladder.Params = &core.StreamParameters{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P144p30fps16x9, ffmpeg.P144p30fps16x9}}
+	RaceMaxCost = 2
+	racing, dropped = limitRaceCost(seg, []*BroadcastSession{sessions[0], ladder})
+	assert.Equal([]*BroadcastSession{sessions[0]}, racing)
+	assert.Equal([]*BroadcastSession{ladder}, dropped)
+}
+
+func TestTranscodeSegment_Race(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldRaceSessions := RaceSessions
+	RaceSessions = 2
+	defer func() { RaceSessions = oldRaceSessions }()
+
+	params := &core.StreamParameters{ManifestID: core.ManifestID("foo"), Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}}
+	cancelled := make(chan string, 2)
+	ended := make(chan string, 2)
+	newOrch := func(url string, fail bool, delay time.Duration) *BroadcastSession {
+		ts, mux := stubTLSServer()
+		t.Cleanup(ts.Close)
+		mux.HandleFunc("/net.Orchestrator/EndTranscodingSession", func(w http.ResponseWriter, r *http.Request) {
+			ended <- url
+		})
+		mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				cancelled <- url
+				return
+			case <-time.After(delay):
+			}
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			buf, err := proto.Marshal(&net.TranscodeResult{
+				Result: &net.TranscodeResult_Data{Data: &net.TranscodeData{
+					Segments: []*net.TranscodedSegmentData{{Url: url, Pixels: 100}},
+				}},
+			})
+			require.Nil(err)
+			w.WriteHeader(http.StatusOK)
+			w.Write(buf)
+		})
+		sess := StubBroadcastSession(ts.URL)
+		sess.Params = params
+		return sess
+	}
+	newCxn := func(sessions []*BroadcastSession) *rtmpConnection {
+		return &rtmpConnection{
+			mid:         params.ManifestID,
+			nonce:       7,
+			pl:          &stubPlaylistManager{manifestID: params.ManifestID},
+			profile:     &ffmpeg.P144p30fps16x9,
+			params:      params,
+			sessManager: bsmWithSessList(sessions),
+		}
+	}
+
+	// The first results are used and the slower submission is cancelled
+	fast, slow := newOrch("fast.ts", false, 0), newOrch("slow.ts", false, 5*time.Second)
+	cxn := newCxn([]*BroadcastSession{fast, slow})
+	urls, _, err := transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Equal([]string{"fast.ts"}, urls)
+	select {
+	case url := <-cancelled:
+		assert.Equal("slow.ts", url)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the submission to be cancelled")
+	}
+	// The cancelled session is not removed
+	time.Sleep(50 * time.Millisecond)
+	_, ok := cxn.sessManager.trustedPool.sessMap[slow.Transcoder()]
+	assert.True(ok)
+	// The transcoding session of the cancelled submission is ended
+	select {
+	case url := <-ended:
+		assert.Equal("slow.ts", url)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the transcoding session to end")
+	}
+
+	// Failed results are skipped in favor of the next results
+	failing, ok2 := newOrch("failing.ts", true, 0), newOrch("ok.ts", false, 100*time.Millisecond)
+	cxn = newCxn([]*BroadcastSession{failing, ok2})
+	urls, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Equal([]string{"ok.ts"}, urls)
+	_, ok = cxn.sessManager.trustedPool.sessMap[failing.Transcoder()]
+	assert.False(ok)
+
+	// An error is returned if all the submissions fail
+	cxn = newCxn([]*BroadcastSession{newOrch("a.ts", true, 0), newOrch("b.ts", true, 0)})
+	_, _, err = transcodeSegment(context.TODO(), cxn, &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}, "dummy", nil, nil)
+	assert.NotNil(err)
+}
+
+func TestTranscodeSegment_RaceUntrusted(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldRaceSessions := RaceSessions
+	RaceSessions = 2
+	defer func() { RaceSessions = oldRaceSessions }()
+
+	params := &core.StreamParameters{ManifestID: core.ManifestID("foo")}
+	buf, err := proto.Marshal(&net.TranscodeResult{Result: &net.TranscodeResult_Data{Data: &net.TranscodeData{}}})
+	require.Nil(err)
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	newOrch := func(delay time.Duration) *BroadcastSession {
+		ts, mux := stubTLSServer()
+		t.Cleanup(ts.Close)
+		mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[ts.URL]++
+			mu.Unlock()
+			time.Sleep(delay)
+			w.WriteHeader(http.StatusOK)
+			w.Write(buf)
+		})
+		sess := StubBroadcastSession(ts.URL)
+		sess.Params = params
+		return sess
+	}
+	trusted := newOrch(50 * time.Millisecond)
+	untrusted := newOrch(0)
+	untrusted.OrchestratorScore = common.Score_Untrusted
+	cxn := &rtmpConnection{
+		mid:         params.ManifestID,
+		nonce:       7,
+		pl:          &stubPlaylistManager{manifestID: params.ManifestID},
+		profile:     &ffmpeg.P144p30fps16x9,
+		params:      params,
+		sessManager: bsmWithSessListExt([]*BroadcastSession{trusted}, []*BroadcastSession{untrusted}, false),
+	}
+	seg := &stream.HLSSegment{Data: []byte("dummy"), Duration: 2.0}
+
+	// Untrusted orchestrators do not race if the results are not verified
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", nil, nil)
+	require.Nil(err)
+	assert.Equal(1, hits[trusted.Transcoder()])
+	assert.Zero(hits[untrusted.Transcoder()])
+
+	// Untrusted orchestrators race if the results of the winner are verified
+	verifier := &stubVerifier{}
+	policy := &verification.Policy{Verifier: verifier}
+	cxn.sessManager = bsmWithSessListExt([]*BroadcastSession{trusted}, []*BroadcastSession{untrusted}, false)
+	cxn.sessManager.VerificationPolicy = policy
+	_, _, err = transcodeSegment(context.TODO(), cxn, seg, "dummy", verification.NewSegmentVerifier(policy), nil)
+	require.Nil(err)
+	assert.Equal(2, hits[trusted.Transcoder()])
+	assert.Equal(1, hits[untrusted.Transcoder()])
+	require.Equal(1, verifier.calls)
+	assert.Equal(untrusted.Transcoder(), verifier.params.Orchestrator.Transcoder)
+}
+
 func TestVerifier_Invocation(t *testing.T) {
 	// Various tests around ensuring that the verifier itself is invoked within
 	// transcodeSegment, as well as various unusual verifier configurations
@@ -1378,6 +1763,67 @@ func TestVerifier_Verify(t *testing.T) {
 	c.Cleanup()
 }
 
+type stubEvidenceStore struct {
+	evidence chan *verification.Evidence
+}
+
+func (s *stubEvidenceStore) StoreEvidence(ctx context.Context, e *verification.Evidence) error {
+	s.evidence <- e
+	return nil
+}
+
+func TestVerifier_Evidence(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	store := &stubEvidenceStore{evidence: make(chan *verification.Evidence, 1)}
+	oldStore := EvidenceStore
+	EvidenceStore = store
+	defer func() { EvidenceStore = oldStore }()
+
+	orch := pm.RandAddress()
+	sess := &BroadcastSession{
+		Params:            &core.StreamParameters{ManifestID: core.ManifestID("streamName")},
+		OrchestratorScore: common.Score_Trusted,
+		OrchestratorInfo:  &net.OrchestratorInfo{Transcoder: "asdf", Address: orch.Bytes()},
+		lock:              &sync.RWMutex{},
+	}
+	pl := core.NewBasicPlaylistManager(core.ManifestID("streamName"), drivers.NewMemoryDriver(nil).NewSession(""), nil)
+	defer pl.Cleanup()
+	cxn := &rtmpConnection{pl: pl, sessManager: bsmWithSessList([]*BroadcastSession{sess})}
+	source := &stream.HLSSegment{SeqNo: 5, Data: []byte("source")}
+	res := &net.TranscodeData{Segments: []*net.TranscodedSegmentData{{Url: "filename"}}}
+	URIs := []string{"filename"}
+	renditionData := [][]byte{[]byte("foo")}
+
+	// Non-retryable errors are not verification failures, so there is no evidence
+	sv := &stubVerifier{err: errors.New("NonRetryable")}
+	err := verify(newStubSegmentVerifier(sv), cxn, sess, source, res, URIs, renditionData)
+	assert.Equal(sv.err, err)
+	select {
+	case <-store.evidence:
+		assert.Fail("unexpected evidence")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Failed verifications are recorded
+	sv = &stubVerifier{err: verification.ErrAudioMismatch, retries: 10}
+	err = verify(newStubSegmentVerifier(sv), cxn, sess, source, res, URIs, renditionData)
+	assert.Equal(sv.err, err)
+	select {
+	case e := <-store.evidence:
+		require.NotNil(e)
+		assert.Equal(orch, e.Orchestrator)
+		assert.Equal("streamName", e.ManifestID)
+		assert.Equal(uint64(5), e.SeqNo)
+		assert.Equal("AudioMismatch", e.Reason)
+		assert.True(e.Fatal)
+		assert.Len(e.RenditionHashes, 1)
+	case <-time.After(time.Second):
+		assert.Fail("timed out waiting for evidence")
+	}
+}
+
 func TestVerifier_HLSInsertion(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1777,31 +2223,103 @@ func TestCollectResults(t *testing.T) {
 	assert.Equal(untrustedSessVerified, untrustedResults[0].Session)
 }
 
+func TestVerifcationEnabledWhenRedundancyGreaterThanOne(t *testing.T) {
+	b := BroadcastSessionsManager{}
+	require.False(t, b.isVerificationEnabled())
+
+	b.VerificationPolicy = &verification.Policy{Redundancy: 1}
+	require.False(t, b.isVerificationEnabled())
+
+	b.VerificationPolicy.Redundancy = 3
+	require.True(t, b.isVerificationEnabled())
+	require.Equal(t, 3, b.verificationRedundancy())
+}
+
 func TestVerifcationEnabledWhenFreqGreaterThanZero(t *testing.T) {
 	b := BroadcastSessionsManager{}
 	require.False(t, b.isVerificationEnabled())
 
+	// Streams that only set a frequency use 1 trusted and 2 untrusted orchestrators
 	b.VerificationFreq = 1
 	require.True(t, b.isVerificationEnabled())
+	require.Equal(t, 3, b.verificationRedundancy())
+
+	b.VerificationPolicy = &verification.Policy{Redundancy: 4}
+	require.Equal(t, 4, b.verificationRedundancy())
 }
 
 func TestVerifcationDoesntRunWhenNoVerifiedSessionPresent(t *testing.T) {
 	b := BroadcastSessionsManager{
-		VerificationFreq: 1,
+		VerificationPolicy: &verification.Policy{Redundancy: 3},
 	}
-	require.False(t, b.shouldSkipVerification(nil))
+	seg := &stream.HLSSegment{Data: []byte("segment")}
+	require.False(t, b.shouldSkipVerification(nil, seg))
 
 	b.verifiedSession = &BroadcastSession{
 		LatencyScore: 1.23,
 	}
 
-	require.False(t, b.shouldSkipVerification([]*BroadcastSession{}))
+	require.False(t, b.shouldSkipVerification([]*BroadcastSession{}, seg))
+}
+
+func TestNeedsSignatures(t *testing.T) {
+	assert := assert.New(t)
+
+	b := BroadcastSessionsManager{VerificationPolicy: &verification.Policy{}}
+	seg := &stream.HLSSegment{Data: []byte("segment")}
+	assert.False(b.needsSignatures(seg))
+
+	// Signatures are only requested for segments sampled for local signature verification
+	b.VerificationPolicy.Verifier = &verification.SignatureVerifier{}
+	assert.True(b.needsSignatures(seg))
+
+	b.VerificationPolicy.SampleRate = 0.5
+	var sampled int
+	for i := 0; i < 1000; i++ {
+		seg := &stream.HLSSegment{Data: []byte(fmt.Sprintf("segment %d", i))}
+		needs := b.needsSignatures(seg)
+		assert.Equal(b.VerificationPolicy.ShouldVerify(seg.Data), needs)
+		if needs {
+			sampled++
+		}
+	}
+	assert.Greater(sampled, 0)
+	assert.Less(sampled, 1000)
+}
+
+func TestVerifcationRunsBasedOnSampleRate(t *testing.T) {
+	sampleRate := 0.2
+	b := BroadcastSessionsManager{
+		VerificationPolicy: &verification.Policy{Redundancy: 3, SampleRate: sampleRate}, // Verification should run approximately 1 in 5 times
+	}
+
+	var verifiedSession = &BroadcastSession{
+		LatencyScore: 1.23,
+	}
+
+	b.verifiedSession = verifiedSession
+
+	var shouldSkipCount int
+	numTests := 10000
+	for i := 0; i < numTests; i++ {
+		seg := &stream.HLSSegment{Data: []byte(fmt.Sprintf("segment %d", i))}
+		skip := b.shouldSkipVerification([]*BroadcastSession{verifiedSession}, seg)
+		// The decision is reproducible for the same segment
+		require.Equal(t, skip, b.shouldSkipVerification([]*BroadcastSession{verifiedSession}, seg))
+		if skip {
+			shouldSkipCount++
+		}
+	}
+
+	require.Greater(t, float64(shouldSkipCount), float64(numTests)*(1-2*sampleRate))
+	require.Less(t, float64(shouldSkipCount), float64(numTests)*(1-0.5*sampleRate))
 }
 
 func TestVerifcationRunsBasedOnVerificationFrequency(t *testing.T) {
 	verificationFreq := 5
 	b := BroadcastSessionsManager{
-		VerificationFreq: uint(verificationFreq), // Verification should run approximately 1 in 5 times
+		VerificationFreq:   uint(verificationFreq), // Verification should run approximately 1 in 5 times
+		VerificationPolicy: &verification.Policy{SampleRate: 1e-12},
 	}
 
 	var verifiedSession = &BroadcastSession{
@@ -1810,10 +2328,12 @@ func TestVerifcationRunsBasedOnVerificationFrequency(t *testing.T) {
 
 	b.verifiedSession = verifiedSession
 
+	// The frequency is used instead of the sample rate of the policy
 	var shouldSkipCount int
 	numTests := 10000
+	seg := &stream.HLSSegment{Data: []byte("segment")}
 	for i := 0; i < numTests; i++ {
-		if b.shouldSkipVerification([]*BroadcastSession{verifiedSession}) {
+		if b.shouldSkipVerification([]*BroadcastSession{verifiedSession}, seg) {
 			shouldSkipCount++
 		}
 	}
@@ -1821,3 +2341,26 @@ func TestVerifcationRunsBasedOnVerificationFrequency(t *testing.T) {
 	require.Greater(t, float32(shouldSkipCount), float32(numTests)*(1-2/float32(verificationFreq)))
 	require.Less(t, float32(shouldSkipCount), float32(numTests)*(1-0.5/float32(verificationFreq)))
 }
+
+func TestVerificationPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	oldPolicy := Policy
+	defer func() { Policy = oldPolicy }()
+
+	Policy = nil
+	assert.Nil(verificationPolicy(nil))
+	assert.Nil(verificationPolicy(&core.StreamParameters{VerificationFreq: 5}))
+	assert.Equal(&verification.Policy{SampleRate: 0.5, Redundancy: 3},
+		verificationPolicy(&core.StreamParameters{VerificationSampleRate: 0.5, VerificationRedundancy: 3}))
+
+	// Streams override the node wide policy
+	verifier := &verification.EpicClassifier{Addr: "http://verifier"}
+	Policy = &verification.Policy{Retries: 2, Verifier: verifier, SampleRate: 0.1, Redundancy: 2}
+	// Streams without a sample rate or redundancy use the node wide policy
+	assert.Same(Policy, verificationPolicy(&core.StreamParameters{}))
+	assert.Same(Policy, verificationPolicy(&core.StreamParameters{VerificationFreq: 5}))
+	assert.Equal(&verification.Policy{Retries: 2, Verifier: verifier, SampleRate: 0.5, Redundancy: 4},
+		verificationPolicy(&core.StreamParameters{VerificationSampleRate: 0.5, VerificationRedundancy: 4}))
+	assert.Equal(2, Policy.Redundancy)
+}
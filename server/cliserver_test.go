@@ -30,7 +30,7 @@ func newMockServer() *httptest.Server {
 	n.NodeType = core.TranscoderNode
 	n.TranscoderManager = core.NewRemoteTranscoderManager()
 	strm := &common.StubServerStream{}
-	go func() { n.TranscoderManager.Manage(strm, 5, nil) }()
+	go func() { n.TranscoderManager.Manage(strm, "", false, 5, nil) }()
 	time.Sleep(1 * time.Millisecond)
 	n.Transcoder = n.TranscoderManager
 	s, _ := NewLivepeerServer("127.0.0.1:1938", n, true, "")
@@ -49,7 +49,7 @@ func TestActivateOrchestrator(t *testing.T) {
 	n.NodeType = core.TranscoderNode
 	n.TranscoderManager = core.NewRemoteTranscoderManager()
 	strm := &common.StubServerStream{}
-	go func() { n.TranscoderManager.Manage(strm, 5, nil) }()
+	go func() { n.TranscoderManager.Manage(strm, "", false, 5, nil) }()
 	time.Sleep(1 * time.Millisecond)
 	n.Transcoder = n.TranscoderManager
 	s, _ := NewLivepeerServer("127.0.0.1:1938", n, true, "")
@@ -203,7 +203,7 @@ func TestGetStatus(t *testing.T) {
 	req.Nil(err)
 	// expected := fmt.Sprintf(`{"Manifests":{},"InternalManifests":{},"StreamInfo":{},"OrchestratorPool":[],"Version":"undefined","GolangRuntimeVersion":"%s","GOArch":"%s","GOOS":"%s","RegisteredTranscodersNumber":1,"RegisteredTranscoders":[{"Address":"TestAddress","Capacity":5}],"LocalTranscoding":false}`,
 	// 	runtime.Version(), runtime.GOARCH, runtime.GOOS)
-	expected := fmt.Sprintf(`{"Manifests":{},"InternalManifests":{},"StreamInfo":{},"OrchestratorPool":[],"OrchestratorPoolInfos":null,"Version":"undefined","GolangRuntimeVersion":"%s","GOArch":"%s","GOOS":"%s","RegisteredTranscodersNumber":1,"RegisteredTranscoders":[{"Address":"TestAddress","Capacity":5}],"LocalTranscoding":false,"BroadcasterPrices":{}}`,
+	expected := fmt.Sprintf(`{"Manifests":{},"InternalManifests":{},"StreamInfo":{},"OrchestratorPool":[],"OrchestratorPoolInfos":null,"Version":"undefined","GolangRuntimeVersion":"%s","GOArch":"%s","GOOS":"%s","RegisteredTranscodersNumber":1,"RegisteredTranscoders":[{"ID":"TestAddress","Address":"TestAddress","Capacity":5,"Load":0,"Capabilities":[],"Draining":false}],"LocalTranscoding":false,"BroadcasterPrices":{}}`,
 		runtime.Version(), runtime.GOARCH, runtime.GOOS)
 	assert.Equal(expected, string(body))
 }
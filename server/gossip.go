@@ -0,0 +1,374 @@
+package server
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	gonet "net"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/common"
+	lpcrypto "github.com/livepeer/go-livepeer/crypto"
+	"github.com/livepeer/go-livepeer/net"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+var ErrGossipPeer = errors.New("unknown gossip peer")
+var ErrGossipSignature = errors.New("invalid gossip signature")
+var ErrGossipStale = errors.New("stale gossip view")
+var ErrGossipFuture = errors.New("gossip view from the future")
+
+// How long a gateway reports an orchestrator as suspended after suspending it
+var GossipSuspension = 5 * time.Minute
+
+// Views of peers that were not updated for this long are ignored
+var GossipViewTTL = 10 * time.Minute
+
+// Views whose timestamp is further ahead of the gateway's clock are rejected
+var GossipMaxClockSkew = time.Minute
+
+// Segment counts are halved every GossipStatsHalfLife so that the stats
+// reflect the recent outcomes of each transcoder
+var GossipStatsHalfLife = 10 * time.Minute
+
+// OrchStats keeps the outcome of the segments submitted to each transcoder
+// when gossip is enabled
+var OrchStats *OrchestratorStats
+
+// OrchestratorStats counts the segments submitted to each transcoder, how
+// many were transcoded successfully and when the transcoder was last suspended
+type OrchestratorStats struct {
+	mu      sync.Mutex
+	stats   map[string]*net.GossipStats
+	decayed time.Time
+}
+
+func NewOrchestratorStats() *OrchestratorStats {
+	return &OrchestratorStats{stats: make(map[string]*net.GossipStats), decayed: time.Now()}
+}
+
+// decay halves the segment counts once per half-life elapsed since the last
+// decay and drops the transcoders that have nothing left to report
+func (s *OrchestratorStats) decay(now time.Time) {
+	if GossipStatsHalfLife <= 0 {
+		return
+	}
+	halvings := now.Sub(s.decayed) / GossipStatsHalfLife
+	if halvings <= 0 {
+		return
+	}
+	s.decayed = s.decayed.Add(halvings * GossipStatsHalfLife)
+	if halvings > 63 {
+		halvings = 63
+	}
+	for transcoder, st := range s.stats {
+		st.Attempts >>= uint(halvings)
+		st.Successes >>= uint(halvings)
+		if st.Attempts == 0 && st.SuspendedUntil <= now.Unix() {
+			delete(s.stats, transcoder)
+		}
+	}
+}
+
+func (s *OrchestratorStats) get(transcoder string) *net.GossipStats {
+	st, ok := s.stats[transcoder]
+	if !ok {
+		st = &net.GossipStats{Transcoder: transcoder}
+		s.stats[transcoder] = st
+	}
+	return st
+}
+
+func (s *OrchestratorStats) recordSegment(transcoder string, success bool) {
+	if s == nil || transcoder == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decay(time.Now())
+	st := s.get(transcoder)
+	st.Attempts++
+	if success {
+		st.Successes++
+	}
+}
+
+func (s *OrchestratorStats) recordSuspension(transcoder string) {
+	if s == nil || transcoder == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.get(transcoder).SuspendedUntil = time.Now().Add(GossipSuspension).Unix()
+}
+
+// Stats returns a copy of the stats of all transcoders sorted by transcoder
+func (s *OrchestratorStats) Stats() []*net.GossipStats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.decay(time.Now())
+	res := make([]*net.GossipStats, 0, len(s.stats))
+	for _, st := range s.stats {
+		res = append(res, proto.Clone(st).(*net.GossipStats))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Transcoder < res[j].Transcoder })
+	return res
+}
+
+// GossipPeer is a gateway that views are exchanged with
+type GossipPeer struct {
+	URL     *url.URL
+	Address ethcommon.Address
+}
+
+// ParseGossipPeers parses a comma-separated list of peers formatted as
+// <ETH address>@<host>:<port>
+func ParseGossipPeers(peers string) ([]GossipPeer, error) {
+	var res []GossipPeer
+	for _, p := range strings.Split(peers, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		parts := strings.SplitN(p, "@", 2)
+		if len(parts) != 2 || !ethcommon.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid gossip peer %q, expected <address>@<host>:<port>", p)
+		}
+		uri, err := url.ParseRequestURI("https://" + parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid gossip peer %q: %w", p, err)
+		}
+		res = append(res, GossipPeer{URL: uri, Address: ethcommon.HexToAddress(parts[0])})
+	}
+	return res, nil
+}
+
+// LoadGossipKey loads the gateway's gossip key from a file, creating the key
+// if the file doesn't exist
+func LoadGossipKey(path string) (*ecdsa.PrivateKey, error) {
+	key, err := ethcrypto.LoadECDSA(path)
+	if err == nil {
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+	if key, err = ethcrypto.GenerateKey(); err != nil {
+		return nil, err
+	}
+	if err := ethcrypto.SaveECDSA(path, key); err != nil {
+		return nil, err
+	}
+	glog.Infof("Created gossip key path=%s address=%v", path, ethcrypto.PubkeyToAddress(key.PublicKey).Hex())
+	return key, nil
+}
+
+// Gossip exchanges the gateway's view of the orchestrators with its peers.
+// Views are signed by the gateway key and only the views signed by the
+// configured peers are accepted.
+type Gossip struct {
+	// Pool whose orchestrators are advertised to peers
+	Pool common.OrchestratorPool
+
+	key     *ecdsa.PrivateKey
+	address ethcommon.Address
+	peers   map[ethcommon.Address]GossipPeer
+	stats   *OrchestratorStats
+
+	mu    sync.RWMutex
+	views map[ethcommon.Address]*net.GossipView
+
+	server *grpc.Server
+	net.UnimplementedGossipServer
+}
+
+func NewGossip(key *ecdsa.PrivateKey, peers []GossipPeer, stats *OrchestratorStats) *Gossip {
+	g := &Gossip{
+		key:     key,
+		address: ethcrypto.PubkeyToAddress(key.PublicKey),
+		peers:   make(map[ethcommon.Address]GossipPeer),
+		stats:   stats,
+		views:   make(map[ethcommon.Address]*net.GossipView),
+	}
+	for _, p := range peers {
+		g.peers[p.Address] = p
+	}
+	return g
+}
+
+// Address returns the ETH address that signs the gateway's views
+func (g *Gossip) Address() ethcommon.Address {
+	return g.address
+}
+
+// Start starts a Gossip server
+// This method will block
+func (g *Gossip) Start(uri *url.URL, workDir string) error {
+	listener, err := gonet.Listen("tcp", uri.Host)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	certFile, keyFile, err := getCert(uri, workDir)
+	if err != nil {
+		return err
+	}
+
+	creds, err := credentials.NewServerTLSFromFile(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+
+	s := grpc.NewServer(grpc.Creds(creds))
+	g.server = s
+
+	net.RegisterGossipServer(s, g)
+
+	glog.Infof("Listening for gossip on %v address=%v", uri.Host, g.address.Hex())
+	return s.Serve(listener)
+}
+
+// Stop stops the Gossip server
+func (g *Gossip) Stop() {
+	if g.server != nil {
+		g.server.Stop()
+	}
+}
+
+// Exchange accepts the view of a peer and returns the gateway's view
+func (g *Gossip) Exchange(ctx context.Context, req *net.SignedGossipView) (*net.SignedGossipView, error) {
+	if err := g.acceptView(req); err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	view, err := g.signedView()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return view, nil
+}
+
+// Run exchanges views with all peers every interval until ctx is done
+func (g *Gossip) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for _, peer := range g.peers {
+			go func(peer GossipPeer) {
+				if err := g.exchangeWith(ctx, peer); err != nil {
+					glog.Errorf("Unable to exchange gossip peer=%v err=%q", peer.URL.Host, err)
+				}
+			}(peer)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (g *Gossip) exchangeWith(ctx context.Context, peer GossipPeer) error {
+	view, err := g.signedView()
+	if err != nil {
+		return err
+	}
+	conn, err := grpc.Dial(peer.URL.Host,
+		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		grpc.WithBlock(),
+		grpc.WithTimeout(GRPCConnectTimeout))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, GRPCTimeout)
+	defer cancel()
+	res, err := net.NewGossipClient(conn).Exchange(ctx, view)
+	if err != nil {
+		return err
+	}
+	return g.acceptView(res)
+}
+
+func (g *Gossip) signedView() (*net.SignedGossipView, error) {
+	view := &net.GossipView{
+		Gateway:   g.address.Bytes(),
+		Timestamp: time.Now().UnixMilli(),
+		Stats:     g.stats.Stats(),
+	}
+	if g.Pool != nil {
+		for _, info := range g.Pool.GetInfos() {
+			view.Orchestrators = append(view.Orchestrators, info.URL.String())
+		}
+	}
+	data, err := proto.Marshal(view)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := ethcrypto.Sign(accounts.TextHash(ethcrypto.Keccak256(data)), g.key)
+	if err != nil {
+		return nil, err
+	}
+	// Use the same V as ETH account signatures, see crypto.VerifySig
+	sig[64] += 27
+	return &net.SignedGossipView{View: data, Sig: sig}, nil
+}
+
+// acceptView verifies that the view is signed by a peer, newer than the last
+// view of the peer and not ahead of the gateway's clock before keeping it
+func (g *Gossip) acceptView(signed *net.SignedGossipView) error {
+	var view net.GossipView
+	if err := proto.Unmarshal(signed.View, &view); err != nil {
+		return err
+	}
+	peer := ethcommon.BytesToAddress(view.Gateway)
+	if _, ok := g.peers[peer]; !ok {
+		return fmt.Errorf("%w gateway=%v", ErrGossipPeer, peer.Hex())
+	}
+	if !lpcrypto.VerifySig(peer, ethcrypto.Keccak256(signed.View), signed.Sig) {
+		return fmt.Errorf("%w gateway=%v", ErrGossipSignature, peer.Hex())
+	}
+	if maxTimestamp := time.Now().Add(GossipMaxClockSkew).UnixMilli(); view.Timestamp > maxTimestamp {
+		return fmt.Errorf("%w gateway=%v timestamp=%d", ErrGossipFuture, peer.Hex(), view.Timestamp)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if prev, ok := g.views[peer]; ok && prev.Timestamp >= view.Timestamp {
+		return fmt.Errorf("%w gateway=%v", ErrGossipStale, peer.Hex())
+	}
+	g.views[peer] = &view
+	return nil
+}
+
+// Views returns the latest views of the peers that are not expired
+func (g *Gossip) Views() []*net.GossipView {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	since := time.Now().Add(-GossipViewTTL).UnixMilli()
+	var views []*net.GossipView
+	for _, view := range g.views {
+		if view.Timestamp >= since {
+			views = append(views, view)
+		}
+	}
+	return views
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGossip(t *testing.T, peers ...*Gossip) *Gossip {
+	key, err := ethcrypto.GenerateKey()
+	require.Nil(t, err)
+	var gossipPeers []GossipPeer
+	for _, p := range peers {
+		gossipPeers = append(gossipPeers, GossipPeer{Address: p.Address()})
+	}
+	return NewGossip(key, gossipPeers, NewOrchestratorStats())
+}
+
+func TestGossip_Exchange(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a := newTestGossip(t)
+	b := newTestGossip(t, a)
+	a.peers[b.Address()] = GossipPeer{Address: b.Address()}
+	a.stats.recordSegment("https://127.0.0.1:8935", true)
+	a.stats.recordSegment("https://127.0.0.1:8935", false)
+	a.stats.recordSuspension("https://127.0.0.1:8936")
+
+	view, err := a.signedView()
+	require.Nil(err)
+	res, err := b.Exchange(context.Background(), view)
+	require.Nil(err)
+
+	// b keeps a's view
+	views := b.Views()
+	require.Len(views, 1)
+	assert.Equal(a.Address().Bytes(), views[0].Gateway)
+	require.Len(views[0].Stats, 2)
+	assert.Equal("https://127.0.0.1:8935", views[0].Stats[0].Transcoder)
+	assert.Equal(uint64(2), views[0].Stats[0].Attempts)
+	assert.Equal(uint64(1), views[0].Stats[0].Successes)
+	assert.Greater(views[0].Stats[1].SuspendedUntil, time.Now().Unix())
+
+	// and a accepts b's view in return
+	assert.Nil(a.acceptView(res))
+	assert.Len(a.Views(), 1)
+
+	// Views that aren't newer are rejected
+	_, err = b.Exchange(context.Background(), view)
+	assert.Contains(err.Error(), ErrGossipStale.Error())
+
+	// Views are ignored once expired
+	oldTTL := GossipViewTTL
+	defer func() { GossipViewTTL = oldTTL }()
+	GossipViewTTL = -time.Second
+	assert.Empty(b.Views())
+}
+
+func TestGossip_RejectsInvalidViews(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	a := newTestGossip(t)
+	b := newTestGossip(t, a)
+
+	// Views of gateways that aren't peers are rejected
+	c := newTestGossip(t)
+	view, err := c.signedView()
+	require.Nil(err)
+	assert.ErrorIs(b.acceptView(view), ErrGossipPeer)
+
+	// Views must be signed by the peer
+	view, err = a.signedView()
+	require.Nil(err)
+	var v net.GossipView
+	require.Nil(proto.Unmarshal(view.View, &v))
+	v.Orchestrators = []string{"https://127.0.0.1:8935"}
+	view.View, err = proto.Marshal(&v)
+	require.Nil(err)
+	assert.ErrorIs(b.acceptView(view), ErrGossipSignature)
+
+	view, err = c.signedView()
+	require.Nil(err)
+	view.View, err = proto.Marshal(&net.GossipView{Gateway: a.Address().Bytes(), Timestamp: time.Now().UnixMilli()})
+	require.Nil(err)
+	assert.ErrorIs(b.acceptView(view), ErrGossipSignature)
+
+	// Views from the future are rejected
+	view, err = a.signedView()
+	require.Nil(err)
+	require.Nil(proto.Unmarshal(view.View, &v))
+	v.Timestamp = time.Now().Add(GossipMaxClockSkew + time.Minute).UnixMilli()
+	view = signGossipView(t, a, &v)
+	assert.ErrorIs(b.acceptView(view), ErrGossipFuture)
+	assert.Empty(b.Views())
+
+	// but a small clock skew is tolerated
+	v.Timestamp = time.Now().Add(GossipMaxClockSkew / 2).UnixMilli()
+	view = signGossipView(t, a, &v)
+	assert.Nil(b.acceptView(view))
+	assert.Len(b.Views(), 1)
+}
+
+func signGossipView(t *testing.T, g *Gossip, view *net.GossipView) *net.SignedGossipView {
+	data, err := proto.Marshal(view)
+	require.Nil(t, err)
+	sig, err := ethcrypto.Sign(accounts.TextHash(ethcrypto.Keccak256(data)), g.key)
+	require.Nil(t, err)
+	sig[64] += 27
+	return &net.SignedGossipView{View: data, Sig: sig}
+}
+
+func TestOrchestratorStats_Decay(t *testing.T) {
+	assert := assert.New(t)
+
+	oldSuspension := GossipSuspension
+	defer func() { GossipSuspension = oldSuspension }()
+	GossipSuspension = 3 * GossipStatsHalfLife
+
+	stats := NewOrchestratorStats()
+	for i := 0; i < 8; i++ {
+		stats.recordSegment("https://127.0.0.1:8935", i%2 == 0)
+	}
+	stats.recordSegment("https://127.0.0.1:8936", true)
+	stats.recordSuspension("https://127.0.0.1:8937")
+
+	// Counts are kept within a half-life
+	stats.decay(time.Now())
+	assert.Len(stats.Stats(), 3)
+
+	// and halved once per elapsed half-life
+	stats.decay(stats.decayed.Add(2 * GossipStatsHalfLife))
+	res := stats.Stats()
+	require.Len(t, res, 2)
+	assert.Equal("https://127.0.0.1:8935", res[0].Transcoder)
+	assert.Equal(uint64(2), res[0].Attempts)
+	assert.Equal(uint64(1), res[0].Successes)
+	// Transcoders without segments are only kept while suspended
+	assert.Equal("https://127.0.0.1:8937", res[1].Transcoder)
+}
+
+func TestOrchestratorStats_Nil(t *testing.T) {
+	var stats *OrchestratorStats
+	stats.recordSegment("https://127.0.0.1:8935", true)
+	stats.recordSuspension("https://127.0.0.1:8935")
+	assert.Nil(t, stats.Stats())
+}
+
+func TestParseGossipPeers(t *testing.T) {
+	assert := assert.New(t)
+
+	peers, err := ParseGossipPeers("0x0000000000000000000000000000000000000001@127.0.0.1:9945, 0x0000000000000000000000000000000000000002@gw.example.com:9945")
+	assert.Nil(err)
+	assert.Len(peers, 2)
+	assert.Equal(ethcommon.Address{19: 1}, peers[0].Address)
+	assert.Equal("127.0.0.1:9945", peers[0].URL.Host)
+	assert.Equal("gw.example.com:9945", peers[1].URL.Host)
+
+	peers, err = ParseGossipPeers("")
+	assert.Nil(err)
+	assert.Empty(peers)
+
+	_, err = ParseGossipPeers("127.0.0.1:9945")
+	assert.Contains(err.Error(), "invalid gossip peer")
+	_, err = ParseGossipPeers("0xnotanaddress@127.0.0.1:9945")
+	assert.Contains(err.Error(), "invalid gossip peer")
+}
+
+func TestLoadGossipKey(t *testing.T) {
+	assert := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "gossip.key")
+	key, err := LoadGossipKey(path)
+	assert.Nil(err)
+	info, err := os.Stat(path)
+	assert.Nil(err)
+	assert.Equal(os.FileMode(0600), info.Mode().Perm())
+
+	// The key is reused on restart
+	loaded, err := LoadGossipKey(path)
+	assert.Nil(err)
+	assert.Equal(ethcrypto.PubkeyToAddress(key.PublicKey), ethcrypto.PubkeyToAddress(loaded.PublicKey))
+}
@@ -1,14 +1,17 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"net/http"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff"
@@ -16,6 +19,7 @@ import (
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	"github.com/golang/glog"
+	"github.com/livepeer/go-livepeer/clog"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/eth"
@@ -190,6 +194,64 @@ func setBroadcastConfigHandler() http.Handler {
 	})
 }
 
+// reserveCapacityHandler reserves sessions on an orchestrator for a time window given in unix seconds
+func (s *LivepeerServer) reserveCapacityHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orchURL, err := url.ParseRequestURI(r.FormValue("orchestrator"))
+		if err != nil {
+			respond400(w, fmt.Sprintf("invalid orchestrator: %v", err))
+			return
+		}
+		sessions, err := strconv.Atoi(r.FormValue("sessions"))
+		if err != nil || sessions < 1 {
+			respond400(w, fmt.Sprintf("invalid sessions: %v", r.FormValue("sessions")))
+			return
+		}
+		start, err := strconv.ParseInt(r.FormValue("start"), 10, 64)
+		if err != nil {
+			respond400(w, fmt.Sprintf("invalid start: %v", err))
+			return
+		}
+		end, err := strconv.ParseInt(r.FormValue("end"), 10, 64)
+		if err != nil || end <= start {
+			respond400(w, fmt.Sprintf("invalid end: %v", r.FormValue("end")))
+			return
+		}
+
+		profiles := BroadcastJobVideoProfiles
+		if transcodingOptions := r.FormValue("transcodingOptions"); transcodingOptions != "" {
+			profiles = nil
+			for _, pName := range strings.Split(transcodingOptions, ",") {
+				if p, ok := ffmpeg.VideoProfileLookup[pName]; ok {
+					profiles = append(profiles, p)
+				}
+			}
+			if len(profiles) == 0 {
+				respond400(w, fmt.Sprintf("invalid transcoding options: %v", transcodingOptions))
+				return
+			}
+		}
+
+		var prepay *big.Rat
+		if prepayStr := r.FormValue("prepay"); prepayStr != "" {
+			var ok bool
+			if prepay, ok = new(big.Rat).SetString(prepayStr); !ok || prepay.Sign() < 0 {
+				respond400(w, fmt.Sprintf("invalid prepay: %v", prepayStr))
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), GRPCTimeout)
+		defer cancel()
+		res, err := ReserveCapacity(ctx, s.LivepeerNode, orchURL, sessions, profiles, time.Unix(start, 0), time.Unix(end, 0), prepay)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondJson(w, res)
+	})
+}
+
 func getBroadcastConfigHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var pNames []string
@@ -604,6 +666,48 @@ func (s *LivepeerServer) setMaxSessions() http.Handler {
 	})
 }
 
+func (s *LivepeerServer) registeredTranscodersHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.TranscoderManager == nil {
+			respond400(w, "node does not accept remote transcoders")
+			return
+		}
+		respondJson(w, s.LivepeerNode.TranscoderManager.RegisteredTranscodersInfo())
+	})
+}
+
+func (s *LivepeerServer) capacityReservationsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respondJson(w, s.LivepeerNode.CapacityReservations())
+	})
+}
+
+func (s *LivepeerServer) setTranscoderDrainingHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.LivepeerNode.TranscoderManager == nil {
+			respond400(w, "node does not accept remote transcoders")
+			return
+		}
+		transcoder := r.FormValue("transcoder")
+		draining, err := strconv.ParseBool(r.FormValue("draining"))
+		if err != nil {
+			respond400(w, fmt.Sprintf("invalid draining: %v", err))
+			return
+		}
+
+		err = s.LivepeerNode.TranscoderManager.SetTranscoderDraining(transcoder, draining)
+		if err == core.ErrTranscoderNotFound {
+			respond400(w, fmt.Sprintf("transcoder %v not found", transcoder))
+			return
+		}
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondOk(w, []byte(fmt.Sprintf("Transcoder %v draining set to %v\n", transcoder, draining)))
+	})
+}
+
 // Bond, withdraw, reward
 func bondHandler(client eth.LivepeerEthClient) http.Handler {
 	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -863,6 +967,143 @@ func delegatorInfoHandler(client eth.LivepeerEthClient) http.Handler {
 	}))
 }
 
+// Number of rounds covered by default by the delegator earnings history and the orchestrator yield projections
+const defaultEarningsRounds = 10
+
+func delegatorEarningsHandler(client eth.LivepeerEthClient) http.Handler {
+	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		delegator := client.Account().Address
+		if addr := r.URL.Query().Get("delegator"); addr != "" {
+			if !ethcommon.IsHexAddress(addr) {
+				respond400(w, "invalid delegator address")
+				return
+			}
+			delegator = ethcommon.HexToAddress(addr)
+		}
+
+		var endRound *big.Int
+		if endRoundStr := r.URL.Query().Get("endRound"); endRoundStr != "" {
+			var err error
+			if endRound, err = common.ParseBigInt(endRoundStr); err != nil {
+				respond400(w, err.Error())
+				return
+			}
+		} else {
+			currentRound, err := client.CurrentRound()
+			if err != nil {
+				respond500(w, err.Error())
+				return
+			}
+			endRound = currentRound
+		}
+
+		startRound := new(big.Int).Sub(endRound, big.NewInt(defaultEarningsRounds-1))
+		if startRoundStr := r.URL.Query().Get("startRound"); startRoundStr != "" {
+			var err error
+			if startRound, err = common.ParseBigInt(startRoundStr); err != nil {
+				respond400(w, err.Error())
+				return
+			}
+		}
+
+		history, err := eth.DelegatorEarnings(client, delegator, startRound, endRound)
+		if err == eth.ErrInvalidRoundRange {
+			respond400(w, fmt.Sprintf("%v, at most %v rounds can be requested", err, eth.MaxEarningsRounds))
+			return
+		}
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+
+		respondJson(w, history)
+	}))
+}
+
+// orchestratorYieldsCache holds the yield projections for the current round. The projections are derived from
+// completed rounds, so they are computed at most once per round for each number of sampled rounds
+type orchestratorYieldsCache struct {
+	mu     sync.Mutex
+	round  *big.Int
+	yields map[int][]*eth.OrchestratorYield
+}
+
+func (c *orchestratorYieldsCache) get(round *big.Int, rounds int, project func() ([]*eth.OrchestratorYield, error)) ([]*eth.OrchestratorYield, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.round == nil || c.round.Cmp(round) != 0 {
+		c.round = round
+		c.yields = make(map[int][]*eth.OrchestratorYield)
+	}
+	if yields, ok := c.yields[rounds]; ok {
+		return yields, nil
+	}
+
+	yields, err := project()
+	if err != nil {
+		return nil, err
+	}
+	c.yields[rounds] = yields
+	return yields, nil
+}
+
+func orchestratorYieldsHandler(client eth.LivepeerEthClient) http.Handler {
+	cache := &orchestratorYieldsCache{}
+	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rounds := defaultEarningsRounds
+		if roundsStr := r.URL.Query().Get("rounds"); roundsStr != "" {
+			var err error
+			if rounds, err = strconv.Atoi(roundsStr); err != nil || rounds <= 0 || rounds > eth.MaxEarningsRounds {
+				respond400(w, fmt.Sprintf("rounds must be between 1 and %v", eth.MaxEarningsRounds))
+				return
+			}
+		}
+
+		currentRound, err := client.CurrentRound()
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+
+		yields, err := cache.get(currentRound, rounds, func() ([]*eth.OrchestratorYield, error) {
+			orchestrators, err := client.TranscoderPool()
+			if err != nil {
+				return nil, err
+			}
+			if len(orchestrators) == 0 {
+				return []*eth.OrchestratorYield{}, nil
+			}
+
+			params, err := eth.FetchYieldParams(client)
+			if err != nil {
+				return nil, err
+			}
+
+			yields := make([]*eth.OrchestratorYield, 0, len(orchestrators))
+			for _, o := range orchestrators {
+				y, err := eth.ProjectOrchestratorYield(client, params, o, currentRound, rounds)
+				if err != nil {
+					glog.Errorf("Unable to project yield for orchestrator=%v err=%q", o.Address.Hex(), err)
+					continue
+				}
+				yields = append(yields, y)
+			}
+
+			sort.SliceStable(yields, func(i, j int) bool {
+				return yields[i].RewardYield > yields[j].RewardYield
+			})
+			return yields, nil
+		})
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+
+		respondJson(w, yields)
+	}))
+}
+
 func orchestratorEarningPoolsForRoundHandler(client eth.LivepeerEthClient) http.Handler {
 	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		roundStr := r.URL.Query().Get("round")
@@ -925,6 +1166,45 @@ func rewardHandler(client eth.LivepeerEthClient) http.Handler {
 	}))
 }
 
+func (s *LivepeerServer) rewardEventsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs := s.LivepeerNode.RewardService
+		if rs == nil {
+			respond500(w, "reward service is not running")
+			return
+		}
+
+		events := make(chan *eth.RewardEvent, 10)
+		sub := rs.SubscribeRewardEvents(events)
+		defer sub.Unsubscribe()
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		flusher, _ := w.(http.Flusher)
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		enc := json.NewEncoder(w)
+		for {
+			select {
+			case ev := <-events:
+				if err := enc.Encode(ev); err != nil {
+					glog.Errorf("Error writing reward event err=%q", err)
+					return
+				}
+				if flusher != nil {
+					flusher.Flush()
+				}
+			case <-sub.Err():
+				return
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
+
 // Protocol parameters
 func protocolParametersHandler(client eth.LivepeerEthClient, db ChainIdGetter) http.Handler {
 	return mustHaveDb(db, mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1198,6 +1478,139 @@ func voteHandler(client eth.LivepeerEthClient) http.Handler {
 	}))
 }
 
+// pollsHandler returns the polls discovered by the poll watcher with the tallies stored for the ended polls and tallies
+// weighted by the current stake of the voters for the other polls
+func pollsHandler(client eth.LivepeerEthClient, db *common.DB) http.Handler {
+	return mustHaveDb(db, mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		polls, err := db.Polls()
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+
+		addr := client.Account().Address
+		res := []*eth.PollInfo{}
+		for _, p := range polls {
+			votes, err := db.PollVotes(p.Address)
+			if err != nil {
+				respond500(w, err.Error())
+				return
+			}
+
+			// Ended polls are tallied by the poll watcher once the L1 block passes their end block.
+			// The other polls are still active and their tally with the current stake of the voters is an estimate
+			final, err := db.PollTally(p.Address)
+			if err != nil {
+				respond500(w, err.Error())
+				return
+			}
+
+			var (
+				tally    *eth.PollTally
+				status   string
+				estimate bool
+			)
+			if final != nil {
+				tally = &eth.PollTally{Yes: final.Yes, No: final.No, Voters: int(final.Voters)}
+				status = eth.PollStatus(p, tally, final.TotalBonded, big.NewInt(final.L1Block))
+				estimate = final.Estimate
+			} else {
+				tally, err = eth.TallyPoll(client, votes, nil)
+				if err != nil {
+					respond500(w, err.Error())
+					return
+				}
+				status = eth.PollActive
+				estimate = true
+			}
+
+			info := &eth.PollInfo{
+				Address:        p.Address,
+				Proposal:       p.Proposal,
+				EndBlock:       p.EndBlock,
+				Quorum:         p.Quorum,
+				Quota:          p.Quota,
+				Status:         status,
+				Yes:            tally.Yes,
+				No:             tally.No,
+				TotalVoteStake: tally.Total(),
+				Voters:         tally.Voters,
+				Estimate:       estimate,
+			}
+			for _, v := range votes {
+				if v.Voter == addr {
+					choice := v.ChoiceID
+					info.Vote = &choice
+				}
+			}
+			res = append(res, info)
+		}
+
+		respondJson(w, res)
+	})))
+}
+
+// Verification evidence
+func verificationEvidenceHandler(db *common.DB) http.Handler {
+	return mustHaveDb(db, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		filter := &common.DBEvidenceFilter{}
+		if orch := r.FormValue("orchestrator"); orch != "" {
+			if !ethcommon.IsHexAddress(orch) {
+				respond400(w, fmt.Sprintf("invalid orchestrator address: %v", orch))
+				return
+			}
+			addr := ethcommon.HexToAddress(orch)
+			filter.Orchestrator = &addr
+		}
+		if limit := r.FormValue("limit"); limit != "" {
+			l, err := strconv.Atoi(limit)
+			if err != nil || l < 0 {
+				respond400(w, fmt.Sprintf("invalid limit: %v", limit))
+				return
+			}
+			filter.Limit = l
+		}
+
+		records, err := db.SelectEvidence(filter)
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		// Records are exported as stored, so they can be verified independently of this node
+		res := make([]json.RawMessage, 0, len(records))
+		for _, e := range records {
+			res = append(res, e.Data)
+		}
+		respondJson(w, res)
+	}))
+}
+
+func orchestratorPenaltiesHandler(db *common.DB) http.Handler {
+	return mustHaveDb(db, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		penalties, err := db.OrchPenalties()
+		if err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondJson(w, penalties)
+	}))
+}
+
+func clearOrchestratorPenaltyHandler(db *common.DB) http.Handler {
+	return mustHaveDb(db, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orch := r.FormValue("orchestrator")
+		if !ethcommon.IsHexAddress(orch) {
+			respond400(w, fmt.Sprintf("invalid orchestrator address: %v", orch))
+			return
+		}
+		if err := db.DeleteOrchPenalty(ethcommon.HexToAddress(orch)); err != nil {
+			respond500(w, err.Error())
+			return
+		}
+		respondOk(w, []byte(fmt.Sprintf("Penalty of orchestrator %v cleared\n", orch)))
+	}))
+}
+
 // Gas Price
 func setMaxGasPriceHandler(client eth.LivepeerEthClient) http.Handler {
 	return mustHaveClient(client, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1407,11 +1820,23 @@ func setLogLevelHandler() http.Handler {
 			respond500(w, "nil log level")
 			return
 		}
-		err := vFlag.Set(r.FormValue("loglevel"))
-		if err != nil {
-			respond400(w, "parameter 'logLevel' not defined")
+		logLevel, logFormat := r.FormValue("loglevel"), r.FormValue("logformat")
+		if logLevel == "" && logFormat == "" {
+			respond400(w, "need to set 'loglevel' or 'logformat'")
 			return
 		}
+		if logLevel != "" {
+			if err := vFlag.Set(logLevel); err != nil {
+				respond400(w, "parameter 'logLevel' not defined")
+				return
+			}
+		}
+		if logFormat != "" {
+			if err := clog.SetFormat(logFormat); err != nil {
+				respond400(w, err.Error())
+				return
+			}
+		}
 		respondOk(w, nil)
 	})
 }
@@ -12,9 +12,12 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts"
 	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/eth"
 	"github.com/livepeer/go-livepeer/eth/types"
@@ -22,6 +25,7 @@ import (
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Status
@@ -173,6 +177,33 @@ func TestSetMaxFaceValueHandler_WrongValueSet(t *testing.T) {
 	assert.Equal("maxfacevalue not set to number", body)
 }
 
+func TestSetLogLevelHandler(t *testing.T) {
+	assert := assert.New(t)
+	oldLevel := vFlag.String()
+	defer func() {
+		vFlag.Set(oldLevel)
+		clog.SetFormat(clog.TextFormat)
+	}()
+
+	handler := setLogLevelHandler()
+	status, _ := postForm(handler, url.Values{"loglevel": {"6"}})
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("6", vFlag.String())
+
+	status, _ = postForm(handler, url.Values{"logformat": {"json"}})
+	assert.Equal(http.StatusOK, status)
+	assert.Equal(clog.JSONFormat, clog.GetFormat())
+	assert.Equal("6", vFlag.String())
+
+	status, body := postForm(handler, url.Values{"logformat": {"xml"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal(`invalid log format "xml", must be "text" or "json"`, body)
+
+	status, body = postForm(handler, url.Values{})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("need to set 'loglevel' or 'logformat'", body)
+}
+
 // Broadcast / Transcoding config
 func TestSetBroadcastConfigHandler_MissingPricePerUnitError(t *testing.T) {
 	assert := assert.New(t)
@@ -614,6 +645,57 @@ func TestSetPriceForBroadcasterHandler_WrongInput(t *testing.T) {
 	assert.Equal(http.StatusBadRequest, status3)
 }
 
+func TestRegisteredTranscodersHandler(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+
+	status, body := get(s.registeredTranscodersHandler())
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("node does not accept remote transcoders", body)
+
+	s.LivepeerNode.TranscoderManager = core.NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { s.LivepeerNode.TranscoderManager.Manage(strm, "", false, 5, nil) }()
+	time.Sleep(1 * time.Millisecond)
+
+	status, body = get(s.registeredTranscodersHandler())
+	assert.Equal(http.StatusOK, status)
+	var transcoders []common.RemoteTranscoderInfo
+	require.Nil(t, json.Unmarshal([]byte(body), &transcoders))
+	require.Len(t, transcoders, 1)
+	assert.Equal("TestAddress", transcoders[0].Address)
+	assert.Equal(5, transcoders[0].Capacity)
+	assert.False(transcoders[0].Draining)
+}
+
+func TestSetTranscoderDrainingHandler(t *testing.T) {
+	assert := assert.New(t)
+	s := stubServer()
+	handler := s.setTranscoderDrainingHandler()
+
+	status, body := postForm(handler, url.Values{"transcoder": {"TestAddress"}, "draining": {"true"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("node does not accept remote transcoders", body)
+
+	s.LivepeerNode.TranscoderManager = core.NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { s.LivepeerNode.TranscoderManager.Manage(strm, "", false, 5, nil) }()
+	time.Sleep(1 * time.Millisecond)
+
+	status, body = postForm(handler, url.Values{"transcoder": {"TestAddress"}, "draining": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Contains(body, "invalid draining")
+
+	status, body = postForm(handler, url.Values{"transcoder": {"foo"}, "draining": {"true"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("transcoder foo not found", body)
+
+	status, body = postForm(handler, url.Values{"transcoder": {"TestAddress"}, "draining": {"true"}})
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("Transcoder TestAddress draining set to true", body)
+	assert.True(s.LivepeerNode.TranscoderManager.RegisteredTranscodersInfo()[0].Draining)
+}
+
 // Bond, withdraw, reward
 func TestBondHandler(t *testing.T) {
 	assert := assert.New(t)
@@ -863,6 +945,70 @@ func TestDelegatorInfoHandler(t *testing.T) {
 	assert.Contains(body, `"StartRound":4`)
 }
 
+func TestDelegatorEarningsHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &eth.MockClient{}
+	handler := delegatorEarningsHandler(client)
+	addr := ethcommon.Address{}
+	client.On("Account").Return(accounts.Account{Address: addr})
+	client.On("CurrentRound").Return(big.NewInt(20), nil)
+	delegator := &types.Delegator{
+		BondedAmount:   big.NewInt(0),
+		LastClaimRound: big.NewInt(5),
+	}
+	client.On("GetDelegator", addr).Return(delegator, nil)
+
+	// Defaults to the last rounds up to the current round
+	status, body := get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, `"StartRound":11`)
+	assert.Contains(body, `"EndRound":20`)
+
+	status, body = getWithQuery(handler, url.Values{"startRound": {"8"}, "endRound": {"12"}})
+	assert.Equal(http.StatusOK, status)
+	assert.Contains(body, `"StartRound":8`)
+	assert.Contains(body, `"EndRound":12`)
+
+	status, body = getWithQuery(handler, url.Values{"delegator": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid delegator address", body)
+
+	status, _ = getWithQuery(handler, url.Values{"startRound": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+
+	status, body = getWithQuery(handler, url.Values{"startRound": {"12"}, "endRound": {"8"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Contains(body, "invalid round range")
+}
+
+func TestOrchestratorYieldsHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &eth.MockClient{}
+	handler := orchestratorYieldsHandler(client)
+	client.On("CurrentRound").Return(big.NewInt(20), nil)
+	client.On("TranscoderPool").Return([]*types.Transcoder{}, nil)
+
+	status, body := get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+
+	// Projections are cached for the round
+	status, _ = get(handler)
+	assert.Equal(http.StatusOK, status)
+	client.AssertNumberOfCalls(t, "TranscoderPool", 1)
+	status, _ = getWithQuery(handler, url.Values{"rounds": {"5"}})
+	assert.Equal(http.StatusOK, status)
+	client.AssertNumberOfCalls(t, "TranscoderPool", 2)
+
+	status, _ = getWithQuery(handler, url.Values{"rounds": {"0"}})
+	assert.Equal(http.StatusBadRequest, status)
+
+	status, _ = getWithQuery(handler, url.Values{"rounds": {"1000"}})
+	assert.Equal(http.StatusBadRequest, status)
+}
+
 func TestRewardHandler(t *testing.T) {
 	assert := assert.New(t)
 
@@ -876,6 +1022,18 @@ func TestRewardHandler(t *testing.T) {
 	assert.Equal(http.StatusOK, status)
 }
 
+func TestRewardEventsHandler_NoRewardService(t *testing.T) {
+	assert := assert.New(t)
+
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	s := &LivepeerServer{LivepeerNode: n}
+
+	status, body := get(s.rewardEventsHandler())
+
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("reward service is not running", body)
+}
+
 // Eth
 func TestTransferTokensHandler(t *testing.T) {
 	assert := assert.New(t)
@@ -1010,6 +1168,151 @@ func TestSignMessageHandler(t *testing.T) {
 	assert.Equal("foo", body)
 }
 
+func TestPollsHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	addr := pm.RandAddress()
+	orch := pm.RandAddress()
+	client := &eth.MockClient{}
+	client.On("Account").Return(accounts.Account{Address: addr})
+	client.On("VotingStake", addr, (*big.Int)(nil)).Return(orch, big.NewInt(100), nil)
+	client.On("VotingStake", orch, (*big.Int)(nil)).Return(orch, big.NewInt(500), nil)
+	handler := pollsHandler(client, dbh)
+
+	// No polls
+	status, body := get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+
+	active := &common.DBPoll{Address: pm.RandAddress(), Proposal: "QmActive", EndBlock: 200, Quorum: 333300, Quota: 500000}
+	ended := &common.DBPoll{Address: pm.RandAddress(), Proposal: "QmEnded", EndBlock: 100, Quorum: 333300, Quota: 500000}
+	require.Nil(dbh.UpdatePoll(active))
+	require.Nil(dbh.UpdatePoll(ended))
+	require.Nil(dbh.UpdatePollVote(&common.DBPollVote{Poll: active.Address, Voter: addr, ChoiceID: 1, Block: 120}))
+	require.Nil(dbh.UpdatePollVote(&common.DBPollVote{Poll: active.Address, Voter: orch, ChoiceID: 0, Block: 121}))
+	require.Nil(dbh.UpdatePollVote(&common.DBPollVote{Poll: ended.Address, Voter: orch, ChoiceID: 0, Block: 90}))
+	// The final tally is used for ended polls instead of the current stake
+	require.Nil(dbh.UpdatePollTally(&common.DBPollTally{Poll: ended.Address, Yes: big.NewInt(700), No: big.NewInt(0), Voters: 1, TotalBonded: big.NewInt(1000), L1Block: 101}))
+
+	status, body = get(handler)
+	require.Equal(http.StatusOK, status)
+
+	var polls []*eth.PollInfo
+	require.Nil(json.Unmarshal([]byte(body), &polls))
+	require.Len(polls, 2)
+
+	assert.Equal(active.Address, polls[0].Address)
+	assert.Equal("QmActive", polls[0].Proposal)
+	assert.Equal(eth.PollActive, polls[0].Status)
+	assert.Equal(big.NewInt(400), polls[0].Yes)
+	assert.Equal(big.NewInt(100), polls[0].No)
+	assert.Equal(big.NewInt(500), polls[0].TotalVoteStake)
+	assert.Equal(2, polls[0].Voters)
+	assert.True(polls[0].Estimate)
+	require.NotNil(polls[0].Vote)
+	assert.Equal(int64(1), *polls[0].Vote)
+
+	assert.Equal(ended.Address, polls[1].Address)
+	assert.Equal(eth.PollPassed, polls[1].Status)
+	assert.Equal(big.NewInt(700), polls[1].Yes)
+	assert.Equal(1, polls[1].Voters)
+	assert.False(polls[1].Estimate)
+	assert.Nil(polls[1].Vote)
+	client.AssertNotCalled(t, "GetTotalBonded")
+
+	// The tally of an ended poll taken without the historical state is an estimate
+	require.Nil(dbh.UpdatePollTally(&common.DBPollTally{Poll: ended.Address, Yes: big.NewInt(700), No: big.NewInt(0), Voters: 1, TotalBonded: big.NewInt(1000), L1Block: 101, Estimate: true}))
+	status, body = get(handler)
+	require.Equal(http.StatusOK, status)
+	require.Nil(json.Unmarshal([]byte(body), &polls))
+	require.Len(polls, 2)
+	assert.Equal(eth.PollPassed, polls[1].Status)
+	assert.True(polls[1].Estimate)
+
+	// Missing client
+	status, body = get(pollsHandler(nil, dbh))
+	assert.Equal(http.StatusInternalServerError, status)
+	assert.Equal("missing ETH client", body)
+}
+
+func TestVerificationEvidenceHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+	handler := verificationEvidenceHandler(dbh)
+
+	// No evidence
+	status, body := get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+
+	o0, o1 := pm.RandAddress(), pm.RandAddress()
+	require.Nil(dbh.InsertEvidence(&common.DBEvidence{Orchestrator: o0, ManifestID: "foo", SeqNo: 1, Reason: "AudioMismatch", Fatal: true, Data: []byte(`{"seqNo":1}`)}))
+	require.Nil(dbh.InsertEvidence(&common.DBEvidence{Orchestrator: o1, ManifestID: "foo", SeqNo: 2, Reason: "Tampered", Data: []byte(`{"seqNo":2}`)}))
+	require.Nil(dbh.InsertEvidence(&common.DBEvidence{Orchestrator: o0, ManifestID: "foo", SeqNo: 3, Reason: "Tampered", Data: []byte(`{"seqNo":3}`)}))
+
+	status, body = get(handler)
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[{"seqNo":3},{"seqNo":2},{"seqNo":1}]`, body)
+
+	status, body = getWithQuery(handler, url.Values{"orchestrator": {o0.Hex()}, "limit": {"1"}})
+	assert.Equal(http.StatusOK, status)
+	assert.JSONEq(`[{"seqNo":3}]`, body)
+
+	status, body = getWithQuery(handler, url.Values{"orchestrator": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid orchestrator address: foo", body)
+
+	status, body = getWithQuery(handler, url.Values{"limit": {"-1"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid limit: -1", body)
+}
+
+func TestOrchestratorPenaltiesHandler(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	status, body := get(orchestratorPenaltiesHandler(dbh))
+	assert.Equal(http.StatusOK, status)
+	assert.Equal("[]", body)
+
+	orch := pm.RandAddress()
+	require.Nil(dbh.PenalizeOrch(orch, time.Hour))
+	status, body = get(orchestratorPenaltiesHandler(dbh))
+	assert.Equal(http.StatusOK, status)
+	var penalties []*common.DBOrchPenalty
+	require.Nil(json.Unmarshal([]byte(body), &penalties))
+	require.Len(penalties, 1)
+	assert.Equal(orch, penalties[0].Orchestrator)
+	assert.Equal(int64(1), penalties[0].Failures)
+
+	handler := clearOrchestratorPenaltyHandler(dbh)
+	status, body = postForm(handler, url.Values{"orchestrator": {"foo"}})
+	assert.Equal(http.StatusBadRequest, status)
+	assert.Equal("invalid orchestrator address: foo", body)
+
+	status, _ = postForm(handler, url.Values{"orchestrator": {orch.Hex()}})
+	assert.Equal(http.StatusOK, status)
+	penaltiesLeft, err := dbh.OrchPenalties()
+	require.Nil(err)
+	assert.Len(penaltiesLeft, 0)
+}
+
 func TestVoteHandler(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1623,6 +1926,16 @@ func get(handler http.Handler) (int, string) {
 	return resp.StatusCode, trim(body)
 }
 
+func getWithQuery(handler http.Handler, query url.Values) (int, string) {
+	req := httptest.NewRequest("GET", "http://example.com?"+query.Encode(), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	resp := w.Result()
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, trim(body)
+}
+
 func httpGetResp(handler http.Handler) *http.Response {
 	return httpResp(handler, "GET", nil, nil)
 }
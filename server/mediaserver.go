@@ -62,6 +62,9 @@ const StreamKeyBytes = 6
 const SegLen = 2 * time.Second
 const BroadcastRetry = 15 * time.Second
 
+// Number of most recent reward calls reported in the node status
+const rewardCallsStatusLimit = 10
+
 var BroadcastJobVideoProfiles = []ffmpeg.VideoProfile{ffmpeg.P240p30fps4x3, ffmpeg.P360p30fps16x9}
 
 var AuthWebhookURL *url.URL
@@ -137,11 +140,14 @@ type authWebhookResponse struct {
 	RecordObjectStoreURL string   `json:"recordObjectStoreUrl"`
 	// Same json structure is used in lpms to decode profile from
 	// files, while here we decode from HTTP
-	Profiles           []ffmpeg.JsonProfile `json:"profiles"`
-	PreviousSessions   []string             `json:"previousSessions"`
-	VerificationFreq   uint                 `json:"verificationFreq"`
-	TimeoutMultiplier  int                  `json:"timeoutMultiplier"`
-	ForceSessionReinit bool                 `json:"forceSessionReinit"`
+	Profiles               []ffmpeg.JsonProfile `json:"profiles"`
+	PreviousSessions       []string             `json:"previousSessions"`
+	VerificationFreq       uint                 `json:"verificationFreq"` // Used when no sample rate is set
+	VerificationSampleRate float64              `json:"verificationSampleRate"`
+	VerificationRedundancy int                  `json:"verificationRedundancy"`
+	TimeoutMultiplier      int                  `json:"timeoutMultiplier"`
+	ForceSessionReinit     bool                 `json:"forceSessionReinit"`
+	Priority               int32                `json:"priority"`
 }
 
 func NewLivepeerServer(rtmpAddr string, lpNode *core.LivepeerNode, httpIngest bool, transcodingOptions string) (*LivepeerServer, error) {
@@ -253,7 +259,10 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 		var os, ros drivers.OSDriver
 		var oss, ross drivers.OSSession
 		profiles := []ffmpeg.VideoProfile{}
-		var VerificationFreq uint
+		var verificationFreq uint
+		var sampleRate float64
+		var redundancy int
+		var priority int32
 		nonce := rand.Uint64()
 
 		// do not replace captured _ctx variable
@@ -320,7 +329,16 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 				}
 			}
 
-			VerificationFreq = resp.VerificationFreq
+			sampleRate, redundancy = resp.VerificationSampleRate, resp.VerificationRedundancy
+			if sampleRate == 0 {
+				verificationFreq = resp.VerificationFreq
+			}
+			if sampleRate < 0 || sampleRate > 1 || redundancy < 0 {
+				errMsg := fmt.Sprintf("Invalid verification sample rate or redundancy for streamID url=%s sampleRate=%v redundancy=%d", url.String(), sampleRate, redundancy)
+				clog.Errorf(ctx, errMsg)
+				return nil, fmt.Errorf(errMsg)
+			}
+			priority = resp.Priority
 		} else {
 			profiles = BroadcastJobVideoProfiles
 		}
@@ -363,11 +381,15 @@ func createRTMPStreamIDHandler(_ctx context.Context, s *LivepeerServer, webhookR
 			SessionID:        sessionID,
 			RtmpKey:          key,
 			// HTTP push mutates `profiles` so make a copy of it
-			Profiles:         append([]ffmpeg.VideoProfile(nil), profiles...),
-			OS:               oss,
-			RecordOS:         ross,
-			VerificationFreq: VerificationFreq,
-			Nonce:            nonce,
+			Profiles:               append([]ffmpeg.VideoProfile(nil), profiles...),
+			OS:                     oss,
+			RecordOS:               ross,
+			VerificationFreq:       verificationFreq,
+			VerificationSampleRate: sampleRate,
+			VerificationRedundancy: redundancy,
+			VerifySignatures:       Policy.NeedsSignatures(),
+			Nonce:                  nonce,
+			Priority:               priority,
 		}, nil
 	}
 }
@@ -559,7 +581,7 @@ func (s *LivepeerServer) registerConnection(ctx context.Context, rtmpStrm stream
 func countStreamsWithFastVerificationEnabled(rtmpConnections map[core.ManifestID]*rtmpConnection) (int, int) {
 	var enabled, using int
 	for _, cxn := range rtmpConnections {
-		if cxn.params.VerificationFreq > 0 {
+		if cxn.params.VerificationFreq > 0 || cxn.params.VerificationRedundancy > 1 || cxn.params.VerifySignatures {
 			enabled++
 			if cxn.sessManager != nil && cxn.sessManager.usingVerified() {
 				using++
@@ -946,6 +968,8 @@ func (s *LivepeerServer) HandlePush(w http.ResponseWriter, r *http.Request) {
 		seq = 0
 	}
 	ctx = clog.AddSeqNo(ctx, seq)
+	ctx, span := monitor.StartSpan(monitor.ExtractTraceHeaders(ctx, r.Header), "ingest")
+	defer span.End()
 
 	duration, err := strconv.Atoi(r.Header.Get("Content-Duration"))
 	if err != nil {
@@ -1620,6 +1644,12 @@ func (s *LivepeerServer) GetNodeStatus() *common.NodeStatus {
 
 	res.BroadcasterPrices = s.LivepeerNode.GetBasePrices()
 
+	if calls, err := s.LivepeerNode.Database.RewardCalls(rewardCallsStatusLimit); err != nil {
+		glog.Errorf("Error fetching reward calls err=%q", err)
+	} else {
+		res.RewardCalls = calls
+	}
+
 	return res
 }
 
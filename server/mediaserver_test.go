@@ -542,6 +542,50 @@ func TestCreateRTMPStreamHandlerWebhook(t *testing.T) {
 	assert.Equal(core.ManifestID("xyz"), mid, "Should set manifest to one provided by webhook")
 	assert.Equal("xyz/zyx", params.StreamID(), "Should set streamkey to one provided by webhook")
 	assert.Equal("zyx", params.RtmpKey, "Should set rtmp key to one provided by webhook")
+	assert.Equal(core.PriorityNormal, params.Priority, "Should default to normal priority")
+
+	// set priority
+	tsPriority := makeServer(`{"manifestID":"xyz", "priority":1}`)
+	defer tsPriority.Close()
+	id, err = createSid(u)
+	require.NoError(t, err)
+	params = id.(*core.StreamParameters)
+	assert.Equal(core.PriorityHigh, params.Priority, "Should set priority to one provided by webhook")
+
+	// set verification sample rate and redundancy
+	tsVerification := makeServer(`{"manifestID":"xyz", "verificationSampleRate":0.25, "verificationRedundancy":2}`)
+	defer tsVerification.Close()
+	id, err = createSid(u)
+	require.NoError(t, err)
+	params = id.(*core.StreamParameters)
+	assert.Equal(0.25, params.VerificationSampleRate)
+	assert.Equal(2, params.VerificationRedundancy)
+
+	assert.Zero(params.VerificationFreq)
+
+	// verificationFreq is used without a sample rate
+	tsVerificationFreq := makeServer(`{"manifestID":"xyz", "verificationFreq":4}`)
+	defer tsVerificationFreq.Close()
+	id, err = createSid(u)
+	require.NoError(t, err)
+	params = id.(*core.StreamParameters)
+	assert.Equal(uint(4), params.VerificationFreq)
+	assert.Zero(params.VerificationSampleRate)
+	assert.Zero(params.VerificationRedundancy)
+
+	tsVerificationBoth := makeServer(`{"manifestID":"xyz", "verificationFreq":4, "verificationSampleRate":0.5}`)
+	defer tsVerificationBoth.Close()
+	id, err = createSid(u)
+	require.NoError(t, err)
+	params = id.(*core.StreamParameters)
+	assert.Zero(params.VerificationFreq)
+	assert.Equal(0.5, params.VerificationSampleRate)
+
+	tsVerificationInvalid := makeServer(`{"manifestID":"xyz", "verificationSampleRate":2}`)
+	defer tsVerificationInvalid.Close()
+	id, err = createSid(u)
+	assert.Nil(id)
+	assert.ErrorContains(err, "Invalid verification sample rate or redundancy")
 
 	// set presets (with some invalid)
 	ts6 := makeServer(`{"manifestID":"a", "presets":["P240p30fps16x9", "unknown", "P720p30fps16x9"]}`)
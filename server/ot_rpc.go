@@ -22,6 +22,8 @@ import (
 
 	"github.com/cenkalti/backoff"
 	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/net/http2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -43,17 +45,27 @@ var errZeroCapacity = errors.New("zero capacity")
 var errInterrupted = errors.New("execution interrupted")
 var errCapabilities = errors.New("incompatible segment capabilities")
 
+var drainCheckInterval = time.Second
+
+// How long the orchestrator holds a lease request of a pull mode transcoder when no segment is queued
+var transcoderJobPollTimeout = 30 * time.Second
+
+// How long a pull mode transcoder waits before leasing again after a failed lease request
+var transcoderJobRetryInterval = time.Second
+
 // Standalone Transcoder
 
 // RunTranscoder is main routing of standalone transcoder
 // Exiting it will terminate executable
-func RunTranscoder(n *core.LivepeerNode, orchAddr string, capacity int, caps []core.Capability) {
+func RunTranscoder(n *core.LivepeerNode, orchAddr string, capacity int, pixelCapacity int64, caps []core.Capability, pull bool, drainTimeout time.Duration) {
+	// The ID identifies the transcoder to the orchestrator in drain requests and when it reconnects
+	id := common.RandName()
 	expb := backoff.NewExponentialBackOff()
 	expb.MaxInterval = time.Minute
 	expb.MaxElapsedTime = 0
 	backoff.Retry(func() error {
 		glog.Info("Registering transcoder to ", orchAddr)
-		err := runTranscoder(n, orchAddr, capacity, caps)
+		err := runTranscoder(n, orchAddr, capacity, pixelCapacity, caps, id, pull, drainTimeout)
 		glog.Info("Unregistering transcoder: ", err)
 		if _, fatal := err.(core.RemoteTranscoderFatalError); fatal {
 			glog.Info("Terminating transcoder because of ", err)
@@ -81,7 +93,7 @@ func checkTranscoderError(err error) error {
 	return err
 }
 
-func runTranscoder(n *core.LivepeerNode, orchAddr string, capacity int, caps []core.Capability) error {
+func runTranscoder(n *core.LivepeerNode, orchAddr string, capacity int, pixelCapacity int64, caps []core.Capability, id string, pull bool, drainTimeout time.Duration) error {
 	tlsConfig := &tls.Config{InsecureSkipVerify: true}
 	conn, err := grpc.Dial(orchAddr,
 		grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
@@ -96,49 +108,224 @@ func runTranscoder(n *core.LivepeerNode, orchAddr string, capacity int, caps []c
 	ctx, cancel := context.WithCancel(ctx)
 	// Silence linter
 	defer cancel()
-	r, err := c.RegisterTranscoder(ctx, &net.RegisterRequest{Secret: n.OrchSecret, Capacity: int64(capacity),
+	if pixelCapacity < 0 {
+		pixelCapacity = 0
+	}
+	r, err := c.RegisterTranscoder(ctx, &net.RegisterRequest{Secret: n.OrchSecret, Id: id, Pull: pull, Capacity: int64(capacity), PixelCapacity: pixelCapacity,
 		Capabilities: core.NewCapabilities(caps, []core.Capability{}).ToNetCapabilities()})
 	if err := checkTranscoderError(err); err != nil {
 		glog.Error("Could not register transcoder to orchestrator ", err)
 		return err
 	}
 
+	httpc := &http.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	work := newTranscoderWork()
+
 	// Catch interrupt signal to shut down transcoder
-	exitc := make(chan os.Signal)
+	exitc := make(chan os.Signal, 1)
 	signal.Notify(exitc, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(exitc)
 	go func() {
 		select {
 		case sig := <-exitc:
 			glog.Infof("Exiting Livepeer Transcoder: %v", sig)
+			if drainTimeout > 0 {
+				drainTranscoder(ctx, n, orchAddr, httpc, id, work, drainTimeout, exitc)
+			}
 			// Cancelling context will close connection to orchestrator
 			cancel()
 			return
+		case <-ctx.Done():
+			return
 		}
 	}()
 
-	httpc := &http.Client{Transport: &http2.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
 	var wg sync.WaitGroup
+	pollCtx, stopPolling := context.WithCancel(ctx)
+	defer stopPolling()
+	if pull {
+		// Segments are leased over HTTP and the stream only carries session teardowns
+		for i := 0; i < capacity; i++ {
+			wg.Add(1)
+			go func() {
+				pollTranscoderJobs(pollCtx, n, orchAddr, httpc, id, work)
+				wg.Done()
+			}()
+		}
+	}
+
 	for {
 		notify, err := r.Recv()
 		if err := checkTranscoderError(err); err != nil {
 			glog.Infof(`End of stream receive cycle because of err=%q, waiting for running transcode jobs to complete`, err)
+			stopPolling()
 			wg.Wait()
 			return err
 		}
 		if notify.SegData != nil && notify.SegData.AuthToken != nil && len(notify.SegData.AuthToken.SessionId) > 0 && len(notify.Url) == 0 {
 			// session teardown signal
 			n.Transcoder.EndTranscodingSession(notify.SegData.AuthToken.SessionId)
+			work.endSession(notify.SegData.AuthToken.SessionId)
 		} else {
 			wg.Add(1)
+			work.startTask(notify)
 			go func() {
 				runTranscode(n, orchAddr, httpc, notify)
+				work.endTask()
 				wg.Done()
 			}()
 		}
 	}
 }
 
+// transcoderWork tracks the sessions and the tasks running on a standalone transcoder
+type transcoderWork struct {
+	mu       sync.Mutex
+	sessions map[string]struct{}
+	tasks    int
+}
+
+func newTranscoderWork() *transcoderWork {
+	return &transcoderWork{sessions: make(map[string]struct{})}
+}
+
+func (tw *transcoderWork) startTask(notify *net.NotifySegment) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.tasks++
+	if notify.SegData != nil && notify.SegData.AuthToken != nil && notify.SegData.AuthToken.SessionId != "" {
+		tw.sessions[notify.SegData.AuthToken.SessionId] = struct{}{}
+	}
+}
+
+func (tw *transcoderWork) endTask() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.tasks--
+}
+
+func (tw *transcoderWork) endSession(sessionId string) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	delete(tw.sessions, sessionId)
+}
+
+func (tw *transcoderWork) idle() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.tasks == 0 && len(tw.sessions) == 0
+}
+
+// drainTranscoder asks the orchestrator to stop assigning new sessions to the transcoder and waits until its sessions
+// finish or are migrated to other transcoders, the timeout expires or another signal is received
+func drainTranscoder(ctx context.Context, n *core.LivepeerNode, orchAddr string, httpc *http.Client, id string,
+	work *transcoderWork, timeout time.Duration, exitc chan os.Signal,
+) {
+	if err := sendTranscoderDrain(n, orchAddr, httpc, id, true); err != nil {
+		glog.Errorf("Unable to drain transcoder id=%s err=%q", id, err)
+		return
+	}
+	glog.Infof("Draining transcoder id=%s timeout=%v", id, timeout)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(drainCheckInterval)
+	defer ticker.Stop()
+	for !work.idle() {
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			glog.Infof("Timed out draining transcoder id=%s", id)
+			return
+		case sig := <-exitc:
+			glog.Infof("Stopped draining transcoder id=%s: %v", id, sig)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+	glog.Infof("Drained transcoder id=%s", id)
+}
+
+func sendTranscoderDrain(n *core.LivepeerNode, orchAddr string, httpc *http.Client, id string, draining bool) error {
+	req, err := http.NewRequest("POST", "https://"+orchAddr+"/transcoderDrain", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", protoVerLPT)
+	req.Header.Set("Credentials", n.OrchSecret)
+	req.Header.Set("TranscoderId", id)
+	req.Header.Set("Draining", strconv.FormatBool(draining))
+
+	resp, err := httpc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("orchestrator returned HTTP statusCode=%v err=%q", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// pollTranscoderJobs leases segments from the orchestrator and transcodes them one at a time until ctx is done
+func pollTranscoderJobs(ctx context.Context, n *core.LivepeerNode, orchAddr string, httpc *http.Client, id string, work *transcoderWork) {
+	for {
+		notify, err := leaseTranscoderJob(ctx, n, orchAddr, httpc, id)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			glog.Errorf("Unable to lease segment from orch=%s err=%q", orchAddr, err)
+			select {
+			case <-time.After(transcoderJobRetryInterval):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if notify == nil {
+			// No segment was queued before the lease request timed out
+			continue
+		}
+		work.startTask(notify)
+		runTranscode(n, orchAddr, httpc, notify)
+		work.endTask()
+	}
+}
+
+func leaseTranscoderJob(ctx context.Context, n *core.LivepeerNode, orchAddr string, httpc *http.Client, id string) (*net.NotifySegment, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://"+orchAddr+"/transcoderJob", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", protoVerLPT)
+	req.Header.Set("Credentials", n.OrchSecret)
+	req.Header.Set("TranscoderId", id)
+
+	resp, err := httpc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("orchestrator returned HTTP statusCode=%v err=%q", resp.StatusCode, string(body))
+	}
+	var notify net.NotifySegment
+	if err := proto.Unmarshal(body, &notify); err != nil {
+		return nil, err
+	}
+	return &notify, nil
+}
+
 func runTranscode(n *core.LivepeerNode, orchAddr string, httpc *http.Client, notify *net.NotifySegment) {
 
 	glog.Infof("Transcoding taskId=%d url=%s", notify.TaskId, notify.Url)
@@ -161,12 +348,17 @@ func runTranscode(n *core.LivepeerNode, orchAddr string, httpc *http.Client, not
 	}
 	ctx = clog.AddSeqNo(ctx, uint64(md.Seq))
 	ctx = clog.AddVal(ctx, "taskId", strconv.FormatInt(notify.TaskId, 10))
+	ctx, span := monitor.StartSpan(monitor.WithTraceContext(ctx, notify.TraceContext), "runTranscode",
+		attribute.Int64("taskId", notify.TaskId))
+	defer span.End()
 	if n.Capabilities != nil && !md.Caps.CompatibleWith(n.Capabilities.ToNetCapabilities()) {
 		clog.Errorf(ctx, "Requested capabilities for segment are not compatible with this node taskId=%d url=%s err=%q", notify.TaskId, notify.Url, errCapabilities)
 		sendTranscodeResult(ctx, n, orchAddr, httpc, notify, contentType, &body, tData, errCapabilities)
 		return
 	}
-	data, err := core.GetSegmentData(ctx, notify.Url)
+	dlCtx, dlSpan := monitor.StartSpan(ctx, "GetSegmentData")
+	data, err := core.GetSegmentData(dlCtx, notify.Url)
+	monitor.EndSpan(dlSpan, err)
 	if err != nil {
 		clog.Errorf(ctx, "Transcoder cannot get segment from taskId=%d url=%s err=%q", notify.TaskId, notify.Url, err)
 		sendTranscodeResult(ctx, n, orchAddr, httpc, notify, contentType, &body, tData, err)
@@ -248,6 +440,8 @@ func runTranscode(n *core.LivepeerNode, orchAddr string, httpc *http.Client, not
 func sendTranscodeResult(ctx context.Context, n *core.LivepeerNode, orchAddr string, httpc *http.Client, notify *net.NotifySegment,
 	contentType string, body *bytes.Buffer, tData *core.TranscodeData, err error,
 ) {
+	ctx, span := monitor.StartSpan(ctx, "sendTranscodeResult")
+	defer func() { monitor.EndSpan(span, err) }()
 	if err != nil {
 		clog.Errorf(ctx, "Unable to transcode err=%q", err)
 		body.Write([]byte(err.Error()))
@@ -263,6 +457,7 @@ func sendTranscodeResult(ctx context.Context, n *core.LivepeerNode, orchAddr str
 	req.Header.Set("Credentials", n.OrchSecret)
 	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("TaskId", strconv.FormatInt(notify.TaskId, 10))
+	monitor.InjectTraceHeaders(ctx, req.Header)
 
 	pixels := int64(0)
 	if tData != nil {
@@ -296,7 +491,7 @@ func sendTranscodeResult(ctx context.Context, n *core.LivepeerNode, orchAddr str
 
 func (h *lphttp) RegisterTranscoder(req *net.RegisterRequest, stream net.Transcoder_RegisterTranscoderServer) error {
 	from := common.GetConnectionAddr(stream.Context())
-	glog.Infof("Got a RegisterTranscoder request from transcoder=%s capacity=%d", from, req.Capacity)
+	glog.Infof("Got a RegisterTranscoder request from transcoder=%s capacity=%d pixelCapacity=%d pull=%v", from, req.Capacity, req.PixelCapacity, req.Pull)
 
 	if req.Secret != h.orchestrator.TranscoderSecret() {
 		glog.Errorf("err=%q", errSecret.Error())
@@ -311,26 +506,113 @@ func (h *lphttp) RegisterTranscoder(req *net.RegisterRequest, stream net.Transco
 		req.Capabilities = core.NewCapabilities(core.DefaultCapabilities(), nil).ToNetCapabilities()
 	}
 	// blocks until stream is finished
-	h.orchestrator.ServeTranscoder(stream, int(req.Capacity), req.Capabilities)
+	h.orchestrator.ServeTranscoder(stream, req.Id, req.Pull, int(req.Capacity), req.PixelCapacity, req.Capabilities)
 	return nil
 }
 
 // Orchestrator HTTP
 
-func (h *lphttp) TranscodeResults(w http.ResponseWriter, r *http.Request) {
-	orch := h.orchestrator
-
+// authorizeTranscoder checks the shared secret sent by a remote transcoder and responds with an error if it is invalid
+func (h *lphttp) authorizeTranscoder(w http.ResponseWriter, r *http.Request) bool {
 	authType := r.Header.Get("Authorization")
 	creds := r.Header.Get("Credentials")
 	if protoVerLPT != authType {
 		glog.Error("Invalid auth type ", authType)
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
-		return
+		return false
 	}
 
-	if creds != orch.TranscoderSecret() {
+	if creds != h.orchestrator.TranscoderSecret() {
 		glog.Error("Invalid shared secret")
 		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// TranscoderDrain sets the drain state of a remote transcoder. A draining transcoder is not assigned new sessions
+func (h *lphttp) TranscoderDrain(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTranscoder(w, r) {
+		return
+	}
+
+	id := r.Header.Get("TranscoderId")
+	if id == "" {
+		glog.Error("Missing transcoder ID")
+		http.Error(w, "Missing Transcoder ID", http.StatusBadRequest)
+		return
+	}
+	draining, err := strconv.ParseBool(r.Header.Get("Draining"))
+	if err != nil {
+		glog.Error("Could not parse drain state ", err)
+		http.Error(w, "Invalid Draining", http.StatusBadRequest)
+		return
+	}
+
+	if h.node == nil || h.node.TranscoderManager == nil {
+		http.Error(w, "Remote transcoders are not enabled", http.StatusInternalServerError)
+		return
+	}
+	if err := h.node.TranscoderManager.SetTranscoderDraining(id, draining); err != nil {
+		glog.Errorf("Unable to set drain state for transcoder id=%s err=%q", id, err)
+		status := http.StatusInternalServerError
+		if err == core.ErrTranscoderNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Write([]byte("OK"))
+}
+
+// TranscoderJob leases a segment to a pull mode remote transcoder. It waits for a segment to be assigned to the
+// transcoder and responds with no content if none is assigned before the poll timeout
+func (h *lphttp) TranscoderJob(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeTranscoder(w, r) {
+		return
+	}
+
+	id := r.Header.Get("TranscoderId")
+	if id == "" {
+		glog.Error("Missing transcoder ID")
+		http.Error(w, "Missing Transcoder ID", http.StatusBadRequest)
+		return
+	}
+
+	if h.node == nil || h.node.TranscoderManager == nil {
+		http.Error(w, "Remote transcoders are not enabled", http.StatusInternalServerError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), transcoderJobPollTimeout)
+	defer cancel()
+	notify, err := h.node.TranscoderManager.LeaseTranscoderJob(ctx, id)
+	if err != nil {
+		glog.Errorf("Unable to lease segment to transcoder id=%s err=%q", id, err)
+		status := http.StatusInternalServerError
+		if err == core.ErrTranscoderNotFound {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if notify == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	buf, err := proto.Marshal(notify)
+	if err != nil {
+		glog.Errorf("Unable to marshal segment taskId=%d err=%q", notify.TaskId, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(buf)
+}
+
+func (h *lphttp) TranscodeResults(w http.ResponseWriter, r *http.Request) {
+	orch := h.orchestrator
+
+	if !h.authorizeTranscoder(w, r) {
 		return
 	}
 
@@ -348,7 +630,10 @@ func (h *lphttp) TranscodeResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	_, span := monitor.StartSpan(monitor.ExtractTraceHeaders(r.Context(), r.Header), "TranscodeResults",
+		attribute.Int64("taskId", tid))
 	var res core.RemoteTranscoderResult
+	defer func() { monitor.EndSpan(span, res.Err) }()
 	if transcodingErrorMimeType == mediaType {
 		w.Write([]byte("OK"))
 		body, err := ioutil.ReadAll(r.Body)
@@ -14,10 +14,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"os"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/livepeer/go-livepeer/common"
 	"github.com/livepeer/go-livepeer/core"
 	"github.com/livepeer/go-livepeer/net"
@@ -226,6 +229,233 @@ func TestTranscodeResults_ErrorsWhenPixelsHeaderMissing(t *testing.T) {
 	require.Contains(t, w.Body.String(), "Invalid Pixels")
 }
 
+func TestTranscoderDrain(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	l := lphttp{orchestrator: newStubOrchestrator(), node: n}
+	drain := func(id, draining string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodPost, "/transcoderDrain", nil)
+		require.NoError(err)
+		r.Header.Set("Authorization", protoVerLPT)
+		r.Header.Set("Credentials", "")
+		r.Header.Set("TranscoderId", id)
+		r.Header.Set("Draining", draining)
+		l.TranscoderDrain(w, r)
+		return w
+	}
+
+	w := drain("", "true")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), "Missing Transcoder ID")
+
+	w = drain("TestAddress", "foo")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), "Invalid Draining")
+
+	// Remote transcoders not enabled
+	w = drain("TestAddress", "true")
+	assert.Equal(http.StatusInternalServerError, w.Code)
+
+	n.TranscoderManager = core.NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { n.TranscoderManager.Manage(strm, "", false, 5, nil) }()
+	time.Sleep(1 * time.Millisecond)
+
+	w = drain("foo", "true")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	w = drain("TestAddress", "true")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.True(n.TranscoderManager.RegisteredTranscodersInfo()[0].Draining)
+
+	w = drain("TestAddress", "false")
+	assert.Equal(http.StatusOK, w.Code)
+	assert.False(n.TranscoderManager.RegisteredTranscodersInfo()[0].Draining)
+
+	// Invalid credentials
+	w = httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPost, "/transcoderDrain", nil)
+	require.NoError(err)
+	r.Header.Set("Authorization", protoVerLPT)
+	r.Header.Set("Credentials", "BAD CREDENTIALS")
+	l.TranscoderDrain(w, r)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func TestDrainTranscoder(t *testing.T) {
+	assert := assert.New(t)
+
+	oldInterval := drainCheckInterval
+	drainCheckInterval = time.Millisecond
+	defer func() { drainCheckInterval = oldInterval }()
+
+	var drainReqs int
+	var headers http.Header
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		drainReqs++
+		headers = r.Header
+		w.Write([]byte("OK"))
+	}))
+	defer ts.Close()
+	parsedURL, _ := url.Parse(ts.URL)
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.OrchSecret = "secret"
+	exitc := make(chan os.Signal, 1)
+
+	// Returns once the running session is torn down
+	work := newTranscoderWork()
+	notify := &net.NotifySegment{SegData: &net.SegData{AuthToken: &net.AuthToken{SessionId: "foo"}}}
+	work.startTask(notify)
+	work.endTask()
+	assert.False(work.idle())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		work.endSession("foo")
+	}()
+	drainTranscoder(context.Background(), n, parsedURL.Host, ts.Client(), "bar", work, time.Minute, exitc)
+	assert.True(work.idle())
+	assert.Equal(1, drainReqs)
+	assert.Equal(protoVerLPT, headers.Get("Authorization"))
+	assert.Equal("secret", headers.Get("Credentials"))
+	assert.Equal("bar", headers.Get("TranscoderId"))
+	assert.Equal("true", headers.Get("Draining"))
+
+	// Returns when the timeout expires
+	work.startTask(notify)
+	start := time.Now()
+	drainTranscoder(context.Background(), n, parsedURL.Host, ts.Client(), "bar", work, 20*time.Millisecond, exitc)
+	assert.GreaterOrEqual(time.Since(start), 20*time.Millisecond)
+	assert.False(work.idle())
+
+	// Returns on another signal
+	exitc <- os.Interrupt
+	drainTranscoder(context.Background(), n, parsedURL.Host, ts.Client(), "bar", work, time.Minute, exitc)
+	assert.False(work.idle())
+	assert.Equal(3, drainReqs)
+
+	// Does not wait if the orchestrator rejects the drain request
+	ts.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	})
+	err := sendTranscoderDrain(n, parsedURL.Host, ts.Client(), "bar", true)
+	assert.Contains(err.Error(), "statusCode=401")
+	drainTranscoder(context.Background(), n, parsedURL.Host, ts.Client(), "bar", work, time.Minute, exitc)
+}
+
+func TestTranscoderJob(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldTimeout := transcoderJobPollTimeout
+	transcoderJobPollTimeout = 10 * time.Millisecond
+	defer func() { transcoderJobPollTimeout = oldTimeout }()
+
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	l := lphttp{orchestrator: newStubOrchestrator(), node: n}
+	lease := func(id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodPost, "/transcoderJob", nil)
+		require.NoError(err)
+		r.Header.Set("Authorization", protoVerLPT)
+		r.Header.Set("Credentials", "")
+		r.Header.Set("TranscoderId", id)
+		l.TranscoderJob(w, r)
+		return w
+	}
+
+	w := lease("")
+	assert.Equal(http.StatusBadRequest, w.Code)
+	assert.Contains(w.Body.String(), "Missing Transcoder ID")
+
+	// Remote transcoders not enabled
+	w = lease("foo")
+	assert.Equal(http.StatusInternalServerError, w.Code)
+
+	n.TranscoderManager = core.NewRemoteTranscoderManager()
+	strm := &common.StubServerStream{}
+	go func() { n.TranscoderManager.Manage(strm, "foo", true, 5, nil) }()
+	time.Sleep(1 * time.Millisecond)
+
+	w = lease("bar")
+	assert.Equal(http.StatusNotFound, w.Code)
+
+	// No segment queued before the poll timeout
+	w = lease("foo")
+	assert.Equal(http.StatusNoContent, w.Code)
+
+	go n.TranscoderManager.Transcode(context.Background(), &core.SegTranscodingMetadata{Fname: "foo.ts", AuthToken: stubAuthToken})
+	transcoderJobPollTimeout = time.Second
+	w = lease("foo")
+	require.Equal(http.StatusOK, w.Code)
+	var notify net.NotifySegment
+	require.Nil(proto.Unmarshal(w.Body.Bytes(), &notify))
+	assert.Equal("foo.ts", notify.Url)
+	core.NewOrchestrator(n, nil).TranscoderResults(notify.TaskId, &core.RemoteTranscoderResult{Err: errors.New("TranscodeError")})
+
+	// Invalid credentials
+	w = httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodPost, "/transcoderJob", nil)
+	require.NoError(err)
+	r.Header.Set("Authorization", protoVerLPT)
+	r.Header.Set("Credentials", "BAD CREDENTIALS")
+	l.TranscoderJob(w, r)
+	assert.Equal(http.StatusUnauthorized, w.Code)
+}
+
+func TestLeaseTranscoderJob(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	notify := &net.NotifySegment{TaskId: 7, Url: "foo.ts"}
+	var headers http.Header
+	status := http.StatusOK
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		if status != http.StatusOK {
+			http.Error(w, "error", status)
+			return
+		}
+		buf, err := proto.Marshal(notify)
+		require.NoError(err)
+		w.Write(buf)
+	}))
+	defer ts.Close()
+	parsedURL, _ := url.Parse(ts.URL)
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.OrchSecret = "secret"
+
+	res, err := leaseTranscoderJob(context.Background(), n, parsedURL.Host, ts.Client(), "bar")
+	require.Nil(err)
+	assert.Equal(int64(7), res.TaskId)
+	assert.Equal("foo.ts", res.Url)
+	assert.Equal(protoVerLPT, headers.Get("Authorization"))
+	assert.Equal("secret", headers.Get("Credentials"))
+	assert.Equal("bar", headers.Get("TranscoderId"))
+
+	// No segment queued
+	status = http.StatusNoContent
+	res, err = leaseTranscoderJob(context.Background(), n, parsedURL.Host, ts.Client(), "bar")
+	assert.Nil(err)
+	assert.Nil(res)
+
+	status = http.StatusNotFound
+	_, err = leaseTranscoderJob(context.Background(), n, parsedURL.Host, ts.Client(), "bar")
+	assert.Contains(err.Error(), "statusCode=404")
+
+	// pollTranscoderJobs retries failed lease requests until ctx is done
+	oldInterval := transcoderJobRetryInterval
+	transcoderJobRetryInterval = time.Millisecond
+	defer func() { transcoderJobRetryInterval = oldInterval }()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	work := newTranscoderWork()
+	pollTranscoderJobs(ctx, n, parsedURL.Host, ts.Client(), "bar", work)
+	assert.True(work.idle())
+}
+
 func TestRemoteTranscoder_FullProfiles(t *testing.T) {
 	assert := assert.New(t)
 	httpc := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
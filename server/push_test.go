@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"math"
 	"mime"
 	"mime/multipart"
 	"net/http"
@@ -31,6 +30,7 @@ import (
 	"github.com/livepeer/go-livepeer/core"
 	lpmon "github.com/livepeer/go-livepeer/monitor"
 	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/verification"
 	"github.com/livepeer/go-tools/drivers"
 	"github.com/livepeer/lpms/ffmpeg"
 	"github.com/livepeer/lpms/vidplayer"
@@ -1498,7 +1498,7 @@ func TestPush_MultipartReturnMultiSession(t *testing.T) {
 	sess3.OrchestratorScore = common.Score_Untrusted
 
 	bsm := bsmWithSessListExt([]*BroadcastSession{sess1}, []*BroadcastSession{sess3, sess2}, false)
-	bsm.VerificationFreq = 1
+	bsm.VerificationPolicy = &verification.Policy{Redundancy: 3}
 	assert.Equal(0, bsm.untrustedPool.sus.count)
 	// hack: stop pool from refreshing
 	bsm.untrustedPool.refreshing = true
@@ -1523,7 +1523,7 @@ func TestPush_MultipartReturnMultiSession(t *testing.T) {
 		pl:          pl,
 		profile:     &ffmpeg.P144p30fps16x9,
 		sessManager: bsm,
-		params:      &core.StreamParameters{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p25fps16x9}, VerificationFreq: 1},
+		params:      &core.StreamParameters{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p25fps16x9}, VerificationRedundancy: 3},
 	}
 
 	s.rtmpConnections["mani"] = cxn
@@ -1636,9 +1636,9 @@ func createStubTranscoder(transcodedSegData []byte, transcodedSegPhash []byte, t
 	return ts
 }
 
-func initServerWithBSM(srv *LivepeerServer, trustedSessList []*BroadcastSession, untrustedSessList []*BroadcastSession, verificationFreq uint) *BroadcastSessionsManager {
+func initServerWithBSM(srv *LivepeerServer, trustedSessList []*BroadcastSession, untrustedSessList []*BroadcastSession, policy *verification.Policy) *BroadcastSessionsManager {
 	bsm := bsmWithSessListExt(trustedSessList, untrustedSessList, false)
-	bsm.VerificationFreq = verificationFreq
+	bsm.VerificationPolicy = policy
 
 	// hack: stop pool from refreshing
 	bsm.untrustedPool.refreshing = true
@@ -1661,7 +1661,7 @@ func initServerWithBSM(srv *LivepeerServer, trustedSessList []*BroadcastSession,
 		pl:          pl,
 		profile:     &ffmpeg.P144p30fps16x9,
 		sessManager: bsm,
-		params:      &core.StreamParameters{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p25fps16x9}, VerificationFreq: 1},
+		params:      &core.StreamParameters{Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p25fps16x9}, VerificationRedundancy: 3},
 	}
 
 	srv.rtmpConnections["mani"] = cxn
@@ -1729,7 +1729,7 @@ func TestPush_FastVerificationFlow(t *testing.T) {
 	reader := strings.NewReader("InsteadOf.TS")
 
 	// pass sessions in reverse order because LIFO selector will pick up in reverse order
-	bsm := initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessGood, untrustedSessBadSegment}, 1)
+	bsm := initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessGood, untrustedSessBadSegment}, &verification.Policy{Redundancy: 3})
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/live/mani/17.ts", reader)
 	req.Header.Set("Accept", "multipart/mixed")
@@ -1752,7 +1752,7 @@ func TestPush_FastVerificationFlow(t *testing.T) {
 	assert.Equal("untrustedGood", lastUsedT)
 
 	// reinit and check that 'bad hash' session causes suspension as well
-	bsm = initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessGood, untrustedSessBadHash}, 1)
+	bsm = initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessGood, untrustedSessBadHash}, &verification.Policy{Redundancy: 3})
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest("POST", "/live/mani/18.ts", reader)
 	req.Header.Set("Accept", "multipart/mixed")
@@ -1763,7 +1763,7 @@ func TestPush_FastVerificationFlow(t *testing.T) {
 	assert.Contains(bsm.untrustedPool.sus.list, untrustedTBadHash.URL)
 
 	// check that trusted session is used, when both untrusted sessions are bad
-	bsm = initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessBadSegment, untrustedSessBadHash}, 1)
+	bsm = initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessBadSegment, untrustedSessBadHash}, &verification.Policy{Redundancy: 3})
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest("POST", "/live/mani/18.ts", reader)
 	req.Header.Set("Accept", "multipart/mixed")
@@ -1784,10 +1784,10 @@ func TestPush_FastVerificationFlow(t *testing.T) {
 	validateMultipartResponse(assert, resp, 19, 1)
 	assert.Equal("trusted", lastUsedT)
 
-	// check multiple sessions and verification every segment
+	// check multiple sessions and verification of almost no segments
 	bsm = initServerWithBSM(srv, []*BroadcastSession{trustedSess}, []*BroadcastSession{untrustedSessBadSegment,
 		untrustedSessGood, untrustedSessGood, untrustedSessGood, untrustedSessGood, untrustedSessGood, untrustedSessGood,
-		untrustedSessGood, untrustedSessGood, untrustedSessGood}, math.MaxUint)
+		untrustedSessGood, untrustedSessGood, untrustedSessGood}, &verification.Policy{Redundancy: 3, SampleRate: 1e-12})
 	w = httptest.NewRecorder()
 	req = httptest.NewRequest("POST", "/live/mani/18.ts", reader)
 	req.Header.Set("Accept", "multipart/mixed")
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"net/url"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/livepeer/go-livepeer/clog"
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/patrickmn/go-cache"
+	"github.com/pkg/errors"
+)
+
+// reservationReqs holds the digests of the accepted capacity reservation requests until the reservations would end, so
+// that the requests cannot be replayed
+var reservationReqs = cache.New(core.MaxReservationWindow, time.Minute)
+
+func (h *lphttp) ReserveCapacity(ctx context.Context, req *net.CapacityReservationRequest) (*net.CapacityReservation, error) {
+	return reserveCapacity(ctx, h.orchestrator, req)
+}
+
+func reserveCapacity(ctx context.Context, orch Orchestrator, req *net.CapacityReservationRequest) (*net.CapacityReservation, error) {
+	addr := ethcommon.BytesToAddress(req.Address)
+	digest := capacityReservationDigest(req)
+	if !orch.VerifySig(addr, string(digest), req.Sig) {
+		glog.Error("capacity reservation req sig check failed")
+		return nil, fmt.Errorf("capacity reservation req sig check failed")
+	}
+
+	// Requests are remembered until their window ends, so windows that end later than that are refused. Requests whose
+	// window already ended are refused by the orchestrator
+	end := time.Unix(req.End, 0)
+	if end.After(time.Now().Add(core.MaxReservationWindow)) {
+		return nil, fmt.Errorf("capacity reservation req ends too late")
+	}
+	if ttl := time.Until(end); ttl > 0 {
+		if err := reservationReqs.Add(string(digest), nil, ttl); err != nil {
+			glog.Errorf("capacity reservation req replayed sender=%v", addr.Hex())
+			return nil, fmt.Errorf("capacity reservation req replayed")
+		}
+	}
+
+	if _, err := authenticateBroadcaster(addr.Hex()); err != nil {
+		return nil, fmt.Errorf("authentication failed: %v", err)
+	}
+
+	profiles, err := makeFfmpegVideoProfiles(req.Profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment net.Payment
+	if req.Payment != nil {
+		payment = *req.Payment
+	}
+	r, err := orch.ReserveCapacity(ctx, addr, payment, int(req.Sessions), profiles, time.Unix(req.Start, 0), end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &net.CapacityReservation{
+		Id:       r.ID,
+		Sessions: int32(r.Sessions),
+		Start:    r.Start.Unix(),
+		End:      r.End.Unix(),
+	}, nil
+}
+
+// ReserveCapacity - the broadcaster calls ReserveCapacity to reserve sessions on an orchestrator for a time window.
+// If prepay is set, the broadcaster pays at least that amount for the reservation
+func ReserveCapacity(ctx context.Context, node *core.LivepeerNode, orchestratorServer *url.URL, sessions int,
+	profiles []ffmpeg.VideoProfile, start, end time.Time, prepay *big.Rat) (*net.CapacityReservation, error) {
+
+	c, conn, err := startOrchestratorClient(ctx, orchestratorServer)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	bcast := core.NewBroadcaster(node)
+	req, err := genCapacityReservationReq(bcast, sessions, profiles, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if prepay != nil && prepay.Sign() > 0 && node.Sender != nil {
+		orchReq, err := genOrchestratorReq(bcast)
+		if err != nil {
+			return nil, err
+		}
+		oInfo, err := c.GetOrchestrator(ctx, orchReq)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Could not get orchestrator orch=%v", orchestratorServer)
+		}
+		req.Payment, err = genReservationPayment(ctx, node, bcast, oInfo, prepay)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r, err := c.ReserveCapacity(ctx, req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Could not reserve capacity orch=%v", orchestratorServer)
+	}
+	return r, nil
+}
+
+func genCapacityReservationReq(b common.Broadcaster, sessions int, profiles []ffmpeg.VideoProfile, start, end time.Time) (*net.CapacityReservationRequest, error) {
+	netProfiles, err := common.FFmpegProfiletoNetProfile(profiles)
+	if err != nil {
+		return nil, err
+	}
+	req := &net.CapacityReservationRequest{
+		Address:  b.Address().Bytes(),
+		Sessions: int32(sessions),
+		Profiles: netProfiles,
+		Start:    start.Unix(),
+		End:      end.Unix(),
+		Nonce:    rand.Uint64(),
+	}
+	req.Sig, err = b.Sign(capacityReservationDigest(req))
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// capacityReservationDigest returns the digest of the fields of a capacity reservation request that the broadcaster
+// signs. The payment is not part of it because the tickets carry their own signatures
+func capacityReservationDigest(req *net.CapacityReservationRequest) []byte {
+	data, _ := proto.Marshal(&net.CapacityReservationRequest{
+		Address:  req.Address,
+		Sessions: req.Sessions,
+		Profiles: req.Profiles,
+		Start:    req.Start,
+		End:      req.End,
+		Nonce:    req.Nonce,
+	})
+	return ethcrypto.Keccak256(data)
+}
+
+// genReservationPayment creates tickets for the orchestrator that are worth at least the given amount
+func genReservationPayment(ctx context.Context, node *core.LivepeerNode, bcast common.Broadcaster, oInfo *net.OrchestratorInfo, amount *big.Rat) (*net.Payment, error) {
+	if oInfo.TicketParams == nil {
+		return nil, errors.New("missing ticket params")
+	}
+	sess := &BroadcastSession{
+		Broadcaster:      bcast,
+		Params:           &core.StreamParameters{ManifestID: "reservation"},
+		OrchestratorInfo: oInfo,
+		Sender:           node.Sender,
+	}
+	sess.PMSessionID = node.Sender.StartSession(*pmTicketParams(oInfo.TicketParams))
+
+	ev, err := node.Sender.EV(sess.PMSessionID)
+	if err != nil {
+		return nil, err
+	}
+	if ev.Sign() <= 0 {
+		return nil, errors.New("invalid ticket EV")
+	}
+	tickets := new(big.Rat).Quo(amount, ev)
+	numTickets := new(big.Int).Quo(tickets.Num(), tickets.Denom())
+	if new(big.Rat).SetInt(numTickets).Cmp(tickets) < 0 {
+		numTickets.Add(numTickets, big.NewInt(1))
+	}
+	if !numTickets.IsInt64() {
+		return nil, errors.New("prepayment is too large")
+	}
+
+	data, err := genPayment(ctx, sess, int(numTickets.Int64()))
+	if err != nil {
+		return nil, err
+	}
+	payment, err := getPayment(data)
+	if err != nil {
+		return nil, err
+	}
+	clog.V(common.DEBUG).Infof(ctx, "Created reservation payment orch=%v numTickets=%v", oInfo.Transcoder, numTickets)
+	return &payment, nil
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserveCapacity_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	o := newStubOrchestrator()
+	b := stubBroadcaster2()
+	start := time.Unix(time.Now().Add(time.Hour).Unix(), 0)
+	end := start.Add(2 * time.Hour)
+	profiles := []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9}
+
+	req, err := genCapacityReservationReq(b, 2, profiles, start, end)
+	require.Nil(err)
+	assert.Equal(b.Address().Bytes(), req.Address)
+	assert.Equal(int32(2), req.Sessions)
+	assert.Len(req.Profiles, 1)
+
+	r, err := reserveCapacity(context.TODO(), o, req)
+	require.Nil(err)
+	assert.Equal("foo", r.Id)
+	assert.Equal(int32(2), r.Sessions)
+	assert.Equal(start.Unix(), r.Start)
+	assert.Equal(end.Unix(), r.End)
+
+	// Requests cannot be replayed
+	_, err = reserveCapacity(context.TODO(), o, req)
+	assert.EqualError(err, "capacity reservation req replayed")
+
+	// Identical reservations are signed with different nonces
+	req2, err := genCapacityReservationReq(b, 2, profiles, start, end)
+	require.Nil(err)
+	assert.NotEqual(req.Nonce, req2.Nonce)
+	_, err = reserveCapacity(context.TODO(), o, req2)
+	assert.Nil(err)
+
+	// The sig covers the whole request
+	req3, err := genCapacityReservationReq(b, 2, profiles, start, end)
+	require.Nil(err)
+	req3.Sessions = 3
+	_, err = reserveCapacity(context.TODO(), o, req3)
+	assert.EqualError(err, "capacity reservation req sig check failed")
+
+	// Windows that end after the requests are forgotten are refused
+	req4, err := genCapacityReservationReq(b, 2, profiles, start, time.Now().Add(core.MaxReservationWindow+time.Hour))
+	require.Nil(err)
+	_, err = reserveCapacity(context.TODO(), o, req4)
+	assert.EqualError(err, "capacity reservation req ends too late")
+
+	// Requests with an invalid sig are refused
+	req.Sig = []byte("bar")
+	_, err = reserveCapacity(context.TODO(), o, req)
+	assert.EqualError(err, "capacity reservation req sig check failed")
+
+	// Sign errors are returned
+	b.signErr = errors.New("Sign error")
+	_, err = genCapacityReservationReq(b, 2, profiles, start, end)
+	assert.EqualError(err, "Sign error")
+}
+
+func TestReserveCapacity_OrchestratorError(t *testing.T) {
+	assert := assert.New(t)
+
+	orch := &mockOrchestrator{}
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	payment := defaultPayment(t)
+	req := &net.CapacityReservationRequest{Sessions: 1, Start: 1, End: 2, Payment: payment}
+	orch.On("ReserveCapacity", mock.Anything, *payment, 1, mock.Anything, time.Unix(1, 0), time.Unix(2, 0)).Return(nil, core.ErrReservationCap)
+
+	_, err := reserveCapacity(context.TODO(), orch, req)
+	assert.Equal(core.ErrReservationCap, err)
+	orch.AssertExpectations(t)
+}
+
+func TestGetOrchestrator_BindsReservation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	o := newStubOrchestrator()
+	req, err := genOrchestratorReq(stubBroadcaster2())
+	require.Nil(err)
+
+	oInfo, err := getOrchestrator(o, req)
+	require.Nil(err)
+	assert.Empty(o.bound)
+
+	o.reserved = true
+	oInfo, err = getOrchestrator(o, req)
+	require.Nil(err)
+	assert.Equal([]core.ManifestID{core.ManifestID(oInfo.AuthToken.SessionId)}, o.bound)
+}
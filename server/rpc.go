@@ -49,9 +49,9 @@ type Orchestrator interface {
 	TranscoderSecret() string
 	Sign([]byte) ([]byte, error)
 	VerifySig(ethcommon.Address, string, []byte) bool
-	CheckCapacity(core.ManifestID) error
+	CheckCapacity(core.ManifestID, int32) error
 	TranscodeSeg(context.Context, *core.SegTranscodingMetadata, *stream.HLSSegment) (*core.TranscodeResult, error)
-	ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities)
+	ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities)
 	TranscoderResults(job int64, res *core.RemoteTranscoderResult)
 	ProcessPayment(ctx context.Context, payment net.Payment, manifestID core.ManifestID) error
 	TicketParams(sender ethcommon.Address, priceInfo *net.PriceInfo) (*net.TicketParams, error)
@@ -60,6 +60,9 @@ type Orchestrator interface {
 	DebitFees(addr ethcommon.Address, manifestID core.ManifestID, price *net.PriceInfo, pixels int64)
 	Capabilities() *net.Capabilities
 	AuthToken(sessionID string, expiration int64) *net.AuthToken
+	ReserveCapacity(ctx context.Context, sender ethcommon.Address, payment net.Payment, sessions int, profiles []ffmpeg.VideoProfile, start, end time.Time) (*core.CapacityReservation, error)
+	ReservationAvailable(sender ethcommon.Address) bool
+	BindReservation(sender ethcommon.Address, sessionID core.ManifestID)
 }
 
 // Balance describes methods for a session's balance maintenance
@@ -197,6 +200,8 @@ func StartTranscodeServer(orch Orchestrator, bind string, mux *http.ServeMux, wo
 	if acceptRemoteTranscoders {
 		net.RegisterTranscoderServer(s, &lp)
 		lp.transRPC.HandleFunc("/transcodeResults", lp.TranscodeResults)
+		lp.transRPC.HandleFunc("/transcoderDrain", lp.TranscoderDrain)
+		lp.transRPC.HandleFunc("/transcoderJob", lp.TranscoderJob)
 	}
 
 	cert, key, err := getCert(orch.ServiceURI(), workDir)
@@ -241,6 +246,26 @@ func CheckOrchestratorAvailability(orch Orchestrator) bool {
 	return orch.VerifySig(orch.Address(), string(ping), pong.Value)
 }
 
+// PingOrchestrator - the broadcaster calls PingOrchestrator to measure its round trip time to the orchestrator
+// with the Ping RPC. The connection setup is not included in the round trip time.
+func PingOrchestrator(ctx context.Context, uri *url.URL) (time.Duration, error) {
+	orchClient, conn, err := startOrchestratorClient(ctx, uri)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, GRPCTimeout)
+	defer cancel()
+
+	ping := crypto.Keccak256([]byte(fmt.Sprintf("%v", time.Now())))
+	start := time.Now()
+	if _, err := orchClient.Ping(ctx, &net.PingPong{Value: ping}); err != nil {
+		return 0, errors.Wrapf(err, "Could not ping orchestrator orch=%v", uri)
+	}
+	return time.Since(start), nil
+}
+
 func ping(context context.Context, req *net.PingPong, orch Orchestrator) (*net.PingPong, error) {
 	glog.Info("Received Ping request")
 	value, err := orch.Sign(req.Value)
@@ -326,7 +351,12 @@ func getOrchestrator(orch Orchestrator, req *net.OrchestratorRequest) (*net.Orch
 	}
 
 	// currently, orchestrator == transcoder
-	return orchestratorInfo(orch, addr, orch.ServiceURI().String(), "")
+	oInfo, err := orchestratorInfo(orch, addr, orch.ServiceURI().String(), "")
+	if err != nil {
+		return nil, err
+	}
+	orch.BindReservation(addr, core.ManifestID(oInfo.AuthToken.GetSessionId()))
+	return oInfo, nil
 }
 
 func endTranscodingSession(node *core.LivepeerNode, orch Orchestrator, req *net.EndTranscodingSessionRequest) (*net.EndTranscodingSessionResponse, error) {
@@ -391,7 +421,11 @@ func verifyOrchestratorReq(orch Orchestrator, addr ethcommon.Address, sig []byte
 		glog.Error("orchestrator req sig check failed")
 		return fmt.Errorf("orchestrator req sig check failed")
 	}
-	return orch.CheckCapacity("")
+	// Broadcasters with a reservation use the reserved sessions
+	if orch.ReservationAvailable(addr) {
+		return nil
+	}
+	return orch.CheckCapacity("", core.PriorityNormal)
 }
 
 type discoveryAuthWebhookRes struct {
@@ -537,5 +571,6 @@ func coreSegMetadata(segData *net.SegData) (*core.SegTranscodingMetadata, error)
 		AuthToken:          segData.AuthToken,
 		CalcPerceptualHash: segData.CalcPerceptualHash,
 		SegmentParameters:  &segPar,
+		Priority:           core.ClampPriority(segData.Priority),
 	}, nil
 }
@@ -75,6 +75,8 @@ type stubOrchestrator struct {
 	offchain     bool
 	caps         *core.Capabilities
 	authToken    *net.AuthToken
+	reserved     bool
+	bound        []core.ManifestID
 }
 
 func (r *stubOrchestrator) ServiceURI() *url.URL {
@@ -165,6 +167,20 @@ func (r *stubOrchestrator) AuthToken(sessionID string, expiration int64) *net.Au
 	return &net.AuthToken{Token: []byte("foo"), SessionId: sessionID, Expiration: expiration}
 }
 
+func (r *stubOrchestrator) ReserveCapacity(ctx context.Context, sender ethcommon.Address, payment net.Payment, sessions int, profiles []ffmpeg.VideoProfile, start, end time.Time) (*core.CapacityReservation, error) {
+	return &core.CapacityReservation{ID: "foo", Sender: sender, Sessions: sessions, Profiles: profiles, Start: start, End: end}, nil
+}
+
+func (r *stubOrchestrator) ReservationAvailable(sender ethcommon.Address) bool {
+	return r.reserved
+}
+
+func (r *stubOrchestrator) BindReservation(sender ethcommon.Address, sessionID core.ManifestID) {
+	if r.reserved {
+		r.bound = append(r.bound, sessionID)
+	}
+}
+
 func newStubOrchestrator() *stubOrchestrator {
 	pk, err := ethcrypto.GenerateKey()
 	if err != nil {
@@ -173,10 +189,10 @@ func newStubOrchestrator() *stubOrchestrator {
 	return &stubOrchestrator{priv: pk, block: big.NewInt(5)}
 }
 
-func (r *stubOrchestrator) CheckCapacity(mid core.ManifestID) error {
+func (r *stubOrchestrator) CheckCapacity(mid core.ManifestID, priority int32) error {
 	return r.sessCapErr
 }
-func (r *stubOrchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
+func (r *stubOrchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities) {
 }
 func (r *stubOrchestrator) TranscoderResults(job int64, res *core.RemoteTranscoderResult) {
 }
@@ -220,6 +236,13 @@ func TestRPCTranscoderReq(t *testing.T) {
 	if err := verifyOrchestratorReq(o, addr, req.Sig); err != o.sessCapErr {
 		t.Errorf("Expected %v; got %v", o.sessCapErr, err)
 	}
+
+	// at capacity with a reservation
+	o.reserved = true
+	if err := verifyOrchestratorReq(o, addr, req.Sig); err != nil {
+		t.Errorf("Expected reserved session to pass; got %v", err)
+	}
+	o.reserved = false
 	o.sessCapErr = nil
 
 	// error signing
@@ -1291,7 +1314,7 @@ func (o *mockOrchestrator) TranscodeSeg(ctx context.Context, md *core.SegTransco
 
 	return res, args.Error(1)
 }
-func (o *mockOrchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, capacity int, capabilities *net.Capabilities) {
+func (o *mockOrchestrator) ServeTranscoder(stream net.Transcoder_RegisterTranscoderServer, id string, pull bool, capacity int, pixelCapacity int64, capabilities *net.Capabilities) {
 	o.Called(stream)
 }
 func (o *mockOrchestrator) TranscoderResults(job int64, res *core.RemoteTranscoderResult) {
@@ -1318,7 +1341,7 @@ func (o *mockOrchestrator) PriceInfo(sender ethcommon.Address, manifestID core.M
 	return nil, args.Error(1)
 }
 
-func (o *mockOrchestrator) CheckCapacity(mid core.ManifestID) error {
+func (o *mockOrchestrator) CheckCapacity(mid core.ManifestID, priority int32) error {
 	return nil
 }
 
@@ -1346,6 +1369,21 @@ func (o *mockOrchestrator) AuthToken(sessionID string, expiration int64) *net.Au
 	return nil
 }
 
+func (o *mockOrchestrator) ReserveCapacity(ctx context.Context, sender ethcommon.Address, payment net.Payment, sessions int, profiles []ffmpeg.VideoProfile, start, end time.Time) (*core.CapacityReservation, error) {
+	args := o.Called(sender, payment, sessions, profiles, start, end)
+	if args.Get(0) != nil {
+		return args.Get(0).(*core.CapacityReservation), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (o *mockOrchestrator) ReservationAvailable(sender ethcommon.Address) bool {
+	return false
+}
+
+func (o *mockOrchestrator) BindReservation(sender ethcommon.Address, sessionID core.ManifestID) {
+}
+
 func defaultTicketParams() *net.TicketParams {
 	return &net.TicketParams{
 		Recipient:         pm.RandBytes(123),
@@ -12,6 +12,7 @@ import (
 	gonet "net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/livepeer/go-livepeer/clog"
@@ -29,6 +30,7 @@ import (
 	"github.com/golang/glog"
 	"github.com/golang/protobuf/proto"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const paymentHeader = "Livepeer-Payment"
@@ -71,7 +73,7 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	orch := h.orchestrator
 
 	remoteAddr := getRemoteAddr(r)
-	ctx := clog.AddVal(r.Context(), clog.ClientIP, remoteAddr)
+	ctx := clog.AddVal(monitor.ExtractTraceHeaders(r.Context(), r.Header), clog.ClientIP, remoteAddr)
 
 	payment, err := getPayment(r.Header.Get(paymentHeader))
 	if err != nil {
@@ -123,6 +125,24 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	}
 	// Use existing auth token because new auth tokens should only be sent out in GetOrchestrator() RPC calls
 	oInfo.AuthToken = segData.AuthToken
+	if err := priorityPriceInfo(orch, h.node, sender, oInfo, segData.Priority); err != nil {
+		clog.Errorf(ctx, "Error updating price for priority=%d err=%q", segData.Priority, err)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	// Every segment of a priced priority class is charged the price of the class. The broadcaster receives the price of
+	// the class with the results of the first segment of the stream so the payments for the following segments have
+	// to expect it
+	debitPrice := payment.GetExpectedPrice()
+	if h.node != nil && h.node.CanPreempt(segData.Priority) {
+		if h.node.IsTranscoding(core.ManifestID(segData.AuthToken.SessionId)) && priceInfoBelow(debitPrice, oInfo.PriceInfo) {
+			clog.Errorf(ctx, "Expected price below the price of priority=%d", segData.Priority)
+			http.Error(w, "Expected price below the price of the priority class", http.StatusBadRequest)
+			return
+		}
+		debitPrice = maxPriceInfo(debitPrice, oInfo.PriceInfo)
+	}
 
 	// download the segment and check the hash
 	dlStart := time.Now()
@@ -221,10 +241,15 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 		segments = append(segments, d)
 	}
 
-	// Debit the fee for the total pixel count
-	orch.DebitFees(sender, core.ManifestID(segData.AuthToken.SessionId), payment.GetExpectedPrice(), pixels)
-	if monitor.Enabled {
-		monitor.MilPixelsProcessed(ctx, float64(pixels)/1000000.0)
+	if err == nil && res.Cached && h.node != nil {
+		// Debit the fee for the cached results according to the cache fee policy
+		orch.DebitFees(sender, core.ManifestID(segData.AuthToken.SessionId), debitPrice, h.node.TranscodeCache.FeePixels(pixels))
+	} else {
+		// Debit the fee for the total pixel count
+		orch.DebitFees(sender, core.ManifestID(segData.AuthToken.SessionId), debitPrice, pixels)
+		if monitor.Enabled {
+			monitor.MilPixelsProcessed(ctx, float64(pixels)/1000000.0)
+		}
 	}
 
 	// construct the response
@@ -254,6 +279,64 @@ func (h *lphttp) ServeSegment(w http.ResponseWriter, r *http.Request) {
 	w.Write(buf)
 }
 
+// maxPriceInfo returns the higher of two prices. Invalid prices are ignored
+func maxPriceInfo(a, b *net.PriceInfo) *net.PriceInfo {
+	ra, err := common.RatPriceInfo(a)
+	if err != nil || ra == nil {
+		return b
+	}
+	rb, err := common.RatPriceInfo(b)
+	if err != nil || rb == nil || ra.Cmp(rb) >= 0 {
+		return a
+	}
+	return b
+}
+
+// priceInfoBelow returns whether the price a is below the non-zero price b. A missing price a is below any price b
+func priceInfoBelow(a, b *net.PriceInfo) bool {
+	rb, err := common.RatPriceInfo(b)
+	if err != nil || rb == nil || rb.Sign() == 0 {
+		return false
+	}
+	ra, err := common.RatPriceInfo(a)
+	if err != nil || ra == nil {
+		return true
+	}
+	return ra.Cmp(rb) < 0
+}
+
+// priorityPriceInfo sets the price of the priority class of a stream in the orchestrator info so that the broadcaster
+// pays it for the following segments of the stream
+func priorityPriceInfo(orch Orchestrator, node *core.LivepeerNode, sender ethcommon.Address, oInfo *net.OrchestratorInfo, priority int32) error {
+	if node == nil || len(node.PriorityPrices) == 0 || oInfo.PriceInfo == nil {
+		return nil
+	}
+	price, err := common.RatPriceInfo(oInfo.PriceInfo)
+	if err != nil {
+		return err
+	}
+	classPrice := node.PriorityPrice(price, priority)
+	if classPrice.Cmp(price) == 0 {
+		return nil
+	}
+	fixedPrice, err := common.PriceToFixed(classPrice)
+	if err != nil {
+		return err
+	}
+	classPrice = common.FixedToPrice(fixedPrice)
+	priceInfo := &net.PriceInfo{
+		PricePerUnit:  classPrice.Num().Int64(),
+		PixelsPerUnit: classPrice.Denom().Int64(),
+	}
+	params, err := orch.TicketParams(sender, priceInfo)
+	if err != nil {
+		return err
+	}
+	oInfo.PriceInfo = priceInfo
+	oInfo.TicketParams = params
+	return nil
+}
+
 func getPayment(header string) (net.Payment, error) {
 	buf, err := base64.StdEncoding.DecodeString(header)
 	if err != nil {
@@ -384,7 +467,7 @@ func verifySegCreds(ctx context.Context, orch Orchestrator, segCreds string, bro
 		return nil, ctx, errors.New("expired auth token")
 	}
 
-	if err := orch.CheckCapacity(core.ManifestID(segData.AuthToken.SessionId)); err != nil {
+	if err := orch.CheckCapacity(core.ManifestID(segData.AuthToken.SessionId), md.Priority); err != nil {
 		clog.Errorf(ctx, "Cannot process manifest err=%q", err)
 		return nil, ctx, err
 	}
@@ -392,8 +475,28 @@ func verifySegCreds(ctx context.Context, orch Orchestrator, segCreds string, bro
 	return md, ctx, nil
 }
 
+type submitCancelKey struct{}
+
+// withSubmitCancel returns a context whose segment submissions are cancelled by the returned function. Submissions
+// are otherwise not cancelled with the context they are made with
+func withSubmitCancel(ctx context.Context) (context.Context, context.CancelFunc) {
+	done := make(chan struct{})
+	var once sync.Once
+	cancel := func() { once.Do(func() { close(done) }) }
+	return context.WithValue(ctx, submitCancelKey{}, (<-chan struct{})(done)), cancel
+}
+
+func submitCancelled(ctx context.Context) <-chan struct{} {
+	done, _ := ctx.Value(submitCancelKey{}).(<-chan struct{})
+	return done
+}
+
 func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.SegmentParameters,
-	nonce uint64, calcPerceptualHash, verified bool) (*ReceivedTranscodeResult, error) {
+	nonce uint64, calcPerceptualHash, verified bool) (_ *ReceivedTranscodeResult, err error) {
+
+	if OrchStats != nil {
+		defer func() { OrchStats.recordSegment(sess.Transcoder(), err == nil) }()
+	}
 
 	uploaded := seg.Name != "" // hijack seg.Name to convey the uploaded URI
 	if sess.OrchestratorInfo != nil {
@@ -402,6 +505,8 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 		}
 		ctx = clog.AddVal(ctx, "orchestrator", sess.OrchestratorInfo.Transcoder)
 	}
+	ctx, span := monitor.StartSpan(ctx, "SubmitSegment")
+	defer func() { monitor.EndSpan(span, err) }()
 
 	segCreds, err := genSegCreds(sess, seg, segPar, calcPerceptualHash)
 	if err != nil {
@@ -465,8 +570,18 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 		httpTimeout = time.Duration(params.TimeoutMultiplier) * httpTimeout
 	}
 
-	ctx, cancel := context.WithTimeout(clog.Clone(context.Background(), ctx), httpTimeout)
+	submitCancel := submitCancelled(ctx)
+	ctx, cancel := context.WithTimeout(trace.ContextWithSpan(clog.Clone(context.Background(), ctx), span), httpTimeout)
 	defer cancel()
+	if submitCancel != nil {
+		go func() {
+			select {
+			case <-submitCancel:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+	}
 
 	ti := sess.OrchestratorInfo
 
@@ -481,6 +596,7 @@ func SubmitSegment(ctx context.Context, sess *BroadcastSession, seg *stream.HLSS
 
 	req.Header.Set(segmentHeader, segCreds)
 	req.Header.Set(paymentHeader, payment)
+	monitor.InjectTraceHeaders(ctx, req.Header)
 	if uploaded {
 		req.Header.Set("Content-Type", "application/vnd+livepeer.uri")
 	} else {
@@ -641,6 +757,7 @@ func genSegCreds(sess *BroadcastSession, seg *stream.HLSSegment, segPar *core.Se
 		AuthToken:          sess.OrchestratorInfo.GetAuthToken(),
 		CalcPerceptualHash: calcPerceptualHash,
 		SegmentParameters:  segPar,
+		Priority:           params.Priority,
 	}
 	sig, err := sess.Broadcaster.Sign(md.Flatten())
 	if err != nil {
@@ -29,6 +29,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"golang.org/x/net/http2"
 )
 
@@ -396,6 +400,72 @@ func TestGenSegCreds_Profiles(t *testing.T) {
 	assert.Equal(expectedProfiles, segData.FullProfiles)
 }
 
+func TestGenSegCreds_Priority(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params: &core.StreamParameters{
+			ManifestID: core.RandomManifestID(),
+			Profiles:   []ffmpeg.VideoProfile{ffmpeg.P720p60fps16x9},
+			Priority:   core.PriorityHigh,
+		},
+	}
+
+	data, err := genSegCreds(s, &stream.HLSSegment{Data: []byte("foo")}, nil, false)
+	require.Nil(err)
+	buf, err := base64.StdEncoding.DecodeString(data)
+	require.Nil(err)
+	segData := net.SegData{}
+	require.Nil(proto.Unmarshal(buf, &segData))
+	assert.Equal(core.PriorityHigh, segData.Priority)
+
+	md, err := coreSegMetadata(&segData)
+	require.Nil(err)
+	assert.Equal(core.PriorityHigh, md.Priority)
+
+	// Priorities are clamped to the priority classes
+	segData.Priority = 100
+	md, err = coreSegMetadata(&segData)
+	require.Nil(err)
+	assert.Equal(core.PriorityHigh, md.Priority)
+}
+
+func TestPriorityPriceInfo(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	orch := &mockOrchestrator{}
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	sender := ethcommon.BytesToAddress([]byte("foo"))
+	price := &net.PriceInfo{PricePerUnit: 2, PixelsPerUnit: 1}
+	params := &net.TicketParams{Recipient: []byte("bar")}
+
+	// The price is unchanged without multipliers
+	oInfo := &net.OrchestratorInfo{PriceInfo: price}
+	require.Nil(priorityPriceInfo(orch, n, sender, oInfo, core.PriorityHigh))
+	assert.Equal(price, oInfo.PriceInfo)
+	assert.Nil(oInfo.TicketParams)
+
+	// The price of the priority class comes with ticket params for that price
+	n.PriorityPrices = map[int32]*big.Rat{core.PriorityHigh: big.NewRat(3, 2)}
+	classPrice := &net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 1}
+	orch.On("TicketParams", sender, classPrice).Return(params, nil).Once()
+	require.Nil(priorityPriceInfo(orch, n, sender, oInfo, core.PriorityHigh))
+	assert.Equal(classPrice, oInfo.PriceInfo)
+	assert.Equal(params, oInfo.TicketParams)
+
+	// Streams of other priorities pay the regular price
+	oInfo = &net.OrchestratorInfo{PriceInfo: price}
+	require.Nil(priorityPriceInfo(orch, n, sender, oInfo, core.PriorityNormal))
+	assert.Equal(price, oInfo.PriceInfo)
+
+	orch.On("TicketParams", sender, classPrice).Return(nil, errors.New("TicketParams error")).Once()
+	assert.EqualError(priorityPriceInfo(orch, n, sender, oInfo, core.PriorityHigh), "TicketParams error")
+	assert.Equal(price, oInfo.PriceInfo)
+	orch.AssertExpectations(t)
+}
+
 func TestCoreSegMetadata_FullProfiles(t *testing.T) {
 	assert := assert.New(t)
 
@@ -1329,6 +1399,189 @@ func TestServeSegment_DebitFees_SingleRendition(t *testing.T) {
 	orch.AssertCalled(t, "DebitFees", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId), mock.Anything, tData.Segments[0].Pixels)
 }
 
+func TestServeSegment_DebitFees_Cached(t *testing.T) {
+	orch := &mockOrchestrator{}
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.TranscodeCache = core.NewTranscodeCache(1024, 25)
+	lp := lphttp{orchestrator: orch, node: n}
+	handler := http.HandlerFunc(lp.ServeSegment)
+
+	require := require.New(t)
+
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	orch.On("AuthToken", mock.Anything, mock.Anything).Return(stubAuthToken)
+
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params: &core.StreamParameters{
+			ManifestID: core.RandomManifestID(),
+			Profiles: []ffmpeg.VideoProfile{
+				ffmpeg.P720p60fps16x9,
+			},
+		},
+		OrchestratorInfo: &net.OrchestratorInfo{AuthToken: stubAuthToken},
+	}
+	seg := &stream.HLSSegment{Data: []byte("foo")}
+	creds, err := genSegCreds(s, seg, nil, false)
+	require.Nil(err)
+
+	md, _, err := verifySegCreds(context.TODO(), orch, creds, ethcommon.Address{})
+	require.Nil(err)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	url, _ := url.Parse("foo")
+	orch.On("ServiceURI").Return(url)
+	orch.On("Address").Return(ethcommon.Address{})
+	orch.On("PriceInfo", mock.Anything).Return(&net.PriceInfo{}, nil)
+	orch.On("TicketParams", mock.Anything, mock.Anything).Return(&net.TicketParams{}, nil)
+	orch.On("ProcessPayment", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)).Return(nil)
+	orch.On("SufficientBalance", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)).Return(true)
+
+	tData := &core.TranscodeData{Segments: []*core.TranscodedSegmentData{{Data: []byte("foo"), Pixels: int64(1000)}}}
+	tRes := &core.TranscodeResult{
+		TranscodeData: tData,
+		Sig:           []byte("foo"),
+		OS:            drivers.NewMemoryDriver(nil).NewSession(""),
+		Cached:        true,
+	}
+	orch.On("TranscodeSeg", md, seg).Return(tRes, nil)
+	orch.On("DebitFees", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId), mock.Anything, int64(250))
+
+	headers := map[string]string{
+		paymentHeader: "",
+		segmentHeader: creds,
+	}
+	resp := httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	require.Nil(err)
+
+	var tr net.TranscodeResult
+	err = proto.Unmarshal(body, &tr)
+	require.Nil(err)
+
+	assert := assert.New(t)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	// The broadcaster receives the pixel counts of the cached results but is charged according to the cache fee policy
+	res, ok := tr.Result.(*net.TranscodeResult_Data)
+	assert.True(ok)
+	assert.Equal(1, len(res.Data.Segments))
+	assert.Equal(int64(1000), res.Data.Segments[0].Pixels)
+	orch.AssertCalled(t, "DebitFees", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId), mock.Anything, int64(250))
+}
+
+func TestServeSegment_DebitFees_PriorityClass(t *testing.T) {
+	orch := &mockOrchestrator{}
+	n, _ := core.NewLivepeerNode(nil, "", nil)
+	n.PriorityPrices = map[int32]*big.Rat{core.PriorityHigh: big.NewRat(3, 2)}
+	lp := lphttp{orchestrator: orch, node: n}
+	handler := http.HandlerFunc(lp.ServeSegment)
+
+	require := require.New(t)
+
+	orch.On("VerifySig", mock.Anything, mock.Anything, mock.Anything).Return(true)
+	orch.On("AuthToken", mock.Anything, mock.Anything).Return(stubAuthToken)
+
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params: &core.StreamParameters{
+			ManifestID: core.RandomManifestID(),
+			Profiles: []ffmpeg.VideoProfile{
+				ffmpeg.P720p60fps16x9,
+			},
+			Priority: core.PriorityHigh,
+		},
+		OrchestratorInfo: &net.OrchestratorInfo{AuthToken: stubAuthToken},
+	}
+	seg := &stream.HLSSegment{Data: []byte("foo")}
+	creds, err := genSegCreds(s, seg, nil, false)
+	require.Nil(err)
+
+	md, _, err := verifySegCreds(context.TODO(), orch, creds, ethcommon.Address{})
+	require.Nil(err)
+
+	drivers.NodeStorage = drivers.NewMemoryDriver(nil)
+	url, _ := url.Parse("foo")
+	orch.On("ServiceURI").Return(url)
+	orch.On("Address").Return(ethcommon.Address{})
+	orch.On("PriceInfo", mock.Anything).Return(&net.PriceInfo{PricePerUnit: 2, PixelsPerUnit: 1}, nil)
+	orch.On("TicketParams", mock.Anything, mock.Anything).Return(&net.TicketParams{}, nil)
+	orch.On("ProcessPayment", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)).Return(nil)
+	orch.On("SufficientBalance", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)).Return(true)
+
+	tData := &core.TranscodeData{Segments: []*core.TranscodedSegmentData{{Data: []byte("foo"), Pixels: int64(1000)}}}
+	tRes := &core.TranscodeResult{
+		TranscodeData: tData,
+		Sig:           []byte("foo"),
+		OS:            drivers.NewMemoryDriver(nil).NewSession(""),
+	}
+	orch.On("TranscodeSeg", md, seg).Return(tRes, nil)
+	classPrice := mock.MatchedBy(func(p *net.PriceInfo) bool { return p.PricePerUnit == 3 && p.PixelsPerUnit == 1 })
+	orch.On("DebitFees", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId), classPrice, int64(1000))
+
+	genPayment := func(price *net.PriceInfo) string {
+		payment := &net.Payment{ExpectedPrice: price, Sender: s.Broadcaster.Address().Bytes()}
+		data, err := proto.Marshal(payment)
+		require.Nil(err)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+
+	// The first segment of the stream is charged the price of its priority class without preempting another session
+	headers := map[string]string{
+		paymentHeader: genPayment(&net.PriceInfo{PricePerUnit: 2, PixelsPerUnit: 1}),
+		segmentHeader: creds,
+	}
+	resp := httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	resp.Body.Close()
+
+	assert := assert.New(t)
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	orch.AssertCalled(t, "DebitFees", mock.Anything, core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId), classPrice, int64(1000))
+	orch.AssertNumberOfCalls(t, "DebitFees", 1)
+
+	// Once the stream is transcoding payments expecting a price below the price of the class are rejected
+	n.SegmentChans[core.ManifestID(s.OrchestratorInfo.AuthToken.SessionId)] = make(core.SegmentChan)
+	resp = httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(http.StatusBadRequest, resp.StatusCode)
+	assert.Contains(string(body), "Expected price below the price of the priority class")
+	orch.AssertNumberOfCalls(t, "DebitFees", 1)
+
+	// Payments expecting the price of the class are charged the price of the class
+	headers[paymentHeader] = genPayment(&net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 1})
+	resp = httpPostResp(handler, bytes.NewReader(seg.Data), headers)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+	orch.AssertNumberOfCalls(t, "DebitFees", 2)
+}
+
+func TestPriceInfoBelow(t *testing.T) {
+	assert := assert.New(t)
+
+	low := &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 2}
+	high := &net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 1}
+	assert.True(priceInfoBelow(low, high))
+	assert.True(priceInfoBelow(nil, high))
+	assert.False(priceInfoBelow(high, low))
+	assert.False(priceInfoBelow(high, high))
+	assert.False(priceInfoBelow(nil, nil))
+	assert.False(priceInfoBelow(low, &net.PriceInfo{PricePerUnit: 0, PixelsPerUnit: 1}))
+}
+
+func TestMaxPriceInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	low := &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 2}
+	high := &net.PriceInfo{PricePerUnit: 3, PixelsPerUnit: 1}
+	assert.Equal(high, maxPriceInfo(low, high))
+	assert.Equal(high, maxPriceInfo(high, low))
+	assert.Equal(high, maxPriceInfo(nil, high))
+	assert.Equal(low, maxPriceInfo(low, &net.PriceInfo{PricePerUnit: 1}))
+}
+
 func TestServeSegment_DebitFees_MultipleRenditions(t *testing.T) {
 	orch := &mockOrchestrator{}
 	handler := serveSegmentHandler(orch)
@@ -2172,6 +2425,54 @@ func TestSubmitSegment_Success(t *testing.T) {
 	balance.AssertCalled(t, "Credit", ratMatcher(change))
 }
 
+func TestSubmitSegment_TraceContext(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	oldProvider, oldPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	defer func() {
+		otel.SetTracerProvider(oldProvider)
+		otel.SetTextMapPropagator(oldPropagator)
+	}()
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	buf, err := proto.Marshal(&net.TranscodeResult{
+		Info:   &net.OrchestratorInfo{Transcoder: "foo"},
+		Result: &net.TranscodeResult_Data{Data: &net.TranscodeData{}},
+	})
+	require.Nil(err)
+
+	var traceparent string
+	ts, mux := stubTLSServer()
+	defer ts.Close()
+	mux.HandleFunc("/segment", func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf)
+	})
+
+	s := &BroadcastSession{
+		Broadcaster: stubBroadcaster2(),
+		Params:      &core.StreamParameters{ManifestID: core.RandomManifestID()},
+		OrchestratorInfo: &net.OrchestratorInfo{
+			Transcoder: ts.URL,
+			PriceInfo:  &net.PriceInfo{PricePerUnit: 1, PixelsPerUnit: 1},
+			AuthToken:  stubAuthToken,
+		},
+	}
+	_, err = SubmitSegment(context.TODO(), s, &stream.HLSSegment{Data: []byte("dummy")}, nil, 0, false, true)
+	require.Nil(err)
+
+	// The orchestrator receives the context of the SubmitSegment span
+	spans := recorder.Ended()
+	require.Len(spans, 1)
+	assert.Equal("SubmitSegment", spans[0].Name())
+	sc := spans[0].SpanContext()
+	assert.Equal(fmt.Sprintf("00-%s-%s-01", sc.TraceID(), sc.SpanID()), traceparent)
+}
+
 func TestSendReqWithTimeout(t *testing.T) {
 	assert := assert.New(t)
 
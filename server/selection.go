@@ -197,7 +197,7 @@ func (s *MinLSSelector) selectUnknownSession(ctx context.Context) *BroadcastSess
 		s.perfScore.Mu.Lock()
 		perfScores = map[ethcommon.Address]float64{}
 		for _, addr := range addrs {
-			perfScores[addr] = s.perfScore.Scores[addr]
+			perfScores[addr] = s.perfScore.Score(addr)
 		}
 		s.perfScore.Mu.Unlock()
 	}
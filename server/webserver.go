@@ -49,6 +49,10 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/setBroadcastConfig", mustHaveFormParams(setBroadcastConfigHandler()))
 	mux.Handle("/getBroadcastConfig", getBroadcastConfigHandler())
 	mux.Handle("/getAvailableTranscodingOptions", getAvailableTranscodingOptionsHandler())
+	mux.Handle("/reserveCapacity", mustHaveFormParams(s.reserveCapacityHandler(), "orchestrator", "sessions", "start", "end"))
+	mux.Handle("/verificationEvidence", verificationEvidenceHandler(db))
+	mux.Handle("/orchestratorPenalties", orchestratorPenaltiesHandler(db))
+	mux.Handle("/clearOrchestratorPenalty", mustHaveFormParams(clearOrchestratorPenaltyHandler(db), "orchestrator"))
 
 	// Rounds
 	mux.Handle("/currentRound", currentRoundHandler(client))
@@ -61,6 +65,9 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/setMaxFaceValue", mustHaveFormParams(s.setMaxFaceValueHandler(), "maxfacevalue"))
 	mux.Handle("/setPriceForBroadcaster", mustHaveFormParams(s.setPriceForBroadcaster(), "pricePerUnit", "pixelsPerUnit", "broadcasterEthAddr"))
 	mux.Handle("/setMaxSessions", mustHaveFormParams(s.setMaxSessions(), "maxSessions"))
+	mux.Handle("/registeredTranscoders", s.registeredTranscodersHandler())
+	mux.Handle("/setTranscoderDraining", mustHaveFormParams(s.setTranscoderDrainingHandler(), "transcoder", "draining"))
+	mux.Handle("/capacityReservations", s.capacityReservationsHandler())
 
 	// Bond, withdraw, reward
 	mux.Handle("/bond", mustHaveFormParams(bondHandler(client), "amount", "toAddr"))
@@ -72,8 +79,11 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/claimEarnings", claimEarningsHandler(client))
 	mux.Handle("/delegatorInfo", delegatorInfoHandler(client))
 	mux.Handle("/orchestratorEarningPoolsForRound", orchestratorEarningPoolsForRoundHandler(client))
+	mux.Handle("/delegatorEarnings", delegatorEarningsHandler(client))
+	mux.Handle("/orchestratorYields", orchestratorYieldsHandler(client))
 	mux.Handle("/registeredOrchestrators", registeredOrchestratorsHandler(client, db))
 	mux.Handle("/reward", rewardHandler(client))
+	mux.Handle("/rewardEvents", s.rewardEventsHandler())
 
 	// Protocol parameters
 	mux.Handle("/protocolParameters", protocolParametersHandler(client, db))
@@ -87,6 +97,7 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/requestTokens", requestTokensHandler(client))
 	mux.Handle("/signMessage", mustHaveFormParams(signMessageHandler(client), "message"))
 	mux.Handle("/vote", mustHaveFormParams(voteHandler(client), "poll", "choiceID"))
+	mux.Handle("/polls", pollsHandler(client, db))
 
 	// Gas Price
 	mux.Handle("/setMaxGasPrice", mustHaveFormParams(setMaxGasPriceHandler(client), "amount"))
@@ -104,7 +115,7 @@ func (s *LivepeerServer) cliWebServerHandlers(bindAddr string) *http.ServeMux {
 	mux.Handle("/ticketBrokerParams", ticketBrokerParamsHandler(client))
 
 	// Debug, Log Level
-	mux.Handle("/setLogLevel", mustHaveFormParams(setLogLevelHandler(), "loglevel"))
+	mux.Handle("/setLogLevel", mustHaveFormParams(setLogLevelHandler()))
 	mux.Handle("/getLogLevel", getLogLevelHandler())
 	mux.Handle("/debug", s.debugHandler())
 
@@ -0,0 +1,175 @@
+package verification
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-tools/drivers"
+)
+
+var ErrMissingEvidence = errors.New("MissingEvidence")
+
+// Default duration an orchestrator is denied for every fatal verification failure
+const DefaultFailurePenalty = 24 * time.Hour
+
+// EvidenceTicketParams are the ticket params the orchestrator advertised
+// when it returned the results that failed verification
+type EvidenceTicketParams struct {
+	Recipient         string `json:"recipient"`
+	FaceValue         string `json:"faceValue"`
+	WinProb           string `json:"winProb"`
+	RecipientRandHash string `json:"recipientRandHash"`
+	Seed              string `json:"seed"`
+	ExpirationBlock   string `json:"expirationBlock"`
+}
+
+// Evidence is a record of transcoded results that failed verification.
+// It carries what is needed to prove the failure to a third party: the
+// hashes of the source and renditions that the orchestrator signed, the
+// signature itself and the ticket params the orchestrator was paid with.
+type Evidence struct {
+	Orchestrator    ethcommon.Address     `json:"orchestrator"`
+	Transcoder      string                `json:"transcoder"`
+	ManifestID      string                `json:"manifestID"`
+	SeqNo           uint64                `json:"seqNo"`
+	SourceHash      string                `json:"sourceHash"`
+	Profiles        []string              `json:"profiles"`
+	RenditionHashes []string              `json:"renditionHashes"`
+	Sig             string                `json:"sig"`
+	TicketParams    *EvidenceTicketParams `json:"ticketParams,omitempty"`
+	Reason          string                `json:"reason"`
+	Fatal           bool                  `json:"fatal"`
+	Timestamp       time.Time             `json:"timestamp"`
+}
+
+// NewEvidence builds an evidence record from the params of a verification
+// that failed with err. Hashes are Keccak256, as used for the signature
+// over the renditions.
+func NewEvidence(params *Params, err error) *Evidence {
+	e := &Evidence{
+		ManifestID: string(params.ManifestID),
+		Fatal:      IsFatal(err),
+		Timestamp:  time.Now(),
+	}
+	if err != nil {
+		e.Reason = err.Error()
+	}
+	if params.Source != nil {
+		e.SeqNo = params.Source.SeqNo
+		e.SourceHash = hex.EncodeToString(crypto.Keccak256(params.Source.Data))
+	}
+	for _, p := range params.Profiles {
+		e.Profiles = append(e.Profiles, p.Name)
+	}
+	for _, r := range params.Renditions {
+		e.RenditionHashes = append(e.RenditionHashes, hex.EncodeToString(crypto.Keccak256(r)))
+	}
+	if params.Results != nil {
+		e.Sig = hex.EncodeToString(params.Results.Sig)
+	}
+	if orch := params.Orchestrator; orch != nil {
+		e.Transcoder = orch.Transcoder
+		e.Orchestrator = ethcommon.BytesToAddress(orch.Address)
+		if tp := orch.TicketParams; tp != nil {
+			// Same fallback as the signature check
+			if (e.Orchestrator == ethcommon.Address{}) {
+				e.Orchestrator = ethcommon.BytesToAddress(tp.Recipient)
+			}
+			e.TicketParams = &EvidenceTicketParams{
+				Recipient:         ethcommon.BytesToAddress(tp.Recipient).Hex(),
+				FaceValue:         new(big.Int).SetBytes(tp.FaceValue).String(),
+				WinProb:           new(big.Int).SetBytes(tp.WinProb).String(),
+				RecipientRandHash: ethcommon.BytesToHash(tp.RecipientRandHash).Hex(),
+				Seed:              new(big.Int).SetBytes(tp.Seed).String(),
+				ExpirationBlock:   new(big.Int).SetBytes(tp.ExpirationBlock).String(),
+			}
+		}
+	}
+	return e
+}
+
+// EvidenceStore persists evidence of failed verifications
+type EvidenceStore interface {
+	StoreEvidence(ctx context.Context, e *Evidence) error
+}
+
+// DBEvidenceStore stores evidence in the node database. Orchestrators with
+// a fatal failure are penalized, which denies them from DB discovery for
+// Penalty, or DefaultFailurePenalty if unset. Penalties of repeated failures
+// add up.
+type DBEvidenceStore struct {
+	DB      *common.DB
+	Penalty time.Duration
+}
+
+func (s *DBEvidenceStore) StoreEvidence(ctx context.Context, e *Evidence) error {
+	if e == nil {
+		return ErrMissingEvidence
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	err = s.DB.InsertEvidence(&common.DBEvidence{
+		Orchestrator: e.Orchestrator,
+		ManifestID:   e.ManifestID,
+		SeqNo:        e.SeqNo,
+		Reason:       e.Reason,
+		Fatal:        e.Fatal,
+		Data:         data,
+		CreatedAt:    e.Timestamp,
+	})
+	if err != nil {
+		return err
+	}
+	if !e.Fatal || (e.Orchestrator == ethcommon.Address{}) {
+		return nil
+	}
+	penalty := s.Penalty
+	if penalty <= 0 {
+		penalty = DefaultFailurePenalty
+	}
+	return s.DB.PenalizeOrch(e.Orchestrator, penalty)
+}
+
+// OSEvidenceStore uploads evidence as JSON to an object store, under
+// <orchestrator>/<manifestID>/<seqNo>-<timestamp>.json
+type OSEvidenceStore struct {
+	OS drivers.OSSession
+}
+
+func (s *OSEvidenceStore) StoreEvidence(ctx context.Context, e *Evidence) error {
+	if e == nil {
+		return ErrMissingEvidence
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s/%s/%d-%d.json", e.Orchestrator.Hex(), e.ManifestID, e.SeqNo, e.Timestamp.UnixNano())
+	_, err = s.OS.SaveData(ctx, name, bytes.NewReader(data), nil, 0)
+	return err
+}
+
+// EvidenceStores stores evidence in each of its stores and returns the first error
+type EvidenceStores []EvidenceStore
+
+func (s EvidenceStores) StoreEvidence(ctx context.Context, e *Evidence) error {
+	var err error
+	for _, store := range s {
+		if serr := store.StoreEvidence(ctx, e); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
@@ -0,0 +1,170 @@
+package verification
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	ethcommon "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/livepeer/go-livepeer/common"
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-livepeer/pm"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+)
+
+func evidenceParams() *Params {
+	return &Params{
+		ManifestID: "foo",
+		Source:     &stream.HLSSegment{SeqNo: 7, Data: []byte("source")},
+		Profiles:   []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9, ffmpeg.P240p30fps16x9},
+		Renditions: [][]byte{[]byte("r0"), []byte("r1")},
+		Results:    &net.TranscodeData{Sig: []byte("sig")},
+		Orchestrator: &net.OrchestratorInfo{
+			Transcoder: "https://orch.lpt:8935",
+			TicketParams: &net.TicketParams{
+				Recipient:         pm.RandAddress().Bytes(),
+				FaceValue:         big.NewInt(100).Bytes(),
+				WinProb:           big.NewInt(5).Bytes(),
+				RecipientRandHash: pm.RandHash().Bytes(),
+				Seed:              big.NewInt(9).Bytes(),
+				ExpirationBlock:   big.NewInt(1000).Bytes(),
+			},
+		},
+	}
+}
+
+func TestEvidence_New(t *testing.T) {
+	assert := assert.New(t)
+
+	params := evidenceParams()
+	e := NewEvidence(params, ErrAudioMismatch)
+	assert.Equal("foo", e.ManifestID)
+	assert.Equal(uint64(7), e.SeqNo)
+	assert.Equal(hex.EncodeToString(crypto.Keccak256([]byte("source"))), e.SourceHash)
+	assert.Equal([]string{"P144p30fps16x9", "P240p30fps16x9"}, e.Profiles)
+	assert.Equal([]string{
+		hex.EncodeToString(crypto.Keccak256([]byte("r0"))),
+		hex.EncodeToString(crypto.Keccak256([]byte("r1"))),
+	}, e.RenditionHashes)
+	assert.Equal(hex.EncodeToString([]byte("sig")), e.Sig)
+	assert.Equal("https://orch.lpt:8935", e.Transcoder)
+	assert.Equal("AudioMismatch", e.Reason)
+	assert.True(e.Fatal)
+
+	// Without an orchestrator address, the ticket recipient is used
+	recipient := ethcommon.BytesToAddress(params.Orchestrator.TicketParams.Recipient)
+	assert.Equal(recipient, e.Orchestrator)
+	assert.Equal(&EvidenceTicketParams{
+		Recipient:         recipient.Hex(),
+		FaceValue:         "100",
+		WinProb:           "5",
+		RecipientRandHash: ethcommon.BytesToHash(params.Orchestrator.TicketParams.RecipientRandHash).Hex(),
+		Seed:              "9",
+		ExpirationBlock:   "1000",
+	}, e.TicketParams)
+
+	// The orchestrator address takes precedence
+	addr := pm.RandAddress()
+	params.Orchestrator.Address = addr.Bytes()
+	e = NewEvidence(params, ErrTampered)
+	assert.Equal(addr, e.Orchestrator)
+	assert.Equal("Tampered", e.Reason)
+	assert.False(e.Fatal)
+
+	// Missing fields are left empty
+	e = NewEvidence(&Params{}, ErrPixelMismatch)
+	assert.Equal(ethcommon.Address{}, e.Orchestrator)
+	assert.Nil(e.TicketParams)
+	assert.Empty(e.SourceHash)
+	assert.Empty(e.RenditionHashes)
+}
+
+func TestEvidence_DBStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	dbh, dbraw, err := common.TempDB(t)
+	require.Nil(err)
+	defer dbh.Close()
+	defer dbraw.Close()
+
+	store := &DBEvidenceStore{DB: dbh, Penalty: time.Hour}
+	assert.Equal(ErrMissingEvidence, store.StoreEvidence(context.Background(), nil))
+
+	// Non-fatal failures are recorded without a penalty
+	e := NewEvidence(evidenceParams(), ErrTampered)
+	require.Nil(store.StoreEvidence(context.Background(), e))
+	penalties, err := dbh.OrchPenalties()
+	require.Nil(err)
+	assert.Len(penalties, 0)
+
+	// Fatal failures penalize the orchestrator
+	e = NewEvidence(evidenceParams(), ErrAudioMismatch)
+	require.Nil(store.StoreEvidence(context.Background(), e))
+	penalties, err = dbh.OrchPenalties()
+	require.Nil(err)
+	require.Len(penalties, 1)
+	assert.Equal(e.Orchestrator, penalties[0].Orchestrator)
+	assert.WithinDuration(time.Now().Add(time.Hour), penalties[0].DeniedUntil, 2*time.Second)
+
+	// Records can be exported and decoded back
+	records, err := dbh.SelectEvidence(&common.DBEvidenceFilter{Orchestrator: &e.Orchestrator})
+	require.Nil(err)
+	require.Len(records, 1)
+	assert.Equal("AudioMismatch", records[0].Reason)
+	assert.True(records[0].Fatal)
+	var decoded Evidence
+	require.Nil(json.Unmarshal(records[0].Data, &decoded))
+	assert.Equal(e.RenditionHashes, decoded.RenditionHashes)
+	assert.Equal(e.TicketParams, decoded.TicketParams)
+	assert.True(e.Timestamp.Equal(decoded.Timestamp))
+}
+
+type stubEvidenceStore struct {
+	evidence []*Evidence
+	err      error
+}
+
+func (s *stubEvidenceStore) StoreEvidence(ctx context.Context, e *Evidence) error {
+	s.evidence = append(s.evidence, e)
+	return s.err
+}
+
+func TestEvidence_OSStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mem, ok := drivers.NewMemoryDriver(nil).NewSession("evidence").(*drivers.MemorySession)
+	require.True(ok)
+	store := &OSEvidenceStore{OS: mem}
+	assert.Equal(ErrMissingEvidence, store.StoreEvidence(context.Background(), nil))
+
+	e := NewEvidence(evidenceParams(), ErrAudioMismatch)
+	require.Nil(store.StoreEvidence(context.Background(), e))
+	data := mem.GetData(fmt.Sprintf("evidence/%s/foo/7-%d.json", e.Orchestrator.Hex(), e.Timestamp.UnixNano()))
+	require.NotNil(data)
+	var decoded Evidence
+	require.Nil(json.Unmarshal(data, &decoded))
+	assert.Equal(e.SourceHash, decoded.SourceHash)
+	assert.Equal(e.Orchestrator, decoded.Orchestrator)
+
+	// Evidence is stored in every store, and the first error is returned
+	s0 := &stubEvidenceStore{err: errors.New("s0")}
+	s1 := &stubEvidenceStore{err: errors.New("s1")}
+	err := EvidenceStores{s0, s1}.StoreEvidence(context.Background(), e)
+	assert.EqualError(err, "s0")
+	assert.Equal([]*Evidence{e}, s0.evidence)
+	assert.Equal([]*Evidence{e}, s1.evidence)
+}
@@ -0,0 +1,381 @@
+package verification
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/livepeer/go-livepeer/common"
+
+	"github.com/livepeer/lpms/ffmpeg"
+)
+
+var ErrLowQuality = Retryable{errors.New("LowQuality")}
+var ErrMissingRenditions = errors.New("MissingRenditions")
+var ErrVMAFUnavailable = errors.New("VMAF thresholds require a VMAF scorer")
+
+// Frame rate at which frames are sampled from the source and renditions
+const defaultQualitySampleFPS = 2
+
+// Upper bound on the number of sampled frames compared per rendition
+const defaultQualityMaxFrames = 8
+
+// QualityThresholds are the minimum scores a rendition must reach when
+// compared against the source. Zero values disable the respective check.
+type QualityThresholds struct {
+	SSIM float64
+	PSNR float64
+	VMAF float64
+}
+
+// VMAFScorer computes the VMAF score of a distorted sequence of raw frames
+// against a reference sequence of the same geometry. It is optional, since
+// VMAF needs libvmaf, which is not part of the default FFmpeg build.
+type VMAFScorer interface {
+	VMAF(ref, dist []byte, width, height, frames int) (float64, error)
+}
+
+// QualityVerifier is an in-process Verifier that decodes a sample of frames
+// from the source and each rendition and compares them with SSIM and PSNR
+// on the luma plane (and VMAF when a scorer is available). Renditions that
+// score below the thresholds of their profile fail with ErrLowQuality.
+type QualityVerifier struct {
+	// Thresholds applied to every profile without an entry in Profiles
+	Default QualityThresholds
+
+	// Thresholds by profile name
+	Profiles map[string]QualityThresholds
+
+	// Frame rate at which frames are sampled; defaults to 2 fps
+	SampleFPS uint
+
+	// Maximum number of frames compared per rendition; defaults to 8
+	MaxFrames int
+
+	// Optional VMAF scorer; ParseQualityThresholds rejects VMAF thresholds
+	// without one
+	VMAF VMAFScorer
+}
+
+type qualityScores struct {
+	ssim, psnr, vmaf float64
+}
+
+func (q *QualityVerifier) thresholds(profile string) QualityThresholds {
+	if t, ok := q.Profiles[profile]; ok {
+		return t
+	}
+	return q.Default
+}
+
+func (q *QualityVerifier) Verify(params *Params) (*Results, error) {
+	if params.Source == nil || len(params.Source.Data) <= 0 {
+		return nil, ErrMissingSource
+	}
+	if len(params.Renditions) != len(params.Profiles) {
+		return nil, ErrMissingRenditions
+	}
+	glog.V(common.DEBUG).Infof("Verifying segment quality manifestID=%s seqNo=%d",
+		params.ManifestID, params.Source.SeqNo)
+
+	dir, err := ioutil.TempDir("", "quality")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp dir for quality verification: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	srcPath := filepath.Join(dir, "source")
+	if err := ioutil.WriteFile(srcPath, params.Source.Data, 0644); err != nil {
+		return nil, fmt.Errorf("error writing source for quality verification: %w", err)
+	}
+
+	startTime := time.Now()
+	var (
+		score  float64
+		pixels = make([]int64, len(params.Renditions))
+	)
+	// As with the Epic classifier, gather scores and pixel counts for all
+	// renditions even if one fails, and only return the first error.
+	var verr error
+	for i, data := range params.Renditions {
+		p := params.Profiles[i]
+		renditionPath := filepath.Join(dir, fmt.Sprintf("rendition%d", i))
+		if err := ioutil.WriteFile(renditionPath, data, 0644); err != nil {
+			return nil, fmt.Errorf("error writing rendition for quality verification: %w", err)
+		}
+		scores, px, err := q.compare(srcPath, renditionPath)
+		if err != nil && !IsRetryable(err) {
+			return nil, err
+		}
+		pixels[i] = px
+		if err == nil {
+			err = scores.check(q.thresholds(p.Name), q.VMAF != nil)
+		}
+		glog.V(common.DEBUG).Infof("Segment quality manifestID=%s seqNo=%d profile=%s ssim=%.4f psnr=%.2f vmaf=%.2f err=%q",
+			params.ManifestID, params.Source.SeqNo, p.Name, scores.ssim, scores.psnr, scores.vmaf, err)
+		if err != nil && verr == nil {
+			verr = err
+		}
+		score += scores.ssim / float64(len(params.Renditions))
+	}
+	glog.Infof("Quality verification complete manifestID=%s seqNo=%d err=%q dur=%v",
+		params.ManifestID, params.Source.SeqNo, verr, time.Since(startTime))
+	return &Results{Score: score, Pixels: pixels}, verr
+}
+
+func (s qualityScores) check(t QualityThresholds, hasVMAF bool) error {
+	if t.SSIM > 0 && s.ssim < t.SSIM {
+		return ErrLowQuality
+	}
+	if t.PSNR > 0 && s.psnr < t.PSNR {
+		return ErrLowQuality
+	}
+	if hasVMAF && t.VMAF > 0 && s.vmaf < t.VMAF {
+		return ErrLowQuality
+	}
+	return nil
+}
+
+// compare decodes sampled frames of the source scaled to the geometry of
+// the rendition and of the rendition itself, and scores them. It also
+// returns the number of pixels decoded from the rendition.
+func (q *QualityVerifier) compare(srcPath, renditionPath string) (qualityScores, int64, error) {
+	var scores qualityScores
+	_, info, err := ffmpeg.GetCodecInfo(renditionPath)
+	if err != nil {
+		return scores, 0, err
+	}
+	w, h := info.Width, info.Height
+	if w <= 0 || h <= 0 {
+		return scores, 0, ErrLowQuality
+	}
+
+	dist, distFrames, px, err := q.decodeFrames(renditionPath, renditionPath+".yuv", w, h)
+	if err != nil {
+		return scores, 0, err
+	}
+	ref, refFrames, _, err := q.decodeFrames(srcPath, renditionPath+".src.yuv", w, h)
+	if err != nil {
+		return scores, px, err
+	}
+	if distFrames <= 0 || refFrames <= 0 {
+		return scores, px, ErrLowQuality
+	}
+
+	// Frames are raw planar 8-bit YUV with the luma plane first. A rendition
+	// whose frames differ in size from the scaled source has a different
+	// geometry or pixel format, which is never a faithful transcode.
+	frameSize := len(dist) / distFrames
+	if frameSize < w*h || len(dist)%distFrames != 0 || len(ref)/refFrames != frameSize {
+		return scores, px, ErrLowQuality
+	}
+	frames := distFrames
+	if refFrames < frames {
+		frames = refFrames
+	}
+	maxFrames := q.MaxFrames
+	if maxFrames <= 0 {
+		maxFrames = defaultQualityMaxFrames
+	}
+	if frames > maxFrames {
+		frames = maxFrames
+	}
+
+	for i := 0; i < frames; i++ {
+		refY := ref[i*frameSize : i*frameSize+w*h]
+		distY := dist[i*frameSize : i*frameSize+w*h]
+		scores.ssim += ssim(refY, distY, w, h) / float64(frames)
+		scores.psnr += math.Min(psnr(refY, distY), maxPSNR) / float64(frames)
+	}
+	if q.VMAF != nil {
+		scores.vmaf, err = q.VMAF.VMAF(ref[:frames*frameSize], dist[:frames*frameSize], w, h, frames)
+		if err != nil {
+			return scores, px, err
+		}
+	}
+	return scores, px, nil
+}
+
+// decodeFrames decodes frames sampled at SampleFPS into raw video scaled to
+// w x h, and returns the frames along with their count and decoded pixels.
+func (q *QualityVerifier) decodeFrames(fname, oname string, w, h int) ([]byte, int, int64, error) {
+	fps := q.SampleFPS
+	if fps <= 0 {
+		fps = defaultQualitySampleFPS
+	}
+	in := &ffmpeg.TranscodeOptionsIn{Fname: fname}
+	out := []ffmpeg.TranscodeOptions{{
+		Oname: oname,
+		Profile: ffmpeg.VideoProfile{
+			Name:       "quality",
+			Resolution: fmt.Sprintf("%dx%d", w, h),
+			Bitrate:    "0",
+			Framerate:  fps,
+		},
+		Muxer:        ffmpeg.ComponentOptions{Name: "rawvideo"},
+		VideoEncoder: ffmpeg.ComponentOptions{Name: "rawvideo"},
+		AudioEncoder: ffmpeg.ComponentOptions{Name: "drop"},
+	}}
+	res, err := ffmpeg.Transcode3(in, out)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	data, err := ioutil.ReadFile(oname)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	frames := 0
+	if len(res.Encoded) > 0 {
+		frames = res.Encoded[0].Frames
+	}
+	return data, frames, res.Decoded.Pixels, nil
+}
+
+// PSNR of identical frames is infinite; cap it so scores can be averaged
+const maxPSNR = 100.0
+
+// psnr returns the peak signal-to-noise ratio in dB of two 8-bit planes
+func psnr(ref, dist []byte) float64 {
+	if len(ref) <= 0 || len(ref) != len(dist) {
+		return 0
+	}
+	var sse float64
+	for i := range ref {
+		d := float64(ref[i]) - float64(dist[i])
+		sse += d * d
+	}
+	if sse == 0 {
+		return math.Inf(1)
+	}
+	mse := sse / float64(len(ref))
+	return 10 * math.Log10(255*255/mse)
+}
+
+// Block size of the windows over which SSIM is computed
+const ssimBlock = 8
+
+// ssim returns the mean structural similarity of two 8-bit planes of size
+// w x h, computed over non-overlapping 8x8 windows
+func ssim(ref, dist []byte, w, h int) float64 {
+	if w*h <= 0 || len(ref) < w*h || len(dist) < w*h {
+		return 0
+	}
+	const (
+		c1 = (0.01 * 255) * (0.01 * 255)
+		c2 = (0.03 * 255) * (0.03 * 255)
+	)
+	var (
+		total  float64
+		blocks int
+	)
+	for by := 0; by+ssimBlock <= h; by += ssimBlock {
+		for bx := 0; bx+ssimBlock <= w; bx += ssimBlock {
+			var sumR, sumD, sumRR, sumDD, sumRD float64
+			for y := by; y < by+ssimBlock; y++ {
+				for x := bx; x < bx+ssimBlock; x++ {
+					r, d := float64(ref[y*w+x]), float64(dist[y*w+x])
+					sumR += r
+					sumD += d
+					sumRR += r * r
+					sumDD += d * d
+					sumRD += r * d
+				}
+			}
+			n := float64(ssimBlock * ssimBlock)
+			muR, muD := sumR/n, sumD/n
+			varR := sumRR/n - muR*muR
+			varD := sumDD/n - muD*muD
+			cov := sumRD/n - muR*muD
+			total += ((2*muR*muD + c1) * (2*cov + c2)) /
+				((muR*muR + muD*muD + c1) * (varR + varD + c2))
+			blocks++
+		}
+	}
+	if blocks <= 0 {
+		return 0
+	}
+	return total / float64(blocks)
+}
+
+// ParseQualityThresholds parses a comma separated list of thresholds into a
+// QualityVerifier that scores VMAF with the given scorer. Entries are
+// metric=value, optionally prefixed with a profile name and a colon to only
+// apply to that profile, eg ssim=0.9,psnr=30,P144p30fps16x9:psnr=25. VMAF
+// thresholds are rejected when the scorer is nil.
+func ParseQualityThresholds(s string, vmaf VMAFScorer) (*QualityVerifier, error) {
+	q := &QualityVerifier{Profiles: make(map[string]QualityThresholds), VMAF: vmaf}
+	// Profile specific thresholds inherit the defaults, so parse those first
+	var profileEntries []string
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.Contains(entry, ":") {
+			profileEntries = append(profileEntries, entry)
+			continue
+		}
+		if err := setQualityThreshold(&q.Default, entry); err != nil {
+			return nil, err
+		}
+	}
+	for _, entry := range profileEntries {
+		kv := strings.SplitN(entry, ":", 2)
+		name := strings.TrimSpace(kv[0])
+		if name == "" {
+			return nil, fmt.Errorf("missing profile name in quality threshold %q", entry)
+		}
+		t, ok := q.Profiles[name]
+		if !ok {
+			t = q.Default
+		}
+		if err := setQualityThreshold(&t, kv[1]); err != nil {
+			return nil, err
+		}
+		q.Profiles[name] = t
+	}
+	if vmaf == nil {
+		if q.Default.VMAF > 0 {
+			return nil, ErrVMAFUnavailable
+		}
+		for _, t := range q.Profiles {
+			if t.VMAF > 0 {
+				return nil, ErrVMAFUnavailable
+			}
+		}
+	}
+	return q, nil
+}
+
+func setQualityThreshold(t *QualityThresholds, entry string) error {
+	kv := strings.SplitN(entry, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("invalid quality threshold %q", entry)
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64)
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid value for quality threshold %q", entry)
+	}
+	switch strings.ToLower(strings.TrimSpace(kv[0])) {
+	case "ssim":
+		if v > 1 {
+			return fmt.Errorf("SSIM threshold must be at most 1, got %v", v)
+		}
+		t.SSIM = v
+	case "psnr":
+		t.PSNR = v
+	case "vmaf":
+		t.VMAF = v
+	default:
+		return fmt.Errorf("unknown quality metric in %q", entry)
+	}
+	return nil
+}
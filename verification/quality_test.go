@@ -0,0 +1,130 @@
+package verification
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+)
+
+func qualityTestPlane(w, h int, f func(x, y int) byte) []byte {
+	plane := make([]byte, w*h)
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			plane[y*w+x] = f(x, y)
+		}
+	}
+	return plane
+}
+
+func TestQuality_PSNR(t *testing.T) {
+	assert := assert.New(t)
+
+	ref := qualityTestPlane(16, 16, func(x, y int) byte { return byte(x * y) })
+	assert.True(math.IsInf(psnr(ref, ref), 1))
+
+	// A constant offset of 1 gives an MSE of 1
+	dist := qualityTestPlane(16, 16, func(x, y int) byte { return byte(x*y) + 1 })
+	assert.InDelta(10*math.Log10(255*255), psnr(ref, dist), 1e-9)
+
+	// Mismatched or empty planes score 0
+	assert.Equal(0.0, psnr(ref, dist[1:]))
+	assert.Equal(0.0, psnr(nil, nil))
+}
+
+func TestQuality_SSIM(t *testing.T) {
+	assert := assert.New(t)
+
+	w, h := 32, 16
+	ref := qualityTestPlane(w, h, func(x, y int) byte { return byte((x*7 + y*13) % 256) })
+	assert.InDelta(1.0, ssim(ref, ref, w, h), 1e-9)
+
+	// Black output is far from the source
+	black := make([]byte, w*h)
+	assert.Less(ssim(ref, black, w, h), 0.1)
+
+	// Slightly noisy output stays close to the source
+	noisy := qualityTestPlane(w, h, func(x, y int) byte { return byte((x*7+y*13)%256) ^ byte((x+y)%2) })
+	assert.Greater(ssim(ref, noisy, w, h), 0.9)
+
+	// Planes too small for a single window, or short planes, score 0
+	assert.Equal(0.0, ssim(ref[:16], ref[:16], 4, 4))
+	assert.Equal(0.0, ssim(ref[:10], ref, w, h))
+}
+
+func TestQuality_Check(t *testing.T) {
+	assert := assert.New(t)
+
+	s := qualityScores{ssim: 0.9, psnr: 30, vmaf: 70}
+	assert.Nil(s.check(QualityThresholds{}, true))
+	assert.Nil(s.check(QualityThresholds{SSIM: 0.9, PSNR: 30, VMAF: 70}, true))
+	assert.Equal(ErrLowQuality, s.check(QualityThresholds{SSIM: 0.95}, true))
+	assert.Equal(ErrLowQuality, s.check(QualityThresholds{PSNR: 35}, true))
+	assert.Equal(ErrLowQuality, s.check(QualityThresholds{VMAF: 80}, true))
+
+	// VMAF thresholds are ignored without a scorer
+	assert.Nil(s.check(QualityThresholds{VMAF: 80}, false))
+
+	// Low quality results are retryable but not fatal
+	assert.True(IsRetryable(ErrLowQuality))
+	assert.False(IsFatal(ErrLowQuality))
+}
+
+func TestQuality_Thresholds(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	q, err := ParseQualityThresholds("", nil)
+	require.Nil(err)
+	assert.Equal(QualityThresholds{}, q.Default)
+	assert.Empty(q.Profiles)
+
+	// Profile thresholds inherit the defaults regardless of order
+	scorer := &stubVMAFScorer{}
+	q, err = ParseQualityThresholds("P144p30fps16x9:psnr=25, ssim=0.9,PSNR=30,vmaf=60,P720p30fps16x9:ssim=0.95", scorer)
+	require.Nil(err)
+	assert.Equal(scorer, q.VMAF)
+	assert.Equal(QualityThresholds{SSIM: 0.9, PSNR: 30, VMAF: 60}, q.Default)
+	assert.Equal(QualityThresholds{SSIM: 0.9, PSNR: 25, VMAF: 60}, q.Profiles["P144p30fps16x9"])
+	assert.Equal(QualityThresholds{SSIM: 0.95, PSNR: 30, VMAF: 60}, q.Profiles["P720p30fps16x9"])
+	assert.Equal(q.Default, q.thresholds("P360p30fps16x9"))
+	assert.Equal(q.Profiles["P144p30fps16x9"], q.thresholds("P144p30fps16x9"))
+
+	for _, s := range []string{"ssim", "ssim=", "ssim=abc", "ssim=1.5", "psnr=-1", "foo=1", ":ssim=0.9", "P144p30fps16x9:ssim"} {
+		_, err = ParseQualityThresholds(s, nil)
+		assert.NotNil(err, s)
+	}
+
+	// VMAF thresholds are rejected without a scorer
+	for _, s := range []string{"vmaf=60", "ssim=0.9,P144p30fps16x9:vmaf=60"} {
+		_, err = ParseQualityThresholds(s, nil)
+		assert.Equal(ErrVMAFUnavailable, err, s)
+	}
+	q, err = ParseQualityThresholds("vmaf=0,ssim=0.9", nil)
+	require.Nil(err)
+	assert.Nil(q.VMAF)
+}
+
+type stubVMAFScorer struct{}
+
+func (s *stubVMAFScorer) VMAF(ref, dist []byte, width, height, frames int) (float64, error) {
+	return 100, nil
+}
+
+func TestQuality_MissingInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	q := &QualityVerifier{}
+	_, err := q.Verify(&Params{})
+	assert.Equal(ErrMissingSource, err)
+
+	_, err = q.Verify(&Params{
+		Source:   &stream.HLSSegment{Data: []byte("source")},
+		Profiles: []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9},
+	})
+	assert.Equal(ErrMissingRenditions, err)
+}
@@ -0,0 +1,196 @@
+package verification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/golang/glog"
+
+	"github.com/livepeer/go-livepeer/core"
+	"github.com/livepeer/go-tools/drivers"
+
+	"github.com/livepeer/lpms/ffmpeg"
+)
+
+var ErrMissingSignature = Retryable{errors.New("MissingSignature")}
+var ErrSignatureMismatch = Retryable{errors.New("SignatureMismatch")}
+var ErrVideoMismatch = Retryable{errors.New("VideoMismatch")}
+
+// SignatureVerifier is an in-process Verifier that does fast verification
+// without a trusted orchestrator. It re-transcodes a random rendition of the
+// segment on the gateway and compares the MPEG-7 video signature of the local
+// rendition with the one computed by the orchestrator. When the signatures
+// match, the videos themselves are compared as well. The orchestrator must
+// have been asked to compute signatures, see Policy.NeedsSignatures.
+type SignatureVerifier struct {
+	// Acceleration used for the local transcode
+	Accel ffmpeg.Acceleration
+
+	// Optional object store for the segments that fail verification
+	FailStore drivers.OSSession
+
+	// Overridable for tests
+	getData   func(ctx context.Context, uri string) ([]byte, error)
+	transcode func(source []byte, profile ffmpeg.VideoProfile, accel ffmpeg.Acceleration) ([]byte, []byte, error)
+}
+
+func (v *SignatureVerifier) Verify(params *Params) (*Results, error) {
+	if params.Source == nil || len(params.Source.Data) <= 0 {
+		return nil, ErrMissingSource
+	}
+	if len(params.Renditions) != len(params.Profiles) || len(params.Profiles) == 0 ||
+		params.Results == nil || len(params.Results.Segments) != len(params.Profiles) {
+		return nil, ErrMissingRenditions
+	}
+	getData, transcode := v.getData, v.transcode
+	if getData == nil {
+		getData = core.GetSegmentData
+	}
+	if transcode == nil {
+		transcode = transcodeWithSignature
+	}
+
+	ctx := context.Background()
+	startTime := time.Now()
+	i := rand.Intn(len(params.Profiles))
+	p := params.Profiles[i]
+	res := &Results{}
+
+	hashURL := params.Results.Segments[i].PerceptualHashUrl
+	if hashURL == "" {
+		return res, ErrMissingSignature
+	}
+	hash, err := getData(ctx, hashURL)
+	if err != nil || len(hash) <= 0 {
+		glog.Errorf("Error downloading signature manifestID=%s seqNo=%d url=%s err=%q",
+			params.ManifestID, params.Source.SeqNo, hashURL, err)
+		return res, ErrMissingSignature
+	}
+
+	local, localHash, err := transcode(params.Source.Data, p, v.Accel)
+	if err != nil {
+		return nil, fmt.Errorf("error transcoding segment for signature verification: %w", err)
+	}
+
+	equal, err := ffmpeg.CompareSignatureByBuffer(localHash, hash)
+	if err != nil {
+		glog.Errorf("Error comparing signatures manifestID=%s seqNo=%d profile=%s err=%q",
+			params.ManifestID, params.Source.SeqNo, p.Name, err)
+	}
+	if !equal {
+		v.saveFailure(params, hashURL, localHash, hash, "phase1.hash")
+		glog.Infof("Signature verification failed manifestID=%s seqNo=%d profile=%s dur=%v",
+			params.ManifestID, params.Source.SeqNo, p.Name, time.Since(startTime))
+		return res, ErrSignatureMismatch
+	}
+
+	vequal, err := ffmpeg.CompareVideoByBuffer(local, params.Renditions[i])
+	if err != nil {
+		return nil, fmt.Errorf("error comparing video for signature verification: %w", err)
+	}
+	if !vequal {
+		v.saveFailure(params, params.Results.Segments[i].Url, local, params.Renditions[i], "phase2.ts")
+		glog.Infof("Video verification failed manifestID=%s seqNo=%d profile=%s dur=%v",
+			params.ManifestID, params.Source.SeqNo, p.Name, time.Since(startTime))
+		return res, ErrVideoMismatch
+	}
+
+	glog.Infof("Signature verification complete manifestID=%s seqNo=%d profile=%s dur=%v",
+		params.ManifestID, params.Source.SeqNo, p.Name, time.Since(startTime))
+	res.Score = 1
+	return res, nil
+}
+
+func (v *SignatureVerifier) saveFailure(params *Params, untrustedURI string, trusted, untrusted []byte, suffix string) {
+	if v.FailStore == nil {
+		return
+	}
+	go func() {
+		err := SaveFailedPair(context.Background(), v.FailStore, "", trusted, untrustedURI, untrusted, suffix, params.Source.Data)
+		if err != nil {
+			glog.Errorf("Error saving failed signature verification manifestID=%s seqNo=%d err=%q",
+				params.ManifestID, params.Source.SeqNo, err)
+		}
+	}()
+}
+
+// transcodeWithSignature transcodes the source to the profile and returns
+// the rendition along with its MPEG-7 signature
+func transcodeWithSignature(source []byte, profile ffmpeg.VideoProfile, accel ffmpeg.Acceleration) ([]byte, []byte, error) {
+	dir, err := ioutil.TempDir("", "signature")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	srcPath := filepath.Join(dir, "source")
+	if err := ioutil.WriteFile(srcPath, source, 0644); err != nil {
+		return nil, nil, err
+	}
+	// Same options as the orchestrator, see core.profilesToTranscodeOptions
+	oname := filepath.Join(dir, "out.tempfile")
+	_, err = ffmpeg.Transcode3(&ffmpeg.TranscodeOptionsIn{Fname: srcPath, Accel: accel}, []ffmpeg.TranscodeOptions{{
+		Oname:        oname,
+		Profile:      profile,
+		Accel:        accel,
+		AudioEncoder: ffmpeg.ComponentOptions{Name: "copy"},
+		CalcSign:     true,
+	}})
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := ioutil.ReadFile(oname)
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := ioutil.ReadFile(oname + ".bin")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, sig, nil
+}
+
+// SaveFailedPair uploads the artifacts of a failed fast verification to an
+// object store: the trusted and untrusted data, named after the host they
+// were retrieved from, and the source segment if any. An empty trusted URI
+// denotes data produced locally by the gateway.
+func SaveFailedPair(ctx context.Context, store drivers.OSSession, trustedURI string, trusted []byte,
+	untrustedURI string, untrusted []byte, suffix string, src []byte) error {
+
+	pfx := strconv.Itoa(rand.Int()) + "-"
+	names := []string{
+		pfx + failedPairHost(trustedURI) + "-trust-" + suffix,
+		pfx + failedPairHost(untrustedURI) + "-untrust-" + suffix,
+	}
+	datas := [][]byte{trusted, untrusted}
+	if src != nil {
+		names = append(names, pfx+"source.ts")
+		datas = append(datas, src)
+	}
+	for i, name := range names {
+		if _, err := store.SaveData(ctx, name, bytes.NewReader(datas[i]), nil, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func failedPairHost(uri string) string {
+	if uri == "" {
+		return "local"
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return "unknown"
+	}
+	return u.Host
+}
@@ -0,0 +1,176 @@
+package verification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livepeer/go-livepeer/net"
+	"github.com/livepeer/go-tools/drivers"
+	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
+)
+
+func signatureParams() *Params {
+	return &Params{
+		ManifestID: "foo",
+		Source:     &stream.HLSSegment{SeqNo: 3, Data: []byte("source")},
+		Profiles:   []ffmpeg.VideoProfile{ffmpeg.P144p30fps16x9},
+		Renditions: [][]byte{[]byte("rendition")},
+		Results: &net.TranscodeData{Segments: []*net.TranscodedSegmentData{{
+			Url:               "https://orch.lpt/stream/foo/P144p30fps16x9/3.ts",
+			PerceptualHashUrl: "https://orch.lpt/stream/foo/P144p30fps16x9/3.ts.phash",
+		}}},
+	}
+}
+
+func memoryFiles(mem *drivers.MemorySession, prefix string) []string {
+	var names []string
+	pi, err := mem.ListFiles(context.Background(), prefix, "")
+	if err != nil {
+		return nil
+	}
+	for _, f := range pi.Files() {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+func TestSignature_MissingInputs(t *testing.T) {
+	assert := assert.New(t)
+
+	v := &SignatureVerifier{}
+	_, err := v.Verify(&Params{})
+	assert.Equal(ErrMissingSource, err)
+
+	params := signatureParams()
+	params.Renditions = nil
+	_, err = v.Verify(params)
+	assert.Equal(ErrMissingRenditions, err)
+
+	params = signatureParams()
+	params.Results = nil
+	_, err = v.Verify(params)
+	assert.Equal(ErrMissingRenditions, err)
+}
+
+func TestSignature_MissingSignature(t *testing.T) {
+	assert := assert.New(t)
+
+	var fetched []string
+	v := &SignatureVerifier{
+		getData: func(ctx context.Context, uri string) ([]byte, error) {
+			fetched = append(fetched, uri)
+			return nil, errors.New("not found")
+		},
+	}
+
+	// Orchestrators that did not compute a signature can be retried
+	params := signatureParams()
+	params.Results.Segments[0].PerceptualHashUrl = ""
+	res, err := v.Verify(params)
+	assert.Equal(ErrMissingSignature, err)
+	assert.NotNil(res)
+	assert.Empty(fetched)
+
+	// As can orchestrators whose signature can't be downloaded
+	params = signatureParams()
+	res, err = v.Verify(params)
+	assert.Equal(ErrMissingSignature, err)
+	assert.NotNil(res)
+	assert.Equal([]string{params.Results.Segments[0].PerceptualHashUrl}, fetched)
+	assert.True(IsRetryable(err))
+	assert.False(IsFatal(err))
+}
+
+func TestSignature_TranscodeError(t *testing.T) {
+	v := &SignatureVerifier{
+		getData: func(ctx context.Context, uri string) ([]byte, error) { return []byte("sig"), nil },
+		transcode: func(source []byte, profile ffmpeg.VideoProfile, accel ffmpeg.Acceleration) ([]byte, []byte, error) {
+			return nil, nil, errors.New("transcode failed")
+		},
+	}
+
+	// Local failures are not the orchestrator's fault, so aren't retryable
+	_, err := v.Verify(signatureParams())
+	assert.EqualError(t, err, "error transcoding segment for signature verification: transcode failed")
+	assert.False(t, IsRetryable(err))
+}
+
+func TestSignature_Mismatch(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mem, ok := drivers.NewMemoryDriver(nil).NewSession("fvfail").(*drivers.MemorySession)
+	require.True(ok)
+	var transcoded ffmpeg.VideoProfile
+	v := &SignatureVerifier{
+		FailStore: mem,
+		getData:   func(ctx context.Context, uri string) ([]byte, error) { return []byte("remote signature"), nil },
+		transcode: func(source []byte, profile ffmpeg.VideoProfile, accel ffmpeg.Acceleration) ([]byte, []byte, error) {
+			transcoded = profile
+			return []byte("local"), []byte("local signature"), nil
+		},
+	}
+
+	res, err := v.Verify(signatureParams())
+	assert.Equal(ErrSignatureMismatch, err)
+	require.NotNil(res)
+	assert.Equal(0.0, res.Score)
+	assert.Equal(ffmpeg.P144p30fps16x9, transcoded)
+
+	// The signatures and the source are stored in the background
+	var names []string
+	assert.Eventually(func() bool {
+		names = memoryFiles(mem, "fvfail/")
+		return len(names) == 3
+	}, time.Second, 10*time.Millisecond)
+	for _, name := range names {
+		switch {
+		case strings.HasSuffix(name, "-local-trust-phase1.hash"):
+			assert.Equal([]byte("local signature"), mem.GetData(name))
+		case strings.HasSuffix(name, "-orch.lpt-untrust-phase1.hash"):
+			assert.Equal([]byte("remote signature"), mem.GetData(name))
+		case strings.HasSuffix(name, "-source.ts"):
+			assert.Equal([]byte("source"), mem.GetData(name))
+		default:
+			assert.Fail("unexpected file", name)
+		}
+	}
+}
+
+func TestSignature_SaveFailedPair(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	mem, ok := drivers.NewMemoryDriver(nil).NewSession("fvfail").(*drivers.MemorySession)
+	require.True(ok)
+
+	// The source is optional
+	err := SaveFailedPair(context.Background(), mem, "https://trusted.lpt/a.ts", []byte("a"), "not a url", []byte("b"), "phase2.ts", nil)
+	require.Nil(err)
+	names := memoryFiles(mem, "fvfail/")
+	require.Len(names, 2)
+	pfx := strings.SplitN(strings.TrimPrefix(names[0], "fvfail/"), "-", 2)[0]
+	assert.ElementsMatch([]string{
+		"fvfail/" + pfx + "-trusted.lpt-trust-phase2.ts",
+		"fvfail/" + pfx + "-unknown-untrust-phase2.ts",
+	}, names)
+	assert.Equal([]byte("a"), mem.GetData("fvfail/"+pfx+"-trusted.lpt-trust-phase2.ts"))
+	assert.Equal([]byte("b"), mem.GetData("fvfail/"+pfx+"-unknown-untrust-phase2.ts"))
+}
+
+func TestSignature_NeedsSignatures(t *testing.T) {
+	assert := assert.New(t)
+
+	var p *Policy
+	assert.False(p.NeedsSignatures())
+	assert.False((&Policy{}).NeedsSignatures())
+	assert.False((&Policy{Verifier: &QualityVerifier{}}).NeedsSignatures())
+	assert.True((&Policy{Verifier: &SignatureVerifier{}}).NeedsSignatures())
+}
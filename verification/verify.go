@@ -1,9 +1,14 @@
 package verification
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"sort"
 
@@ -83,11 +88,51 @@ type Policy struct {
 	// Maximum number of retries until the policy chooses a winner
 	Retries int
 
-	// How often to invoke the verifier, on a per-segment basis
-	SampleRate float64 // XXX for later
+	// How often to invoke the verifier, on a per-segment basis. The rate is
+	// the fraction of segments to verify; 0 or 1 verifies every segment
+	SampleRate float64
 
-	// How many parallel transcodes to support
-	Redundancy int // XXX for later
+	// How many parallel transcodes to support. Segments are transcoded by
+	// one trusted and Redundancy - 1 untrusted orchestrators whose results
+	// are compared; values below 2 disable parallel transcodes
+	Redundancy int
+}
+
+// sampleKey is the secret of this gateway that keys the sampling of
+// segments, so that orchestrators cannot predict which segments are verified
+var sampleKey = newSampleKey()
+
+func newSampleKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// ShouldVerify returns whether a segment is sampled for verification.
+// Sampling is seeded by the HMAC of the source segment keyed by the secret
+// of the gateway, so the decision is the same every time the segment is
+// verified but cannot be predicted by orchestrators.
+func (p *Policy) ShouldVerify(source []byte) bool {
+	if p.SampleRate <= 0 || p.SampleRate >= 1 {
+		return true
+	}
+	mac := hmac.New(sha256.New, sampleKey)
+	mac.Write(source)
+	h := mac.Sum(nil)
+	sample := float64(binary.BigEndian.Uint64(h[:8])) / math.MaxUint64
+	return sample < p.SampleRate
+}
+
+// NeedsSignatures returns whether the verifier compares MPEG-7 signatures,
+// in which case orchestrators are asked to compute them for sampled segments
+func (p *Policy) NeedsSignatures() bool {
+	if p == nil {
+		return false
+	}
+	_, ok := p.Verifier.(*SignatureVerifier)
+	return ok
 }
 
 type SegmentVerifierResults struct {
@@ -123,11 +168,14 @@ func (sv *SegmentVerifier) Verify(params *Params) (*Params, error) {
 		return nil, nil
 	}
 
+	// Segments that are not sampled are accepted without verification
+	if params.Source != nil && !sv.policy.ShouldVerify(params.Source.Data) {
+		return params, nil
+	}
+
 	var err error
 	res := &Results{}
 
-	// TODO Use policy sampling rate to determine whether to invoke verifier.
-	//      If not, exit early. Seed sample using source data for repeatability!
 	if sv.policy.Verifier != nil {
 		res, err = sv.policy.Verifier.Verify(params)
 	}
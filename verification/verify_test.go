@@ -3,6 +3,7 @@ package verification
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -16,6 +17,7 @@ import (
 	"github.com/livepeer/go-livepeer/pm"
 	"github.com/livepeer/go-tools/drivers"
 	"github.com/livepeer/lpms/ffmpeg"
+	"github.com/livepeer/lpms/stream"
 )
 
 func TestFatalRetryable(t *testing.T) {
@@ -303,6 +305,70 @@ func TestVerify(t *testing.T) {
 	assert.Nil(res)
 }
 
+func TestShouldVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	// Every segment is verified without a sample rate
+	p := &Policy{}
+	assert.True(p.ShouldVerify([]byte("abc")))
+	p.SampleRate = 1
+	assert.True(p.ShouldVerify([]byte("abc")))
+
+	// The decision is the same for the same source
+	p.SampleRate = 0.25
+	sampled := 0
+	for i := 0; i < 1000; i++ {
+		source := []byte(fmt.Sprintf("segment %d", i))
+		verify := p.ShouldVerify(source)
+		assert.Equal(verify, p.ShouldVerify(source))
+		if verify {
+			sampled++
+		}
+	}
+	assert.Greater(sampled, 150)
+	assert.Less(sampled, 350)
+
+	// The decision depends on the secret of the gateway
+	oldKey := sampleKey
+	defer func() { sampleKey = oldKey }()
+	var differ bool
+	for i := 0; i < 100 && !differ; i++ {
+		source := []byte(fmt.Sprintf("segment %d", i))
+		verify := p.ShouldVerify(source)
+		sampleKey = newSampleKey()
+		differ = verify != p.ShouldVerify(source)
+		sampleKey = oldKey
+	}
+	assert.True(differ)
+}
+
+func TestVerify_Sampling(t *testing.T) {
+	assert := assert.New(t)
+
+	verifier := &stubVerifier{err: errors.New("Stub Verifier Error")}
+	policy := &Policy{Verifier: verifier, Retries: 3, SampleRate: 0.5}
+	var verified, skipped *stream.HLSSegment
+	for i := 0; verified == nil || skipped == nil; i++ {
+		seg := &stream.HLSSegment{Data: []byte(fmt.Sprintf("segment %d", i))}
+		if policy.ShouldVerify(seg.Data) {
+			verified = seg
+		} else {
+			skipped = seg
+		}
+	}
+
+	// Segments that are not sampled are accepted without invoking the verifier
+	sv := NewSegmentVerifier(policy)
+	params := &Params{Source: skipped}
+	res, err := sv.Verify(params)
+	assert.Nil(err)
+	assert.Equal(params, res)
+
+	res, err = sv.Verify(&Params{Source: verified})
+	assert.Nil(res)
+	assert.Equal(verifier.err, err)
+}
+
 func TestPixels(t *testing.T) {
 	ffmpeg.InitFFmpeg()
 